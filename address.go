@@ -0,0 +1,128 @@
+package bacnet
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ulbios/bacnet/plumbing"
+)
+
+// Address is the BACnet address of a device: the BACnet/IP node it's reached through and,
+// for devices living on networks behind routers, their network number and MAC address.
+// It implements net.Addr, so the Client takes it wherever it takes a destination.
+type Address struct {
+	Addr net.Addr
+	// Net is zero for devices on the local network.
+	Net uint16
+	MAC []byte
+}
+
+// Network returns the network of the BACnet/IP node the device is reached through.
+func (a *Address) Network() string {
+	return a.Addr.Network()
+}
+
+func (a *Address) String() string {
+	if !a.Routed() {
+		return a.Addr.String()
+	}
+	return fmt.Sprintf("%d:%x@%s", a.Net, a.MAC, a.Addr)
+}
+
+// Routed tells whether the device lives on a remote network.
+func (a *Address) Routed() bool {
+	return a.Net != 0
+}
+
+// Route returns the network number and MAC address of the device along with the BACnet/IP
+// node it's reached through.
+func (a *Address) Route() (uint16, []byte, net.Addr) {
+	return a.Net, a.MAC, a.Addr
+}
+
+// route addresses msg to the network dst lives on and returns where msg must be sent to.
+func route(msg plumbing.BACnet, dst net.Addr) net.Addr {
+	if a, ok := dst.(*Address); ok && a.Routed() {
+		msg.GetNPDU().SetDestination(a.Net, a.MAC)
+	}
+	return physical(dst)
+}
+
+// physical returns the BACnet/IP node messages for dst are sent to.
+func physical(dst net.Addr) net.Addr {
+	if a, ok := dst.(*Address); ok {
+		return a.Addr
+	}
+	return dst
+}
+
+// DeviceInfo is a device binding, as learnt from its last I-Am.
+type DeviceInfo struct {
+	DeviceId     uint32
+	Address      Address
+	MaxAPDU      uint16
+	Segmentation uint8
+	VendorId     uint16
+	LastSeen     time.Time
+}
+
+// maxAPDU returns the size of the largest APDU the device and this Client can both take,
+// as this Client neither sends nor takes segmented messages.
+func (info DeviceInfo) maxAPDU() int {
+	if info.MaxAPDU != 0 && int(info.MaxAPDU) < plumbing.MaxAPDULengthIP {
+		return int(info.MaxAPDU)
+	}
+	return plumbing.MaxAPDULengthIP
+}
+
+// DeviceTable binds device instances to their addresses. It's safe for concurrent use.
+type DeviceTable struct {
+	mu      sync.Mutex
+	devices map[uint32]DeviceInfo
+}
+
+// NewDeviceTable creates an empty DeviceTable.
+func NewDeviceTable() *DeviceTable {
+	return &DeviceTable{devices: map[uint32]DeviceInfo{}}
+}
+
+// Bind adds or updates the binding of a device.
+func (t *DeviceTable) Bind(info DeviceInfo) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.devices[info.DeviceId] = info
+}
+
+// Forget removes the binding of a device.
+func (t *DeviceTable) Forget(deviceId uint32) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.devices, deviceId)
+}
+
+// Lookup returns the binding of a device.
+func (t *DeviceTable) Lookup(deviceId uint32) (DeviceInfo, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	info, ok := t.devices[deviceId]
+	return info, ok
+}
+
+// Devices returns every binding ordered by device instance.
+func (t *DeviceTable) Devices() []DeviceInfo {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	devices := make([]DeviceInfo, 0, len(t.devices))
+	for _, info := range t.devices {
+		devices = append(devices, info)
+	}
+	sort.Slice(devices, func(i, j int) bool { return devices[i].DeviceId < devices[j].DeviceId })
+	return devices
+}
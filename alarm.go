@@ -0,0 +1,219 @@
+package bacnet
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+	"github.com/ulbios/bacnet/services"
+)
+
+// getEventInformationOverhead is the longest encoding of what surrounds the event summaries
+// of GetEventInformation ACKs: the tags enclosing them and the more events flag.
+const getEventInformationOverhead = 2 + 2
+
+// EventBufferSize is the capacity of the channel an AlarmConsole delivers notifications on.
+const EventBufferSize = 64
+
+// EventNotification is an event notification received by an AlarmConsole.
+type EventNotification struct {
+	Source    net.Addr
+	Confirmed bool
+	services.EventNotificationDec
+}
+
+// AlarmConsole receives the event notifications sent to a Client, such as those due to the
+// Notification Classes of devices listing it as a recipient, and acknowledges alarms.
+type AlarmConsole struct {
+	// C delivers notifications unless a callback was given. Notifications are dropped when
+	// it's full.
+	C <-chan EventNotification
+
+	client   *Client
+	callback func(EventNotification)
+	c        chan EventNotification
+}
+
+// NewAlarmConsole creates an AlarmConsole receiving notifications through c, whose read loop
+// must be running. If callback isn't nil, it receives the notifications instead of the
+// channel. It runs on the read loop of the Client, so it must not block.
+func NewAlarmConsole(c *Client, callback func(EventNotification)) *AlarmConsole {
+	ch := make(chan EventNotification, EventBufferSize)
+	a := &AlarmConsole{C: ch, client: c, callback: callback, c: ch}
+
+	c.HandleConfirmed(services.ServiceConfirmedEventNotification, a.confirmedNotification)
+	c.HandleUnconfirmed(services.ServiceUnconfirmedEventNotification, a.unconfirmedNotification)
+
+	return a
+}
+
+// Acknowledge acknowledges the transition notified by n on the device which sent it, telling
+// source acknowledged it.
+func (a *AlarmConsole) Acknowledge(ctx context.Context, n EventNotification, source string) error {
+	req := services.NewConfirmedAcknowledgeAlarm(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+	req.APDU.Objects = services.ConfirmedAcknowledgeAlarmObjects(n.ProcessId, n.Object.ObjectType, n.Object.InstanceNumber,
+		n.ToState, n.TimeStamp, source, timeOfAcknowledge())
+
+	_, err := a.client.Request(ctx, n.Source, req)
+	return err
+}
+
+func (a *AlarmConsole) confirmedNotification(msg plumbing.BACnet, src net.Addr) ([]objects.APDUPayload, error) {
+	dec, err := msg.(*services.ConfirmedEventNotification).Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	a.deliver(EventNotification{Source: src, Confirmed: true, EventNotificationDec: dec})
+	return nil, nil
+}
+
+func (a *AlarmConsole) unconfirmedNotification(msg plumbing.BACnet, src net.Addr) {
+	dec, err := msg.(*services.UnconfirmedEventNotification).Decode()
+	if err != nil {
+		return
+	}
+
+	a.deliver(EventNotification{Source: src, EventNotificationDec: dec})
+}
+
+func (a *AlarmConsole) deliver(n EventNotification) {
+	if a.callback != nil {
+		a.callback(n)
+		return
+	}
+
+	select {
+	case a.c <- n:
+	default:
+	}
+}
+
+// AcknowledgeAlarm acknowledges the transition of an object of a device bound in Devices to
+// an Event_State. The time of acknowledgment is filled in with the current time.
+func (c *Client) AcknowledgeAlarm(ctx context.Context, deviceId uint32, processId uint32, object objects.ObjectIdentifier, eventState uint8, timeStamp services.TimeStamp, source string) error {
+	req := services.NewConfirmedAcknowledgeAlarm(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+	req.APDU.Objects = services.ConfirmedAcknowledgeAlarmObjects(processId, object.ObjectType, object.InstanceNumber,
+		eventState, timeStamp, source, timeOfAcknowledge())
+
+	_, err := c.RequestDevice(ctx, deviceId, req)
+	return err
+}
+
+func timeOfAcknowledge() services.TimeStamp {
+	return services.TimeStamp{Choice: services.TimeStampDateTime, DateTime: objects.DateTimeOf(time.Now())}
+}
+
+// GetEventInformation reads the event summaries of the objects of a device bound in Devices
+// which aren't NORMAL or have transitions yet to be acknowledged, asking for more pages as
+// long as the device has more events.
+func (c *Client) GetEventInformation(ctx context.Context, deviceId uint32) ([]services.EventSummary, error) {
+	summaries := []services.EventSummary{}
+
+	var last *objects.ObjectIdentifier
+	for {
+		req := services.NewConfirmedGetEventInformation(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+		req.APDU.Objects = services.ConfirmedGetEventInformationObjects(last)
+
+		reply, err := c.RequestDevice(ctx, deviceId, req)
+		if err != nil {
+			return summaries, err
+		}
+		cACK, ok := reply.(*services.ComplexACK)
+		if !ok {
+			return summaries, common.ErrWrongStructure
+		}
+		dec, err := cACK.DecodeGetEventInformation()
+		if err != nil {
+			return summaries, err
+		}
+
+		summaries = append(summaries, dec.Summaries...)
+		if !dec.MoreEvents {
+			return summaries, nil
+		}
+		if len(dec.Summaries) == 0 {
+			// The device has more events but can't fit any in a reply.
+			return summaries, common.ErrWrongStructure
+		}
+		last = &dec.Summaries[len(dec.Summaries)-1].Object
+	}
+}
+
+// GetAlarmSummary reads the alarm summaries of the objects of a device bound in Devices
+// which are in alarm.
+func (c *Client) GetAlarmSummary(ctx context.Context, deviceId uint32) ([]services.AlarmSummary, error) {
+	req := services.NewConfirmedGetAlarmSummary(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+
+	reply, err := c.RequestDevice(ctx, deviceId, req)
+	if err != nil {
+		return nil, err
+	}
+	cACK, ok := reply.(*services.ComplexACK)
+	if !ok {
+		return nil, common.ErrWrongStructure
+	}
+	return cACK.DecodeGetAlarmSummary()
+}
+
+// GetEnrollmentSummary reads the enrollment summaries of the objects of a device bound in
+// Devices which report events and match the filter.
+func (c *Client) GetEnrollmentSummary(ctx context.Context, deviceId uint32, filter services.EnrollmentFilter) ([]services.EnrollmentSummary, error) {
+	req := services.NewConfirmedGetEnrollmentSummary(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+	req.APDU.Objects = services.ConfirmedGetEnrollmentSummaryObjects(filter)
+
+	reply, err := c.RequestDevice(ctx, deviceId, req)
+	if err != nil {
+		return nil, err
+	}
+	cACK, ok := reply.(*services.ComplexACK)
+	if !ok {
+		return nil, common.ErrWrongStructure
+	}
+	return cACK.DecodeGetEnrollmentSummary()
+}
+
+func (s *Server) acknowledgeAlarm(msg plumbing.BACnet, src net.Addr) ([]objects.APDUPayload, error) {
+	req, err := msg.(*services.ConfirmedAcknowledgeAlarm).Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, s.Device.AcknowledgeAlarm(req)
+}
+
+func (s *Server) getEventInformation(msg plumbing.BACnet, src net.Addr) ([]objects.APDUPayload, error) {
+	c := msg.(*services.ConfirmedGetEventInformation)
+	req, err := c.Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	maxLen := plumbing.DecMaxAPDU(c.APDU.MaxSize)
+	if maxLen > plumbing.MaxAPDULengthIP {
+		maxLen = plumbing.MaxAPDULengthIP
+	}
+	maxLen -= apduHeaderLen(plumbing.ComplexAck) + getEventInformationOverhead
+
+	summaries, more, err := s.Device.EventInformation(req.LastReceivedObject, maxLen)
+	if err != nil {
+		return nil, err
+	}
+	return services.GetEventInformationACKObjects(summaries, more), nil
+}
+
+func (s *Server) getAlarmSummary(msg plumbing.BACnet, src net.Addr) ([]objects.APDUPayload, error) {
+	return services.GetAlarmSummaryACKObjects(s.Device.AlarmSummaries()), nil
+}
+
+func (s *Server) getEnrollmentSummary(msg plumbing.BACnet, src net.Addr) ([]objects.APDUPayload, error) {
+	req, err := msg.(*services.ConfirmedGetEnrollmentSummary).Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	return services.GetEnrollmentSummaryACKObjects(s.Device.EnrollmentSummaries(req.EnrollmentFilter)), nil
+}
@@ -0,0 +1,244 @@
+package bacnet
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+	"github.com/ulbios/bacnet/services"
+)
+
+// estimatedValueLen is the encoded length assumed for each value read when packing points
+// into ReadPropertyMultiple requests. Batches whose replies turn out bigger are split.
+const estimatedValueLen = 16
+
+// Encoded lengths of the parts of ReadPropertyMultiple requests and replies.
+const (
+	// rpmObjectLen covers an object identifier and the tags enclosing its properties.
+	rpmObjectLen = 5 + 2
+	// rpmResultLen covers the tags enclosing the value of a property.
+	rpmResultLen = 2
+)
+
+// Point is a property of an object living on a device bound in the Devices of a Client.
+// It must name a single property rather than objects.PropertyIdAll and the like.
+type Point struct {
+	DeviceId   uint32
+	ObjectType uint16
+	InstanceId uint32
+	PropertyId uint32
+	// ArrayIndex is objects.ArrayAll to read the property as a whole.
+	ArrayIndex uint32
+}
+
+// PointValue is the outcome of reading a Point.
+type PointValue struct {
+	Point
+	Values []objects.APDUPayload
+	// Err is a *common.BACnetError if the device couldn't read the property, or whatever
+	// kept the request from completing otherwise.
+	Err error
+}
+
+// ReadPoints reads points off the devices bound in Devices, the devices being read from
+// concurrently. The points of each device are packed into the fewest ReadPropertyMultiple
+// requests whose requests and estimated replies fit in a single APDU of the size the device
+// accepts, as this Client doesn't take segmented replies. Batches aborted for their replies
+// not fitting are split and retried, and devices rejecting ReadPropertyMultiple are read
+// property by property. Values are returned in the order of points. The error is the one
+// of ctx if it's done before every point is read.
+func (c *Client) ReadPoints(ctx context.Context, points []Point) ([]PointValue, error) {
+	results := make([]PointValue, len(points))
+	devices := []uint32{}
+	byDevice := map[uint32][]int{}
+	for i, p := range points {
+		results[i].Point = p
+		if _, ok := byDevice[p.DeviceId]; !ok {
+			devices = append(devices, p.DeviceId)
+		}
+		byDevice[p.DeviceId] = append(byDevice[p.DeviceId], i)
+	}
+
+	var wg sync.WaitGroup
+	for _, deviceId := range devices {
+		wg.Add(1)
+		go func(indexes []int) {
+			defer wg.Done()
+			c.readDevicePoints(ctx, results, indexes)
+		}(byDevice[deviceId])
+	}
+	wg.Wait()
+
+	return results, ctx.Err()
+}
+
+// readDevicePoints reads the results with the given indexes, which belong to a single device.
+func (c *Client) readDevicePoints(ctx context.Context, results []PointValue, indexes []int) {
+	deviceId := results[indexes[0]].DeviceId
+	info, ok := c.Devices.Lookup(deviceId)
+	if !ok {
+		for _, i := range indexes {
+			results[i].Err = common.ErrUnknownDevice
+		}
+		return
+	}
+
+	rpm := true
+	for _, batch := range packPoints(results, indexes, info.maxAPDU()) {
+		if rpm {
+			var rErr *common.RejectError
+			if err := c.readBatch(ctx, results, batch); !errors.As(err, &rErr) {
+				continue
+			}
+			rpm = false
+		}
+
+		for _, i := range batch {
+			p := &results[i]
+			p.Values, p.Err = c.readProperty(ctx, deviceId, p.ObjectType, p.InstanceId, p.PropertyId, p.ArrayIndex)
+		}
+	}
+}
+
+// packPoints sorts the results with the given indexes by object and splits them into batches
+// whose ReadPropertyMultiple requests and estimated replies fit in maxAPDU.
+func packPoints(results []PointValue, indexes []int, maxAPDU int) [][]int {
+	sorted := append([]int{}, indexes...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := results[sorted[i]].Point, results[sorted[j]].Point
+		if a.ObjectType != b.ObjectType {
+			return a.ObjectType < b.ObjectType
+		}
+		return a.InstanceId < b.InstanceId
+	})
+
+	batches := [][]int{}
+	var batch []int
+	var reqLen, replyLen int
+	for _, i := range sorted {
+		p := results[i].Point
+
+		cost := func() (int, int) {
+			l := payloadsLen(services.EncPropertyReference(services.PropertyReference{PropertyId: p.PropertyId, ArrayIndex: p.ArrayIndex}))
+			req, reply := l, l+rpmResultLen+estimatedValueLen
+			if len(batch) == 0 || !sameObject(results[batch[len(batch)-1]].Point, p) {
+				req += rpmObjectLen
+				reply += rpmObjectLen
+			}
+			return req, reply
+		}
+
+		req, reply := cost()
+		if len(batch) > 0 && (reqLen+req > maxAPDU || replyLen+reply > maxAPDU) {
+			batches = append(batches, batch)
+			batch = nil
+		}
+		if len(batch) == 0 {
+			reqLen, replyLen = apduHeaderLen(plumbing.ConfirmedReq), apduHeaderLen(plumbing.ComplexAck)
+			req, reply = cost()
+		}
+
+		batch = append(batch, i)
+		reqLen += req
+		replyLen += reply
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+
+	return batches
+}
+
+// readBatch reads the results of a batch with ReadPropertyMultiple, splitting it when the
+// reply is aborted. It only returns an error if the request is rejected, which leaves the
+// results untouched.
+func (c *Client) readBatch(ctx context.Context, results []PointValue, batch []int) error {
+	deviceId := results[batch[0]].DeviceId
+
+	var specs []services.ReadAccessSpecification
+	for _, i := range batch {
+		p := results[i].Point
+		if n := len(specs); n == 0 || specs[n-1].ObjectType != p.ObjectType || specs[n-1].InstanceId != p.InstanceId {
+			specs = append(specs, services.ReadAccessSpecification{ObjectType: p.ObjectType, InstanceId: p.InstanceId})
+		}
+		spec := &specs[len(specs)-1]
+		spec.Properties = append(spec.Properties, services.PropertyReference{PropertyId: p.PropertyId, ArrayIndex: p.ArrayIndex})
+	}
+
+	req := services.NewConfirmedReadPropertyMultiple(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+	req.APDU.Objects = services.ConfirmedReadPropertyMultipleObjects(specs)
+
+	reply, err := c.RequestDevice(ctx, deviceId, req)
+
+	var aErr *common.AbortError
+	var rErr *common.RejectError
+	switch {
+	case errors.As(err, &aErr) && len(batch) > 1:
+		half := len(batch) / 2
+		if err := c.readBatch(ctx, results, batch[:half]); err != nil {
+			return err
+		}
+		return c.readBatch(ctx, results, batch[half:])
+	case errors.As(err, &rErr):
+		return err
+	case err == nil:
+		err = unpackBatch(results, batch, reply)
+	}
+
+	if err != nil {
+		for _, i := range batch {
+			results[i].Err = err
+		}
+	}
+	return nil
+}
+
+// unpackBatch hands the values of a ReadPropertyMultiple reply to the results of the batch
+// it was built from, as they come in the same order.
+func unpackBatch(results []PointValue, batch []int, reply plumbing.BACnet) error {
+	cACK, ok := reply.(*services.ComplexACK)
+	if !ok {
+		return common.ErrWrongStructure
+	}
+	dec, err := cACK.DecodeReadPropertyMultiple()
+	if err != nil {
+		return err
+	}
+
+	var read []services.ReadResult
+	for _, result := range dec.Results {
+		read = append(read, result.Results...)
+	}
+	if len(read) != len(batch) {
+		return common.ErrWrongObjectCount
+	}
+
+	for n, i := range batch {
+		if read[n].PropertyId != results[i].PropertyId {
+			return common.ErrWrongStructure
+		}
+	}
+	for n, i := range batch {
+		results[i].Values = read[n].Values
+		if read[n].Error != nil {
+			results[i].Err = read[n].Error
+		}
+	}
+	return nil
+}
+
+func sameObject(a, b Point) bool {
+	return a.ObjectType == b.ObjectType && a.InstanceId == b.InstanceId
+}
+
+func payloadsLen(payloads []objects.APDUPayload) int {
+	l := 0
+	for _, payload := range payloads {
+		l += payload.MarshalLen()
+	}
+	return l
+}
@@ -0,0 +1,583 @@
+package bacnet
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+	"github.com/ulbios/bacnet/services"
+)
+
+const maxPacketLen = 1500
+
+// DefaultPort is the UDP port BACnet/IP devices listen on by default.
+const DefaultPort = 0xBAC0
+
+// npduExpectingReply is the NPDU control bit flagging confirmed requests.
+const npduExpectingReply = 1 << 2
+
+// abortServer is the APDU flag telling Aborts sent by servers apart from those sent by clients.
+const abortServer = 1
+
+// ConfirmedHandler serves a confirmed request. Returning nil payloads yields a Simple ACK
+// and anything else a Complex ACK carrying them. Errors of type *common.BACnetError and
+// *common.RejectError are reported back as Error and Reject PDUs respectively, the Error PDU
+// of *common.WritePropertyMultipleError naming the failed property as well and those of
+// *common.CreateObjectError and *common.ChangeListError the failed element.
+type ConfirmedHandler func(msg plumbing.BACnet, src net.Addr) ([]objects.APDUPayload, error)
+
+// UnconfirmedHandler serves an unconfirmed request.
+type UnconfirmedHandler func(msg plumbing.BACnet, src net.Addr)
+
+// watcher is an unconfirmed handler registered for a while only.
+type watcher struct {
+	service uint8
+	h       UnconfirmedHandler
+}
+
+type pendingKey struct {
+	peer     string
+	invokeID uint8
+}
+
+// Client issues confirmed requests over BACnet/IP and matches their replies. It also
+// serves the requests peers send its way through the registered handlers. Handlers run
+// on the read loop, so they must not block nor wait for replies themselves.
+type Client struct {
+	// Timeout and Retries drive the retransmission of confirmed requests.
+	Timeout time.Duration
+	Retries int
+	// BroadcastAddr is where broadcasts are sent. When nil, they go to the limited broadcast
+	// address on the port the Client is bound to.
+	BroadcastAddr net.Addr
+	// Devices binds device instances to their addresses for SendDevice and RequestDevice.
+	// A Discoverer keeps it up to date.
+	Devices *DeviceTable
+
+	conn net.PacketConn
+	tsm  *plumbing.ServerTSM
+	// communication returns the state DeviceCommunicationControl left the Client in. It's
+	// nil for Clients that don't honour it.
+	communication func() uint8
+
+	mu          sync.Mutex
+	invokeIDs   map[string]uint8
+	pending     map[pendingKey]chan plumbing.BACnet
+	confirmed   map[uint8]ConfirmedHandler
+	unconfirmed map[uint8][]UnconfirmedHandler
+	watchers    map[*watcher]struct{}
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+// NewClient creates a Client talking over conn. Call Run to start processing incoming messages.
+func NewClient(conn net.PacketConn) *Client {
+	return &Client{
+		Timeout:     plumbing.DefaultAPDUTimeout,
+		Retries:     plumbing.DefaultAPDURetries,
+		Devices:     NewDeviceTable(),
+		conn:        conn,
+		tsm:         plumbing.NewServerTSM(0),
+		invokeIDs:   map[string]uint8{},
+		pending:     map[pendingKey]chan plumbing.BACnet{},
+		confirmed:   map[uint8]ConfirmedHandler{},
+		unconfirmed: map[uint8][]UnconfirmedHandler{},
+		watchers:    map[*watcher]struct{}{},
+		closed:      make(chan struct{}),
+	}
+}
+
+// HandleConfirmed registers the handler for a confirmed service, replacing any previous one.
+func (c *Client) HandleConfirmed(service uint8, h ConfirmedHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.confirmed[service] = h
+}
+
+// HandleUnconfirmed registers an additional handler for an unconfirmed service.
+func (c *Client) HandleUnconfirmed(service uint8, h UnconfirmedHandler) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.unconfirmed[service] = append(c.unconfirmed[service], h)
+}
+
+// watch registers a handler for an unconfirmed service until the returned function is called.
+func (c *Client) watch(service uint8, h UnconfirmedHandler) (stop func()) {
+	w := &watcher{service: service, h: h}
+
+	c.mu.Lock()
+	c.watchers[w] = struct{}{}
+	c.mu.Unlock()
+
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+
+		delete(c.watchers, w)
+	}
+}
+
+// Run reads and processes incoming messages until the Client is closed.
+func (c *Client) Run() error {
+	b := make([]byte, maxPacketLen)
+	for {
+		n, src, err := c.conn.ReadFrom(b)
+		if err != nil {
+			select {
+			case <-c.closed:
+				return nil
+			default:
+				return err
+			}
+		}
+		c.process(append([]byte{}, b[:n]...), src)
+	}
+}
+
+// Close closes the underlying connection, failing every ongoing request.
+func (c *Client) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		err = c.conn.Close()
+	})
+	return err
+}
+
+// LocalAddr returns the address the Client is bound to.
+func (c *Client) LocalAddr() net.Addr {
+	return c.conn.LocalAddr()
+}
+
+// Send sends a message expecting no reply, such as an unconfirmed request. Messages sent
+// to an *Address on a remote network are routed to it.
+func (c *Client) Send(dst net.Addr, msg plumbing.BACnet) error {
+	if !c.mayInitiate() {
+		return common.ErrCommunicationDisabled
+	}
+	return c.send(dst, msg)
+}
+
+// send sends a message whatever the communication state, which answers are allowed to do.
+func (c *Client) send(dst net.Addr, msg plumbing.BACnet) error {
+	to := route(msg, dst)
+	msg.GetBVLC().Length = uint16(msg.MarshalLen())
+
+	b, err := msg.MarshalBinary()
+	if err != nil {
+		return err
+	}
+
+	_, err = c.conn.WriteTo(b, to)
+	return err
+}
+
+// SendDevice sends a message expecting no reply to a device bound in Devices.
+func (c *Client) SendDevice(deviceId uint32, msg plumbing.BACnet) error {
+	info, ok := c.Devices.Lookup(deviceId)
+	if !ok {
+		return common.ErrUnknownDevice
+	}
+	return c.Send(&info.Address, msg)
+}
+
+// Broadcast sends a message to every device on the local network.
+func (c *Client) Broadcast(msg plumbing.BACnet) error {
+	if !c.mayInitiate() {
+		return common.ErrCommunicationDisabled
+	}
+	return c.broadcast(msg)
+}
+
+func (c *Client) broadcast(msg plumbing.BACnet) error {
+	msg.GetBVLC().Function = plumbing.BVLCFuncBroadcast
+	return c.send(c.broadcastAddr(), msg)
+}
+
+func (c *Client) communicationState() uint8 {
+	if c.communication == nil {
+		return objects.CommunicationEnable
+	}
+	return c.communication()
+}
+
+// mayInitiate tells whether DeviceCommunicationControl lets the Client initiate requests.
+func (c *Client) mayInitiate() bool {
+	return c.communicationState() == objects.CommunicationEnable
+}
+
+func (c *Client) broadcastAddr() net.Addr {
+	if c.BroadcastAddr != nil {
+		return c.BroadcastAddr
+	}
+
+	port := DefaultPort
+	if udpAddr, ok := c.conn.LocalAddr().(*net.UDPAddr); ok && udpAddr.Port != 0 {
+		port = udpAddr.Port
+	}
+	return &net.UDPAddr{IP: net.IPv4bcast, Port: port}
+}
+
+// Request sends a confirmed request and waits for its reply, retransmitting it on timeouts.
+// Error, Reject and Abort PDUs are returned as *common.BACnetError, *common.RejectError and
+// *common.AbortError respectively, WritePropertyMultiple, CreateObject and list change
+// errors being returned as *common.WritePropertyMultipleError, *common.CreateObjectError and
+// *common.ChangeListError. Requests sent to an *Address on a remote network are
+// routed to it.
+func (c *Client) Request(ctx context.Context, dst net.Addr, req plumbing.BACnet) (plumbing.BACnet, error) {
+	if !c.mayInitiate() {
+		return nil, common.ErrCommunicationDisabled
+	}
+
+	key, replies, err := c.begin(dst)
+	if err != nil {
+		return nil, err
+	}
+	defer c.end(key)
+
+	to := route(req, dst)
+
+	apdu := req.GetAPDU()
+	apdu.Type = plumbing.ConfirmedReq
+	apdu.MaxSize = plumbing.EncMaxAPDU(plumbing.MaxAPDULengthIP)
+	apdu.InvokeID = key.invokeID
+	req.GetNPDU().Control |= npduExpectingReply
+	req.GetBVLC().Length = uint16(req.MarshalLen())
+
+	b, err := req.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; attempt <= c.Retries; attempt++ {
+		if _, err := c.conn.WriteTo(b, to); err != nil {
+			return nil, err
+		}
+
+		timer := time.NewTimer(c.Timeout)
+		select {
+		case reply := <-replies:
+			timer.Stop()
+			return reply, replyError(reply)
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-c.closed:
+			timer.Stop()
+			return nil, common.ErrClosed
+		}
+	}
+
+	return nil, common.ErrTimeout
+}
+
+// RequestDevice sends a confirmed request to a device bound in Devices and waits for its
+// reply as Request does.
+func (c *Client) RequestDevice(ctx context.Context, deviceId uint32, req plumbing.BACnet) (plumbing.BACnet, error) {
+	info, ok := c.Devices.Lookup(deviceId)
+	if !ok {
+		return nil, common.ErrUnknownDevice
+	}
+	return c.Request(ctx, &info.Address, req)
+}
+
+// begin allocates an invoke ID for a new transaction with dst.
+func (c *Client) begin(dst net.Addr) (pendingKey, chan plumbing.BACnet, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	peer := dst.String()
+	for i := 0; i < 256; i++ {
+		key := pendingKey{peer: peer, invokeID: c.invokeIDs[peer]}
+		c.invokeIDs[peer]++
+
+		if _, busy := c.pending[key]; !busy {
+			replies := make(chan plumbing.BACnet, 1)
+			c.pending[key] = replies
+			return key, replies, nil
+		}
+	}
+
+	return pendingKey{}, nil, common.ErrNoInvokeID
+}
+
+func (c *Client) end(key pendingKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.pending, key)
+}
+
+// replyError turns Error, Reject and Abort PDUs into errors.
+func replyError(reply plumbing.BACnet) error {
+	switch r := reply.(type) {
+	case *services.Error:
+		if r.APDU.Service == services.ServiceConfirmedWritePropMultiple {
+			dec, err := r.DecodeWritePropertyMultiple()
+			if err != nil {
+				return err
+			}
+			return &common.WritePropertyMultipleError{
+				BACnetError: common.BACnetError{Class: dec.ErrorClass, Code: dec.ErrorCode},
+				ObjectType:  dec.ObjectType,
+				InstanceId:  dec.InstanceId,
+				PropertyId:  dec.PropertyId,
+				ArrayIndex:  dec.ArrayIndex,
+			}
+		}
+		if r.APDU.Service == services.ServiceConfirmedCreateObject {
+			dec, err := r.DecodeCreateObject()
+			if err != nil {
+				return err
+			}
+			return &common.CreateObjectError{
+				BACnetError:        common.BACnetError{Class: dec.ErrorClass, Code: dec.ErrorCode},
+				FirstFailedElement: dec.FirstFailedElement,
+			}
+		}
+		if r.APDU.Service == services.ServiceConfirmedAddListElement || r.APDU.Service == services.ServiceConfirmedRemoveListElement {
+			dec, err := r.DecodeChangeList()
+			if err != nil {
+				return err
+			}
+			return &common.ChangeListError{
+				BACnetError:        common.BACnetError{Class: dec.ErrorClass, Code: dec.ErrorCode},
+				FirstFailedElement: dec.FirstFailedElement,
+			}
+		}
+		dec, err := r.Decode()
+		if err != nil {
+			return err
+		}
+		return &common.BACnetError{Class: dec.ErrorClass, Code: dec.ErrorCode}
+	case *services.Reject:
+		return &common.RejectError{Reason: r.APDU.Service}
+	case *services.Abort:
+		return &common.AbortError{Reason: r.APDU.Service}
+	}
+	return nil
+}
+
+func (c *Client) process(b []byte, src net.Addr) {
+	disabled := c.communicationState() == objects.CommunicationDisable
+
+	msg, err := Parse(b)
+	if err != nil {
+		// Confirmed requests we can't make sense of are rejected so that peers don't
+		// keep on retrying them.
+		if invokeID, ok := confirmedInvokeID(b); ok && !disabled {
+			reason := plumbing.RejectReasonInvalidTag
+			if errors.Is(err, common.ErrNotImplemented) {
+				reason = plumbing.RejectReasonUnrecognizedService
+			}
+			c.send(src, newReject(invokeID, reason))
+		}
+		return
+	}
+
+	// Only DeviceCommunicationControl and ReinitializeDevice get through while disabled.
+	if apdu := msg.GetAPDU(); disabled && (apdu.Type != plumbing.ConfirmedReq ||
+		apdu.Service != services.ServiceConfirmedDeviceCommunicationControl && apdu.Service != services.ServiceConfirmedReinitializeDevice) {
+		return
+	}
+
+	// Messages coming from remote networks are answered through the router they came from.
+	if npdu := msg.GetNPDU(); npdu.HasSource() {
+		src = &Address{Addr: src, Net: npdu.SNET, MAC: npdu.SADR}
+	}
+
+	apdu := msg.GetAPDU()
+	switch apdu.Type {
+	case plumbing.UnConfirmedReq:
+		c.mu.Lock()
+		handlers := append([]UnconfirmedHandler{}, c.unconfirmed[apdu.Service]...)
+		for w := range c.watchers {
+			if w.service == apdu.Service {
+				handlers = append(handlers, w.h)
+			}
+		}
+		c.mu.Unlock()
+
+		for _, h := range handlers {
+			h(msg, src)
+		}
+	case plumbing.ConfirmedReq:
+		c.serveConfirmed(msg, src)
+	default:
+		c.mu.Lock()
+		replies, ok := c.pending[pendingKey{peer: src.String(), invokeID: apdu.InvokeID}]
+		c.mu.Unlock()
+
+		if ok {
+			select {
+			case replies <- msg:
+			default:
+			}
+		}
+	}
+}
+
+func (c *Client) serveConfirmed(msg plumbing.BACnet, src net.Addr) {
+	apdu := msg.GetAPDU()
+
+	state, cached := c.tsm.Begin(src, apdu)
+	switch state {
+	case plumbing.TransactionAwaitResponse:
+		return
+	case plumbing.TransactionResponded:
+		c.conn.WriteTo(cached, physical(src))
+		return
+	}
+
+	c.mu.Lock()
+	h := c.confirmed[apdu.Service]
+	c.mu.Unlock()
+
+	var reply plumbing.BACnet
+	if h == nil {
+		reply = newReject(apdu.InvokeID, plumbing.RejectReasonUnrecognizedService)
+	} else {
+		payloads, err := h(msg, src)
+		reply = newReply(apdu, payloads, err)
+	}
+
+	// Replies are never segmented, so those the requester can't take in a single APDU are aborted.
+	if reply.GetAPDU().MarshalLen() > plumbing.DecMaxAPDU(apdu.MaxSize) {
+		reply = newAbort(apdu.InvokeID, plumbing.AbortReasonSegmentationNotSupported)
+	}
+
+	to := route(reply, src)
+	reply.GetBVLC().Length = uint16(reply.MarshalLen())
+
+	b, err := reply.MarshalBinary()
+	if err != nil {
+		c.tsm.Abandon(src, apdu)
+		return
+	}
+	c.tsm.Complete(src, apdu, b)
+	c.conn.WriteTo(b, to)
+}
+
+// newReply builds the answer to a confirmed request out of what its handler returned.
+func newReply(req *plumbing.APDU, payloads []objects.APDUPayload, err error) plumbing.BACnet {
+	bvlc := plumbing.NewBVLC(plumbing.BVLCFuncUnicast)
+	npdu := plumbing.NewNPDU(false, false, false, false)
+
+	var wErr *common.WritePropertyMultipleError
+	var cErr *common.CreateObjectError
+	var lErr *common.ChangeListError
+	var bErr *common.BACnetError
+	var rErr *common.RejectError
+	switch {
+	case errors.As(err, &wErr):
+		e := services.NewError(bvlc, npdu)
+		e.APDU.Service = req.Service
+		e.APDU.InvokeID = req.InvokeID
+		e.APDU.Objects = services.WritePropertyMultipleErrorObjects(
+			wErr.Class, wErr.Code, wErr.ObjectType, wErr.InstanceId, wErr.PropertyId, wErr.ArrayIndex)
+		e.SetLength()
+		return e
+	case errors.As(err, &cErr):
+		e := services.NewError(bvlc, npdu)
+		e.APDU.Service = req.Service
+		e.APDU.InvokeID = req.InvokeID
+		e.APDU.Objects = services.CreateObjectErrorObjects(cErr.Class, cErr.Code, cErr.FirstFailedElement)
+		e.SetLength()
+		return e
+	case errors.As(err, &lErr):
+		e := services.NewError(bvlc, npdu)
+		e.APDU.Service = req.Service
+		e.APDU.InvokeID = req.InvokeID
+		e.APDU.Objects = services.ChangeListErrorObjects(lErr.Class, lErr.Code, lErr.FirstFailedElement)
+		e.SetLength()
+		return e
+	case errors.As(err, &bErr):
+		e := services.NewError(bvlc, npdu)
+		e.APDU.Service = req.Service
+		e.APDU.InvokeID = req.InvokeID
+		e.APDU.Objects = services.ErrorObjects(bErr.Class, bErr.Code)
+		e.SetLength()
+		return e
+	case errors.As(err, &rErr):
+		return newReject(req.InvokeID, rErr.Reason)
+	case err != nil:
+		return newReject(req.InvokeID, rejectReason(err))
+	case payloads == nil:
+		a := services.NewSimpleACK(bvlc, npdu)
+		a.APDU.Service = req.Service
+		a.APDU.InvokeID = req.InvokeID
+		a.SetLength()
+		return a
+	}
+
+	c := services.NewComplexACK(bvlc, npdu)
+	c.APDU.Service = req.Service
+	c.APDU.InvokeID = req.InvokeID
+	c.APDU.Objects = payloads
+	c.SetLength()
+	return c
+}
+
+func newReject(invokeID uint8, reason uint8) *services.Reject {
+	r := services.NewReject(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, false))
+	r.APDU.InvokeID = invokeID
+	r.APDU.Service = reason
+	r.SetLength()
+	return r
+}
+
+// newAbort creates an Abort sent by the server side of a transaction.
+func newAbort(invokeID uint8, reason uint8) *services.Abort {
+	a := services.NewAbort(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, false))
+	a.APDU.Flags = abortServer
+	a.APDU.InvokeID = invokeID
+	a.APDU.Service = reason
+	a.SetLength()
+	return a
+}
+
+// rejectReason maps the errors decoding requests yield to reject reasons.
+func rejectReason(err error) uint8 {
+	switch {
+	case errors.Is(err, common.ErrWrongObjectCount):
+		return plumbing.RejectReasonMissingRequiredParameter
+	case errors.Is(err, common.ErrWrongTagNumber), errors.Is(err, common.ErrWrongStructure):
+		return plumbing.RejectReasonInvalidTag
+	case errors.Is(err, common.ErrWrongPayload):
+		return plumbing.RejectReasonInvalidParameterDataType
+	case errors.Is(err, common.ErrUndefinedEnumeration):
+		return plumbing.RejectReasonUndefinedEnumeration
+	case errors.Is(err, common.ErrTooBigValue):
+		return plumbing.RejectReasonParameterOutOfRange
+	}
+	return plumbing.RejectReasonOther
+}
+
+// confirmedInvokeID returns the invoke ID of b if it looks like a confirmed request.
+func confirmedInvokeID(b []byte) (uint8, bool) {
+	var bvlc plumbing.BVLC
+	var npdu plumbing.NPDU
+
+	if err := bvlc.UnmarshalBinary(b); err != nil {
+		return 0, false
+	}
+	offset := bvlc.MarshalLen()
+	if err := npdu.UnmarshalBinary(b[offset:]); err != nil {
+		return 0, false
+	}
+	offset += npdu.MarshalLen()
+
+	if len(b) < offset+apduHeaderLen(plumbing.ConfirmedReq) || b[offset]>>4 != plumbing.ConfirmedReq {
+		return 0, false
+	}
+	return b[offset+2], true
+}
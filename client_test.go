@@ -0,0 +1,1841 @@
+package bacnet_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ulbios/bacnet"
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/device"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+	"github.com/ulbios/bacnet/services"
+)
+
+func newTestClient(t *testing.T) *bacnet.Client {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := bacnet.NewClient(conn)
+	c.Timeout = 200 * time.Millisecond
+	go c.Run()
+	t.Cleanup(func() { c.Close() })
+
+	return c
+}
+
+// newTestServer starts serving dev once the configuration functions have been applied.
+func newTestServer(t *testing.T, dev *device.Device, configure ...func(*bacnet.Server)) (*bacnet.Server, net.Addr) {
+	t.Helper()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s := bacnet.NewServer(conn, dev)
+	s.Timeout = 200 * time.Millisecond
+	for _, f := range configure {
+		f(s)
+	}
+	go s.Serve()
+	t.Cleanup(func() { s.Close() })
+
+	return s, conn.LocalAddr()
+}
+
+func TestClientRequest(t *testing.T) {
+	dev := device.New(321, "dev", 31)
+	ao := device.NewAnalogOutput(1, "AO-1", objects.UnitsNoUnits)
+	if err := ao.Set(objects.PropertyIdPresentValue, float32(2.5)); err != nil {
+		t.Fatal(err)
+	}
+	if err := dev.Add(ao); err != nil {
+		t.Fatal(err)
+	}
+
+	_, addr := newTestServer(t, dev)
+	c := newTestClient(t)
+
+	readProperty := func(instance uint32) (plumbing.BACnet, error) {
+		req := services.NewConfirmedReadProperty(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+		req.APDU.Objects = services.ConfirmedReadPropertyObjects(
+			objects.ObjectTypeAnalogOutput, instance, objects.PropertyIdPresentValue, objects.ArrayAll)
+		return c.Request(context.Background(), addr, req)
+	}
+
+	reply, err := readProperty(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec, err := reply.(*services.ComplexACK).Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dec.PresentValue != 2.5 {
+		t.Errorf("got Present_Value %v, want 2.5", dec.PresentValue)
+	}
+
+	_, err = readProperty(2)
+	var bErr *common.BACnetError
+	if !errors.As(err, &bErr) || bErr.Code != objects.ErrorCodeUnknownObject {
+		t.Errorf("got error %v, want an unknown object error", err)
+	}
+}
+
+func TestCOVManager(t *testing.T) {
+	// The peer plays the part of a device keeping track of subscriptions.
+	peer := newTestClient(t)
+	subscriptions := make(chan services.ConfirmedSubscribeCOVDec, 8)
+	peer.HandleConfirmed(services.ServiceConfirmedSubscribeCOV, func(msg plumbing.BACnet, src net.Addr) ([]objects.APDUPayload, error) {
+		dec, err := msg.(*services.ConfirmedSubscribeCOV).Decode()
+		if err != nil {
+			return nil, err
+		}
+		subscriptions <- dec
+		return nil, nil
+	})
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := bacnet.NewClient(conn)
+	c.Timeout = 200 * time.Millisecond
+	go c.Run()
+	defer c.Close()
+
+	m := bacnet.NewCOVManager(c)
+
+	sub, err := m.Subscribe(context.Background(), peer.LocalAddr(), 321, objects.ObjectTypeAnalogInput, 10,
+		bacnet.COVOptions{Confirmed: true, Lifetime: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var dec services.ConfirmedSubscribeCOVDec
+	select {
+	case dec = <-subscriptions:
+	case <-time.After(time.Second):
+		t.Fatal("the subscription never made it to the peer")
+	}
+	if dec.Cancel || !dec.Confirmed || dec.Lifetime != 3600 || dec.InstanceId != 10 {
+		t.Errorf("unexpected subscription %+v", dec)
+	}
+
+	// Confirmed notifications get acknowledged and delivered.
+	notification := services.NewConfirmedCOVNotification(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+	notification.APDU.Objects = services.COVNotificationObjects(dec.ProcessId, 321, objects.ObjectTypeAnalogInput, 10, 3600,
+		[]services.PropertyValue{{
+			PropertyId: objects.PropertyIdPresentValue,
+			ArrayIndex: objects.ArrayAll,
+			Values:     []objects.APDUPayload{objects.EncReal(21.5)},
+		}})
+	reply, err := peer.Request(context.Background(), conn.LocalAddr(), notification)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := reply.(*services.SimpleACK); !ok {
+		t.Errorf("got %T, want a Simple ACK", reply)
+	}
+
+	select {
+	case n := <-sub.C:
+		if !n.Confirmed || len(n.Values) != 1 || n.Values[0].PropertyId != objects.PropertyIdPresentValue {
+			t.Errorf("unexpected notification %+v", n)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the notification was never delivered")
+	}
+
+	// An I-Am from the device triggers a resubscription.
+	iAm := services.NewUnconfirmedIAm(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, false))
+	iAm.APDU.Objects = services.IAmObjects(321, 1476, objects.SegmentationNone, 31)
+	if err := peer.Send(conn.LocalAddr(), iAm); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case dec = <-subscriptions:
+		if dec.Cancel {
+			t.Errorf("got a cancellation, want a resubscription")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the I-Am didn't trigger a resubscription")
+	}
+
+	// Closing the manager cancels the subscriptions.
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case dec = <-subscriptions:
+		if !dec.Cancel {
+			t.Errorf("got %+v, want a cancellation", dec)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("the subscription was never cancelled")
+	}
+	if _, ok := <-sub.C; ok {
+		t.Errorf("the subscription channel is still open")
+	}
+}
+
+func TestServerCOV(t *testing.T) {
+	dev := device.New(321, "dev", 31)
+	ai := device.NewAnalogInput(10, "AI-10", objects.UnitsNoUnits)
+	if err := ai.Set(objects.PropertyIdCOVIncrement, float32(1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := dev.Add(ai); err != nil {
+		t.Fatal(err)
+	}
+	_, addr := newTestServer(t, dev)
+
+	c := newTestClient(t)
+	m := bacnet.NewCOVManager(c)
+	defer m.Close()
+
+	sub, err := m.Subscribe(context.Background(), addr, 321, objects.ObjectTypeAnalogInput, 10,
+		bacnet.COVOptions{Confirmed: true, Lifetime: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	presentValue := func() float32 {
+		t.Helper()
+		select {
+		case n := <-sub.C:
+			if len(n.Values) != 2 || n.Values[1].PropertyId != objects.PropertyIdStatusFlags {
+				t.Fatalf("unexpected notification %+v", n)
+			}
+			value, err := objects.DecReal(n.Values[0].Values[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			return value
+		case <-time.After(time.Second):
+			t.Fatal("no notification was delivered")
+		}
+		return 0
+	}
+
+	if got := presentValue(); got != 0 {
+		t.Errorf("got an initial Present_Value of %v, want 0", got)
+	}
+
+	// Changes below COV_Increment go unnoticed.
+	for _, value := range []float32{0.5, 1.5} {
+		if err := ai.Set(objects.PropertyIdPresentValue, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := presentValue(); got != 1.5 {
+		t.Errorf("got a Present_Value of %v, want 1.5", got)
+	}
+
+	// Status_Flags changes are always notified.
+	if err := ai.Set(objects.PropertyIdOutOfService, true); err != nil {
+		t.Fatal(err)
+	}
+	if got := presentValue(); got != 1.5 {
+		t.Errorf("got a Present_Value of %v, want 1.5", got)
+	}
+
+	values, err := dev.ReadProperty(objects.ObjectTypeDevice, 321, objects.PropertyIdActiveCOVSubscriptions, objects.ArrayAll)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) == 0 {
+		t.Errorf("Active_COV_Subscriptions is empty")
+	}
+}
+
+func TestActiveCOVSubscriptions(t *testing.T) {
+	dev := device.New(321, "dev", 31)
+	if err := dev.Add(device.NewAnalogInput(10, "AI-10", objects.UnitsNoUnits)); err != nil {
+		t.Fatal(err)
+	}
+
+	node := &net.UDPAddr{IP: net.IPv4(192, 168, 1, 2), Port: bacnet.DefaultPort}
+	for i, recipient := range []net.Addr{
+		node,
+		&bacnet.Address{Addr: node, Net: 5, MAC: []byte{0x0c}},
+		&bacnet.Address{Addr: node},
+	} {
+		if err := dev.SubscribeCOV(device.COVSubscription{
+			Recipient:  recipient,
+			ProcessId:  uint32(i + 1),
+			Object:     objects.ObjectIdentifier{ObjectType: objects.ObjectTypeAnalogInput, InstanceNumber: 10},
+			PropertyId: objects.PropertyIdAll,
+			ArrayIndex: objects.ArrayAll,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	values, err := dev.ReadProperty(objects.ObjectTypeDevice, 321, objects.PropertyIdActiveCOVSubscriptions, objects.ArrayAll)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var encoded []byte
+	for _, v := range values {
+		b, err := v.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		encoded = append(encoded, b...)
+	}
+
+	for _, want := range []struct {
+		description string
+		recipient   []byte
+	}{
+		{"local", []byte{0x0e, 0x0e, 0x1e, 0x21, 0x00, 0x65, 0x06, 0xc0, 0xa8, 0x01, 0x02, 0xba, 0xc0, 0x1f, 0x0f, 0x19, 0x01}},
+		{"routed", []byte{0x0e, 0x0e, 0x1e, 0x21, 0x05, 0x61, 0x0c, 0x1f, 0x0f, 0x19, 0x02}},
+		{"local by Address", []byte{0x0e, 0x0e, 0x1e, 0x21, 0x00, 0x65, 0x06, 0xc0, 0xa8, 0x01, 0x02, 0xba, 0xc0, 0x1f, 0x0f, 0x19, 0x03}},
+	} {
+		if !bytes.Contains(encoded, want.recipient) {
+			t.Errorf("the %s recipient isn't encoded as %x in %x", want.description, want.recipient, encoded)
+		}
+	}
+}
+
+func TestServerCOVProperty(t *testing.T) {
+	dev := device.New(321, "dev", 31)
+	ai := device.NewAnalogInput(10, "AI-10", objects.UnitsNoUnits)
+	if err := dev.Add(ai); err != nil {
+		t.Fatal(err)
+	}
+	_, addr := newTestServer(t, dev)
+
+	c := newTestClient(t)
+	notifications := make(chan services.COVNotificationDec, 8)
+	c.HandleUnconfirmed(services.ServiceUnconfirmedCOVNotification, func(msg plumbing.BACnet, src net.Addr) {
+		if dec, err := msg.(*services.UnconfirmedCOVNotification).Decode(); err == nil {
+			notifications <- dec
+		}
+	})
+
+	increment := float32(2)
+	req := services.NewConfirmedSubscribeCOVProperty(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+	req.APDU.Objects = services.ConfirmedSubscribeCOVPropertyObjects(7, objects.ObjectTypeAnalogInput, 10, false, 60,
+		services.PropertyReference{PropertyId: objects.PropertyIdPresentValue, ArrayIndex: objects.ArrayAll}, &increment)
+	if _, err := c.Request(context.Background(), addr, req); err != nil {
+		t.Fatal(err)
+	}
+
+	presentValue := func() float32 {
+		t.Helper()
+		select {
+		case n := <-notifications:
+			if n.ProcessId != 7 || len(n.Values) == 0 || n.Values[0].PropertyId != objects.PropertyIdPresentValue {
+				t.Fatalf("unexpected notification %+v", n)
+			}
+			value, err := objects.DecReal(n.Values[0].Values[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			return value
+		case <-time.After(time.Second):
+			t.Fatal("no notification was delivered")
+		}
+		return 0
+	}
+
+	if got := presentValue(); got != 0 {
+		t.Errorf("got an initial Present_Value of %v, want 0", got)
+	}
+
+	// The increment of the subscription prevails over the missing COV_Increment.
+	for _, value := range []float32{1, 2.5} {
+		if err := ai.Set(objects.PropertyIdPresentValue, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := presentValue(); got != 2.5 {
+		t.Errorf("got a Present_Value of %v, want 2.5", got)
+	}
+}
+
+func TestServerReadPropertyMultiple(t *testing.T) {
+	dev := device.New(321, "dev", 31)
+	_, addr := newTestServer(t, dev)
+	c := newTestClient(t)
+
+	req := services.NewConfirmedReadPropertyMultiple(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+	req.APDU.Objects = services.ConfirmedReadPropertyMultipleObjects([]services.ReadAccessSpecification{{
+		ObjectType: objects.ObjectTypeDevice,
+		InstanceId: objects.MaxInstance,
+		Properties: []services.PropertyReference{{PropertyId: objects.PropertyIdAll, ArrayIndex: objects.ArrayAll}},
+	}})
+
+	reply, err := c.Request(context.Background(), addr, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec, err := reply.(*services.ComplexACK).DecodeReadPropertyMultiple()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(dec.Results) != 1 || dec.Results[0].InstanceId != 321 {
+		t.Fatalf("unexpected results %+v", dec.Results)
+	}
+	for _, r := range dec.Results[0].Results {
+		if r.Error != nil {
+			t.Errorf("reading property %d failed: %v", r.PropertyId, r.Error)
+		}
+	}
+}
+
+func TestServerWritePropertyMultiple(t *testing.T) {
+	dev := device.New(321, "dev", 31)
+	ao := device.NewAnalogOutput(1, "AO-1", objects.UnitsNoUnits)
+	ai := device.NewAnalogInput(2, "AI-2", objects.UnitsNoUnits)
+	for _, o := range []*device.Object{ao, ai} {
+		if err := dev.Add(o); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, addr := newTestServer(t, dev)
+	c := newTestClient(t)
+
+	req := services.NewConfirmedWritePropertyMultiple(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+	req.APDU.Objects = services.ConfirmedWritePropertyMultipleObjects([]services.WriteAccessSpecification{
+		{
+			ObjectType: objects.ObjectTypeAnalogOutput,
+			InstanceId: 1,
+			Values: []services.PropertyValue{{
+				PropertyId: objects.PropertyIdPresentValue,
+				ArrayIndex: objects.ArrayAll,
+				Values:     []objects.APDUPayload{objects.EncReal(21.5)},
+				Priority:   8,
+			}},
+		},
+		{
+			ObjectType: objects.ObjectTypeAnalogInput,
+			InstanceId: 2,
+			Values: []services.PropertyValue{{
+				PropertyId: objects.PropertyIdPresentValue,
+				ArrayIndex: objects.ArrayAll,
+				Values:     []objects.APDUPayload{objects.EncReal(3)},
+			}},
+		},
+	})
+
+	_, err := c.Request(context.Background(), addr, req)
+	var wErr *common.WritePropertyMultipleError
+	if !errors.As(err, &wErr) {
+		t.Fatalf("got error %v, want a WritePropertyMultiple error", err)
+	}
+	if wErr.Code != objects.ErrorCodeWriteAccessDenied || wErr.ObjectType != objects.ObjectTypeAnalogInput ||
+		wErr.InstanceId != 2 || wErr.PropertyId != objects.PropertyIdPresentValue {
+		t.Errorf("unexpected error %+v", wErr)
+	}
+	var bErr *common.BACnetError
+	if !errors.As(err, &bErr) {
+		t.Errorf("the error doesn't unwrap into a BACnetError")
+	}
+
+	// The writes preceding the failed one were carried out.
+	if got := ao.Get(objects.PropertyIdPresentValue); got != float32(21.5) {
+		t.Errorf("got Present_Value %v, want 21.5", got)
+	}
+	if got := ao.Get(objects.PropertyIdCurrentCommandPriority); got != uint32(8) {
+		t.Errorf("got Current_Command_Priority %v, want 8", got)
+	}
+}
+
+func TestServerCreateDeleteObject(t *testing.T) {
+	dev := device.New(321, "dev", 31)
+	dev.SetFactory(objects.ObjectTypeBinaryValue, func(instance uint32, name string) *device.Object {
+		return device.NewBinaryValue(instance, name)
+	})
+	_, addr := newTestServer(t, dev)
+	c := newTestClient(t)
+
+	create := func(values []services.PropertyValue) (plumbing.BACnet, error) {
+		req := services.NewConfirmedCreateObject(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+		req.APDU.Objects = services.ConfirmedCreateObjectObjects(objects.ObjectTypeBinaryValue, objects.MaxInstance, values)
+		return c.Request(context.Background(), addr, req)
+	}
+	name := services.PropertyValue{
+		PropertyId: objects.PropertyIdObjectName,
+		ArrayIndex: objects.ArrayAll,
+		Values:     []objects.APDUPayload{objects.EncCharacterString("BV-new")},
+	}
+
+	reply, err := create([]services.PropertyValue{name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := reply.(*services.ComplexACK).DecodeCreateObject()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (objects.ObjectIdentifier{ObjectType: objects.ObjectTypeBinaryValue}); id != want {
+		t.Errorf("got %v, want %v", id, want)
+	}
+	if o := dev.Lookup(objects.ObjectTypeBinaryValue, 0); o == nil || o.Name() != "BV-new" {
+		t.Fatal("object not created")
+	}
+
+	// A second object can't take the same name.
+	_, err = create([]services.PropertyValue{name})
+	var cErr *common.CreateObjectError
+	if !errors.As(err, &cErr) {
+		t.Fatalf("got error %v, want a CreateObject error", err)
+	}
+	if cErr.Code != objects.ErrorCodeDuplicateName || cErr.FirstFailedElement != 1 {
+		t.Errorf("unexpected error %+v", cErr)
+	}
+
+	del := services.NewConfirmedDeleteObject(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+	del.APDU.Objects = services.ConfirmedDeleteObjectObjects(objects.ObjectTypeBinaryValue, 0)
+	if _, err := c.Request(context.Background(), addr, del); err != nil {
+		t.Fatal(err)
+	}
+	if o := dev.Lookup(objects.ObjectTypeBinaryValue, 0); o != nil {
+		t.Error("object not deleted")
+	}
+	_, err = c.Request(context.Background(), addr, del)
+	var bErr *common.BACnetError
+	if !errors.As(err, &bErr) || bErr.Code != objects.ErrorCodeUnknownObject {
+		t.Errorf("got error %v deleting a deleted object, want an unknown object error", err)
+	}
+}
+
+func TestServerListElement(t *testing.T) {
+	dev := device.New(321, "dev", 31)
+	bv := device.NewBinaryValue(0, "BV-0")
+	if err := bv.AddProperty(&device.Property{
+		Identifier: objects.PropertyIdDateList,
+		Datatype:   device.ListOf(device.Date),
+		Access:     device.AccessWritable,
+		Value:      []interface{}{},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := dev.Add(bv); err != nil {
+		t.Fatal(err)
+	}
+	_, addr := newTestServer(t, dev)
+	c := newTestClient(t)
+
+	date := objects.Date{Year: 120, Month: 12, Day: 25, Weekday: 5}
+	add := services.NewConfirmedAddListElement(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+	add.APDU.Objects = services.ConfirmedListElementObjects(objects.ObjectTypeBinaryValue, 0, objects.PropertyIdDateList,
+		objects.ArrayAll, []objects.APDUPayload{objects.EncDate(date)})
+	if _, err := c.Request(context.Background(), addr, add); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]interface{}{date}, bv.Get(objects.PropertyIdDateList)); diff != "" {
+		t.Errorf("Date_List mismatch (-want +got):\n%s", diff)
+	}
+
+	remove := services.NewConfirmedRemoveListElement(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+	remove.APDU.Objects = services.ConfirmedListElementObjects(objects.ObjectTypeBinaryValue, 0, objects.PropertyIdDateList,
+		objects.ArrayAll, []objects.APDUPayload{objects.EncDate(date), objects.EncDate(date)})
+	_, err := c.Request(context.Background(), addr, remove)
+	var lErr *common.ChangeListError
+	if !errors.As(err, &lErr) {
+		t.Fatalf("got error %v, want a ChangeList error", err)
+	}
+	if lErr.Code != objects.ErrorCodeListElementNotFound || lErr.FirstFailedElement != 2 {
+		t.Errorf("unexpected error %+v", lErr)
+	}
+	if got := bv.Get(objects.PropertyIdDateList).([]interface{}); len(got) != 1 {
+		t.Errorf("got Date_List %v after a failed removal, want it untouched", got)
+	}
+}
+
+func TestIAmResponder(t *testing.T) {
+	c := newTestClient(t)
+	iAms := make(chan *services.UnconfirmedIAm, 8)
+	c.HandleUnconfirmed(services.ServiceUnconfirmedIAm, func(msg plumbing.BACnet, src net.Addr) {
+		iAms <- msg.(*services.UnconfirmedIAm)
+	})
+
+	// Broadcasts can't be told apart from unicasts on the loopback interface, so they're
+	// sent straight to the client.
+	_, addr := newTestServer(t, device.New(321, "dev", 31), func(s *bacnet.Server) {
+		s.BroadcastAddr = c.LocalAddr()
+		s.IAm.MaxDelay = 50 * time.Millisecond
+	})
+	_, unicastAddr := newTestServer(t, device.New(322, "dev", 31), func(s *bacnet.Server) {
+		s.BroadcastAddr = c.LocalAddr()
+		s.IAm.Unicast = true
+	})
+
+	whoIs := func(dst net.Addr, bvlcFunc uint8, objs []objects.APDUPayload) {
+		t.Helper()
+		u := services.NewUnconfirmedWhoIs(plumbing.NewBVLC(bvlcFunc), plumbing.NewNPDU(false, false, false, false))
+		u.APDU.Objects = objs
+		if err := c.Send(dst, u); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	receive := func() *services.UnconfirmedIAm {
+		t.Helper()
+		select {
+		case iAm := <-iAms:
+			return iAm
+		case <-time.After(500 * time.Millisecond):
+			return nil
+		}
+	}
+
+	// Devices out of range keep quiet.
+	whoIs(addr, plumbing.BVLCFuncBroadcast, services.WhoIsObjects(0, 320))
+	if iAm := receive(); iAm != nil {
+		t.Fatalf("got an I-Am for a Who-Is out of range")
+	}
+
+	whoIs(addr, plumbing.BVLCFuncBroadcast, services.WhoIsObjects(321, 400))
+	iAm := receive()
+	if iAm == nil {
+		t.Fatal("got no I-Am")
+	}
+	if iAm.BVLC.Function != plumbing.BVLCFuncBroadcast || iAm.NPDU.DNET != 0xFFFF {
+		t.Errorf("the I-Am wasn't globally broadcast")
+	}
+	dec, err := iAm.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dec.DeviceId != 321 || dec.VendorId != 31 {
+		t.Errorf("unexpected I-Am %+v", dec)
+	}
+
+	whoIs(unicastAddr, plumbing.BVLCFuncUnicast, nil)
+	if iAm := receive(); iAm == nil || iAm.BVLC.Function != plumbing.BVLCFuncUnicast {
+		t.Errorf("got %v, want a unicast I-Am", iAm)
+	}
+}
+
+func TestWhoHas(t *testing.T) {
+	c := newTestClient(t)
+
+	dev := device.New(321, "dev", 31)
+	if err := dev.Add(device.NewAnalogInput(10, "AI-10", objects.UnitsNoUnits)); err != nil {
+		t.Fatal(err)
+	}
+	// Broadcasts go straight to their peer, as in TestIAmResponder.
+	_, addr := newTestServer(t, dev, func(s *bacnet.Server) {
+		s.BroadcastAddr = c.LocalAddr()
+		s.IHave.MaxDelay = 50 * time.Millisecond
+	})
+	c.BroadcastAddr = addr
+
+	for _, tc := range []struct {
+		description string
+		objs        []objects.APDUPayload
+		want        int
+	}{
+		{"by name", services.WhoHasNameObjects(0, objects.MaxInstance, "AI-10"), 1},
+		{"by identifier", services.WhoHasObjects(300, 400, objects.ObjectTypeAnalogInput, 10), 1},
+		{"unknown name", services.WhoHasNameObjects(0, objects.MaxInstance, "AI-11"), 0},
+		{"out of range", services.WhoHasObjects(0, 320, objects.ObjectTypeAnalogInput, 10), 0},
+	} {
+		t.Run(tc.description, func(t *testing.T) {
+			replies, err := c.WhoHas(context.Background(), tc.objs, 200*time.Millisecond)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(replies) != tc.want {
+				t.Fatalf("got %d replies, want %d", len(replies), tc.want)
+			}
+			for _, r := range replies {
+				want := services.UnconfirmedIHaveDec{DeviceId: 321, ObjectType: objects.ObjectTypeAnalogInput, InstanceId: 10, ObjectName: "AI-10"}
+				if r.UnconfirmedIHaveDec != want {
+					t.Errorf("got %+v, want %+v", r.UnconfirmedIHaveDec, want)
+				}
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := c.WhoHas(ctx, services.WhoHasNameObjects(0, objects.MaxInstance, "AI-10"), time.Second); !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+}
+
+func TestDiscoverer(t *testing.T) {
+	c := newTestClient(t)
+	d := bacnet.NewDiscoverer(c)
+	d.Window = 100 * time.Millisecond
+
+	// Broadcasts go straight to their peer, as in TestIAmResponder.
+	_, addr := newTestServer(t, device.New(321, "dev", 31), func(s *bacnet.Server) {
+		s.BroadcastAddr = c.LocalAddr()
+	})
+	c.BroadcastAddr = addr
+
+	devices, err := d.Discover(context.Background(), 0, objects.MaxInstance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(devices) != 1 || devices[0].DeviceId != 321 || devices[0].VendorId != 31 ||
+		devices[0].Address.Routed() || devices[0].Address.String() != addr.String() {
+		t.Fatalf("unexpected devices %+v", devices)
+	}
+
+	d.ChunkSize = 100
+	devices, err = d.Discover(context.Background(), 100, 399)
+	if err != nil || len(devices) != 1 {
+		t.Errorf("got %+v, %v discovering in chunks", devices, err)
+	}
+	if devices, _ := d.Discover(context.Background(), 0, 99); len(devices) != 0 {
+		t.Errorf("got %+v out of range", devices)
+	}
+
+	c.Devices.Forget(321)
+	if _, err := d.Find(context.Background(), 322); !errors.Is(err, common.ErrUnknownDevice) {
+		t.Errorf("got %v finding a missing device, want common.ErrUnknownDevice", err)
+	}
+	if info, err := d.Find(context.Background(), 321); err != nil || info.DeviceId != 321 {
+		t.Fatalf("got %+v, %v finding device 321", info, err)
+	}
+
+	req := services.NewConfirmedReadProperty(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+	req.APDU.Objects = services.ConfirmedReadPropertyObjects(
+		objects.ObjectTypeDevice, 321, objects.PropertyIdVendorIdentifier, objects.ArrayAll)
+	if _, err := c.RequestDevice(context.Background(), 321, req); err != nil {
+		t.Errorf("request to device 321 failed: %v", err)
+	}
+	if _, err := c.RequestDevice(context.Background(), 322, req); !errors.Is(err, common.ErrUnknownDevice) {
+		t.Errorf("got %v requesting an unbound device, want common.ErrUnknownDevice", err)
+	}
+}
+
+func TestRoutedDevice(t *testing.T) {
+	c := newTestClient(t)
+	d := bacnet.NewDiscoverer(c)
+
+	router, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer router.Close()
+	router.SetDeadline(time.Now().Add(2 * time.Second))
+
+	routed := func(msg plumbing.BACnet) []byte {
+		t.Helper()
+		msg.GetNPDU().SetSource(5, []byte{0x0a})
+		msg.GetBVLC().Length = uint16(msg.MarshalLen())
+		b, err := msg.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+
+	// The router relays the I-Am of device 400, which lives on network 5.
+	iAm := services.NewUnconfirmedIAm(plumbing.NewBVLC(plumbing.BVLCFuncBroadcast), plumbing.NewNPDU(false, false, false, false))
+	iAm.APDU.Objects = services.IAmObjects(400, 480, 3, 7)
+	if _, err := router.WriteTo(routed(iAm), c.LocalAddr()); err != nil {
+		t.Fatal(err)
+	}
+
+	var info bacnet.DeviceInfo
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		var ok bool
+		if info, ok = c.Devices.Lookup(400); ok {
+			break
+		}
+	}
+	want := bacnet.Address{Addr: router.LocalAddr(), Net: 5, MAC: []byte{0x0a}}
+	if info.Address.String() != want.String() || info.MaxAPDU != 480 || info.Segmentation != 3 || info.VendorId != 7 {
+		t.Fatalf("unexpected binding %+v", info)
+	}
+	if found, err := d.Find(context.Background(), 400); err != nil || found.DeviceId != 400 {
+		t.Errorf("got %+v, %v finding a bound device", found, err)
+	}
+
+	go func() {
+		b := make([]byte, 1500)
+		n, src, err := router.ReadFrom(b)
+		if err != nil {
+			return
+		}
+		msg, err := bacnet.Parse(b[:n])
+		if err != nil {
+			return
+		}
+		npdu := msg.GetNPDU()
+		if npdu.DNET != 5 || string(npdu.DADR) != "\x0a" {
+			return
+		}
+
+		ack := services.NewSimpleACK(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, false))
+		ack.APDU.Service = msg.GetAPDU().Service
+		ack.APDU.InvokeID = msg.GetAPDU().InvokeID
+		router.WriteTo(routed(ack), src)
+	}()
+
+	req := services.NewConfirmedSubscribeCOV(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+	req.APDU.Objects = services.ConfirmedSubscribeCOVObjects(1, objects.ObjectTypeAnalogInput, 1, false, 60)
+	if _, err := c.RequestDevice(context.Background(), 400, req); err != nil {
+		t.Errorf("routed request failed: %v", err)
+	}
+}
+
+func TestCrawl(t *testing.T) {
+	newDevice := func(n int) *device.Device {
+		dev := device.New(321, "dev", 31)
+		for i := 1; i <= n; i++ {
+			ai := device.NewAnalogInput(uint32(i), fmt.Sprintf("AI-%d", i), objects.UnitsDegreesCelsius)
+			if err := ai.Set(objects.PropertyIdPresentValue, float32(i)); err != nil {
+				t.Fatal(err)
+			}
+			if err := dev.Add(ai); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return dev
+	}
+
+	for _, tc := range []struct {
+		description string
+		objects     int
+		configure   func(*bacnet.Server)
+	}{
+		{"ReadPropertyMultiple", 2, func(*bacnet.Server) {}},
+		// Hundreds of objects don't fit an unsegmented Object_List.
+		{"ReadProperty element by element", 300, func(s *bacnet.Server) {
+			s.HandleConfirmed(services.ServiceConfirmedReadPropMultiple, nil)
+		}},
+	} {
+		t.Run(tc.description, func(t *testing.T) {
+			_, addr := newTestServer(t, newDevice(tc.objects), tc.configure)
+			c := newTestClient(t)
+			c.Devices.Bind(bacnet.DeviceInfo{DeviceId: 321, Address: bacnet.Address{Addr: addr}})
+
+			inv, err := c.Crawl(context.Background(), 321)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(inv.Objects) != tc.objects+1 {
+				t.Fatalf("got %d objects, want %d", len(inv.Objects), tc.objects+1)
+			}
+
+			if o := inv.Objects[0]; o.ObjectType != objects.ObjectTypeDevice || o.InstanceId != 321 || o.Name != "dev" {
+				t.Errorf("unexpected device object %+v", o)
+			}
+			o := inv.Objects[tc.objects]
+			if o.ObjectType != objects.ObjectTypeAnalogInput || o.InstanceId != uint32(tc.objects) ||
+				o.Name != fmt.Sprintf("AI-%d", tc.objects) || o.PresentValue != float32(tc.objects) {
+				t.Errorf("unexpected object %+v", o)
+			}
+			if o.Units == nil || *o.Units != objects.UnitsDegreesCelsius {
+				t.Errorf("got units %v, want %d", o.Units, objects.UnitsDegreesCelsius)
+			}
+		})
+	}
+
+	c := newTestClient(t)
+	if _, err := c.Crawl(context.Background(), 400); !errors.Is(err, common.ErrUnknownDevice) {
+		t.Errorf("got %v crawling an unbound device, want common.ErrUnknownDevice", err)
+	}
+}
+
+func TestReadPoints(t *testing.T) {
+	newDevice := func(id uint32, n int) *device.Device {
+		dev := device.New(id, "dev", 31)
+		for i := 1; i <= n; i++ {
+			ai := device.NewAnalogInput(uint32(i), fmt.Sprintf("AI-%d", i), objects.UnitsDegreesCelsius)
+			if err := ai.Set(objects.PropertyIdPresentValue, float32(i)); err != nil {
+				t.Fatal(err)
+			}
+			if err := dev.Add(ai); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return dev
+	}
+
+	// The first device counts the ReadPropertyMultiple it serves, the second rejects them and
+	// the third has an Object_List too big for a single APDU.
+	var rpms int32
+	dev := newDevice(321, 40)
+	_, addr := newTestServer(t, dev, func(s *bacnet.Server) {
+		s.HandleConfirmed(services.ServiceConfirmedReadPropMultiple, func(msg plumbing.BACnet, src net.Addr) ([]objects.APDUPayload, error) {
+			atomic.AddInt32(&rpms, 1)
+			dec, err := msg.(*services.ConfirmedReadPropertyMultiple).Decode()
+			if err != nil {
+				return nil, err
+			}
+			return services.ReadPropertyMultipleACKObjects(dev.ReadPropertyMultiple(dec.Specifications)), nil
+		})
+	})
+	_, rejectingAddr := newTestServer(t, newDevice(322, 3), func(s *bacnet.Server) {
+		s.HandleConfirmed(services.ServiceConfirmedReadPropMultiple, nil)
+	})
+	_, bigAddr := newTestServer(t, newDevice(323, 300))
+
+	c := newTestClient(t)
+	c.Devices.Bind(bacnet.DeviceInfo{DeviceId: 321, Address: bacnet.Address{Addr: addr}, MaxAPDU: 206})
+	c.Devices.Bind(bacnet.DeviceInfo{DeviceId: 322, Address: bacnet.Address{Addr: rejectingAddr}, MaxAPDU: 1476})
+	c.Devices.Bind(bacnet.DeviceInfo{DeviceId: 323, Address: bacnet.Address{Addr: bigAddr}, MaxAPDU: 1476})
+
+	var points []bacnet.Point
+	for i := uint32(40); i >= 1; i-- {
+		for _, propertyId := range []uint32{objects.PropertyIdPresentValue, objects.PropertyIdObjectName, objects.PropertyIdUnits, objects.PropertyIdStatusFlags} {
+			points = append(points, bacnet.Point{DeviceId: 321, ObjectType: objects.ObjectTypeAnalogInput, InstanceId: i, PropertyId: propertyId, ArrayIndex: objects.ArrayAll})
+		}
+	}
+	points = append(points,
+		bacnet.Point{DeviceId: 321, ObjectType: objects.ObjectTypeAnalogInput, InstanceId: 1, PropertyId: objects.PropertyIdPriorityArray, ArrayIndex: objects.ArrayAll},
+		bacnet.Point{DeviceId: 322, ObjectType: objects.ObjectTypeAnalogInput, InstanceId: 2, PropertyId: objects.PropertyIdPresentValue, ArrayIndex: objects.ArrayAll},
+		bacnet.Point{DeviceId: 322, ObjectType: objects.ObjectTypeAnalogInput, InstanceId: 3, PropertyId: objects.PropertyIdPresentValue, ArrayIndex: objects.ArrayAll},
+		bacnet.Point{DeviceId: 323, ObjectType: objects.ObjectTypeDevice, InstanceId: 323, PropertyId: objects.PropertyIdObjectList, ArrayIndex: objects.ArrayAll},
+		bacnet.Point{DeviceId: 323, ObjectType: objects.ObjectTypeAnalogInput, InstanceId: 300, PropertyId: objects.PropertyIdPresentValue, ArrayIndex: objects.ArrayAll},
+		bacnet.Point{DeviceId: 324, ObjectType: objects.ObjectTypeAnalogInput, InstanceId: 1, PropertyId: objects.PropertyIdPresentValue, ArrayIndex: objects.ArrayAll},
+	)
+
+	results, err := c.ReadPoints(context.Background(), points)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != len(points) {
+		t.Fatalf("got %d results for %d points", len(results), len(points))
+	}
+
+	// Nine properties fit the replies of the device accepting 206 bytes long APDUs.
+	if n := atomic.LoadInt32(&rpms); n != 18 {
+		t.Errorf("got %d ReadPropertyMultiple requests, want 18", n)
+	}
+
+	value := func(r bacnet.PointValue) interface{} {
+		t.Helper()
+		if r.Err != nil || len(r.Values) != 1 {
+			t.Fatalf("reading %+v: got %v, %v", r.Point, r.Values, r.Err)
+		}
+		v, err := objects.DecValue(r.Values[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+
+	for i, r := range results[:160] {
+		if r.Point != points[i] {
+			t.Fatalf("result %d is for %+v, want %+v", i, r.Point, points[i])
+		}
+		switch r.PropertyId {
+		case objects.PropertyIdPresentValue:
+			if v := value(r); v != float32(r.InstanceId) {
+				t.Errorf("got Present_Value %v for instance %d", v, r.InstanceId)
+			}
+		case objects.PropertyIdObjectName:
+			if v := value(r); v != fmt.Sprintf("AI-%d", r.InstanceId) {
+				t.Errorf("got Object_Name %v for instance %d", v, r.InstanceId)
+			}
+		}
+	}
+
+	var bErr *common.BACnetError
+	if r := results[160]; !errors.As(r.Err, &bErr) || bErr.Code != objects.ErrorCodeUnknownProperty {
+		t.Errorf("got %v reading a missing property, want an unknown property error", r.Err)
+	}
+	if r := results[161]; value(r) != float32(2) {
+		t.Errorf("got %v reading off a device rejecting ReadPropertyMultiple", r.Values)
+	}
+	if r := results[162]; value(r) != float32(3) {
+		t.Errorf("got %v reading off a device rejecting ReadPropertyMultiple", r.Values)
+	}
+	var aErr *common.AbortError
+	if r := results[163]; !errors.As(r.Err, &aErr) {
+		t.Errorf("got %v reading a property too big for an APDU, want an abort", r.Err)
+	}
+	if r := results[164]; value(r) != float32(300) {
+		t.Errorf("got %v reading a point batched with an aborted one", r.Values)
+	}
+	if r := results[165]; !errors.Is(r.Err, common.ErrUnknownDevice) {
+		t.Errorf("got %v reading off an unbound device, want common.ErrUnknownDevice", r.Err)
+	}
+}
+
+func TestTimeMaster(t *testing.T) {
+	dev := device.New(321, "dev", 31)
+	dev.SetLocation(time.FixedZone("CET", 3600))
+	_, addr := newTestServer(t, dev)
+
+	c := newTestClient(t)
+	c.Devices.Bind(bacnet.DeviceInfo{DeviceId: 321, Address: bacnet.Address{Addr: addr}})
+
+	synced := func(want time.Time) {
+		t.Helper()
+		for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			if got := dev.Now(); got.Sub(want) >= 0 && got.Sub(want) < time.Second {
+				return
+			}
+		}
+		t.Errorf("got device time %v, want %v", dev.Now(), want)
+	}
+
+	m := bacnet.NewTimeMaster(c)
+	m.Recipients = []net.Addr{addr}
+	m.Location = time.FixedZone("CET", 3600)
+
+	want := time.Date(2026, 10, 19, 13, 45, 30, 0, time.UTC)
+	m.Clock = func() time.Time { return want }
+	if err := m.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	synced(want)
+
+	want = want.Add(-time.Hour)
+	m.UTC = true
+	m.Recipients = nil
+	m.Devices = []uint32{321}
+	m.Interval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := m.Run(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, want context.DeadlineExceeded", err)
+	}
+	synced(want)
+}
+
+func TestDeviceCommunicationControl(t *testing.T) {
+	c := newTestClient(t)
+	c.Timeout = 100 * time.Millisecond
+	c.Retries = 0
+
+	var skew int64
+	dev := device.New(321, "dev", 31)
+	dev.SetPassword("secret")
+	dev.SetClock(func() time.Time { return time.Now().Add(time.Duration(atomic.LoadInt64(&skew))) })
+
+	iAms := make(chan struct{}, 8)
+	c.HandleUnconfirmed(services.ServiceUnconfirmedIAm, func(msg plumbing.BACnet, src net.Addr) {
+		iAms <- struct{}{}
+	})
+
+	s, addr := newTestServer(t, dev, func(s *bacnet.Server) {
+		s.BroadcastAddr = c.LocalAddr()
+		s.IAm.MaxDelay = 0
+	})
+
+	dcc := func(duration uint16, state uint8, password string) error {
+		req := services.NewConfirmedDeviceCommunicationControl(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+		req.APDU.Objects = services.ConfirmedDeviceCommunicationControlObjects(duration, state, password)
+		_, err := c.Request(context.Background(), addr, req)
+		return err
+	}
+	readProperty := func() error {
+		req := services.NewConfirmedReadProperty(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+		req.APDU.Objects = services.ConfirmedReadPropertyObjects(
+			objects.ObjectTypeDevice, 321, objects.PropertyIdObjectName, objects.ArrayAll)
+		_, err := c.Request(context.Background(), addr, req)
+		return err
+	}
+	whoIs := func() bool {
+		t.Helper()
+		u := services.NewUnconfirmedWhoIs(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, false))
+		if err := c.Send(addr, u); err != nil {
+			t.Fatal(err)
+		}
+		select {
+		case <-iAms:
+			return true
+		case <-time.After(200 * time.Millisecond):
+			return false
+		}
+	}
+
+	var bErr *common.BACnetError
+	if err := dcc(0, objects.CommunicationDisable, "wrong"); !errors.As(err, &bErr) || bErr.Code != objects.ErrorCodePasswordFailure {
+		t.Fatalf("got error %v, want a password failure", err)
+	}
+	var rErr *common.RejectError
+	if err := dcc(0, objects.CommunicationDisableInitiation+1, "secret"); !errors.As(err, &rErr) || rErr.Reason != plumbing.RejectReasonUndefinedEnumeration {
+		t.Fatalf("got error %v, want an undefined enumeration reject", err)
+	}
+
+	if err := dcc(0, objects.CommunicationDisable, "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if err := readProperty(); !errors.Is(err, common.ErrTimeout) {
+		t.Errorf("got %v reading while disabled, want a timeout", err)
+	}
+	if whoIs() {
+		t.Errorf("got an I-Am while disabled")
+	}
+	if err := s.IAm.Announce(); !errors.Is(err, common.ErrCommunicationDisabled) {
+		t.Errorf("got %v announcing while disabled, want ErrCommunicationDisabled", err)
+	}
+
+	if err := dcc(1, objects.CommunicationDisableInitiation, "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if err := readProperty(); err != nil {
+		t.Errorf("got %v reading with initiation disabled, want nil", err)
+	}
+	if !whoIs() {
+		t.Errorf("got no I-Am with initiation disabled")
+	}
+	if err := s.IAm.Announce(); !errors.Is(err, common.ErrCommunicationDisabled) {
+		t.Errorf("got %v announcing with initiation disabled, want ErrCommunicationDisabled", err)
+	}
+
+	// Communication is enabled again once the minute is up.
+	atomic.StoreInt64(&skew, int64(time.Minute))
+	if err := s.IAm.Announce(); err != nil {
+		t.Errorf("got %v announcing once the duration elapsed, want nil", err)
+	}
+}
+
+type testReinitializer struct {
+	mu    sync.Mutex
+	calls []string
+	files []objects.ObjectIdentifier
+	err   error
+}
+
+func (r *testReinitializer) Reinitialize(state uint8) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, fmt.Sprintf("reinitialize %d", state))
+	return r.err
+}
+
+func (r *testReinitializer) StartBackup() ([]objects.ObjectIdentifier, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, "start backup")
+	return r.files, r.err
+}
+
+func (r *testReinitializer) EndBackup() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, "end backup")
+	return r.err
+}
+
+func (r *testReinitializer) StartRestore() ([]objects.ObjectIdentifier, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, "start restore")
+	return r.files, r.err
+}
+
+func (r *testReinitializer) EndRestore() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, "end restore")
+	return r.err
+}
+
+func (r *testReinitializer) AbortRestore() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.calls = append(r.calls, "abort restore")
+	return r.err
+}
+
+func TestReinitializeDevice(t *testing.T) {
+	dev := device.New(321, "dev", 31)
+	dev.SetPassword("secret")
+	dev.SetCommunicationState(objects.CommunicationDisableInitiation, 0)
+
+	r := &testReinitializer{files: []objects.ObjectIdentifier{{ObjectType: objects.ObjectTypeFile, InstanceNumber: 1}}}
+	_, addr := newTestServer(t, dev, func(s *bacnet.Server) {
+		s.Reinitializer = r
+	})
+	c := newTestClient(t)
+
+	reinitialize := func(state uint8, password string) error {
+		req := services.NewConfirmedReinitializeDevice(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+		req.APDU.Objects = services.ConfirmedReinitializeDeviceObjects(state, password)
+		_, err := c.Request(context.Background(), addr, req)
+		return err
+	}
+	errorCode := func(err error) uint8 {
+		t.Helper()
+		var bErr *common.BACnetError
+		if !errors.As(err, &bErr) {
+			t.Fatalf("got %v, want a BACnet error", err)
+		}
+		return bErr.Code
+	}
+
+	if code := errorCode(reinitialize(objects.ReinitializedStateColdstart, "wrong")); code != objects.ErrorCodePasswordFailure {
+		t.Errorf("got error code %d, want a password failure", code)
+	}
+	var rErr *common.RejectError
+	if err := reinitialize(objects.ReinitializedStateActivateChanges+1, "secret"); !errors.As(err, &rErr) || rErr.Reason != plumbing.RejectReasonUndefinedEnumeration {
+		t.Errorf("got error %v, want an undefined enumeration reject", err)
+	}
+	if err := reinitialize(objects.ReinitializedStateWarmstart, "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if got := dev.CommunicationState(); got != objects.CommunicationEnable {
+		t.Errorf("got communication state %d after a warmstart, want enabled", got)
+	}
+
+	if err := reinitialize(objects.ReinitializedStateStartBackup, "secret"); err != nil {
+		t.Fatal(err)
+	}
+	files, err := dev.ReadProperty(objects.ObjectTypeDevice, 321, objects.PropertyIdConfigurationFiles, objects.ArrayAll)
+	if err != nil || len(files) != 1 {
+		t.Errorf("got Configuration_Files %v, %v during the backup, want a file", files, err)
+	}
+	if code := errorCode(reinitialize(objects.ReinitializedStateStartRestore, "secret")); code != objects.ErrorCodeConfigurationInProgress {
+		t.Errorf("got error code %d starting a restore during a backup, want configuration in progress", code)
+	}
+	if err := reinitialize(objects.ReinitializedStateEndBackup, "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if code := errorCode(reinitialize(objects.ReinitializedStateEndBackup, "secret")); code != objects.ErrorCodeServiceRequestDenied {
+		t.Errorf("got error code %d ending a backup twice, want service request denied", code)
+	}
+
+	if err := reinitialize(objects.ReinitializedStateStartRestore, "secret"); err != nil {
+		t.Fatal(err)
+	}
+	if err := reinitialize(objects.ReinitializedStateAbortRestore, "secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	r.mu.Lock()
+	r.err = errors.New("no room")
+	r.mu.Unlock()
+	if code := errorCode(reinitialize(objects.ReinitializedStateStartRestore, "secret")); code != objects.ErrorCodeOther {
+		t.Errorf("got error code %d when preparing fails, want other", code)
+	}
+	if got := dev.BackupState(); got != objects.BackupStateRestoreFailure {
+		t.Errorf("got Backup_And_Restore_State %d, want restore failure", got)
+	}
+
+	want := []string{
+		fmt.Sprintf("reinitialize %d", objects.ReinitializedStateWarmstart),
+		"start backup", "end backup", "start restore", "abort restore", "start restore",
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if diff := cmp.Diff(want, r.calls); diff != "" {
+		t.Errorf("calls mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestFileTransfer(t *testing.T) {
+	content := make([]byte, 5000)
+	for i := range content {
+		content[i] = byte(i)
+	}
+	buf := device.NewFileBuffer(content)
+
+	dev := device.New(321, "dev", 31)
+	if err := dev.Add(device.NewFile(1, "config", "application/octet-stream", buf)); err != nil {
+		t.Fatal(err)
+	}
+	if err := dev.Add(device.NewFile(2, "firmware", "application/octet-stream", bytes.NewReader(content))); err != nil {
+		t.Fatal(err)
+	}
+
+	var requests int32
+	_, addr := newTestServer(t, dev, func(s *bacnet.Server) {
+		s.HandleConfirmed(services.ServiceConfirmedAtomicReadFile, func(msg plumbing.BACnet, src net.Addr) ([]objects.APDUPayload, error) {
+			atomic.AddInt32(&requests, 1)
+			dec, _ := msg.(*services.ConfirmedAtomicReadFile).Decode()
+			data, eof, err := s.Device.Lookup(objects.ObjectTypeFile, 1).ReadStream(dec.Start, dec.Count, math.MaxInt32)
+			if err != nil {
+				return nil, err
+			}
+
+			objs := services.AtomicReadFileACKStreamObjects(eof, dec.Start, data)
+			if l := plumbing.NewAPDU(plumbing.ComplexAck, 0, objs).MarshalLen(); l > 206 {
+				t.Errorf("got a %d octet reply", l)
+			}
+			return objs, nil
+		})
+	})
+
+	c := newTestClient(t)
+	c.Devices.Bind(bacnet.DeviceInfo{DeviceId: 321, Address: bacnet.Address{Addr: addr}, MaxAPDU: 206})
+
+	var got bytes.Buffer
+	n, err := c.DownloadFile(context.Background(), 321, 1, &got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 5000 || !bytes.Equal(got.Bytes(), content) {
+		t.Errorf("downloaded %d octets not matching the file", n)
+	}
+	// Replies of 206 octets carry 188 octets of data.
+	if got := atomic.LoadInt32(&requests); got != 27 {
+		t.Errorf("got %d requests, want 27", got)
+	}
+
+	upload := bytes.Repeat([]byte("new configuration\n"), 100)
+	if n, err := c.UploadFile(context.Background(), 321, 1, bytes.NewReader(upload)); err != nil || n != int64(len(upload)) {
+		t.Fatalf("got %d, %v uploading, want %d octets", n, err, len(upload))
+	}
+	if !bytes.Equal(buf.Bytes(), upload) {
+		t.Errorf("got %d octets after uploading, want the %d octets uploaded", len(buf.Bytes()), len(upload))
+	}
+
+	_, err = c.UploadFile(context.Background(), 321, 2, bytes.NewReader(upload))
+	var bErr *common.BACnetError
+	if !errors.As(err, &bErr) || bErr.Code != objects.ErrorCodeWriteAccessDenied {
+		t.Errorf("got %v uploading a read-only file, want a write access denied error", err)
+	}
+}
+
+func TestServerAtomicReadFileLimit(t *testing.T) {
+	dev := device.New(321, "dev", 31)
+	stream := device.NewFile(1, "config", "application/octet-stream", device.NewFileBuffer(make([]byte, 5000)))
+	records := device.NewRecordFile(2, "log", "text/csv", nil)
+	if _, err := records.WriteRecords(-1, bytes.Split(bytes.Repeat([]byte("record-000\n"), 500), []byte("\n"))); err != nil {
+		t.Fatal(err)
+	}
+	for _, o := range []*device.Object{stream, records} {
+		if err := dev.Add(o); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_, addr := newTestServer(t, dev)
+	c := newTestClient(t)
+
+	// Huge counts are cut down to what fits in a single APDU rather than aborted.
+	for _, tc := range []struct {
+		description string
+		stream      bool
+		instance    uint32
+	}{
+		{"stream", true, 1},
+		{"records", false, 2},
+	} {
+		t.Run(tc.description, func(t *testing.T) {
+			req := services.NewConfirmedAtomicReadFile(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+			req.APDU.Objects = services.ConfirmedAtomicReadFileObjects(tc.instance, tc.stream, 0, math.MaxInt32)
+			reply, err := c.Request(context.Background(), addr, req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cACK, ok := reply.(*services.ComplexACK)
+			if !ok {
+				t.Fatalf("got %T, want a ComplexACK", reply)
+			}
+			if l := cACK.APDU.MarshalLen(); l > plumbing.MaxAPDULengthIP {
+				t.Errorf("got a %d octet reply", l)
+			}
+			dec, err := cACK.DecodeAtomicReadFile()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if dec.EndOfFile || (len(dec.Data) == 0 && len(dec.Records) == 0) {
+				t.Errorf("got %d octets and %d records, end of file %v, want part of the file", len(dec.Data), len(dec.Records), dec.EndOfFile)
+			}
+		})
+	}
+}
+
+func TestLogIterator(t *testing.T) {
+	// The log holds records 11 to 35, of which 10 fit on a page, and logs another record
+	// after answering each request.
+	var mu sync.Mutex
+	first, last := uint32(11), uint32(35)
+	_, addr := newTestServer(t, device.New(321, "dev", 31), func(s *bacnet.Server) {
+		s.HandleConfirmed(services.ServiceConfirmedReadRange, func(msg plumbing.BACnet, src net.Addr) ([]objects.APDUPayload, error) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			dec, _ := msg.(*services.ConfirmedReadRange).Decode()
+			from := first
+			switch dec.Range.Type {
+			case services.RangeBySequenceNumber:
+				from = dec.Range.Reference
+			case services.RangeByTime:
+			default:
+				t.Errorf("unexpected range %+v", dec.Range)
+			}
+
+			result := services.RangeResult{FirstSequenceNumber: from, FirstItem: from == first, ItemData: []objects.APDUPayload{}}
+			for seq := from; seq <= last && result.ItemCount < 10; seq++ {
+				result.ItemData = append(result.ItemData, objects.EncUnsignedInteger(seq))
+				result.ItemCount++
+			}
+			result.LastItem = from+result.ItemCount-1 == last
+			result.MoreItems = !result.LastItem
+			last++
+
+			return services.ReadRangeACKObjects(dec.ObjectType, dec.InstanceId, dec.PropertyId, dec.ArrayIndex, result), nil
+		})
+	})
+
+	c := newTestClient(t)
+	c.Devices.Bind(bacnet.DeviceInfo{DeviceId: 321, Address: bacnet.Address{Addr: addr}})
+
+	var got []uint32
+	it := c.IterateLog(321, objects.ObjectTypeTrendLog, 1, objects.PropertyIdLogBuffer)
+	for it.Next(context.Background()) {
+		if it.SequenceNumber() != 11+uint32(len(got)) {
+			t.Errorf("got page starting at %d after %d records", it.SequenceNumber(), len(got))
+		}
+		for _, obj := range it.Records() {
+			seq, err := objects.DecUnisgnedInteger(obj)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got = append(got, seq)
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []uint32{}
+	for seq := uint32(11); seq <= 37; seq++ {
+		want = append(want, seq)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("records mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestServerTrendLog(t *testing.T) {
+	dev := device.New(321, "dev", 31)
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	dev.SetClock(func() time.Time { return now })
+	dev.SetLocation(time.UTC)
+
+	ai := device.NewAnalogInput(0, "AI-0", objects.UnitsDegreesCelsius)
+	tl := device.NewTrendLog(0, "TL-0", services.DeviceObjectPropertyReference{
+		Object: ai.Identifier, PropertyId: objects.PropertyIdPresentValue, ArrayIndex: objects.ArrayAll, DeviceId: objects.MaxInstance,
+	}, time.Second, 200)
+	for _, o := range []*device.Object{ai, tl} {
+		if err := dev.Add(o); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The first record tells logging started and the 100 that follow span more than a page.
+	for i := 0; i < 100; i++ {
+		if err := ai.Set(objects.PropertyIdPresentValue, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+		dev.Tick()
+		now = now.Add(time.Second)
+	}
+
+	_, addr := newTestServer(t, dev)
+	c := newTestClient(t)
+	c.Devices.Bind(bacnet.DeviceInfo{DeviceId: 321, Address: bacnet.Address{Addr: addr}})
+
+	var got []services.LogRecord
+	pages := 0
+	it := c.IterateLog(321, objects.ObjectTypeTrendLog, 0, objects.PropertyIdLogBuffer)
+	for it.Next(context.Background()) {
+		records, err := it.LogRecords()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, records...)
+		pages++
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 101 || pages < 2 {
+		t.Fatalf("got %d records on %d pages, want 101 on several", len(got), pages)
+	}
+	if got[0].LogStatus == nil || got[0].LogStatus[services.LogStatusLogDisabled] {
+		t.Errorf("first record is %+v, want logging enabled", got[0])
+	}
+	for i, r := range got[1:] {
+		if r.Value != float32(i) {
+			t.Errorf("record %d holds %v, want %d", i+2, r.Value, i)
+		}
+	}
+}
+
+func TestServerEventNotification(t *testing.T) {
+	dev := device.New(321, "dev", 31)
+	nc := device.NewNotificationClass(1, "NC-1")
+	bi := device.NewBinaryInput(2, "BI-2")
+	for _, o := range []*device.Object{nc, bi} {
+		if err := dev.Add(o); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := bi.AddIntrinsicReporting(1); err != nil {
+		t.Fatal(err)
+	}
+
+	c := newTestClient(t)
+	confirmed := make(chan services.EventNotificationDec, 1)
+	unconfirmed := make(chan services.EventNotificationDec, 1)
+	c.HandleConfirmed(services.ServiceConfirmedEventNotification, func(msg plumbing.BACnet, src net.Addr) ([]objects.APDUPayload, error) {
+		dec, err := msg.(*services.ConfirmedEventNotification).Decode()
+		if err != nil {
+			return nil, err
+		}
+		confirmed <- dec
+		return nil, nil
+	})
+	c.HandleUnconfirmed(services.ServiceUnconfirmedEventNotification, func(msg plumbing.BACnet, src net.Addr) {
+		if dec, err := msg.(*services.UnconfirmedEventNotification).Decode(); err == nil {
+			unconfirmed <- dec
+		}
+	})
+
+	s, _ := newTestServer(t, dev)
+	s.Devices.Bind(bacnet.DeviceInfo{DeviceId: 77, Address: bacnet.Address{Addr: c.LocalAddr()}})
+
+	udpAddr := c.LocalAddr().(*net.UDPAddr)
+	mac := append(append([]byte{}, udpAddr.IP.To4()...), byte(udpAddr.Port>>8), byte(udpAddr.Port))
+	always := device.Destination{
+		ValidDays:   objects.BitString{true, true, true, true, true, true, true},
+		ToTime:      objects.Time{Hour: 23, Minute: 59, Second: 59, Hundredths: 99},
+		Transitions: objects.BitString{true, true, true},
+	}
+	byDevice, byAddress := always, always
+	byDevice.Recipient, byDevice.ProcessId, byDevice.Confirmed = services.Recipient{DeviceId: 77}, 1, true
+	byAddress.Recipient, byAddress.ProcessId = services.Recipient{Address: &services.Address{MAC: mac}}, 2
+	if err := nc.Set(objects.PropertyIdRecipientList, []interface{}{byDevice, byAddress}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bi.Set(objects.PropertyIdPresentValue, objects.Enumerated(objects.BinaryActive)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, ch := range []chan services.EventNotificationDec{confirmed, unconfirmed} {
+		select {
+		case n := <-ch:
+			if n.DeviceId != 321 || n.Object != bi.Identifier || n.EventType != objects.EventTypeChangeOfState ||
+				n.ToState != objects.EventStateOffnormal || n.Priority != 255 {
+				t.Errorf("unexpected notification %+v", n)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("no notification was delivered")
+		}
+	}
+}
+
+func TestServerEventNotificationRecipients(t *testing.T) {
+	dev := device.New(321, "dev", 31)
+	nc := device.NewNotificationClass(1, "NC-1")
+	bi := device.NewBinaryInput(2, "BI-2")
+	for _, o := range []*device.Object{nc, bi} {
+		if err := dev.Add(o); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := bi.AddIntrinsicReporting(1); err != nil {
+		t.Fatal(err)
+	}
+
+	// The console starts reading once it knows where to broadcast its I-Am.
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := bacnet.NewClient(conn)
+	t.Cleanup(func() { c.Close() })
+	console := bacnet.NewIAmResponder(c, device.New(77, "console", 31))
+	c.HandleUnconfirmed(services.ServiceUnconfirmedWhoIs, console.ServeWhoIs)
+	confirmed := make(chan services.EventNotificationDec, 1)
+	unconfirmed := make(chan *services.UnconfirmedEventNotification, 1)
+	c.HandleConfirmed(services.ServiceConfirmedEventNotification, func(msg plumbing.BACnet, src net.Addr) ([]objects.APDUPayload, error) {
+		dec, err := msg.(*services.ConfirmedEventNotification).Decode()
+		if err != nil {
+			return nil, err
+		}
+		confirmed <- dec
+		return nil, nil
+	})
+	c.HandleUnconfirmed(services.ServiceUnconfirmedEventNotification, func(msg plumbing.BACnet, src net.Addr) {
+		unconfirmed <- msg.(*services.UnconfirmedEventNotification)
+	})
+
+	// Broadcasts go straight to their peer, as in TestIAmResponder.
+	failed := make(chan device.EventNotification, 1)
+	_, addr := newTestServer(t, dev, func(s *bacnet.Server) {
+		s.BroadcastAddr = c.LocalAddr()
+		s.Discoverer.Window = 200 * time.Millisecond
+		s.EventFailed = func(n device.EventNotification, err error) {
+			if !errors.Is(err, common.ErrUnknownDevice) {
+				t.Errorf("got %v, want common.ErrUnknownDevice", err)
+			}
+			failed <- n
+		}
+	})
+	c.BroadcastAddr = addr
+	go c.Run()
+
+	always := device.Destination{
+		ValidDays:   objects.BitString{true, true, true, true, true, true, true},
+		ToTime:      objects.Time{Hour: 23, Minute: 59, Second: 59, Hundredths: 99},
+		Confirmed:   true,
+		Transitions: objects.BitString{true, true, true},
+	}
+	unbound, broadcast, missing := always, always, always
+	unbound.Recipient, unbound.ProcessId = services.Recipient{DeviceId: 77}, 1
+	broadcast.Recipient, broadcast.ProcessId = services.Recipient{Address: &services.Address{}}, 2
+	missing.Recipient, missing.ProcessId = services.Recipient{DeviceId: 78}, 3
+	if err := nc.Set(objects.PropertyIdRecipientList, []interface{}{unbound, broadcast, missing}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bi.Set(objects.PropertyIdPresentValue, objects.Enumerated(objects.BinaryActive)); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case n := <-confirmed:
+		if n.ProcessId != 1 {
+			t.Errorf("got process %d confirmed, want 1", n.ProcessId)
+		}
+	case <-time.After(time.Second):
+		t.Error("the recipient given by device instance wasn't bound")
+	}
+	select {
+	case u := <-unconfirmed:
+		dec, err := u.Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if dec.ProcessId != 2 || u.BVLC.Function != plumbing.BVLCFuncBroadcast {
+			t.Errorf("got process %d with BVLC function %#x, want a broadcast to process 2", dec.ProcessId, u.BVLC.Function)
+		}
+	case <-time.After(time.Second):
+		t.Error("the confirmed notification to a broadcast address wasn't sent unconfirmed")
+	}
+	select {
+	case n := <-failed:
+		if n.ProcessId != 3 || n.Recipient.DeviceId != 78 {
+			t.Errorf("unexpected failed notification %+v", n)
+		}
+	case <-time.After(time.Second):
+		t.Error("the notification to a missing device didn't fail")
+	}
+}
+
+func TestServerEventNotificationLookup(t *testing.T) {
+	dev := device.New(321, "dev", 31)
+	nc := device.NewNotificationClass(1, "NC-1")
+	bi := device.NewBinaryInput(2, "BI-2")
+	for _, o := range []*device.Object{nc, bi} {
+		if err := dev.Add(o); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := bi.AddIntrinsicReporting(1); err != nil {
+		t.Fatal(err)
+	}
+
+	// The console starts reading once it knows where to broadcast its I-Am, which it holds
+	// back long enough for both transitions to happen before the recipient is bound.
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := bacnet.NewClient(conn)
+	t.Cleanup(func() { c.Close() })
+	console := bacnet.NewIAmResponder(c, device.New(77, "console", 31))
+	var whoIs int32
+	c.HandleUnconfirmed(services.ServiceUnconfirmedWhoIs, func(msg plumbing.BACnet, src net.Addr) {
+		atomic.AddInt32(&whoIs, 1)
+		time.AfterFunc(50*time.Millisecond, func() { console.ServeWhoIs(msg, src) })
+	})
+	notifications := make(chan services.EventNotificationDec, 2)
+	c.HandleConfirmed(services.ServiceConfirmedEventNotification, func(msg plumbing.BACnet, src net.Addr) ([]objects.APDUPayload, error) {
+		dec, err := msg.(*services.ConfirmedEventNotification).Decode()
+		if err != nil {
+			return nil, err
+		}
+		notifications <- dec
+		return nil, nil
+	})
+
+	// Broadcasts go straight to their peer, as in TestIAmResponder.
+	_, addr := newTestServer(t, dev, func(s *bacnet.Server) {
+		s.BroadcastAddr = c.LocalAddr()
+		s.Discoverer.Window = 500 * time.Millisecond
+	})
+	c.BroadcastAddr = addr
+	go c.Run()
+
+	operator := device.Destination{
+		ValidDays:   objects.BitString{true, true, true, true, true, true, true},
+		ToTime:      objects.Time{Hour: 23, Minute: 59, Second: 59, Hundredths: 99},
+		Recipient:   services.Recipient{DeviceId: 77},
+		ProcessId:   1,
+		Confirmed:   true,
+		Transitions: objects.BitString{true, true, true},
+	}
+	if err := nc.Set(objects.PropertyIdRecipientList, []interface{}{operator}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, value := range []uint8{objects.BinaryActive, objects.BinaryInactive} {
+		if err := bi.Set(objects.PropertyIdPresentValue, objects.Enumerated(value)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	states := map[uint8]bool{}
+	for i := 0; i < 2; i++ {
+		select {
+		case n := <-notifications:
+			states[n.ToState] = true
+		case <-time.After(time.Second):
+			t.Fatal("not every notification was delivered")
+		}
+	}
+	if !states[objects.EventStateOffnormal] || !states[objects.EventStateNormal] {
+		t.Errorf("got notifications to %v, want both transitions", states)
+	}
+	if got := atomic.LoadInt32(&whoIs); got != 1 {
+		t.Errorf("got %d Who-Is, want the notifications to share a single one", got)
+	}
+}
+
+func TestServerAlarms(t *testing.T) {
+	dev := device.New(321, "dev", 31)
+	nc := device.NewNotificationClass(1, "NC-1")
+	if err := dev.Add(nc); err != nil {
+		t.Fatal(err)
+	}
+	// More alarms than fit in a single GetEventInformation ACK.
+	var inputs []*device.Object
+	for i := uint32(1); i <= 30; i++ {
+		bi := device.NewBinaryInput(i, fmt.Sprintf("BI-%d", i))
+		if err := dev.Add(bi); err != nil {
+			t.Fatal(err)
+		}
+		if err := bi.AddIntrinsicReporting(1); err != nil {
+			t.Fatal(err)
+		}
+		inputs = append(inputs, bi)
+	}
+
+	c := newTestClient(t)
+	console := bacnet.NewAlarmConsole(c, nil)
+
+	s, addr := newTestServer(t, dev)
+	s.Devices.Bind(bacnet.DeviceInfo{DeviceId: 77, Address: bacnet.Address{Addr: c.LocalAddr()}})
+	c.Devices.Bind(bacnet.DeviceInfo{DeviceId: 321, Address: bacnet.Address{Addr: addr}})
+
+	operator := device.Destination{
+		ValidDays:   objects.BitString{true, true, true, true, true, true, true},
+		ToTime:      objects.Time{Hour: 23, Minute: 59, Second: 59, Hundredths: 99},
+		Recipient:   services.Recipient{DeviceId: 77},
+		ProcessId:   5,
+		Confirmed:   true,
+		Transitions: objects.BitString{true, true, true},
+	}
+	if err := nc.Set(objects.PropertyIdRecipientList, []interface{}{operator}); err != nil {
+		t.Fatal(err)
+	}
+	if err := nc.Set(objects.PropertyIdAckRequired, objects.BitString{true, false, false}); err != nil {
+		t.Fatal(err)
+	}
+
+	receive := func() bacnet.EventNotification {
+		t.Helper()
+		select {
+		case n := <-console.C:
+			return n
+		case <-time.After(time.Second):
+			t.Fatal("no notification was delivered")
+		}
+		return bacnet.EventNotification{}
+	}
+
+	for _, bi := range inputs {
+		if err := bi.Set(objects.PropertyIdPresentValue, objects.Enumerated(objects.BinaryActive)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var alarm bacnet.EventNotification
+	for range inputs {
+		if n := receive(); n.Object == inputs[0].Identifier {
+			alarm = n
+		}
+	}
+	if !alarm.Confirmed || alarm.ProcessId != 5 || alarm.ToState != objects.EventStateOffnormal || !alarm.AckRequired {
+		t.Fatalf("got alarm %+v", alarm)
+	}
+
+	ctx := context.Background()
+	summaries, err := c.GetEventInformation(ctx, 321)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(summaries) != len(inputs) {
+		t.Fatalf("got %d event summaries, want %d", len(summaries), len(inputs))
+	}
+	for i, s := range summaries {
+		if s.Object != inputs[i].Identifier || s.EventState != objects.EventStateOffnormal || s.AckedTransitions[objects.TransitionToOffnormal] {
+			t.Errorf("got event summary %+v for %v", s, inputs[i].Identifier)
+		}
+	}
+
+	alarms, err := c.GetAlarmSummary(ctx, 321)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(alarms) != len(inputs) {
+		t.Errorf("got %d alarm summaries, want %d", len(alarms), len(inputs))
+	}
+
+	// Acknowledging with the wrong time stamp fails.
+	err = c.AcknowledgeAlarm(ctx, 321, alarm.ProcessId, alarm.Object, alarm.ToState, services.TimeStamp{Choice: services.TimeStampSequence}, "operator")
+	var bErr *common.BACnetError
+	if !errors.As(err, &bErr) || bErr.Code != objects.ErrorCodeInvalidTimeStamp {
+		t.Errorf("got %v acknowledging with a wrong time stamp, want INVALID_TIME_STAMP", err)
+	}
+
+	if err := console.Acknowledge(ctx, alarm, "operator"); err != nil {
+		t.Fatal(err)
+	}
+	if n := receive(); n.Object != alarm.Object || n.NotifyType != objects.NotifyTypeAckNotification || n.ToState != objects.EventStateOffnormal {
+		t.Errorf("got notification %+v, want the acknowledgment", n)
+	}
+
+	notAcked, err := c.GetEnrollmentSummary(ctx, 321, services.EnrollmentFilter{Acknowledgment: services.EnrollmentNotAcked})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notAcked) != len(inputs)-1 {
+		t.Errorf("got %d enrollment summaries awaiting acknowledgment, want %d", len(notAcked), len(inputs)-1)
+	}
+	for _, s := range notAcked {
+		if s.Object == alarm.Object {
+			t.Error("the acknowledged alarm still awaits acknowledgment")
+		}
+		if s.EventType != objects.EventTypeChangeOfState || s.NotificationClass == nil || *s.NotificationClass != 1 || s.Priority != 255 {
+			t.Errorf("got enrollment summary %+v", s)
+		}
+	}
+}
@@ -4,7 +4,10 @@
 
 package common
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // Error definitions.
 var (
@@ -12,8 +15,104 @@ var (
 	ErrTooShortToParse         = errors.New("too short to decode as parameter")
 	ErrNotImplemented          = errors.New("not implemented type")
 	ErrTooBigValue             = errors.New("too big value")
+	ErrUndefinedEnumeration    = errors.New("undefined enumeration value")
 	ErrWrongTagNumber          = errors.New("wrong tag number")
 	ErrWrongObjectCount        = errors.New("wrong object count")
 	ErrWrongStructure          = errors.New("unexpected object structure")
 	ErrWrongPayload            = errors.New("wrong payload type")
+	ErrClosed                  = errors.New("use of a closed client")
+	ErrTimeout                 = errors.New("no reply within the APDU timeout")
+	ErrNoInvokeID              = errors.New("no invoke ID available")
+	ErrUnknownDevice           = errors.New("no binding for the device")
+	ErrCommunicationDisabled   = errors.New("communication disabled by DeviceCommunicationControl")
 )
+
+// BACnetError is an error reported by a peer through an Error PDU or meant to be
+// reported to one.
+type BACnetError struct {
+	Class uint8
+	Code  uint8
+}
+
+func (e *BACnetError) Error() string {
+	return fmt.Sprintf("bacnet error: class %d, code %d", e.Class, e.Code)
+}
+
+// WritePropertyMultipleError is a BACnetError naming the first write of a WritePropertyMultiple
+// request that failed. The writes preceding it were carried out.
+type WritePropertyMultipleError struct {
+	BACnetError
+	ObjectType uint16
+	InstanceId uint32
+	PropertyId uint32
+	ArrayIndex uint32
+}
+
+func (e *WritePropertyMultipleError) Error() string {
+	return fmt.Sprintf("bacnet error: class %d, code %d writing property %d of object %d:%d",
+		e.Class, e.Code, e.PropertyId, e.ObjectType, e.InstanceId)
+}
+
+// Unwrap returns the BACnetError the write failed with.
+func (e *WritePropertyMultipleError) Unwrap() error {
+	return &e.BACnetError
+}
+
+// CreateObjectError is a BACnetError a CreateObject request failed with. FirstFailedElement
+// is the 1-based position of the initial value at fault or 0 if none was.
+type CreateObjectError struct {
+	BACnetError
+	FirstFailedElement uint32
+}
+
+func (e *CreateObjectError) Error() string {
+	if e.FirstFailedElement == 0 {
+		return e.BACnetError.Error()
+	}
+	return fmt.Sprintf("bacnet error: class %d, code %d writing initial value %d",
+		e.Class, e.Code, e.FirstFailedElement)
+}
+
+// Unwrap returns the BACnetError the creation failed with.
+func (e *CreateObjectError) Unwrap() error {
+	return &e.BACnetError
+}
+
+// ChangeListError is a BACnetError an AddListElement or RemoveListElement request failed
+// with. FirstFailedElement is the 1-based position of the element at fault or 0 if none was.
+// The list is left as it was.
+type ChangeListError struct {
+	BACnetError
+	FirstFailedElement uint32
+}
+
+func (e *ChangeListError) Error() string {
+	if e.FirstFailedElement == 0 {
+		return e.BACnetError.Error()
+	}
+	return fmt.Sprintf("bacnet error: class %d, code %d changing list element %d",
+		e.Class, e.Code, e.FirstFailedElement)
+}
+
+// Unwrap returns the BACnetError the change failed with.
+func (e *ChangeListError) Unwrap() error {
+	return &e.BACnetError
+}
+
+// RejectError is returned when a peer rejects a request.
+type RejectError struct {
+	Reason uint8
+}
+
+func (e *RejectError) Error() string {
+	return fmt.Sprintf("bacnet reject: reason %d", e.Reason)
+}
+
+// AbortError is returned when a transaction is aborted.
+type AbortError struct {
+	Reason uint8
+}
+
+func (e *AbortError) Error() string {
+	return fmt.Sprintf("bacnet abort: reason %d", e.Reason)
+}
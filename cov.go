@@ -0,0 +1,313 @@
+package bacnet
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+	"github.com/ulbios/bacnet/services"
+)
+
+// Defaults for COV subscriptions.
+const (
+	DefaultCOVLifetime      = 5 * time.Minute
+	DefaultCOVRetryInterval = 30 * time.Second
+
+	// COVBufferSize is the capacity of the channels notifications are delivered on.
+	COVBufferSize = 16
+)
+
+// COVNotification is a change of value reported by a subscription.
+type COVNotification struct {
+	Source    net.Addr
+	Confirmed bool
+	services.COVNotificationDec
+}
+
+// COVOptions tunes a subscription.
+type COVOptions struct {
+	// Confirmed asks for confirmed notifications.
+	Confirmed bool
+	// Lifetime of the subscription on the device, DefaultCOVLifetime if zero. It's
+	// renewed well before it expires.
+	Lifetime time.Duration
+	// Callback, if set, receives the notifications instead of the subscription channel.
+	// It runs on the read loop of the Client, so it must not block.
+	Callback func(COVNotification)
+}
+
+// COVSubscription is a subscription kept alive by a COVManager.
+type COVSubscription struct {
+	DeviceId   uint32
+	ObjectType uint16
+	InstanceId uint32
+
+	// C delivers notifications unless a callback was configured. Notifications are
+	// dropped when it's full. It's closed once the subscription is cancelled.
+	C <-chan COVNotification
+
+	m         *COVManager
+	processId uint32
+	opts      COVOptions
+
+	mu     sync.Mutex
+	addr   net.Addr
+	err    error
+	c      chan COVNotification
+	closed bool
+
+	resubscribe chan struct{}
+	ctx         context.Context
+	cancel      context.CancelFunc
+	done        chan struct{}
+}
+
+// COVManager keeps COV subscriptions alive. It renews them before their lifetime expires,
+// resubscribes when devices announce themselves after a restart through I-Am and retries
+// failed subscriptions every RetryInterval.
+type COVManager struct {
+	RetryInterval time.Duration
+
+	client *Client
+
+	mu            sync.Mutex
+	nextProcessId uint32
+	subscriptions map[uint32]*COVSubscription
+}
+
+// NewCOVManager creates a COVManager issuing requests through c, whose read loop must be running.
+func NewCOVManager(c *Client) *COVManager {
+	m := &COVManager{
+		RetryInterval: DefaultCOVRetryInterval,
+		client:        c,
+		nextProcessId: 1,
+		subscriptions: map[uint32]*COVSubscription{},
+	}
+
+	c.HandleConfirmed(services.ServiceConfirmedCOVNotification, m.confirmedNotification)
+	c.HandleUnconfirmed(services.ServiceUnconfirmedCOVNotification, m.unconfirmedNotification)
+	c.HandleUnconfirmed(services.ServiceUnconfirmedIAm, m.iAm)
+
+	return m
+}
+
+// Subscribe subscribes to changes of value of an object living on the device at addr.
+func (m *COVManager) Subscribe(ctx context.Context, addr net.Addr, deviceId uint32, objectType uint16, instance uint32, opts COVOptions) (*COVSubscription, error) {
+	if opts.Lifetime == 0 {
+		opts.Lifetime = DefaultCOVLifetime
+	}
+
+	c := make(chan COVNotification, COVBufferSize)
+	s := &COVSubscription{
+		DeviceId:    deviceId,
+		ObjectType:  objectType,
+		InstanceId:  instance,
+		C:           c,
+		m:           m,
+		opts:        opts,
+		addr:        addr,
+		c:           c,
+		resubscribe: make(chan struct{}, 1),
+		done:        make(chan struct{}),
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+
+	m.mu.Lock()
+	s.processId = m.nextProcessId
+	m.nextProcessId++
+	m.subscriptions[s.processId] = s
+	m.mu.Unlock()
+
+	if err := s.subscribe(ctx); err != nil {
+		m.forget(s)
+		s.cancel()
+		return nil, err
+	}
+
+	go s.maintain()
+
+	return s, nil
+}
+
+// Close cancels every subscription.
+func (m *COVManager) Close() error {
+	m.mu.Lock()
+	subscriptions := make([]*COVSubscription, 0, len(m.subscriptions))
+	for _, s := range m.subscriptions {
+		subscriptions = append(subscriptions, s)
+	}
+	m.mu.Unlock()
+
+	var err error
+	for _, s := range subscriptions {
+		if cErr := s.Cancel(); cErr != nil && err == nil {
+			err = cErr
+		}
+	}
+	return err
+}
+
+func (m *COVManager) forget(s *COVSubscription) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.subscriptions, s.processId)
+}
+
+func (m *COVManager) lookup(processId uint32) *COVSubscription {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.subscriptions[processId]
+}
+
+func (m *COVManager) confirmedNotification(msg plumbing.BACnet, src net.Addr) ([]objects.APDUPayload, error) {
+	dec, err := msg.(*services.ConfirmedCOVNotification).Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	m.deliver(COVNotification{Source: src, Confirmed: true, COVNotificationDec: dec})
+
+	// Notifications of subscriptions we no longer know about are acknowledged all the same.
+	return nil, nil
+}
+
+func (m *COVManager) unconfirmedNotification(msg plumbing.BACnet, src net.Addr) {
+	dec, err := msg.(*services.UnconfirmedCOVNotification).Decode()
+	if err != nil {
+		return
+	}
+
+	m.deliver(COVNotification{Source: src, COVNotificationDec: dec})
+}
+
+func (m *COVManager) deliver(n COVNotification) {
+	s := m.lookup(n.ProcessId)
+	if s == nil || s.DeviceId != n.DeviceId || s.ObjectType != n.ObjectType || s.InstanceId != n.InstanceId {
+		return
+	}
+
+	if s.opts.Callback != nil {
+		s.opts.Callback(n)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	select {
+	case s.c <- n:
+	default:
+	}
+}
+
+// iAm resubscribes to the objects of devices announcing themselves, as they may have restarted.
+func (m *COVManager) iAm(msg plumbing.BACnet, src net.Addr) {
+	dec, err := msg.(*services.UnconfirmedIAm).Decode()
+	if err != nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, s := range m.subscriptions {
+		if s.DeviceId != dec.DeviceId {
+			continue
+		}
+
+		s.mu.Lock()
+		s.addr = src
+		s.mu.Unlock()
+
+		select {
+		case s.resubscribe <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Addr returns the address of the device the subscription lives on.
+func (s *COVSubscription) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.addr
+}
+
+// Err returns the error of the last attempt to renew the subscription, if any.
+func (s *COVSubscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.err
+}
+
+// Cancel cancels the subscription on the device and closes its channel.
+func (s *COVSubscription) Cancel() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.c)
+	s.mu.Unlock()
+
+	s.m.forget(s)
+	s.cancel()
+	<-s.done
+
+	req := services.NewConfirmedSubscribeCOV(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+	req.APDU.Objects = services.ConfirmedSubscribeCOVCancelObjects(s.processId, s.ObjectType, s.InstanceId)
+
+	_, err := s.m.client.Request(context.Background(), s.Addr(), req)
+	return err
+}
+
+func (s *COVSubscription) subscribe(ctx context.Context) error {
+	req := services.NewConfirmedSubscribeCOV(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+	req.APDU.Objects = services.ConfirmedSubscribeCOVObjects(
+		s.processId, s.ObjectType, s.InstanceId, s.opts.Confirmed, uint32(s.opts.Lifetime/time.Second))
+
+	_, err := s.m.client.Request(ctx, s.Addr(), req)
+
+	s.mu.Lock()
+	s.err = err
+	s.mu.Unlock()
+
+	return err
+}
+
+// maintain renews the subscription until it's cancelled.
+func (s *COVSubscription) maintain() {
+	defer close(s.done)
+
+	for {
+		// Renew once three quarters of the lifetime have elapsed so that a couple of
+		// retransmissions still fit before it expires.
+		wait := s.opts.Lifetime * 3 / 4
+		if s.Err() != nil && s.m.RetryInterval < wait {
+			wait = s.m.RetryInterval
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-s.resubscribe:
+			timer.Stop()
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		}
+
+		s.subscribe(s.ctx)
+	}
+}
@@ -0,0 +1,256 @@
+package bacnet
+
+import (
+	"context"
+	"errors"
+
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+	"github.com/ulbios/bacnet/services"
+)
+
+// crawledProperties are the properties Crawl reads off every object.
+var crawledProperties = []uint32{
+	objects.PropertyIdObjectName,
+	objects.PropertyIdObjectType,
+	objects.PropertyIdUnits,
+	objects.PropertyIdDescription,
+	objects.PropertyIdPresentValue,
+}
+
+// ObjectInfo describes an object found by Crawl. Properties the object lacks are left zero.
+type ObjectInfo struct {
+	ObjectType  uint16
+	InstanceId  uint32
+	Name        string
+	Description string
+	// Units is nil for objects without units.
+	Units *uint32
+	// PresentValue holds the Go value objects.DecValue maps it to.
+	PresentValue interface{}
+}
+
+// Inventory is the list of objects living on a device.
+type Inventory struct {
+	Device  DeviceInfo
+	Objects []ObjectInfo
+}
+
+// Crawl enumerates the objects of a device bound in Devices along with their name, type,
+// units, description and present value. The Object_List is read index by index when the
+// device can't return it whole in a single unsegmented reply. The properties of each object
+// are read at once with ReadPropertyMultiple if the device supports it, and one by one
+// otherwise.
+func (c *Client) Crawl(ctx context.Context, deviceId uint32) (*Inventory, error) {
+	info, ok := c.Devices.Lookup(deviceId)
+	if !ok {
+		return nil, common.ErrUnknownDevice
+	}
+
+	ids, err := c.readObjectList(ctx, deviceId)
+	if err != nil {
+		return nil, err
+	}
+
+	rpm := c.supports(ctx, deviceId, services.ServiceConfirmedReadPropMultiple)
+
+	inv := &Inventory{Device: info, Objects: make([]ObjectInfo, 0, len(ids))}
+	for _, id := range ids {
+		var values map[uint32][]objects.APDUPayload
+		if rpm {
+			values, err = c.readObjectMultiple(ctx, deviceId, id)
+
+			var rErr *common.RejectError
+			if errors.As(err, &rErr) {
+				rpm = false
+			}
+		}
+		if !rpm {
+			values, err = c.readObject(ctx, deviceId, id)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		inv.Objects = append(inv.Objects, objectInfo(id, values))
+	}
+
+	return inv, nil
+}
+
+// readProperty reads a property off a device bound in Devices.
+func (c *Client) readProperty(ctx context.Context, deviceId uint32, objectType uint16, instance uint32, propertyId uint32, arrayIndex uint32) ([]objects.APDUPayload, error) {
+	req := services.NewConfirmedReadProperty(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+	req.APDU.Objects = services.ConfirmedReadPropertyObjects(objectType, instance, propertyId, arrayIndex)
+
+	reply, err := c.RequestDevice(ctx, deviceId, req)
+	if err != nil {
+		return nil, err
+	}
+
+	cACK, ok := reply.(*services.ComplexACK)
+	if !ok {
+		return nil, common.ErrWrongStructure
+	}
+	dec, err := cACK.Decode()
+	if err != nil {
+		return nil, err
+	}
+	return dec.Values, nil
+}
+
+// readObjectList reads the Object_List of a device, falling back to reading it element by
+// element when the reply is aborted for not fitting in a single APDU.
+func (c *Client) readObjectList(ctx context.Context, deviceId uint32) ([]objects.ObjectIdentifier, error) {
+	values, err := c.readProperty(ctx, deviceId, objects.ObjectTypeDevice, deviceId, objects.PropertyIdObjectList, objects.ArrayAll)
+
+	var aErr *common.AbortError
+	if errors.As(err, &aErr) {
+		values, err = c.readObjectListElements(ctx, deviceId)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]objects.ObjectIdentifier, 0, len(values))
+	for _, value := range values {
+		id, err := objects.DecObjectIdentifier(value)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func (c *Client) readObjectListElements(ctx context.Context, deviceId uint32) ([]objects.APDUPayload, error) {
+	values, err := c.readProperty(ctx, deviceId, objects.ObjectTypeDevice, deviceId, objects.PropertyIdObjectList, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != 1 {
+		return nil, common.ErrWrongObjectCount
+	}
+	n, err := objects.DecUnisgnedInteger(values[0])
+	if err != nil {
+		return nil, err
+	}
+
+	elements := make([]objects.APDUPayload, 0, n)
+	for i := uint32(1); i <= n; i++ {
+		values, err := c.readProperty(ctx, deviceId, objects.ObjectTypeDevice, deviceId, objects.PropertyIdObjectList, i)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) != 1 {
+			return nil, common.ErrWrongObjectCount
+		}
+		elements = append(elements, values[0])
+	}
+	return elements, nil
+}
+
+// supports tells whether a device flags a confirmed service on its Protocol_Services_Supported.
+func (c *Client) supports(ctx context.Context, deviceId uint32, service uint8) bool {
+	values, err := c.readProperty(ctx, deviceId, objects.ObjectTypeDevice, deviceId, objects.PropertyIdProtocolServicesSupported, objects.ArrayAll)
+	if err != nil || len(values) != 1 {
+		return false
+	}
+	supported, err := objects.DecBitString(values[0])
+	if err != nil {
+		return false
+	}
+
+	bit := services.ServiceSupportedBit(true, service)
+	return bit >= 0 && bit < len(supported) && supported[bit]
+}
+
+// readObject reads the crawled properties of an object one by one, skipping those the
+// device reports an error for.
+func (c *Client) readObject(ctx context.Context, deviceId uint32, id objects.ObjectIdentifier) (map[uint32][]objects.APDUPayload, error) {
+	values := map[uint32][]objects.APDUPayload{}
+	for _, propertyId := range crawledProperties {
+		v, err := c.readProperty(ctx, deviceId, id.ObjectType, id.InstanceNumber, propertyId, objects.ArrayAll)
+
+		var bErr *common.BACnetError
+		switch {
+		case errors.As(err, &bErr):
+			continue
+		case err != nil:
+			return nil, err
+		}
+		values[propertyId] = v
+	}
+	return values, nil
+}
+
+// readObjectMultiple reads the crawled properties of an object with a single
+// ReadPropertyMultiple, skipping those the device reports an error for.
+func (c *Client) readObjectMultiple(ctx context.Context, deviceId uint32, id objects.ObjectIdentifier) (map[uint32][]objects.APDUPayload, error) {
+	spec := services.ReadAccessSpecification{ObjectType: id.ObjectType, InstanceId: id.InstanceNumber}
+	for _, propertyId := range crawledProperties {
+		spec.Properties = append(spec.Properties, services.PropertyReference{PropertyId: propertyId, ArrayIndex: objects.ArrayAll})
+	}
+
+	req := services.NewConfirmedReadPropertyMultiple(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+	req.APDU.Objects = services.ConfirmedReadPropertyMultipleObjects([]services.ReadAccessSpecification{spec})
+
+	reply, err := c.RequestDevice(ctx, deviceId, req)
+	if err != nil {
+		return nil, err
+	}
+
+	cACK, ok := reply.(*services.ComplexACK)
+	if !ok {
+		return nil, common.ErrWrongStructure
+	}
+	dec, err := cACK.DecodeReadPropertyMultiple()
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[uint32][]objects.APDUPayload{}
+	for _, result := range dec.Results {
+		for _, r := range result.Results {
+			if r.Error == nil {
+				values[r.PropertyId] = r.Values
+			}
+		}
+	}
+	return values, nil
+}
+
+// objectInfo decodes the crawled properties of an object. Values of unexpected types are left out.
+func objectInfo(id objects.ObjectIdentifier, values map[uint32][]objects.APDUPayload) ObjectInfo {
+	o := ObjectInfo{ObjectType: id.ObjectType, InstanceId: id.InstanceNumber}
+
+	single := func(propertyId uint32) objects.APDUPayload {
+		if v := values[propertyId]; len(v) == 1 {
+			return v[0]
+		}
+		return nil
+	}
+
+	if v := single(objects.PropertyIdObjectName); v != nil {
+		o.Name, _ = objects.DecCharacterString(v)
+	}
+	if v := single(objects.PropertyIdObjectType); v != nil {
+		if objectType, err := objects.DecEnumerated(v); err == nil {
+			o.ObjectType = uint16(objectType)
+		}
+	}
+	if v := single(objects.PropertyIdUnits); v != nil {
+		if units, err := objects.DecEnumerated(v); err == nil {
+			o.Units = &units
+		}
+	}
+	if v := single(objects.PropertyIdDescription); v != nil {
+		o.Description, _ = objects.DecCharacterString(v)
+	}
+	if v := single(objects.PropertyIdPresentValue); v != nil {
+		o.PresentValue, _ = objects.DecValue(v)
+	}
+
+	return o
+}
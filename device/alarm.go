@@ -0,0 +1,236 @@
+package device
+
+import (
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/services"
+)
+
+// AcknowledgeAlarm acknowledges the transition of an object reporting events to an
+// Event_State, which must be the latest one of its kind as told by its time stamp. The
+// acknowledgment is notified to the recipients of the object's Notification Class, as long as
+// the transition wasn't acknowledged already.
+func (d *Device) AcknowledgeAlarm(ack services.ConfirmedAcknowledgeAlarmDec) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	o := d.lookup(ack.Object.ObjectType, ack.Object.InstanceNumber)
+	if o == nil {
+		return ErrUnknownObject
+	}
+	e := o.event
+	if e == nil {
+		return ErrEventsNotSupported
+	}
+
+	kind := transitionOf(ack.EventState)
+	stamps, _ := o.value(objects.PropertyIdEventTimeStamps).([]interface{})
+	if stamps[kind] != ack.TimeStamp {
+		return ErrInvalidTimeStamp
+	}
+
+	acked := append(objects.BitString{}, o.value(objects.PropertyIdAckedTransitions).(objects.BitString)...)
+	if acked[kind] {
+		return nil
+	}
+	acked[kind] = true
+	o.properties[objects.PropertyIdAckedTransitions].Value = acked
+
+	class, _ := o.value(objects.PropertyIdNotificationClass).(uint32)
+	if nc := d.notificationClass(class); nc != nil {
+		e.notify(nc, kind, o.localNow(), services.EventNotificationDec{
+			TimeStamp:  ack.TimeOfAcknowledge,
+			NotifyType: objects.NotifyTypeAckNotification,
+			ToState:    ack.EventState,
+		})
+	}
+
+	return nil
+}
+
+// EventInformation returns the event summaries of the objects which aren't NORMAL or have
+// transitions yet to be acknowledged, from the object following last on, or from the first
+// one when last is nil. The summaries are cut down to those fitting in maxLen octets, in
+// which case more is set.
+func (d *Device) EventInformation(last *objects.ObjectIdentifier, maxLen int) (summaries []services.EventSummary, more bool, err error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	order := d.order
+	if last != nil {
+		i := 0
+		for i < len(order) && order[i].Identifier != *last {
+			i++
+		}
+		if i == len(order) {
+			return nil, false, ErrUnknownObject
+		}
+		order = order[i+1:]
+	}
+
+	summaries = []services.EventSummary{}
+	length := 0
+	for _, o := range order {
+		if o.event == nil {
+			continue
+		}
+		state := o.event.state()
+		acked, _ := o.value(objects.PropertyIdAckedTransitions).(objects.BitString)
+		if state == objects.EventStateNormal && allSet(acked) {
+			continue
+		}
+
+		class, _ := o.value(objects.PropertyIdNotificationClass).(uint32)
+		notifyType, _ := o.value(objects.PropertyIdNotifyType).(objects.Enumerated)
+		enable, _ := o.value(objects.PropertyIdEventEnable).(objects.BitString)
+		s := services.EventSummary{
+			Object:           o.Identifier,
+			EventState:       state,
+			AckedTransitions: acked,
+			NotifyType:       uint8(notifyType),
+			EventEnable:      enable,
+			EventPriorities:  priorities(d.notificationClass(class)),
+		}
+		stamps, _ := o.value(objects.PropertyIdEventTimeStamps).([]interface{})
+		for i := range s.EventTimeStamps {
+			s.EventTimeStamps[i], _ = stamps[i].(services.TimeStamp)
+		}
+
+		for _, obj := range services.EncEventSummary(s) {
+			length += obj.MarshalLen()
+		}
+		if length > maxLen {
+			return summaries, true, nil
+		}
+		summaries = append(summaries, s)
+	}
+
+	return summaries, false, nil
+}
+
+// AlarmSummaries returns the alarm summaries of the objects whose Notify_Type is ALARM and
+// which aren't NORMAL.
+func (d *Device) AlarmSummaries() []services.AlarmSummary {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	summaries := []services.AlarmSummary{}
+	for _, o := range d.order {
+		if o.event == nil {
+			continue
+		}
+		state := o.event.state()
+		notifyType, _ := o.value(objects.PropertyIdNotifyType).(objects.Enumerated)
+		if state == objects.EventStateNormal || uint8(notifyType) != objects.NotifyTypeAlarm {
+			continue
+		}
+
+		acked, _ := o.value(objects.PropertyIdAckedTransitions).(objects.BitString)
+		summaries = append(summaries, services.AlarmSummary{Object: o.Identifier, AlarmState: state, AckedTransitions: acked})
+	}
+
+	return summaries
+}
+
+// EnrollmentSummaries returns the enrollment summaries of the objects reporting events which
+// match every filter given.
+func (d *Device) EnrollmentSummaries(filter services.EnrollmentFilter) []services.EnrollmentSummary {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	summaries := []services.EnrollmentSummary{}
+	for _, o := range d.order {
+		if o.event == nil {
+			continue
+		}
+		state := o.event.state()
+		class, _ := o.value(objects.PropertyIdNotificationClass).(uint32)
+		nc := d.notificationClass(class)
+		priority := priorities(nc)[transitionOf(state)]
+
+		acked, _ := o.value(objects.PropertyIdAckedTransitions).(objects.BitString)
+		switch filter.Acknowledgment {
+		case services.EnrollmentAcked:
+			if !allSet(acked) {
+				continue
+			}
+		case services.EnrollmentNotAcked:
+			if allSet(acked) {
+				continue
+			}
+		}
+
+		if filter.Recipient != nil && !enrolls(nc, *filter.Recipient, filter.ProcessId) {
+			continue
+		}
+		if filter.EventState != nil && !matchesState(*filter.EventState, state) {
+			continue
+		}
+		if filter.EventType != nil && *filter.EventType != o.event.eventType {
+			continue
+		}
+		if filter.Priority != nil && (priority < filter.Priority.Min || priority > filter.Priority.Max) {
+			continue
+		}
+		if filter.NotificationClass != nil && *filter.NotificationClass != class {
+			continue
+		}
+
+		summaries = append(summaries, services.EnrollmentSummary{
+			Object:            o.Identifier,
+			EventType:         o.event.eventType,
+			EventState:        state,
+			Priority:          priority,
+			NotificationClass: &class,
+		})
+	}
+
+	return summaries
+}
+
+// enrolls tells whether a recipient, along with its process, is on the Recipient_List of the
+// Notification Class nc.
+func enrolls(nc *Object, recipient services.Recipient, processId uint32) bool {
+	if nc == nil {
+		return false
+	}
+	recipients, _ := nc.value(objects.PropertyIdRecipientList).([]interface{})
+	for _, r := range recipients {
+		dest := r.(Destination)
+		if dest.ProcessId == processId && sameRecipient(dest.Recipient, recipient) {
+			return true
+		}
+	}
+	return false
+}
+
+func sameRecipient(a, b services.Recipient) bool {
+	if a.Address == nil || b.Address == nil {
+		return a.Address == nil && b.Address == nil && a.DeviceId == b.DeviceId
+	}
+	return a.Address.Net == b.Address.Net && string(a.Address.MAC) == string(b.Address.MAC)
+}
+
+// matchesState tells whether an Event_State matches the event state filter of a
+// GetEnrollmentSummary request.
+func matchesState(filter uint8, state uint8) bool {
+	switch filter {
+	case services.EnrollmentStateOffnormal:
+		return transitionOf(state) == objects.TransitionToOffnormal
+	case services.EnrollmentStateFault:
+		return state == objects.EventStateFault
+	case services.EnrollmentStateNormal:
+		return state == objects.EventStateNormal
+	case services.EnrollmentStateActive:
+		return state != objects.EventStateNormal
+	}
+	return true
+}
+
+func allSet(bits objects.BitString) bool {
+	for _, b := range bits {
+		if !b {
+			return false
+		}
+	}
+	return true
+}
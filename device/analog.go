@@ -0,0 +1,58 @@
+package device
+
+import (
+	"github.com/ulbios/bacnet/objects"
+)
+
+// NewAnalogInput creates an Analog Input object whose Present_Value is read-only unless
+// it's Out_Of_Service.
+func NewAnalogInput(instance uint32, name string, units uint32) *Object {
+	return newAnalog(objects.ObjectTypeAnalogInput, instance, name, units, AccessWritableOutOfService)
+}
+
+// NewAnalogOutput creates an Analog Output object.
+func NewAnalogOutput(instance uint32, name string, units uint32) *Object {
+	o := newAnalog(objects.ObjectTypeAnalogOutput, instance, name, units, AccessWritable)
+	o.makeCommandable(o.properties[objects.PropertyIdPresentValue])
+	return o
+}
+
+// NewAnalogValue creates an Analog Value object. Call MakeCommandable to command it.
+func NewAnalogValue(instance uint32, name string, units uint32) *Object {
+	return newAnalog(objects.ObjectTypeAnalogValue, instance, name, units, AccessWritable)
+}
+
+func validatePositive(value interface{}) error {
+	if v, _ := value.(float32); v < 0 {
+		return ErrValueOutOfRange
+	}
+	return nil
+}
+
+func newAnalog(objectType uint16, instance uint32, name string, units uint32, access uint8) *Object {
+	o := newObject(objectType, instance, name)
+
+	o.add(&Property{
+		Identifier: objects.PropertyIdPresentValue,
+		Datatype:   Real,
+		Required:   true,
+		Access:     access,
+		Value:      float32(0),
+	})
+	o.addStatus()
+	o.add(&Property{
+		Identifier: objects.PropertyIdUnits,
+		Datatype:   Enumerated,
+		Required:   true,
+		Value:      objects.Enumerated(units),
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdCOVIncrement,
+		Datatype:   Real,
+		Access:     AccessWritable,
+		Value:      float32(0),
+		Validate:   validatePositive,
+	})
+
+	return o
+}
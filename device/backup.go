@@ -0,0 +1,178 @@
+package device
+
+import (
+	"time"
+
+	"github.com/ulbios/bacnet/objects"
+)
+
+// DefaultBackupFailureTimeout is the Backup_Failure_Timeout of new Devices, in seconds.
+const DefaultBackupFailureTimeout = 300
+
+// BackupState returns the Backup_And_Restore_State of the Device.
+func (d *Device) BackupState() uint8 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.backupState
+}
+
+// StartBackup moves the Device to preparing for a backup, reporting System_Status as
+// backup in progress until the backup is over. It fails with ErrConfigurationInProgress if
+// a backup or restore is under way already.
+func (d *Device) StartBackup() error {
+	return d.startBackup(objects.BackupStatePreparingForBackup)
+}
+
+// StartRestore moves the Device to preparing for a restore, reporting System_Status as
+// backup in progress until the restore is over. It fails with ErrConfigurationInProgress if
+// a backup or restore is under way already.
+func (d *Device) StartRestore() error {
+	return d.startBackup(objects.BackupStatePreparingForRestore)
+}
+
+// BackupPrepared moves a Device prepared for a backup or restore to performing it, the
+// Configuration_Files listing the File objects a client reads or writes the configuration
+// through. Backups and restores fail once Backup_Failure_Timeout seconds go by without any
+// of them being read or written.
+func (d *Device) BackupPrepared(files []objects.ObjectIdentifier) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	switch d.backupState {
+	case objects.BackupStatePreparingForBackup:
+		d.backupState = objects.BackupStatePerformingABackup
+	case objects.BackupStatePreparingForRestore:
+		d.backupState = objects.BackupStatePerformingARestore
+	default:
+		return
+	}
+
+	d.configurationFiles = append([]objects.ObjectIdentifier{}, files...)
+	d.restartBackupDeadline()
+}
+
+// EndBackup ends the backup being performed. It fails with ErrServiceRequestDenied if
+// there's none.
+func (d *Device) EndBackup() error {
+	return d.endBackup(objects.BackupStatePerformingABackup, false)
+}
+
+// EndRestore ends the restore being performed, which bumps the Database_Revision. It fails
+// with ErrServiceRequestDenied if there's none.
+func (d *Device) EndRestore() error {
+	return d.endBackup(objects.BackupStatePerformingARestore, true)
+}
+
+// AbortRestore gives up the restore being performed. It fails with ErrServiceRequestDenied
+// if there's none.
+func (d *Device) AbortRestore() error {
+	return d.endBackup(objects.BackupStatePerformingARestore, false)
+}
+
+// FailBackup moves a Device preparing or performing a backup or restore to the matching
+// failure state.
+func (d *Device) FailBackup() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.failBackup()
+}
+
+func (d *Device) startBackup(state uint8) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.backingUp() {
+		return ErrConfigurationInProgress
+	}
+
+	if d.systemStatus == nil {
+		d.systemStatus = d.object.value(objects.PropertyIdSystemStatus)
+	}
+	d.object.properties[objects.PropertyIdSystemStatus].Value = objects.Enumerated(objects.SystemStatusBackupInProgress)
+	d.backupState = state
+	d.configurationFiles = nil
+	d.backupDeadline = time.Time{}
+	return nil
+}
+
+func (d *Device) endBackup(performing uint8, restored bool) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.backupState != performing {
+		return ErrServiceRequestDenied
+	}
+	if restored {
+		d.revision++
+	}
+	d.leaveBackup(objects.BackupStateIdle)
+	return nil
+}
+
+func (d *Device) failBackup() {
+	switch d.backupState {
+	case objects.BackupStatePreparingForBackup, objects.BackupStatePerformingABackup:
+		d.leaveBackup(objects.BackupStateBackupFailure)
+	case objects.BackupStatePreparingForRestore, objects.BackupStatePerformingARestore:
+		d.leaveBackup(objects.BackupStateRestoreFailure)
+	}
+}
+
+// leaveBackup ends a backup or restore, restoring the System_Status it overrode.
+func (d *Device) leaveBackup(state uint8) {
+	d.backupState = state
+	d.configurationFiles = nil
+	d.backupDeadline = time.Time{}
+	if d.systemStatus != nil {
+		d.object.properties[objects.PropertyIdSystemStatus].Value = d.systemStatus
+		d.systemStatus = nil
+	}
+}
+
+// backingUp tells whether a backup or restore is being prepared or performed.
+func (d *Device) backingUp() bool {
+	switch d.backupState {
+	case objects.BackupStateIdle, objects.BackupStateBackupFailure, objects.BackupStateRestoreFailure:
+		return false
+	}
+	return true
+}
+
+// restartBackupDeadline gives the backup or restore being performed Backup_Failure_Timeout
+// seconds from now, or no deadline at all if it's 0.
+func (d *Device) restartBackupDeadline() {
+	d.backupDeadline = time.Time{}
+	if timeout, _ := d.object.value(objects.PropertyIdBackupFailureTimeout).(uint32); timeout != 0 {
+		d.backupDeadline = d.clock().Add(time.Duration(timeout) * time.Second)
+	}
+}
+
+// configurationAccessed restarts the deadline of the backup or restore being performed
+// when one of its Configuration_Files is read or written.
+func (d *Device) configurationAccessed(id objects.ObjectIdentifier) {
+	if d.backupState != objects.BackupStatePerformingABackup && d.backupState != objects.BackupStatePerformingARestore {
+		return
+	}
+	for _, file := range d.configurationFiles {
+		if file == id {
+			d.restartBackupDeadline()
+			return
+		}
+	}
+}
+
+func (d *Device) expireBackup() {
+	if !d.backupDeadline.IsZero() && !d.clock().Before(d.backupDeadline) {
+		d.failBackup()
+	}
+}
+
+func (d *Device) configurationFilesList() interface{} {
+	list := make([]interface{}, 0, len(d.configurationFiles))
+	for _, id := range d.configurationFiles {
+		list = append(list, id)
+	}
+	return list
+}
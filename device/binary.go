@@ -0,0 +1,76 @@
+package device
+
+import (
+	"github.com/ulbios/bacnet/objects"
+)
+
+// NewBinaryInput creates a Binary Input object whose Present_Value is read-only unless
+// it's Out_Of_Service.
+func NewBinaryInput(instance uint32, name string) *Object {
+	o := newBinary(objects.ObjectTypeBinaryInput, instance, name, AccessWritableOutOfService)
+	o.addPolarity()
+	return o
+}
+
+// NewBinaryOutput creates a Binary Output object. Its Minimum_On_Time and Minimum_Off_Time,
+// in seconds, are disabled by default.
+func NewBinaryOutput(instance uint32, name string) *Object {
+	o := newBinary(objects.ObjectTypeBinaryOutput, instance, name, AccessWritable)
+	o.addPolarity()
+	o.addMinimumTimes()
+	o.makeCommandable(o.properties[objects.PropertyIdPresentValue])
+	return o
+}
+
+// NewBinaryValue creates a Binary Value object. Call MakeCommandable to command it.
+func NewBinaryValue(instance uint32, name string) *Object {
+	return newBinary(objects.ObjectTypeBinaryValue, instance, name, AccessWritable)
+}
+
+func newBinary(objectType uint16, instance uint32, name string, access uint8) *Object {
+	o := newObject(objectType, instance, name)
+
+	o.add(&Property{
+		Identifier: objects.PropertyIdPresentValue,
+		Datatype:   Enumerated,
+		Required:   true,
+		Access:     access,
+		Value:      objects.Enumerated(objects.BinaryInactive),
+		Validate:   validateBinary,
+	})
+	o.addStatus()
+
+	return o
+}
+
+func (o *Object) addPolarity() {
+	o.add(&Property{
+		Identifier: objects.PropertyIdPolarity,
+		Datatype:   Enumerated,
+		Required:   true,
+		Value:      objects.Enumerated(objects.PolarityNormal),
+		Validate:   validateBinary,
+	})
+}
+
+func (o *Object) addMinimumTimes() {
+	o.add(&Property{
+		Identifier: objects.PropertyIdMinimumOffTime,
+		Datatype:   Unsigned,
+		Access:     AccessWritable,
+		Value:      uint32(0),
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdMinimumOnTime,
+		Datatype:   Unsigned,
+		Access:     AccessWritable,
+		Value:      uint32(0),
+	})
+}
+
+func validateBinary(value interface{}) error {
+	if v, _ := value.(objects.Enumerated); v > 1 {
+		return ErrValueOutOfRange
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package device
+
+import (
+	"time"
+
+	"github.com/ulbios/bacnet/objects"
+)
+
+// DateRange is a BACnetDateRange covering the days from Start to End, both included. A Start
+// or End whose year, month or day is unspecified leaves the range open on that side.
+type DateRange struct {
+	Start objects.Date
+	End   objects.Date
+}
+
+// WeekNDay is a BACnetWeekNDay matching days by month, by week of the month, weeks 1 to 5
+// starting on days 1, 8, 15, 22 and 29, and by day of the week, 1 being Monday. Any field can
+// be objects.Unspecified.
+type WeekNDay struct {
+	Month       uint8
+	WeekOfMonth uint8
+	DayOfWeek   uint8
+}
+
+// NewCalendar creates a Calendar object whose Present_Value tells whether the current date
+// of its Device matches any of the entries on its Date_List, which must be objects.Date,
+// DateRange or WeekNDay values.
+func NewCalendar(instance uint32, name string, entries []interface{}) *Object {
+	o := newObject(objects.ObjectTypeCalendar, instance, name)
+
+	o.add(&Property{
+		Identifier: objects.PropertyIdDescription,
+		Datatype:   CharacterString,
+		Access:     AccessWritable,
+		Value:      "",
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdPresentValue,
+		Datatype:   Boolean,
+		Required:   true,
+		compute:    func() interface{} { return o.calendarMatches(o.localNow()) },
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdDateList,
+		Datatype:   ListOf(CalendarEntryType),
+		Required:   true,
+		Access:     AccessWritable,
+		Value:      append([]interface{}{}, entries...),
+	})
+
+	return o
+}
+
+// calendarMatches tells whether day matches any entry on the Date_List of a Calendar.
+func (o *Object) calendarMatches(day time.Time) bool {
+	entries, _ := o.value(objects.PropertyIdDateList).([]interface{})
+	for _, entry := range entries {
+		if matchEntry(entry, day) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchEntry tells whether day matches a BACnetCalendarEntry.
+func matchEntry(entry interface{}, day time.Time) bool {
+	switch e := entry.(type) {
+	case objects.Date:
+		return matchDate(e, day)
+	case DateRange:
+		return e.matches(day)
+	case WeekNDay:
+		return e.matches(day)
+	}
+	return false
+}
+
+// matchDate tells whether day matches a Date, which may hold wildcards and special values.
+func matchDate(date objects.Date, day time.Time) bool {
+	if date.Year != objects.Unspecified && int(date.Year)+1900 != day.Year() {
+		return false
+	}
+	if !matchMonth(date.Month, day) {
+		return false
+	}
+
+	switch date.Day {
+	case objects.Unspecified:
+	case objects.DayLast:
+		if day.AddDate(0, 0, 1).Month() == day.Month() {
+			return false
+		}
+	case objects.DayOdd, objects.DayEven:
+		if (day.Day()%2 == 1) != (date.Day == objects.DayOdd) {
+			return false
+		}
+	default:
+		if int(date.Day) != day.Day() {
+			return false
+		}
+	}
+
+	return matchWeekday(date.Weekday, day)
+}
+
+func matchMonth(month uint8, day time.Time) bool {
+	switch month {
+	case objects.Unspecified:
+		return true
+	case objects.MonthOdd, objects.MonthEven:
+		return (int(day.Month())%2 == 1) == (month == objects.MonthOdd)
+	}
+	return int(month) == int(day.Month())
+}
+
+func matchWeekday(weekday uint8, day time.Time) bool {
+	return weekday == objects.Unspecified || weekday == objects.DateOf(day).Weekday
+}
+
+func (r DateRange) matches(day time.Time) bool {
+	d := dayNumber(objects.DateOf(day))
+	if bounded(r.Start) && d < dayNumber(r.Start) {
+		return false
+	}
+	if bounded(r.End) && d > dayNumber(r.End) {
+		return false
+	}
+	return true
+}
+
+// bounded tells whether a Date names a single day, the day of the week aside.
+func bounded(date objects.Date) bool {
+	return date.Year != objects.Unspecified && date.Month != objects.Unspecified && date.Day != objects.Unspecified
+}
+
+// dayNumber orders the days named by Dates.
+func dayNumber(date objects.Date) int {
+	return int(date.Year)*10000 + int(date.Month)*100 + int(date.Day)
+}
+
+func (w WeekNDay) matches(day time.Time) bool {
+	if !matchMonth(w.Month, day) || !matchWeekday(w.DayOfWeek, day) {
+		return false
+	}
+
+	switch {
+	case w.WeekOfMonth == objects.Unspecified:
+		return true
+	case w.WeekOfMonth >= objects.WeekLast && w.WeekOfMonth <= objects.WeekLast+3:
+		// Count weeks backwards from the last day of the month.
+		last := time.Date(day.Year(), day.Month()+1, 0, 0, 0, 0, 0, day.Location()).Day()
+		return int(w.WeekOfMonth-objects.WeekLast) == (last-day.Day())/7
+	}
+	return int(w.WeekOfMonth) == (day.Day()-1)/7+1
+}
+
+type calendarEntryType struct{}
+
+// CalendarEntryType is a BACnetCalendarEntry whose values are objects.Date, DateRange or
+// WeekNDay.
+var CalendarEntryType Datatype = calendarEntryType{}
+
+func (calendarEntryType) Encode(value interface{}) ([]objects.APDUPayload, error) {
+	switch v := value.(type) {
+	case objects.Date:
+		return []objects.APDUPayload{objects.EncContext(0, objects.EncDate(v))}, nil
+	case DateRange:
+		return objects.EncEnclosed(1, objects.EncDate(v.Start), objects.EncDate(v.End)), nil
+	case WeekNDay:
+		return []objects.APDUPayload{objects.EncContext(2, objects.EncOctetString([]byte{v.Month, v.WeekOfMonth, v.DayOfWeek}))}, nil
+	}
+	return nil, ErrInvalidDataType
+}
+
+func (calendarEntryType) Decode(rawPayloads []objects.APDUPayload) (interface{}, int, error) {
+	if len(rawPayloads) == 0 {
+		return nil, 0, ErrInvalidDataType
+	}
+
+	switch {
+	case objects.IsContextTag(rawPayloads[0], 0):
+		date, err := objects.DecDate(rawPayloads[0])
+		if err != nil {
+			return nil, 0, ErrInvalidDataType
+		}
+		return date, 1, nil
+	case objects.IsOpeningTag(rawPayloads[0], 1):
+		enclosed, next, err := objects.DecEnclosed(rawPayloads, 0, 1)
+		if err != nil {
+			return nil, 0, ErrInvalidDataType
+		}
+		r, err := decodeAll(DateRangeType, enclosed)
+		if err != nil {
+			return nil, 0, err
+		}
+		return r, next, nil
+	case objects.IsContextTag(rawPayloads[0], 2):
+		b, err := objects.DecOctetString(rawPayloads[0])
+		if err != nil || len(b) != 3 {
+			return nil, 0, ErrInvalidDataType
+		}
+		return WeekNDay{Month: b[0], WeekOfMonth: b[1], DayOfWeek: b[2]}, 1, nil
+	}
+	return nil, 0, ErrInvalidDataType
+}
+
+type dateRangeType struct{}
+
+// DateRangeType is a BACnetDateRange whose values are DateRange.
+var DateRangeType Datatype = dateRangeType{}
+
+func (dateRangeType) Encode(value interface{}) ([]objects.APDUPayload, error) {
+	r, ok := value.(DateRange)
+	if !ok {
+		return nil, ErrInvalidDataType
+	}
+	return []objects.APDUPayload{objects.EncDate(r.Start), objects.EncDate(r.End)}, nil
+}
+
+func (dateRangeType) Decode(rawPayloads []objects.APDUPayload) (interface{}, int, error) {
+	if len(rawPayloads) < 2 {
+		return nil, 0, ErrInvalidDataType
+	}
+
+	start, _, err := Date.Decode(rawPayloads[:1])
+	if err != nil {
+		return nil, 0, err
+	}
+	end, _, err := Date.Decode(rawPayloads[1:2])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return DateRange{Start: start.(objects.Date), End: end.(objects.Date)}, 2, nil
+}
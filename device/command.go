@@ -0,0 +1,177 @@
+package device
+
+import (
+	"time"
+
+	"github.com/ulbios/bacnet/objects"
+)
+
+// Command priorities as defined on Clause 19.2.
+const (
+	MaxPriority uint8 = 1
+	MinPriority uint8 = 16
+
+	// PriorityMinOnOff is reserved for the minimum on/off time algorithm.
+	PriorityMinOnOff uint8 = 6
+)
+
+// command holds the prioritization state of a commandable property.
+type command struct {
+	o *Object
+
+	slots   [MinPriority]interface{}
+	present interface{}
+
+	// lockedUntil is when the minimum on/off time holding PriorityMinOnOff expires.
+	lockedUntil time.Time
+}
+
+// MakeCommandable adds Priority_Array, Relinquish_Default and Current_Command_Priority to
+// the Object, turning its Present_Value into a commandable property whose value results
+// from the arbitration of Clause 19.2. Outputs are commandable from the get go.
+func (o *Object) MakeCommandable() error {
+	defer o.lock()()
+
+	pv, ok := o.properties[objects.PropertyIdPresentValue]
+	if !ok || pv.Access == AccessWritableOutOfService {
+		return ErrWriteAccessDenied
+	}
+	if pv.write != nil {
+		return nil
+	}
+
+	o.makeCommandable(pv)
+
+	return nil
+}
+
+func (o *Object) makeCommandable(pv *Property) {
+	c := &command{o: o, present: pv.Value}
+	dt := pv.Datatype
+
+	o.add(&Property{
+		Identifier: objects.PropertyIdPriorityArray,
+		Datatype:   &Array{Element: Choice(Null, dt), Size: int(MinPriority)},
+		Required:   true,
+		compute:    c.priorityArray,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdRelinquishDefault,
+		Datatype:   dt,
+		Required:   true,
+		Access:     AccessWritable,
+		Value:      pv.Value,
+		Validate:   pv.Validate,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdCurrentCommandPriority,
+		Datatype:   Choice(Null, Unsigned),
+		Required:   true,
+		compute:    c.currentPriority,
+	})
+
+	o.properties[objects.PropertyIdRelinquishDefault].write = func(value interface{}, priority uint8) error {
+		o.properties[objects.PropertyIdRelinquishDefault].Value = value
+		c.update()
+		return nil
+	}
+
+	pv.Datatype = Choice(Null, dt)
+	pv.compute = c.presentValue
+	pv.write = c.write
+}
+
+// Command writes a value to the Present_Value of a commandable Object at the given priority.
+// A nil value relinquishes the priority.
+func (o *Object) Command(value interface{}, priority uint8) error {
+	defer o.lock()()
+
+	p, ok := o.properties[objects.PropertyIdPresentValue]
+	if !ok || p.write == nil {
+		return ErrWriteAccessDenied
+	}
+
+	if _, err := p.Datatype.Encode(value); err != nil {
+		return err
+	}
+
+	return o.store(p, value, priority)
+}
+
+func (c *command) write(value interface{}, priority uint8) error {
+	if priority == 0 {
+		priority = MinPriority
+	}
+	if priority < MaxPriority || priority > MinPriority {
+		return ErrValueOutOfRange
+	}
+	if priority == PriorityMinOnOff {
+		return ErrWriteAccessDenied
+	}
+
+	c.slots[priority-1] = value
+	c.update()
+
+	return nil
+}
+
+// update expires the minimum on/off time and arbitrates the Present_Value again.
+func (c *command) update() {
+	now := c.o.now()
+
+	if !c.lockedUntil.IsZero() && !now.Before(c.lockedUntil) {
+		c.slots[PriorityMinOnOff-1] = nil
+		c.lockedUntil = time.Time{}
+	}
+
+	value, _ := c.arbitrate()
+	if value == c.present {
+		return
+	}
+	c.present = value
+
+	// Once changed, binary outputs hold their value for their minimum on/off time.
+	if minimum := c.minimumTime(value); minimum > 0 {
+		c.slots[PriorityMinOnOff-1] = value
+		c.lockedUntil = now.Add(minimum)
+	}
+}
+
+// arbitrate returns the highest priority value along with its priority or the
+// Relinquish_Default and a zero priority when every slot is relinquished.
+func (c *command) arbitrate() (interface{}, uint8) {
+	for i, value := range c.slots {
+		if value != nil {
+			return value, uint8(i) + 1
+		}
+	}
+	return c.o.value(objects.PropertyIdRelinquishDefault), 0
+}
+
+func (c *command) minimumTime(value interface{}) time.Duration {
+	propertyId := objects.PropertyIdMinimumOffTime
+	if value == objects.Enumerated(objects.BinaryActive) {
+		propertyId = objects.PropertyIdMinimumOnTime
+	}
+
+	seconds, _ := c.o.value(propertyId).(uint32)
+	return time.Duration(seconds) * time.Second
+}
+
+func (c *command) presentValue() interface{} {
+	c.update()
+	return c.present
+}
+
+func (c *command) priorityArray() interface{} {
+	c.update()
+	return append([]interface{}{}, c.slots[:]...)
+}
+
+func (c *command) currentPriority() interface{} {
+	c.update()
+	if _, priority := c.arbitrate(); priority != 0 {
+		return uint32(priority)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package device
+
+import (
+	"time"
+
+	"github.com/ulbios/bacnet/objects"
+)
+
+// SetPassword sets the password DeviceCommunicationControl and ReinitializeDevice requests
+// must carry. Requests are accepted whatever their password when it's empty.
+func (d *Device) SetPassword(password string) {
+	d.communicationMu.Lock()
+	defer d.communicationMu.Unlock()
+
+	d.password = password
+}
+
+// CheckPassword checks the password carried by a request, returning ErrPasswordFailure if
+// it's not the one of the Device.
+func (d *Device) CheckPassword(password string) error {
+	d.communicationMu.Lock()
+	defer d.communicationMu.Unlock()
+
+	if d.password != "" && password != d.password {
+		return ErrPasswordFailure
+	}
+	return nil
+}
+
+// SetCommunicationState sets the communication state as DeviceCommunicationControl does.
+// Unless duration is zero, the Device reverts to objects.CommunicationEnable once it elapses.
+func (d *Device) SetCommunicationState(state uint8, duration time.Duration) {
+	d.communicationMu.Lock()
+	defer d.communicationMu.Unlock()
+
+	d.communication = state
+	d.communicationUntil = time.Time{}
+	if state != objects.CommunicationEnable && duration != 0 {
+		d.communicationUntil = d.clock().Add(duration)
+	}
+}
+
+// CommunicationState returns the communication state set by DeviceCommunicationControl.
+func (d *Device) CommunicationState() uint8 {
+	d.communicationMu.Lock()
+	defer d.communicationMu.Unlock()
+
+	if !d.communicationUntil.IsZero() && !d.clock().Before(d.communicationUntil) {
+		d.communication = objects.CommunicationEnable
+		d.communicationUntil = time.Time{}
+	}
+	return d.communication
+}
@@ -0,0 +1,352 @@
+package device
+
+import (
+	"encoding/binary"
+	"math"
+	"net"
+	"reflect"
+	"time"
+
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/services"
+)
+
+// ErrCOVNotSupported is reported when subscribing to objects lacking COV reporting.
+var ErrCOVNotSupported = &common.BACnetError{Class: objects.ErrorClassObject, Code: objects.ErrorCodeOptionalFunctionalityNotSup}
+
+// COVSubscription is a subscription to the changes of value of an object or property.
+type COVSubscription struct {
+	Recipient net.Addr
+	ProcessId uint32
+	Object    objects.ObjectIdentifier
+	// PropertyId is the monitored property, being objects.PropertyIdAll for subscriptions
+	// to the object as a whole, which report its Present_Value and Status_Flags.
+	PropertyId uint32
+	ArrayIndex uint32
+	Confirmed  bool
+	// Lifetime is zero for subscriptions that never expire.
+	Lifetime time.Duration
+	// Increment, when non-zero, overrides the COV_Increment of the object.
+	Increment float32
+}
+
+// COVNotification is a notification due to a subscription's recipient.
+type COVNotification struct {
+	COVSubscription
+
+	DeviceId uint32
+	// TimeRemaining is the subscription's remaining lifetime in seconds.
+	TimeRemaining uint32
+	Values        []services.PropertyValue
+}
+
+type covSubscription struct {
+	COVSubscription
+
+	expires time.Time
+	// value and flags are the last ones we notified.
+	value interface{}
+	flags interface{}
+
+	// remaining is the lifetime left in seconds as of the last read of Active_COV_Subscriptions.
+	remaining uint32
+}
+
+func (s *covSubscription) timeRemaining(now time.Time) uint32 {
+	if s.expires.IsZero() || !now.Before(s.expires) {
+		return 0
+	}
+	return uint32(math.Ceil(s.expires.Sub(now).Seconds()))
+}
+
+func (s *covSubscription) matches(other *COVSubscription) bool {
+	return s.Recipient.String() == other.Recipient.String() && s.ProcessId == other.ProcessId &&
+		s.Object == other.Object && s.PropertyId == other.PropertyId && s.ArrayIndex == other.ArrayIndex
+}
+
+// OnCOV sets the function receiving due COV notifications. It's called with the Device
+// locked, so it must not block nor call back into the Device.
+func (d *Device) OnCOV(notify func(COVNotification)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.notify = notify
+}
+
+// SubscribeCOV adds or renews a subscription and issues its initial notification.
+func (d *Device) SubscribeCOV(sub COVSubscription) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	o := d.lookup(sub.Object.ObjectType, sub.Object.InstanceNumber)
+	if o == nil {
+		return ErrUnknownObject
+	}
+	sub.Object = o.Identifier
+
+	if sub.PropertyId == objects.PropertyIdAll {
+		if !o.has(objects.PropertyIdPresentValue) || !o.has(objects.PropertyIdStatusFlags) {
+			return ErrCOVNotSupported
+		}
+	} else if !o.has(sub.PropertyId) {
+		return ErrUnknownProperty
+	}
+
+	s := &covSubscription{COVSubscription: sub}
+	if sub.Lifetime != 0 {
+		s.expires = d.clock().Add(sub.Lifetime)
+	}
+
+	replaced := false
+	for i, other := range d.subscriptions {
+		if other.matches(&sub) {
+			d.subscriptions[i] = s
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		d.subscriptions = append(d.subscriptions, s)
+	}
+
+	d.evaluate(s, o, true)
+
+	return nil
+}
+
+// UnsubscribeCOV cancels a subscription. Cancelling unknown subscriptions is not an error.
+func (d *Device) UnsubscribeCOV(sub COVSubscription) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for i, other := range d.subscriptions {
+		if other.matches(&sub) {
+			d.subscriptions = append(d.subscriptions[:i], d.subscriptions[i+1:]...)
+			return
+		}
+	}
+}
+
+// Tick expires subscriptions and catches up with changes driven by time, such as those
+// caused by minimum on/off times, schedules, time delays of events and trend logs, and fails
+// backups and restores left hanging. It should be called every second or so.
+func (d *Device) Tick() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.expire()
+	d.expireBackup()
+	d.tickSchedules()
+	d.tickEvents()
+	d.tickLogs()
+	for _, s := range d.subscriptions {
+		if o := d.lookup(s.Object.ObjectType, s.Object.InstanceNumber); o != nil {
+			d.evaluate(s, o, false)
+		}
+	}
+}
+
+func (d *Device) expire() {
+	now := d.clock()
+
+	active := d.subscriptions[:0]
+	for _, s := range d.subscriptions {
+		if s.expires.IsZero() || now.Before(s.expires) {
+			active = append(active, s)
+		}
+	}
+	d.subscriptions = active
+}
+
+// changed checks the subscriptions to an object and the Trend Logs logging it after one of
+// its properties changed. Schedules are evaluated again when their own properties change, as
+// are the event conditions of objects reporting events.
+func (d *Device) changed(o *Object) {
+	if o.schedule != nil {
+		o.schedule.update()
+	}
+	if o.event != nil {
+		o.event.update()
+	}
+	d.logChanged(o)
+	if len(d.subscriptions) == 0 {
+		return
+	}
+
+	d.expire()
+	for _, s := range d.subscriptions {
+		if s.Object == o.Identifier {
+			d.evaluate(s, o, false)
+		}
+	}
+}
+
+// evaluate issues a notification if the monitored values moved enough since the last one.
+func (d *Device) evaluate(s *covSubscription, o *Object, force bool) {
+	propertyId := s.PropertyId
+	if propertyId == objects.PropertyIdAll {
+		propertyId = objects.PropertyIdPresentValue
+	}
+
+	value := o.value(propertyId)
+	if s.ArrayIndex != objects.ArrayAll {
+		elements, _ := value.([]interface{})
+		if s.ArrayIndex == 0 || int(s.ArrayIndex) > len(elements) {
+			value = nil
+		} else {
+			value = elements[s.ArrayIndex-1]
+		}
+	}
+	flags := o.value(objects.PropertyIdStatusFlags)
+
+	if !force && reflect.DeepEqual(flags, s.flags) && !s.moved(o, value) {
+		return
+	}
+	s.value, s.flags = value, flags
+
+	if d.notify == nil {
+		return
+	}
+
+	n := COVNotification{COVSubscription: s.COVSubscription, DeviceId: d.Instance(), TimeRemaining: s.timeRemaining(d.clock())}
+
+	ids := []uint32{propertyId}
+	if propertyId != objects.PropertyIdStatusFlags && o.has(objects.PropertyIdStatusFlags) {
+		ids = append(ids, objects.PropertyIdStatusFlags)
+	}
+
+	for _, id := range ids {
+		p := o.properties[id]
+
+		pv := services.PropertyValue{PropertyId: id, ArrayIndex: objects.ArrayAll}
+		if id == propertyId {
+			pv.ArrayIndex = s.ArrayIndex
+		}
+
+		var err error
+		if pv.ArrayIndex == objects.ArrayAll {
+			pv.Values, err = p.Datatype.Encode(p.current())
+		} else {
+			pv.Values, err = o.read(id, pv.ArrayIndex)
+		}
+		if err != nil {
+			continue
+		}
+		n.Values = append(n.Values, pv)
+	}
+
+	d.notify(n)
+}
+
+// moved tells whether value changed enough to be notified.
+func (s *covSubscription) moved(o *Object, value interface{}) bool {
+	return changedBy(o, s.Increment, value, s.value)
+}
+
+// changedBy tells whether value differs from last, by at least increment for Reals. A zero
+// increment falls back to the COV_Increment of o.
+func changedBy(o *Object, increment float32, value, lastValue interface{}) bool {
+	current, ok := value.(float32)
+	last, lastOk := lastValue.(float32)
+	if !ok || !lastOk {
+		return !reflect.DeepEqual(value, lastValue)
+	}
+
+	if increment == 0 {
+		increment, _ = o.value(objects.PropertyIdCOVIncrement).(float32)
+	}
+	if increment == 0 {
+		return current != last
+	}
+
+	return math.Abs(float64(current-last)) >= float64(increment)
+}
+
+func (d *Device) activeCOVSubscriptions() interface{} {
+	d.expire()
+
+	now := d.clock()
+	list := make([]interface{}, 0, len(d.subscriptions))
+	for _, s := range d.subscriptions {
+		s.remaining = s.timeRemaining(now)
+		list = append(list, s)
+	}
+	return list
+}
+
+type covSubscriptionType struct{}
+
+// COVSubscriptionType encodes the BACnetCOVSubscriptions of Active_COV_Subscriptions.
+var COVSubscriptionType Datatype = covSubscriptionType{}
+
+func (covSubscriptionType) Encode(value interface{}) ([]objects.APDUPayload, error) {
+	s, ok := value.(*covSubscription)
+	if !ok {
+		return nil, ErrInvalidDataType
+	}
+
+	propertyId := s.PropertyId
+	if propertyId == objects.PropertyIdAll {
+		propertyId = objects.PropertyIdPresentValue
+	}
+
+	network, mac := bacnetAddress(s.Recipient)
+	recipient := objects.EncEnclosed(0,
+		objects.EncEnclosed(1, objects.EncUnsignedInteger(uint32(network)), objects.EncOctetString(mac))...)
+	reference := []objects.APDUPayload{
+		objects.EncObjectIdentifier(true, 0, s.Object.ObjectType, s.Object.InstanceNumber),
+		objects.EncPropertyIdentifier(true, 1, propertyId),
+	}
+	if s.ArrayIndex != objects.ArrayAll {
+		reference = append(reference, objects.EncArrayIndex(2, s.ArrayIndex))
+	}
+
+	objs := objects.EncEnclosed(0, append(recipient, objects.EncContext(1, objects.EncUnsignedInteger(s.ProcessId)))...)
+	objs = append(objs, objects.EncEnclosed(1, reference...)...)
+	objs = append(objs, objects.EncContext(2, objects.EncBoolean(s.Confirmed)))
+	objs = append(objs, objects.EncContext(3, objects.EncUnsignedInteger(s.remaining)))
+	if s.Increment != 0 {
+		objs = append(objs, objects.EncContext(4, objects.EncReal(s.Increment)))
+	}
+
+	return objs, nil
+}
+
+func (covSubscriptionType) Decode(rawPayloads []objects.APDUPayload) (interface{}, int, error) {
+	return nil, 0, ErrWriteAccessDenied
+}
+
+// routedAddr is implemented by the addresses of devices that may live on networks behind
+// routers, such as bacnet.Address.
+type routedAddr interface {
+	// Route returns the network number and MAC address of the device, the network being
+	// zero on the local network, and the BACnet/IP node it's reached through.
+	Route() (uint16, []byte, net.Addr)
+}
+
+// bacnetAddress returns the network number and MAC address of the device at addr.
+func bacnetAddress(addr net.Addr) (uint16, []byte) {
+	if r, ok := addr.(routedAddr); ok {
+		network, mac, node := r.Route()
+		if network != 0 {
+			return network, mac
+		}
+		addr = node
+	}
+	return 0, macAddress(addr)
+}
+
+// macAddress returns the BACnet/IP MAC address of addr: its IPv4 address and UDP port. It's
+// empty for addresses of any other kind.
+func macAddress(addr net.Addr) []byte {
+	udpAddr, ok := addr.(*net.UDPAddr)
+	if !ok || udpAddr.IP.To4() == nil {
+		return []byte{}
+	}
+
+	mac := make([]byte, 6)
+	copy(mac, udpAddr.IP.To4())
+	binary.BigEndian.PutUint16(mac[4:], uint16(udpAddr.Port))
+	return mac
+}
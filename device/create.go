@@ -0,0 +1,142 @@
+package device
+
+import (
+	"fmt"
+
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/services"
+)
+
+// Factory creates an object of a given type for CreateObject requests. It returns nil when
+// no more objects of the type can be created.
+type Factory func(instance uint32, name string) *Object
+
+// SetFactory lets peers create objects of the given type through CreateObject, which f
+// builds, and delete them through DeleteObject. A nil f forbids both again.
+func (d *Device) SetFactory(objectType uint16, f Factory) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if f == nil {
+		delete(d.factories, objectType)
+		return
+	}
+	d.factories[objectType] = f
+}
+
+// CreateObject creates an object of the given type with the Factory set for it and adds it
+// to the Device. Pass objects.MaxInstance as the instance to have the lowest free instance
+// number picked. The initial values are written in order as WriteProperty would before the
+// object is added, so it isn't created at all unless they all are. Errors are reported as
+// *common.CreateObjectError, naming the initial value at fault if any.
+func (d *Device) CreateObject(objectType uint16, instance uint32, values []services.PropertyValue) (objects.ObjectIdentifier, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	f, ok := d.factories[objectType]
+	if !ok {
+		if d.supports(objectType) {
+			return objects.ObjectIdentifier{}, createError(ErrDynamicCreationNotSupported, 0)
+		}
+		return objects.ObjectIdentifier{}, createError(ErrUnsupportedObjectType, 0)
+	}
+
+	if instance == objects.MaxInstance {
+		instance = d.freeInstance(objectType)
+		if instance == objects.MaxInstance {
+			return objects.ObjectIdentifier{}, createError(ErrNoSpaceForObject, 0)
+		}
+	} else if d.lookup(objectType, instance) != nil {
+		return objects.ObjectIdentifier{}, createError(ErrObjectIdExists, 0)
+	}
+
+	o := f(instance, fmt.Sprintf("%d-%d", objectType, instance))
+	if o == nil {
+		return objects.ObjectIdentifier{}, createError(ErrNoSpaceForObject, 0)
+	}
+
+	// The object stays off the Device until it's added, so writing the initial values
+	// neither counts as a change of its database nor sets off the objects depending on it.
+	o.creating = d
+	defer func() { o.creating = nil }()
+	for i, v := range values {
+		if err := o.write(v.PropertyId, v.ArrayIndex, v.Values, v.Priority); err != nil {
+			return objects.ObjectIdentifier{}, createError(err, uint32(i+1))
+		}
+		if v.PropertyId == objects.PropertyIdObjectName && d.nameTaken(o) {
+			return objects.ObjectIdentifier{}, createError(ErrDuplicateName, uint32(i+1))
+		}
+	}
+	if d.nameTaken(o) {
+		return objects.ObjectIdentifier{}, createError(ErrDuplicateName, 0)
+	}
+
+	o.device = d
+	d.objects[o.Identifier] = o
+	d.order = append(d.order, o)
+	d.revision++
+
+	return o.Identifier, nil
+}
+
+// DeleteObject removes an object from the Device along with the COV subscriptions to it.
+// Only objects of the types peers may create can be deleted.
+func (d *Device) DeleteObject(objectType uint16, instance uint32) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	o := d.lookup(objectType, instance)
+	if o == nil {
+		return ErrUnknownObject
+	}
+	if _, ok := d.factories[objectType]; !ok || o == d.object {
+		return ErrObjectDeletionNotPermitted
+	}
+
+	delete(d.objects, o.Identifier)
+	for i, other := range d.order {
+		if other == o {
+			d.order = append(d.order[:i], d.order[i+1:]...)
+			break
+		}
+	}
+
+	active := d.subscriptions[:0]
+	for _, s := range d.subscriptions {
+		if s.Object != o.Identifier {
+			active = append(active, s)
+		}
+	}
+	d.subscriptions = active
+
+	o.device = nil
+	d.revision++
+
+	return nil
+}
+
+// supports tells whether there are objects of the given type on the Device.
+func (d *Device) supports(objectType uint16) bool {
+	for _, o := range d.order {
+		if o.Identifier.ObjectType == objectType {
+			return true
+		}
+	}
+	return false
+}
+
+// freeInstance returns the lowest instance number no object of the given type has, or
+// objects.MaxInstance if they're all taken.
+func (d *Device) freeInstance(objectType uint16) uint32 {
+	for instance := uint32(0); instance < objects.MaxInstance; instance++ {
+		if d.lookup(objectType, instance) == nil {
+			return instance
+		}
+	}
+	return objects.MaxInstance
+}
+
+func createError(err error, element uint32) *common.CreateObjectError {
+	return &common.CreateObjectError{BACnetError: asBACnetError(err), FirstFailedElement: element}
+}
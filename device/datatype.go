@@ -0,0 +1,257 @@
+package device
+
+import (
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/services"
+)
+
+// Datatype describes how the values of a property look like both in Go and on the wire.
+type Datatype interface {
+	// Encode returns the wire representation of the given value.
+	Encode(value interface{}) ([]objects.APDUPayload, error)
+	// Decode decodes a value off the beginning of the given payloads and returns the
+	// number of payloads it took.
+	Decode(rawPayloads []objects.APDUPayload) (interface{}, int, error)
+}
+
+type primitive struct {
+	tag uint8
+}
+
+// Primitive datatypes. Check objects.EncValue for the Go types they map to.
+var (
+	Null             Datatype = primitive{objects.TagNull}
+	Boolean          Datatype = primitive{objects.TagBoolean}
+	Unsigned         Datatype = primitive{objects.TagUnsignedInteger}
+	Signed           Datatype = primitive{objects.TagSignedInteger}
+	Real             Datatype = primitive{objects.TagReal}
+	Double           Datatype = primitive{objects.TagDouble}
+	OctetString      Datatype = primitive{objects.TagOctetString}
+	CharacterString  Datatype = primitive{objects.TagCharacterString}
+	BitString        Datatype = primitive{objects.TagBitString}
+	Enumerated       Datatype = primitive{objects.TagEnumerated}
+	Date             Datatype = primitive{objects.TagDate}
+	Time             Datatype = primitive{objects.TagTime}
+	ObjectIdentifier Datatype = primitive{objects.TagBACnetObjectIdentifier}
+)
+
+func (p primitive) Encode(value interface{}) ([]objects.APDUPayload, error) {
+	obj, err := objects.EncValue(value)
+	if err != nil || obj.TagNumber != p.tag {
+		return nil, ErrInvalidDataType
+	}
+	return []objects.APDUPayload{obj}, nil
+}
+
+func (p primitive) Decode(rawPayloads []objects.APDUPayload) (interface{}, int, error) {
+	if len(rawPayloads) == 0 {
+		return nil, 0, ErrInvalidDataType
+	}
+
+	rawObject, ok := rawPayloads[0].(*objects.Object)
+	if !ok || rawObject.TagClass || rawObject.TagNumber != p.tag {
+		return nil, 0, ErrInvalidDataType
+	}
+
+	value, err := objects.DecValue(rawObject)
+	if err != nil {
+		return nil, 0, ErrInvalidDataType
+	}
+
+	return value, 1, nil
+}
+
+type anyPrimitive struct{}
+
+// Any takes any primitive value.
+var Any Datatype = anyPrimitive{}
+
+func (anyPrimitive) Encode(value interface{}) ([]objects.APDUPayload, error) {
+	obj, err := objects.EncValue(value)
+	if err != nil {
+		return nil, ErrInvalidDataType
+	}
+	return []objects.APDUPayload{obj}, nil
+}
+
+func (anyPrimitive) Decode(rawPayloads []objects.APDUPayload) (interface{}, int, error) {
+	if len(rawPayloads) == 0 {
+		return nil, 0, ErrInvalidDataType
+	}
+
+	value, err := objects.DecValue(rawPayloads[0])
+	if err != nil {
+		return nil, 0, ErrInvalidDataType
+	}
+
+	return value, 1, nil
+}
+
+type dateTimeType struct{}
+
+// DateTime is a BACnetDateTime whose values are objects.DateTime.
+var DateTime Datatype = dateTimeType{}
+
+func (dateTimeType) Encode(value interface{}) ([]objects.APDUPayload, error) {
+	dt, ok := value.(objects.DateTime)
+	if !ok {
+		return nil, ErrInvalidDataType
+	}
+	return objects.EncDateTime(dt), nil
+}
+
+func (dateTimeType) Decode(rawPayloads []objects.APDUPayload) (interface{}, int, error) {
+	if len(rawPayloads) < 2 {
+		return nil, 0, ErrInvalidDataType
+	}
+
+	dt, err := objects.DecDateTime(rawPayloads[:2])
+	if err != nil {
+		return nil, 0, ErrInvalidDataType
+	}
+
+	return dt, 2, nil
+}
+
+type deviceObjectPropertyReferenceType struct{}
+
+// DeviceObjectPropertyReferenceType is a BACnetDeviceObjectPropertyReference whose values are
+// services.DeviceObjectPropertyReference.
+var DeviceObjectPropertyReferenceType Datatype = deviceObjectPropertyReferenceType{}
+
+func (deviceObjectPropertyReferenceType) Encode(value interface{}) ([]objects.APDUPayload, error) {
+	ref, ok := value.(services.DeviceObjectPropertyReference)
+	if !ok {
+		return nil, ErrInvalidDataType
+	}
+	return services.EncDeviceObjectPropertyReference(ref), nil
+}
+
+func (deviceObjectPropertyReferenceType) Decode(rawPayloads []objects.APDUPayload) (interface{}, int, error) {
+	ref, n, err := services.DecDeviceObjectPropertyReference(rawPayloads, 0)
+	if err != nil {
+		return nil, 0, ErrInvalidDataType
+	}
+	return ref, n, nil
+}
+
+// Array is a BACnetARRAY whose values are []interface{}. A zero Size means the
+// array can grow and shrink.
+type Array struct {
+	Element Datatype
+	Size    int
+}
+
+// ArrayOf returns a variable size Array of the given datatype.
+func ArrayOf(element Datatype) *Array {
+	return &Array{Element: element}
+}
+
+func (a *Array) Encode(value interface{}) ([]objects.APDUPayload, error) {
+	elements, ok := value.([]interface{})
+	if !ok {
+		return nil, ErrInvalidDataType
+	}
+	return encodeElements(a.Element, elements)
+}
+
+func (a *Array) Decode(rawPayloads []objects.APDUPayload) (interface{}, int, error) {
+	elements, err := decodeElements(a.Element, rawPayloads)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if a.Size != 0 && len(elements) != a.Size {
+		return nil, 0, ErrValueOutOfRange
+	}
+
+	return elements, len(rawPayloads), nil
+}
+
+// List is a BACnetLIST whose values are []interface{}.
+type List struct {
+	Element Datatype
+}
+
+// ListOf returns a List of the given datatype.
+func ListOf(element Datatype) *List {
+	return &List{Element: element}
+}
+
+func (l *List) Encode(value interface{}) ([]objects.APDUPayload, error) {
+	elements, ok := value.([]interface{})
+	if !ok {
+		return nil, ErrInvalidDataType
+	}
+	return encodeElements(l.Element, elements)
+}
+
+func (l *List) Decode(rawPayloads []objects.APDUPayload) (interface{}, int, error) {
+	elements, err := decodeElements(l.Element, rawPayloads)
+	if err != nil {
+		return nil, 0, err
+	}
+	return elements, len(rawPayloads), nil
+}
+
+func encodeElements(element Datatype, elements []interface{}) ([]objects.APDUPayload, error) {
+	objs := []objects.APDUPayload{}
+	for _, e := range elements {
+		eObjs, err := element.Encode(e)
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, eObjs...)
+	}
+	return objs, nil
+}
+
+func decodeElements(element Datatype, rawPayloads []objects.APDUPayload) ([]interface{}, error) {
+	elements := []interface{}{}
+	for offset := 0; offset < len(rawPayloads); {
+		e, n, err := element.Decode(rawPayloads[offset:])
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, e)
+		offset += n
+	}
+	return elements, nil
+}
+
+type choice []Datatype
+
+// Choice takes a value of any of the given datatypes, which are tried in order.
+func Choice(options ...Datatype) Datatype {
+	return choice(options)
+}
+
+func (c choice) Encode(value interface{}) ([]objects.APDUPayload, error) {
+	for _, option := range c {
+		if objs, err := option.Encode(value); err == nil {
+			return objs, nil
+		}
+	}
+	return nil, ErrInvalidDataType
+}
+
+func (c choice) Decode(rawPayloads []objects.APDUPayload) (interface{}, int, error) {
+	for _, option := range c {
+		if value, n, err := option.Decode(rawPayloads); err == nil {
+			return value, n, nil
+		}
+	}
+	return nil, 0, ErrInvalidDataType
+}
+
+// decodeAll decodes a value which must take all the given payloads.
+func decodeAll(dt Datatype, rawPayloads []objects.APDUPayload) (interface{}, error) {
+	value, n, err := dt.Decode(rawPayloads)
+	if err != nil {
+		return nil, err
+	}
+	if n != len(rawPayloads) {
+		return nil, ErrInvalidDataType
+	}
+	return value, nil
+}
@@ -0,0 +1,504 @@
+package device
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+	"github.com/ulbios/bacnet/services"
+)
+
+// Defaults for the Device object properties.
+const (
+	DefaultProtocolVersion  uint32 = 1
+	DefaultProtocolRevision uint32 = 14
+
+	// MaxServicesSupported is the length of the Protocol_Services_Supported bit string.
+	MaxServicesSupported = 44
+)
+
+// Device is an in-memory object database for a BACnet device. It holds the Device
+// object along with every other object living on the device.
+type Device struct {
+	mu sync.Mutex
+
+	object  *Object
+	objects map[objects.ObjectIdentifier]*Object
+	order   []*Object
+
+	revision          uint32
+	servicesSupported objects.BitString
+	// factories create the objects of the types peers may create and delete.
+	factories map[uint16]Factory
+
+	clock Clock
+	// offset is how far the time set by time synchronizations is from the clock.
+	offset   time.Duration
+	location *time.Location
+
+	// communicationMu guards the password and communication state rather than mu, as they're
+	// checked when sending COV notifications, which happens with mu held. It's always taken
+	// after mu.
+	communicationMu sync.Mutex
+	password        string
+	// communication is the state set by DeviceCommunicationControl, which reverts to
+	// objects.CommunicationEnable at communicationUntil unless it's zero.
+	communication      uint8
+	communicationUntil time.Time
+
+	// backupState is the Backup_And_Restore_State, configurationFiles the Configuration_Files
+	// of the ongoing backup or restore and backupDeadline the time it fails at.
+	backupState        uint8
+	configurationFiles []objects.ObjectIdentifier
+	backupDeadline     time.Time
+	// systemStatus is the System_Status to go back to once the backup or restore is over.
+	systemStatus interface{}
+
+	subscriptions []*covSubscription
+	notify        func(COVNotification)
+	bufferReady   func(BufferReady)
+	notifyEvent   func(EventNotification)
+}
+
+// Clock tells the time to the objects on a Device. Tests can swap it to travel in time.
+type Clock func() time.Time
+
+// New creates a Device with the given instance number, name and vendor identifier.
+func New(instance uint32, name string, vendorId uint16) *Device {
+	d := &Device{
+		objects:           map[objects.ObjectIdentifier]*Object{},
+		servicesSupported: make(objects.BitString, MaxServicesSupported),
+		factories:         map[uint16]Factory{},
+		clock:             time.Now,
+		location:          time.Local,
+	}
+
+	o := newObject(objects.ObjectTypeDevice, instance, name)
+	o.add(&Property{
+		Identifier: objects.PropertyIdSystemStatus,
+		Datatype:   Enumerated,
+		Required:   true,
+		Value:      objects.Enumerated(objects.SystemStatusOperational),
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdVendorName,
+		Datatype:   CharacterString,
+		Required:   true,
+		Value:      "",
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdVendorIdentifier,
+		Datatype:   Unsigned,
+		Required:   true,
+		Value:      uint32(vendorId),
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdModelName,
+		Datatype:   CharacterString,
+		Required:   true,
+		Value:      "",
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdFirmwareRevision,
+		Datatype:   CharacterString,
+		Required:   true,
+		Value:      "",
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdApplicationSoftwareVersion,
+		Datatype:   CharacterString,
+		Required:   true,
+		Value:      "",
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdProtocolVersion,
+		Datatype:   Unsigned,
+		Required:   true,
+		Value:      DefaultProtocolVersion,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdProtocolRevision,
+		Datatype:   Unsigned,
+		Required:   true,
+		Value:      DefaultProtocolRevision,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdProtocolServicesSupported,
+		Datatype:   BitString,
+		Required:   true,
+		compute:    d.protocolServicesSupported,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdProtocolObjectTypesSupported,
+		Datatype:   BitString,
+		Required:   true,
+		compute:    d.protocolObjectTypesSupported,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdObjectList,
+		Datatype:   ArrayOf(ObjectIdentifier),
+		Required:   true,
+		compute:    d.objectList,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdMaxAPDULengthAccepted,
+		Datatype:   Unsigned,
+		Required:   true,
+		Value:      uint32(plumbing.MaxAPDULengthIP),
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdSegmentationSupported,
+		Datatype:   Enumerated,
+		Required:   true,
+		Value:      objects.Enumerated(objects.SegmentationNone),
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdAPDUTimeout,
+		Datatype:   Unsigned,
+		Required:   true,
+		Value:      uint32(plumbing.DefaultAPDUTimeout.Milliseconds()),
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdNumberOfAPDURetries,
+		Datatype:   Unsigned,
+		Required:   true,
+		Value:      uint32(plumbing.DefaultAPDURetries),
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdDeviceAddressBinding,
+		Datatype:   ListOf(Any),
+		Required:   true,
+		Value:      []interface{}{},
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdDatabaseRevision,
+		Datatype:   Unsigned,
+		Required:   true,
+		compute:    func() interface{} { return d.revision },
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdActiveCOVSubscriptions,
+		Datatype:   ListOf(COVSubscriptionType),
+		compute:    d.activeCOVSubscriptions,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdDescription,
+		Datatype:   CharacterString,
+		Access:     AccessWritable,
+		Value:      "",
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdConfigurationFiles,
+		Datatype:   ArrayOf(ObjectIdentifier),
+		compute:    d.configurationFilesList,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdBackupAndRestoreState,
+		Datatype:   Enumerated,
+		compute:    func() interface{} { return objects.Enumerated(d.backupState) },
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdBackupFailureTimeout,
+		Datatype:   Unsigned,
+		Access:     AccessWritable,
+		Value:      uint32(DefaultBackupFailureTimeout),
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdLocalDate,
+		Datatype:   Date,
+		compute:    func() interface{} { return objects.DateOf(d.now()) },
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdLocalTime,
+		Datatype:   Time,
+		compute:    func() interface{} { return objects.TimeOf(d.now()) },
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdUTCOffset,
+		Datatype:   Signed,
+		compute:    d.utcOffset,
+	})
+
+	o.device = d
+	d.object = o
+	d.objects[o.Identifier] = o
+	d.order = append(d.order, o)
+
+	return d
+}
+
+// Object returns the Device object itself.
+func (d *Device) Object() *Object {
+	return d.object
+}
+
+// Instance returns the instance number of the Device object.
+func (d *Device) Instance() uint32 {
+	return d.object.Identifier.InstanceNumber
+}
+
+// SetClock replaces the clock of the Device, which is time.Now by default.
+func (d *Device) SetClock(clock Clock) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.communicationMu.Lock()
+	defer d.communicationMu.Unlock()
+
+	d.clock = clock
+}
+
+// Now returns the local time of the Device: that of its clock as adjusted by time
+// synchronizations.
+func (d *Device) Now() time.Time {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.now()
+}
+
+func (d *Device) now() time.Time {
+	return d.clock().Add(d.offset).In(d.location)
+}
+
+// SetTime sets the time of the Device as time synchronizations do. Its clock keeps on
+// ticking, so only Now and the Local_Date and Local_Time properties are affected.
+func (d *Device) SetTime(t time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.offset = t.Sub(d.clock())
+}
+
+// Location returns the time zone of the Device.
+func (d *Device) Location() *time.Location {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.location
+}
+
+// SetLocation sets the time zone of the Device, which is time.Local by default.
+func (d *Device) SetLocation(loc *time.Location) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.location = loc
+}
+
+// utcOffset computes UTC_Offset: the minutes to add to the local time to get UTC.
+func (d *Device) utcOffset() interface{} {
+	_, offset := d.now().Zone()
+	return int32(-offset / 60)
+}
+
+// Add adds an object to the Device. Both its identifier and its name must be unique.
+func (d *Device) Add(o *Object) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, ok := d.objects[o.Identifier]; ok {
+		return ErrObjectIdExists
+	}
+
+	if d.nameTaken(o) {
+		return ErrDuplicateName
+	}
+
+	o.device = d
+	d.objects[o.Identifier] = o
+	d.order = append(d.order, o)
+	d.revision++
+
+	return nil
+}
+
+// nameTaken tells whether another object on the Device has the name of o.
+func (d *Device) nameTaken(o *Object) bool {
+	name := o.value(objects.PropertyIdObjectName)
+	for _, other := range d.order {
+		if other != o && other.value(objects.PropertyIdObjectName) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Lookup returns the object with the given identifier or nil if there's none. The Device
+// object can also be looked up with the wildcard instance number.
+func (d *Device) Lookup(objectType uint16, instance uint32) *Object {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return d.lookup(objectType, instance)
+}
+
+func (d *Device) lookup(objectType uint16, instance uint32) *Object {
+	if objectType == objects.ObjectTypeDevice && instance == objects.MaxInstance {
+		return d.object
+	}
+	return d.objects[objects.ObjectIdentifier{ObjectType: objectType, InstanceNumber: instance}]
+}
+
+// LookupName returns the object with the given Object_Name or nil if there's none.
+func (d *Device) LookupName(name string) *Object {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, o := range d.order {
+		if o.value(objects.PropertyIdObjectName) == name {
+			return o
+		}
+	}
+	return nil
+}
+
+// Objects returns every object on the Device, the Device object being the first one.
+func (d *Device) Objects() []*Object {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	return append([]*Object{}, d.order...)
+}
+
+// SetServiceSupported flags a service as supported on Protocol_Services_Supported. The
+// bit positions are defined on Clause 21 of the standard.
+func (d *Device) SetServiceSupported(bit int, supported bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if bit >= 0 && bit < len(d.servicesSupported) {
+		d.servicesSupported[bit] = supported
+	}
+}
+
+// ReadProperty returns the encoded value of a property. Pass objects.ArrayAll as the
+// arrayIndex to read the whole value.
+func (d *Device) ReadProperty(objectType uint16, instance uint32, propertyId uint32, arrayIndex uint32) ([]objects.APDUPayload, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	o := d.lookup(objectType, instance)
+	if o == nil {
+		return nil, ErrUnknownObject
+	}
+
+	return o.read(propertyId, arrayIndex)
+}
+
+// WriteProperty writes an encoded value to a property honouring its write access rules.
+// Pass objects.ArrayAll as the arrayIndex to write the whole value.
+func (d *Device) WriteProperty(objectType uint16, instance uint32, propertyId uint32, arrayIndex uint32, values []objects.APDUPayload, priority uint8) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	o := d.lookup(objectType, instance)
+	if o == nil {
+		return ErrUnknownObject
+	}
+
+	return o.write(propertyId, arrayIndex, values, priority)
+}
+
+// ReadPropertyMultiple reads the properties listed on every specification. Failing reads
+// are reported on their results rather than failing the whole request.
+func (d *Device) ReadPropertyMultiple(specs []services.ReadAccessSpecification) []services.ReadAccessResult {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	results := make([]services.ReadAccessResult, 0, len(specs))
+	for _, spec := range specs {
+		result := services.ReadAccessResult{ObjectType: spec.ObjectType, InstanceId: spec.InstanceId}
+
+		o := d.lookup(spec.ObjectType, spec.InstanceId)
+		if o != nil {
+			result.InstanceId = o.Identifier.InstanceNumber
+		}
+
+		for _, ref := range spec.Properties {
+			if o == nil {
+				result.Results = append(result.Results, readResult(ref, nil, ErrUnknownObject))
+				continue
+			}
+			for _, ref := range o.expand(ref) {
+				values, err := o.read(ref.PropertyId, ref.ArrayIndex)
+				result.Results = append(result.Results, readResult(ref, values, err))
+			}
+		}
+
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// WritePropertyMultiple carries out the writes of every specification in order, stopping at
+// the first one failing, which is reported as a *common.WritePropertyMultipleError.
+func (d *Device) WritePropertyMultiple(specs []services.WriteAccessSpecification) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, spec := range specs {
+		o := d.lookup(spec.ObjectType, spec.InstanceId)
+
+		for _, v := range spec.Values {
+			var err error = ErrUnknownObject
+			if o != nil {
+				err = o.write(v.PropertyId, v.ArrayIndex, v.Values, v.Priority)
+			}
+			if err == nil {
+				continue
+			}
+
+			return &common.WritePropertyMultipleError{
+				BACnetError: asBACnetError(err),
+				ObjectType:  spec.ObjectType,
+				InstanceId:  spec.InstanceId,
+				PropertyId:  v.PropertyId,
+				ArrayIndex:  v.ArrayIndex,
+			}
+		}
+	}
+
+	return nil
+}
+
+func readResult(ref services.PropertyReference, values []objects.APDUPayload, err error) services.ReadResult {
+	r := services.ReadResult{PropertyId: ref.PropertyId, ArrayIndex: ref.ArrayIndex, Values: values}
+	if err != nil {
+		r.Values = nil
+		if !errors.As(err, &r.Error) {
+			r.Error = &common.BACnetError{Class: objects.ErrorClassDevice, Code: objects.ErrorCodeOther}
+		}
+	}
+	return r
+}
+
+func (d *Device) objectList() interface{} {
+	list := make([]interface{}, 0, len(d.order))
+	for _, o := range d.order {
+		list = append(list, o.Identifier)
+	}
+	return list
+}
+
+func (d *Device) protocolServicesSupported() interface{} {
+	return append(objects.BitString{}, d.servicesSupported...)
+}
+
+func (d *Device) protocolObjectTypesSupported() interface{} {
+	supported := make(objects.BitString, objects.MaxObjectType)
+	for _, o := range d.order {
+		if int(o.Identifier.ObjectType) < len(supported) {
+			supported[o.Identifier.ObjectType] = true
+		}
+	}
+	for objectType := range d.factories {
+		if int(objectType) < len(supported) {
+			supported[objectType] = true
+		}
+	}
+	return supported
+}
@@ -0,0 +1,1556 @@
+package device_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/device"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/services"
+)
+
+func newTestDevice(t *testing.T) *device.Device {
+	t.Helper()
+
+	dev := device.New(321, "dev", 31)
+	for _, o := range []*device.Object{
+		device.NewAnalogInput(0, "AI-0", objects.UnitsNoUnits),
+		device.NewAnalogOutput(0, "AO-0", objects.UnitsNoUnits),
+		device.NewBinaryValue(0, "BV-0"),
+		device.NewMultiStateValue(0, "MSV-0", []string{"off", "low", "high"}),
+	} {
+		if err := dev.Add(o); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dev
+}
+
+func TestReadProperty(t *testing.T) {
+	dev := newTestDevice(t)
+
+	var testcases = []struct {
+		description string
+		objectType  uint16
+		instance    uint32
+		propertyId  uint32
+		arrayIndex  uint32
+		want        []objects.APDUPayload
+		err         error
+	}{
+		{
+			description: "Present_Value",
+			objectType:  objects.ObjectTypeAnalogOutput,
+			propertyId:  objects.PropertyIdPresentValue,
+			arrayIndex:  objects.ArrayAll,
+			want:        []objects.APDUPayload{objects.EncReal(0)},
+		},
+		{
+			description: "Object_List length",
+			objectType:  objects.ObjectTypeDevice,
+			instance:    objects.MaxInstance,
+			propertyId:  objects.PropertyIdObjectList,
+			arrayIndex:  0,
+			want:        []objects.APDUPayload{objects.EncUnsignedInteger(5)},
+		},
+		{
+			description: "Object_List element",
+			objectType:  objects.ObjectTypeDevice,
+			instance:    321,
+			propertyId:  objects.PropertyIdObjectList,
+			arrayIndex:  1,
+			want: []objects.APDUPayload{
+				objects.EncObjectIdentifier(false, objects.TagBACnetObjectIdentifier, objects.ObjectTypeDevice, 321),
+			},
+		},
+		{
+			description: "Unknown object",
+			objectType:  objects.ObjectTypeAnalogValue,
+			propertyId:  objects.PropertyIdPresentValue,
+			arrayIndex:  objects.ArrayAll,
+			err:         device.ErrUnknownObject,
+		},
+		{
+			description: "Unknown property",
+			objectType:  objects.ObjectTypeBinaryValue,
+			propertyId:  objects.PropertyIdUnits,
+			arrayIndex:  objects.ArrayAll,
+			err:         device.ErrUnknownProperty,
+		},
+		{
+			description: "Array index on a scalar",
+			objectType:  objects.ObjectTypeAnalogOutput,
+			propertyId:  objects.PropertyIdPresentValue,
+			arrayIndex:  1,
+			err:         device.ErrPropertyIsNotAnArray,
+		},
+	}
+
+	for _, c := range testcases {
+		t.Run(c.description, func(t *testing.T) {
+			got, err := dev.ReadProperty(c.objectType, c.instance, c.propertyId, c.arrayIndex)
+			if err != c.err {
+				t.Fatalf("got error %v, want %v", err, c.err)
+			}
+			if diff := cmp.Diff(c.want, got); diff != "" {
+				t.Errorf("differs: (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWriteProperty(t *testing.T) {
+	var testcases = []struct {
+		description string
+		objectType  uint16
+		propertyId  uint32
+		values      []objects.APDUPayload
+		err         error
+	}{
+		{
+			description: "Present_Value of an output",
+			objectType:  objects.ObjectTypeAnalogOutput,
+			propertyId:  objects.PropertyIdPresentValue,
+			values:      []objects.APDUPayload{objects.EncReal(4.2)},
+		},
+		{
+			description: "Present_Value of an input in service",
+			objectType:  objects.ObjectTypeAnalogInput,
+			propertyId:  objects.PropertyIdPresentValue,
+			values:      []objects.APDUPayload{objects.EncReal(4.2)},
+			err:         device.ErrWriteAccessDenied,
+		},
+		{
+			description: "Wrong datatype",
+			objectType:  objects.ObjectTypeAnalogOutput,
+			propertyId:  objects.PropertyIdPresentValue,
+			values:      []objects.APDUPayload{objects.EncUnsignedInteger(4)},
+			err:         device.ErrInvalidDataType,
+		},
+		{
+			description: "Out of range state",
+			objectType:  objects.ObjectTypeMultiStateValue,
+			propertyId:  objects.PropertyIdPresentValue,
+			values:      []objects.APDUPayload{objects.EncUnsignedInteger(4)},
+			err:         device.ErrValueOutOfRange,
+		},
+		{
+			description: "Read-only property",
+			objectType:  objects.ObjectTypeBinaryValue,
+			propertyId:  objects.PropertyIdObjectType,
+			values:      []objects.APDUPayload{objects.EncEnumerated(uint32(objects.ObjectTypeBinaryValue))},
+			err:         device.ErrWriteAccessDenied,
+		},
+		{
+			description: "Duplicate Object_Name",
+			objectType:  objects.ObjectTypeBinaryValue,
+			propertyId:  objects.PropertyIdObjectName,
+			values:      []objects.APDUPayload{objects.EncCharacterString("AO-0")},
+			err:         device.ErrDuplicateName,
+		},
+	}
+
+	for _, c := range testcases {
+		t.Run(c.description, func(t *testing.T) {
+			dev := newTestDevice(t)
+
+			err := dev.WriteProperty(c.objectType, 0, c.propertyId, objects.ArrayAll, c.values, 0)
+			if err != c.err {
+				t.Fatalf("got error %v, want %v", err, c.err)
+			}
+			if err != nil {
+				return
+			}
+
+			got, err := dev.ReadProperty(c.objectType, 0, c.propertyId, objects.ArrayAll)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(c.values, got); diff != "" {
+				t.Errorf("differs: (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCommand(t *testing.T) {
+	type write struct {
+		value    objects.APDUPayload
+		priority uint8
+		err      error
+	}
+
+	var testcases = []struct {
+		description string
+		writes      []write
+		want        float32
+		priority    interface{}
+	}{
+		{
+			description: "Relinquish_Default",
+			want:        0,
+		},
+		{
+			description: "Highest priority wins",
+			writes: []write{
+				{value: objects.EncReal(10), priority: 10},
+				{value: objects.EncReal(8), priority: 8},
+				{value: objects.EncReal(12), priority: 12},
+			},
+			want:     8,
+			priority: uint32(8),
+		},
+		{
+			description: "Relinquishing falls back to the next priority",
+			writes: []write{
+				{value: objects.EncReal(10), priority: 10},
+				{value: objects.EncReal(8), priority: 8},
+				{value: objects.EncNull(), priority: 8},
+			},
+			want:     10,
+			priority: uint32(10),
+		},
+		{
+			description: "Relinquishing everything falls back to Relinquish_Default",
+			writes: []write{
+				{value: objects.EncReal(10)},
+				{value: objects.EncNull()},
+			},
+			want: 0,
+		},
+		{
+			description: "Priority 6 is reserved",
+			writes: []write{
+				{value: objects.EncReal(6), priority: 6, err: device.ErrWriteAccessDenied},
+			},
+			want: 0,
+		},
+		{
+			description: "Priority out of range",
+			writes: []write{
+				{value: objects.EncReal(17), priority: 17, err: device.ErrValueOutOfRange},
+			},
+			want: 0,
+		},
+	}
+
+	for _, c := range testcases {
+		t.Run(c.description, func(t *testing.T) {
+			dev := newTestDevice(t)
+
+			for _, w := range c.writes {
+				err := dev.WriteProperty(objects.ObjectTypeAnalogOutput, 0, objects.PropertyIdPresentValue,
+					objects.ArrayAll, []objects.APDUPayload{w.value}, w.priority)
+				if err != w.err {
+					t.Fatalf("got error %v, want %v", err, w.err)
+				}
+			}
+
+			ao := dev.Lookup(objects.ObjectTypeAnalogOutput, 0)
+			if diff := cmp.Diff(c.want, ao.Get(objects.PropertyIdPresentValue)); diff != "" {
+				t.Errorf("Present_Value differs: (-want +got)\n%s", diff)
+			}
+			if diff := cmp.Diff(c.priority, ao.Get(objects.PropertyIdCurrentCommandPriority)); diff != "" {
+				t.Errorf("Current_Command_Priority differs: (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMinimumOnOffTime(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	dev := newTestDevice(t)
+	dev.SetClock(func() time.Time { return now })
+
+	bo := device.NewBinaryOutput(0, "BO-0")
+	if err := dev.Add(bo); err != nil {
+		t.Fatal(err)
+	}
+	if err := bo.Set(objects.PropertyIdMinimumOnTime, uint32(60)); err != nil {
+		t.Fatal(err)
+	}
+
+	active, inactive := objects.Enumerated(objects.BinaryActive), objects.Enumerated(objects.BinaryInactive)
+
+	if err := bo.Command(active, 8); err != nil {
+		t.Fatal(err)
+	}
+	if err := bo.Command(nil, 8); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := bo.Get(objects.PropertyIdPresentValue); got != active {
+		t.Errorf("got %v before the minimum on time elapsed, want %v", got, active)
+	}
+	if got := bo.Get(objects.PropertyIdCurrentCommandPriority); got != uint32(device.PriorityMinOnOff) {
+		t.Errorf("got priority %v before the minimum on time elapsed, want %v", got, device.PriorityMinOnOff)
+	}
+
+	// Higher priorities override the minimum on time.
+	if err := bo.Command(inactive, 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := bo.Get(objects.PropertyIdPresentValue); got != inactive {
+		t.Errorf("got %v whilst commanded at priority 1, want %v", got, inactive)
+	}
+	if err := bo.Command(nil, 1); err != nil {
+		t.Fatal(err)
+	}
+
+	now = now.Add(time.Minute)
+
+	if got := bo.Get(objects.PropertyIdPresentValue); got != inactive {
+		t.Errorf("got %v after the minimum on time elapsed, want %v", got, inactive)
+	}
+}
+
+func TestReadPropertyMultiple(t *testing.T) {
+	dev := newTestDevice(t)
+
+	propertyIds := func(results []services.ReadResult) []uint32 {
+		ids := []uint32{}
+		for _, r := range results {
+			ids = append(ids, r.PropertyId)
+		}
+		return ids
+	}
+
+	results := dev.ReadPropertyMultiple([]services.ReadAccessSpecification{
+		{
+			ObjectType: objects.ObjectTypeAnalogInput,
+			InstanceId: 0,
+			Properties: []services.PropertyReference{{PropertyId: objects.PropertyIdOptional, ArrayIndex: objects.ArrayAll}},
+		},
+		{
+			ObjectType: objects.ObjectTypeAnalogInput,
+			InstanceId: 0,
+			Properties: []services.PropertyReference{{PropertyId: objects.PropertyIdRequired, ArrayIndex: objects.ArrayAll}},
+		},
+		{
+			ObjectType: objects.ObjectTypeAnalogInput,
+			InstanceId: 0,
+			Properties: []services.PropertyReference{{PropertyId: objects.PropertyIdAll, ArrayIndex: objects.ArrayAll}},
+		},
+		{
+			ObjectType: objects.ObjectTypeMultiStateValue,
+			InstanceId: 0,
+			Properties: []services.PropertyReference{
+				{PropertyId: objects.PropertyIdStateText, ArrayIndex: 2},
+				{PropertyId: objects.PropertyIdUnits, ArrayIndex: objects.ArrayAll},
+			},
+		},
+		{
+			ObjectType: objects.ObjectTypeDevice,
+			InstanceId: objects.MaxInstance,
+			Properties: []services.PropertyReference{{PropertyId: objects.PropertyIdObjectName, ArrayIndex: objects.ArrayAll}},
+		},
+		{
+			ObjectType: objects.ObjectTypeAnalogValue,
+			InstanceId: 0,
+			Properties: []services.PropertyReference{{PropertyId: objects.PropertyIdAll, ArrayIndex: objects.ArrayAll}},
+		},
+	})
+	if len(results) != 6 {
+		t.Fatalf("got %d results, want 6", len(results))
+	}
+
+	t.Run("OPTIONAL", func(t *testing.T) {
+		want := []uint32{objects.PropertyIdDescription, objects.PropertyIdCOVIncrement}
+		if diff := cmp.Diff(want, propertyIds(results[0].Results)); diff != "" {
+			t.Errorf("differs: (-want +got)\n%s", diff)
+		}
+	})
+
+	t.Run("ALL", func(t *testing.T) {
+		if got, want := len(results[2].Results), len(results[0].Results)+len(results[1].Results); got != want {
+			t.Errorf("got %d properties, want %d", got, want)
+		}
+		for _, r := range results[2].Results {
+			if r.Error != nil {
+				t.Errorf("reading property %d failed: %v", r.PropertyId, r.Error)
+			}
+		}
+	})
+
+	t.Run("Array index and errors", func(t *testing.T) {
+		want := []services.ReadResult{
+			{
+				PropertyId: objects.PropertyIdStateText,
+				ArrayIndex: 2,
+				Values:     []objects.APDUPayload{objects.EncCharacterString("low")},
+			},
+			{
+				PropertyId: objects.PropertyIdUnits,
+				ArrayIndex: objects.ArrayAll,
+				Error:      device.ErrUnknownProperty,
+			},
+		}
+		if diff := cmp.Diff(want, results[3].Results); diff != "" {
+			t.Errorf("differs: (-want +got)\n%s", diff)
+		}
+	})
+
+	t.Run("Wildcard device instance", func(t *testing.T) {
+		if got := results[4].InstanceId; got != 321 {
+			t.Errorf("got instance %d, want 321", got)
+		}
+	})
+
+	t.Run("Unknown object", func(t *testing.T) {
+		want := []services.ReadResult{{
+			PropertyId: objects.PropertyIdAll,
+			ArrayIndex: objects.ArrayAll,
+			Error:      device.ErrUnknownObject,
+		}}
+		if diff := cmp.Diff(want, results[5].Results); diff != "" {
+			t.Errorf("differs: (-want +got)\n%s", diff)
+		}
+	})
+}
+
+func TestSetTime(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	dev := newTestDevice(t)
+	dev.SetClock(func() time.Time { return now })
+	dev.SetLocation(time.FixedZone("CET", 3600))
+
+	if got := dev.Object().Get(objects.PropertyIdUTCOffset); got != int32(-60) {
+		t.Errorf("got UTC_Offset %v, want -60", got)
+	}
+
+	dev.SetTime(time.Date(2026, 10, 19, 13, 45, 30, 0, time.UTC))
+	now = now.Add(time.Minute)
+
+	want := time.Date(2026, 10, 19, 13, 46, 30, 0, time.UTC)
+	if got := dev.Now(); !got.Equal(want) {
+		t.Errorf("got %v after a minute, want %v", got, want)
+	}
+	if got := dev.Object().Get(objects.PropertyIdLocalDate); got != objects.DateOf(want.In(dev.Location())) {
+		t.Errorf("got Local_Date %v", got)
+	}
+	if got := dev.Object().Get(objects.PropertyIdLocalTime); got != (objects.Time{Hour: 14, Minute: 46, Second: 30}) {
+		t.Errorf("got Local_Time %v, want 14:46:30", got)
+	}
+}
+
+func TestCommunicationState(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	dev := newTestDevice(t)
+	dev.SetClock(func() time.Time { return now })
+
+	if err := dev.CheckPassword("anything"); err != nil {
+		t.Errorf("got %v without a password, want nil", err)
+	}
+	dev.SetPassword("secret")
+	if err := dev.CheckPassword("wrong"); err != device.ErrPasswordFailure {
+		t.Errorf("got %v, want ErrPasswordFailure", err)
+	}
+	if err := dev.CheckPassword("secret"); err != nil {
+		t.Errorf("got %v, want nil", err)
+	}
+
+	dev.SetCommunicationState(objects.CommunicationDisable, 0)
+	now = now.Add(24 * time.Hour)
+	if got := dev.CommunicationState(); got != objects.CommunicationDisable {
+		t.Errorf("got state %d, want disabled until changed", got)
+	}
+
+	dev.SetCommunicationState(objects.CommunicationDisableInitiation, 5*time.Minute)
+	now = now.Add(4 * time.Minute)
+	if got := dev.CommunicationState(); got != objects.CommunicationDisableInitiation {
+		t.Errorf("got state %d, want initiation disabled", got)
+	}
+	now = now.Add(time.Minute)
+	if got := dev.CommunicationState(); got != objects.CommunicationEnable {
+		t.Errorf("got state %d once the duration elapsed, want enabled", got)
+	}
+}
+
+func TestBackupState(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	dev := newTestDevice(t)
+	dev.SetClock(func() time.Time { return now })
+	o := dev.Object()
+
+	state := func(want uint8, wantStatus uint8) {
+		t.Helper()
+		if got := o.Get(objects.PropertyIdBackupAndRestoreState); got != objects.Enumerated(want) {
+			t.Errorf("got Backup_And_Restore_State %v, want %d", got, want)
+		}
+		if got := o.Get(objects.PropertyIdSystemStatus); got != objects.Enumerated(wantStatus) {
+			t.Errorf("got System_Status %v, want %d", got, wantStatus)
+		}
+	}
+
+	files := []objects.ObjectIdentifier{{ObjectType: objects.ObjectTypeFile, InstanceNumber: 1}}
+	if err := dev.StartBackup(); err != nil {
+		t.Fatal(err)
+	}
+	state(objects.BackupStatePreparingForBackup, objects.SystemStatusBackupInProgress)
+	if err := dev.StartRestore(); err != device.ErrConfigurationInProgress {
+		t.Errorf("got %v starting a restore during a backup, want ErrConfigurationInProgress", err)
+	}
+
+	dev.BackupPrepared(files)
+	state(objects.BackupStatePerformingABackup, objects.SystemStatusBackupInProgress)
+	if diff := cmp.Diff([]interface{}{files[0]}, o.Get(objects.PropertyIdConfigurationFiles)); diff != "" {
+		t.Errorf("Configuration_Files mismatch (-want +got):\n%s", diff)
+	}
+	if err := dev.EndRestore(); err != device.ErrServiceRequestDenied {
+		t.Errorf("got %v ending a restore during a backup, want ErrServiceRequestDenied", err)
+	}
+	if err := dev.EndBackup(); err != nil {
+		t.Fatal(err)
+	}
+	state(objects.BackupStateIdle, objects.SystemStatusOperational)
+
+	revision := o.Get(objects.PropertyIdDatabaseRevision).(uint32)
+	if err := dev.StartRestore(); err != nil {
+		t.Fatal(err)
+	}
+	dev.BackupPrepared(files)
+	if err := dev.EndRestore(); err != nil {
+		t.Fatal(err)
+	}
+	if got := o.Get(objects.PropertyIdDatabaseRevision); got != revision+1 {
+		t.Errorf("got Database_Revision %v after a restore, want %d", got, revision+1)
+	}
+
+	// Restores not over within Backup_Failure_Timeout fail.
+	if err := o.Set(objects.PropertyIdBackupFailureTimeout, uint32(60)); err != nil {
+		t.Fatal(err)
+	}
+	if err := dev.StartRestore(); err != nil {
+		t.Fatal(err)
+	}
+	dev.BackupPrepared(files)
+	now = now.Add(59 * time.Second)
+	dev.Tick()
+	state(objects.BackupStatePerformingARestore, objects.SystemStatusBackupInProgress)
+	now = now.Add(time.Second)
+	dev.Tick()
+	state(objects.BackupStateRestoreFailure, objects.SystemStatusOperational)
+	if got := o.Get(objects.PropertyIdConfigurationFiles); len(got.([]interface{})) != 0 {
+		t.Errorf("got Configuration_Files %v after the restore failed, want none", got)
+	}
+
+	// Reading the Configuration_Files keeps backups going past Backup_Failure_Timeout.
+	config := device.NewFile(1, "config", "text/plain", device.NewFileBuffer([]byte("abc")))
+	if err := dev.Add(config); err != nil {
+		t.Fatal(err)
+	}
+	if err := dev.StartBackup(); err != nil {
+		t.Fatal(err)
+	}
+	dev.BackupPrepared(files)
+	for i := 0; i < 3; i++ {
+		now = now.Add(45 * time.Second)
+		if _, _, err := config.ReadStream(0, 3, 1024); err != nil {
+			t.Fatal(err)
+		}
+		dev.Tick()
+	}
+	state(objects.BackupStatePerformingABackup, objects.SystemStatusBackupInProgress)
+	now = now.Add(60 * time.Second)
+	dev.Tick()
+	state(objects.BackupStateBackupFailure, objects.SystemStatusOperational)
+}
+
+func TestFile(t *testing.T) {
+	dev := newTestDevice(t)
+
+	buf := device.NewFileBuffer([]byte("abcdef"))
+	stream := device.NewFile(1, "config", "text/plain", buf)
+	readOnly := device.NewFile(2, "firmware", "application/octet-stream", bytes.NewReader([]byte("fw")))
+	records := device.NewRecordFile(3, "log", "text/csv", [][]byte{[]byte("a"), []byte("b")})
+	for _, o := range []*device.Object{stream, readOnly, records} {
+		if err := dev.Add(o); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, eof, err := stream.ReadStream(0, 4, 1024)
+	if err != nil || string(data) != "abcd" || eof {
+		t.Errorf("got %q, %v, %v reading 4 octets, want \"abcd\" short of the end of file", data, eof, err)
+	}
+	data, eof, err = stream.ReadStream(4, 100, 1024)
+	if err != nil || string(data) != "ef" || !eof {
+		t.Errorf("got %q, %v, %v reading the rest, want \"ef\" at the end of file", data, eof, err)
+	}
+	data, eof, err = stream.ReadStream(0, 100, 3)
+	if err != nil || string(data) != "abc" || eof {
+		t.Errorf("got %q, %v, %v reading 3 octets at most, want \"abc\" short of the end of file", data, eof, err)
+	}
+	if _, _, err := stream.ReadStream(7, 1, 1024); err != device.ErrInvalidFileStartPosition {
+		t.Errorf("got %v reading past the end of file, want ErrInvalidFileStartPosition", err)
+	}
+	if _, _, err := stream.ReadRecords(0, 1, 1024); err != device.ErrInvalidFileAccessMethod {
+		t.Errorf("got %v reading records off a stream, want ErrInvalidFileAccessMethod", err)
+	}
+
+	if start, err := stream.WriteStream(-1, []byte("gh")); err != nil || start != 6 {
+		t.Errorf("got %d, %v appending, want 6", start, err)
+	}
+	if start, err := stream.WriteStream(1, []byte("B")); err != nil || start != 1 {
+		t.Errorf("got %d, %v writing, want 1", start, err)
+	}
+	if got := string(buf.Bytes()); got != "aBcdefgh" {
+		t.Errorf("got %q, want \"aBcdefgh\"", got)
+	}
+	if got := stream.Get(objects.PropertyIdFileSize); got != uint32(8) {
+		t.Errorf("got File_Size %v, want 8", got)
+	}
+	if err := dev.WriteProperty(objects.ObjectTypeFile, 1, objects.PropertyIdFileSize, objects.ArrayAll,
+		[]objects.APDUPayload{objects.EncUnsignedInteger(0)}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.Size(); got != 0 {
+		t.Errorf("got %d octets after writing File_Size, want 0", got)
+	}
+
+	if got := readOnly.Get(objects.PropertyIdReadOnly); got != true {
+		t.Errorf("got Read_Only %v for a file that can't be written to", got)
+	}
+	if _, err := readOnly.WriteStream(0, []byte("x")); err != device.ErrFileAccessDenied {
+		t.Errorf("got %v writing a read-only file, want ErrFileAccessDenied", err)
+	}
+
+	if start, err := records.WriteRecords(-1, [][]byte{[]byte("c")}); err != nil || start != 2 {
+		t.Errorf("got %d, %v appending a record, want 2", start, err)
+	}
+	if _, err := records.WriteRecords(1, [][]byte{[]byte("B"), []byte("C"), []byte("D")}); err != nil {
+		t.Fatal(err)
+	}
+	got, eof, err := records.ReadRecords(1, 10, 1024)
+	if err != nil || !eof {
+		t.Fatalf("got %v, %v reading records, want the end of file", eof, err)
+	}
+	if diff := cmp.Diff([][]byte{[]byte("B"), []byte("C"), []byte("D")}, got); diff != "" {
+		t.Errorf("records mismatch (-want +got):\n%s", diff)
+	}
+	// Single octet records take 2 octets each once encoded.
+	got, eof, err = records.ReadRecords(0, 10, 5)
+	if err != nil || eof || len(got) != 2 {
+		t.Errorf("got %d records, %v, %v reading 5 octets at most, want 2 short of the end of file", len(got), eof, err)
+	}
+	if err := records.Set(objects.PropertyIdRecordCount, uint32(1)); err != nil {
+		t.Fatal(err)
+	}
+	if got := records.Get(objects.PropertyIdFileSize); got != uint32(1) {
+		t.Errorf("got File_Size %v after truncating to a record, want 1", got)
+	}
+}
+
+func TestDirectoryFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"a.cfg": "alpha", "b.cfg": "beta"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "logs"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	files, err := device.NewDirectoryFiles(dir, 10, "text/plain", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || files[0].Name() != "a.cfg" || files[1].Identifier.InstanceNumber != 11 {
+		t.Fatalf("got %d files, want a.cfg and b.cfg numbered from 10", len(files))
+	}
+
+	// Files replaced on disk are picked up.
+	if err := os.WriteFile(filepath.Join(dir, "b.cfg"), []byte("gamma"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if data, eof, err := files[1].ReadStream(0, 100, 1024); err != nil || string(data) != "gamma" || !eof {
+		t.Errorf("got %q, %v, %v, want \"gamma\"", data, eof, err)
+	}
+
+	if _, err := files[0].WriteStream(-1, []byte("!")); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "a.cfg")); string(data) != "alpha!" {
+		t.Errorf("got %q on disk, want \"alpha!\"", data)
+	}
+
+	readOnly := device.NewPathFile(20, filepath.Join(dir, "a.cfg"), "text/plain", true)
+	if _, err := readOnly.WriteStream(0, []byte("x")); err != device.ErrFileAccessDenied {
+		t.Errorf("got %v writing a read-only file, want ErrFileAccessDenied", err)
+	}
+}
+
+func TestCreateObject(t *testing.T) {
+	dev := newTestDevice(t)
+
+	wantError := func(err error, want *common.BACnetError, element uint32) {
+		t.Helper()
+		var cErr *common.CreateObjectError
+		if !errors.As(err, &cErr) {
+			t.Fatalf("got %v, want a CreateObjectError", err)
+		}
+		if cErr.BACnetError != *want || cErr.FirstFailedElement != element {
+			t.Errorf("got %v, want %v at element %d", cErr, want, element)
+		}
+	}
+	value := func(propertyId uint32, payload objects.APDUPayload) services.PropertyValue {
+		return services.PropertyValue{PropertyId: propertyId, ArrayIndex: objects.ArrayAll, Values: []objects.APDUPayload{payload}}
+	}
+
+	_, err := dev.CreateObject(objects.ObjectTypeAnalogValue, objects.MaxInstance, nil)
+	wantError(err, device.ErrUnsupportedObjectType, 0)
+	_, err = dev.CreateObject(objects.ObjectTypeAnalogInput, objects.MaxInstance, nil)
+	wantError(err, device.ErrDynamicCreationNotSupported, 0)
+
+	dev.SetFactory(objects.ObjectTypeAnalogValue, func(instance uint32, name string) *device.Object {
+		return device.NewAnalogValue(instance, name, objects.UnitsNoUnits)
+	})
+
+	for _, want := range []uint32{0, 1} {
+		id, err := dev.CreateObject(objects.ObjectTypeAnalogValue, objects.MaxInstance, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if id.InstanceNumber != want {
+			t.Errorf("got instance %d, want %d", id.InstanceNumber, want)
+		}
+	}
+	_, err = dev.CreateObject(objects.ObjectTypeAnalogValue, 1, nil)
+	wantError(err, device.ErrObjectIdExists, 0)
+
+	revision := dev.Object().Get(objects.PropertyIdDatabaseRevision).(uint32)
+	_, err = dev.CreateObject(objects.ObjectTypeAnalogValue, 5, []services.PropertyValue{
+		value(objects.PropertyIdObjectName, objects.EncCharacterString("AV-5")),
+		value(objects.PropertyIdPresentValue, objects.EncReal(2)),
+		value(objects.PropertyIdOutOfService, objects.EncCharacterString("yes")),
+	})
+	wantError(err, device.ErrInvalidDataType, 3)
+	_, err = dev.CreateObject(objects.ObjectTypeAnalogValue, 5, []services.PropertyValue{
+		value(objects.PropertyIdObjectName, objects.EncCharacterString("AI-0")),
+	})
+	wantError(err, device.ErrDuplicateName, 1)
+	if o := dev.Lookup(objects.ObjectTypeAnalogValue, 5); o != nil {
+		t.Error("objects failing to be created were added")
+	}
+	if got := dev.Object().Get(objects.PropertyIdDatabaseRevision); got != revision {
+		t.Errorf("got revision %v after failing to create objects, want %d", got, revision)
+	}
+
+	id, err := dev.CreateObject(objects.ObjectTypeAnalogValue, 5, []services.PropertyValue{
+		value(objects.PropertyIdObjectName, objects.EncCharacterString("AV-5")),
+		value(objects.PropertyIdPresentValue, objects.EncReal(2)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	o := dev.Lookup(id.ObjectType, id.InstanceNumber)
+	if o == nil || o.Name() != "AV-5" || o.Get(objects.PropertyIdPresentValue) != float32(2) {
+		t.Fatalf("created object isn't initialized")
+	}
+	if got := dev.Object().Get(objects.PropertyIdDatabaseRevision); got != revision+1 {
+		t.Errorf("got revision %v after creating a named object, want %d", got, revision+1)
+	}
+
+	list := dev.Object().Get(objects.PropertyIdObjectList).([]interface{})
+	if got := list[len(list)-1]; got != id {
+		t.Errorf("got %v last on Object_List, want %v", got, id)
+	}
+
+	if err := dev.DeleteObject(objects.ObjectTypeAnalogInput, 0); err != device.ErrObjectDeletionNotPermitted {
+		t.Errorf("got %v deleting an Analog Input, want ErrObjectDeletionNotPermitted", err)
+	}
+	if err := dev.DeleteObject(objects.ObjectTypeDevice, objects.MaxInstance); err != device.ErrObjectDeletionNotPermitted {
+		t.Errorf("got %v deleting the Device, want ErrObjectDeletionNotPermitted", err)
+	}
+	if err := dev.DeleteObject(objects.ObjectTypeAnalogValue, 9); err != device.ErrUnknownObject {
+		t.Errorf("got %v deleting an unknown object, want ErrUnknownObject", err)
+	}
+	if err := dev.DeleteObject(objects.ObjectTypeAnalogValue, 0); err != nil {
+		t.Fatal(err)
+	}
+	if len(dev.Object().Get(objects.PropertyIdObjectList).([]interface{})) != len(list)-1 {
+		t.Error("deleted object left on Object_List")
+	}
+	if id, err := dev.CreateObject(objects.ObjectTypeAnalogValue, objects.MaxInstance, nil); err != nil || id.InstanceNumber != 0 {
+		t.Errorf("got %v, %v, want the instance of the deleted object reused", id, err)
+	}
+}
+
+func TestListElement(t *testing.T) {
+	dev := newTestDevice(t)
+	bv := dev.Lookup(objects.ObjectTypeBinaryValue, 0)
+	if err := bv.AddProperty(&device.Property{
+		Identifier: objects.PropertyIdDateList,
+		Datatype:   device.ListOf(device.Date),
+		Access:     device.AccessWritable,
+		Value:      []interface{}{},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	const arrayOfLists = 512
+	if err := bv.AddProperty(&device.Property{
+		Identifier: arrayOfLists,
+		Datatype:   device.ArrayOf(device.ListOf(device.Unsigned)),
+		Access:     device.AccessWritable,
+		Value:      []interface{}{[]interface{}{}, []interface{}{uint32(1)}},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	christmas := objects.Date{Year: 120, Month: 12, Day: 25, Weekday: 5}
+	newYear := objects.Date{Year: 121, Month: 1, Day: 1, Weekday: 5}
+	wantList := func(propertyId uint32, want ...interface{}) {
+		t.Helper()
+		if diff := cmp.Diff(want, bv.Get(propertyId)); diff != "" {
+			t.Errorf("list mismatch (-want +got):\n%s", diff)
+		}
+	}
+	wantError := func(err error, want *common.BACnetError, element uint32) {
+		t.Helper()
+		var lErr *common.ChangeListError
+		if !errors.As(err, &lErr) {
+			t.Fatalf("got %v, want a ChangeListError", err)
+		}
+		if lErr.BACnetError != *want || lErr.FirstFailedElement != element {
+			t.Errorf("got %v, want %v at element %d", lErr, want, element)
+		}
+	}
+	change := func(add bool, propertyId uint32, arrayIndex uint32, elements ...objects.APDUPayload) error {
+		if add {
+			return dev.AddListElement(objects.ObjectTypeBinaryValue, 0, propertyId, arrayIndex, elements)
+		}
+		return dev.RemoveListElement(objects.ObjectTypeBinaryValue, 0, propertyId, arrayIndex, elements)
+	}
+
+	if err := change(true, objects.PropertyIdDateList, objects.ArrayAll, objects.EncDate(christmas)); err != nil {
+		t.Fatal(err)
+	}
+	// Elements already on the list are skipped.
+	if err := change(true, objects.PropertyIdDateList, objects.ArrayAll, objects.EncDate(christmas), objects.EncDate(newYear)); err != nil {
+		t.Fatal(err)
+	}
+	wantList(objects.PropertyIdDateList, christmas, newYear)
+
+	// Failed changes leave the list alone.
+	err := change(true, objects.PropertyIdDateList, objects.ArrayAll, objects.EncDate(objects.Date{Year: 122, Month: 1, Day: 1, Weekday: 6}), objects.EncUnsignedInteger(1))
+	wantError(err, device.ErrInvalidDataType, 2)
+	err = change(false, objects.PropertyIdDateList, objects.ArrayAll, objects.EncDate(christmas), objects.EncDate(objects.Date{Year: 122, Month: 1, Day: 1, Weekday: 6}))
+	wantError(err, device.ErrListElementNotFound, 2)
+	wantList(objects.PropertyIdDateList, christmas, newYear)
+
+	if err := change(false, objects.PropertyIdDateList, objects.ArrayAll, objects.EncDate(christmas)); err != nil {
+		t.Fatal(err)
+	}
+	wantList(objects.PropertyIdDateList, newYear)
+
+	if err := change(true, arrayOfLists, 2, objects.EncUnsignedInteger(2)); err != nil {
+		t.Fatal(err)
+	}
+	wantList(arrayOfLists, []interface{}{}, []interface{}{uint32(1), uint32(2)})
+	wantError(change(true, arrayOfLists, 3, objects.EncUnsignedInteger(2)), device.ErrInvalidArrayIndex, 0)
+
+	wantError(change(true, objects.PropertyIdDescription, objects.ArrayAll, objects.EncCharacterString("x")), device.ErrPropertyIsNotAList, 0)
+	err = dev.AddListElement(objects.ObjectTypeDevice, 321, objects.PropertyIdDeviceAddressBinding, objects.ArrayAll, nil)
+	wantError(err, device.ErrWriteAccessDenied, 0)
+}
+
+func TestReadRange(t *testing.T) {
+	dev := newTestDevice(t)
+	bv := dev.Lookup(objects.ObjectTypeBinaryValue, 0)
+	values := []interface{}{}
+	for i := uint32(1); i <= 10; i++ {
+		values = append(values, i)
+	}
+	if err := bv.AddProperty(&device.Property{
+		Identifier: objects.PropertyIdDateList,
+		Datatype:   device.ListOf(device.Unsigned),
+		Value:      values,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	result := func(first, last, more bool, items ...uint32) services.RangeResult {
+		r := services.RangeResult{FirstItem: first, LastItem: last, MoreItems: more, ItemCount: uint32(len(items)), ItemData: []objects.APDUPayload{}}
+		for _, item := range items {
+			r.ItemData = append(r.ItemData, objects.EncUnsignedInteger(item))
+		}
+		return r
+	}
+
+	var testcases = []struct {
+		description string
+		rng         services.Range
+		maxLen      int
+		want        services.RangeResult
+	}{
+		{"every item", services.Range{}, 100, result(true, true, false, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10)},
+		{"forwards", services.Range{Type: services.RangeByPosition, Reference: 3, Count: 2}, 100, result(false, false, false, 3, 4)},
+		{"backwards", services.Range{Type: services.RangeByPosition, Reference: 10, Count: -3}, 100, result(false, true, false, 8, 9, 10)},
+		{"past the end", services.Range{Type: services.RangeByPosition, Reference: 9, Count: 5}, 100, result(false, true, false, 9, 10)},
+		{"cut forwards", services.Range{Type: services.RangeByPosition, Reference: 1, Count: 10}, 6, result(true, false, true, 1, 2, 3)},
+		{"cut backwards", services.Range{Type: services.RangeByPosition, Reference: 10, Count: -10}, 4, result(false, true, true, 9, 10)},
+		{"unknown reference", services.Range{Type: services.RangeByPosition, Reference: 11, Count: 1}, 100, result(false, false, false)},
+	}
+
+	for _, c := range testcases {
+		got, err := dev.ReadRange(objects.ObjectTypeBinaryValue, 0, objects.PropertyIdDateList, objects.ArrayAll, c.rng, c.maxLen)
+		if err != nil {
+			t.Fatalf("%s: %v", c.description, err)
+		}
+		if diff := cmp.Diff(c.want, got); diff != "" {
+			t.Errorf("%s: mismatch (-want +got):\n%s", c.description, diff)
+		}
+	}
+
+	_, err := dev.ReadRange(objects.ObjectTypeBinaryValue, 0, objects.PropertyIdDateList, objects.ArrayAll,
+		services.Range{Type: services.RangeBySequenceNumber, Reference: 1, Count: 1}, 100)
+	if err != device.ErrOptionalFunctionalityNotSupported {
+		t.Errorf("got %v reading a list by sequence number, want ErrOptionalFunctionalityNotSupported", err)
+	}
+	_, err = dev.ReadRange(objects.ObjectTypeBinaryValue, 0, objects.PropertyIdDescription, objects.ArrayAll, services.Range{}, 100)
+	if err != device.ErrPropertyIsNotAList {
+		t.Errorf("got %v reading a string, want ErrPropertyIsNotAList", err)
+	}
+}
+
+func TestTrendLog(t *testing.T) {
+	dev := newTestDevice(t)
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	dev.SetClock(func() time.Time { return now })
+	dev.SetLocation(time.UTC)
+
+	ready := []device.BufferReady{}
+	dev.OnBufferReady(func(n device.BufferReady) { ready = append(ready, n) })
+
+	ai := dev.Lookup(objects.ObjectTypeAnalogInput, 0)
+	tl := device.NewTrendLog(0, "TL-0", services.DeviceObjectPropertyReference{
+		Object: ai.Identifier, PropertyId: objects.PropertyIdPresentValue, ArrayIndex: objects.ArrayAll, DeviceId: objects.MaxInstance,
+	}, 10*time.Second, 4)
+	if err := dev.Add(tl); err != nil {
+		t.Fatal(err)
+	}
+	if err := tl.Set(objects.PropertyIdNotificationThreshold, uint32(3)); err != nil {
+		t.Fatal(err)
+	}
+
+	read := func(instance uint32, rng services.Range) []services.LogRecord {
+		t.Helper()
+		result, err := dev.ReadRange(objects.ObjectTypeTrendLog, instance, objects.PropertyIdLogBuffer, objects.ArrayAll, rng, 1000)
+		if err != nil {
+			t.Fatal(err)
+		}
+		records, err := services.DecLogRecords(result.ItemData)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return records
+	}
+	counts := func(o *device.Object, records, total uint32) {
+		t.Helper()
+		if got := o.Get(objects.PropertyIdRecordCount); got != records {
+			t.Errorf("Record_Count is %v, want %d", got, records)
+		}
+		if got := o.Get(objects.PropertyIdTotalRecordCount); got != total {
+			t.Errorf("Total_Record_Count is %v, want %d", got, total)
+		}
+	}
+	flags := objects.BitString{false, false, false, false}
+
+	// The log records it's enabled, then a value every 10 seconds.
+	for i := 0; i < 4; i++ {
+		if i > 0 {
+			now = now.Add(5 * time.Second)
+			dev.Tick()
+			now = now.Add(5 * time.Second)
+		}
+		if err := ai.Set(objects.PropertyIdPresentValue, float32(i)); err != nil {
+			t.Fatal(err)
+		}
+		dev.Tick()
+	}
+	counts(tl, 4, 5)
+
+	want := []services.LogRecord{
+		{Timestamp: objects.DateTimeOf(start.Add(10 * time.Second)), Value: float32(1), StatusFlags: flags},
+		{Timestamp: objects.DateTimeOf(start.Add(20 * time.Second)), Value: float32(2), StatusFlags: flags},
+	}
+	if diff := cmp.Diff(want, read(0, services.Range{Type: services.RangeBySequenceNumber, Reference: 3, Count: 2})); diff != "" {
+		t.Errorf("by sequence number mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(want[:1], read(0, services.Range{Type: services.RangeByTime, Time: objects.DateTimeOf(start.Add(15 * time.Second)), Count: -1})); diff != "" {
+		t.Errorf("by time mismatch (-want +got):\n%s", diff)
+	}
+
+	// Purging leaves a record telling so behind.
+	if err := tl.Set(objects.PropertyIdRecordCount, uint32(1)); err != device.ErrValueOutOfRange {
+		t.Errorf("got %v setting Record_Count to 1, want ErrValueOutOfRange", err)
+	}
+	if err := tl.Set(objects.PropertyIdRecordCount, uint32(0)); err != nil {
+		t.Fatal(err)
+	}
+	counts(tl, 1, 6)
+	want = []services.LogRecord{{Timestamp: objects.DateTimeOf(now), LogStatus: objects.BitString{false, true, false}}}
+	if diff := cmp.Diff(want, read(0, services.Range{})); diff != "" {
+		t.Errorf("purged mismatch (-want +got):\n%s", diff)
+	}
+
+	wantReady := []device.BufferReady{
+		{Log: tl.Identifier, PreviousNotification: 0, CurrentNotification: 3},
+		{Log: tl.Identifier, PreviousNotification: 3, CurrentNotification: 6},
+	}
+	if diff := cmp.Diff(wantReady, ready); diff != "" {
+		t.Errorf("buffer ready mismatch (-want +got):\n%s", diff)
+	}
+
+	// The buffer can only be resized whilst the log is disabled, which is recorded as well.
+	if err := tl.Set(objects.PropertyIdBufferSize, uint32(10)); err != device.ErrWriteAccessDenied {
+		t.Errorf("got %v resizing an enabled log, want ErrWriteAccessDenied", err)
+	}
+	if err := tl.Set(objects.PropertyIdEnable, false); err != nil {
+		t.Fatal(err)
+	}
+	if err := tl.Set(objects.PropertyIdBufferSize, uint32(10)); err != nil {
+		t.Fatal(err)
+	}
+	counts(tl, 2, 7)
+
+	// Logging resumes at the Start_Time.
+	if err := tl.Set(objects.PropertyIdStartTime, objects.DateTimeOf(now.Add(time.Minute))); err != nil {
+		t.Fatal(err)
+	}
+	if err := tl.Set(objects.PropertyIdEnable, true); err != nil {
+		t.Fatal(err)
+	}
+	dev.Tick()
+	counts(tl, 2, 7)
+	now = now.Add(time.Minute)
+	dev.Tick()
+	counts(tl, 4, 9)
+
+	// COV logs record changes only and stop when full if told to.
+	bv := dev.Lookup(objects.ObjectTypeBinaryValue, 0)
+	cov := device.NewTrendLog(1, "TL-1", services.DeviceObjectPropertyReference{
+		Object: bv.Identifier, PropertyId: objects.PropertyIdPresentValue, ArrayIndex: objects.ArrayAll, DeviceId: objects.MaxInstance,
+	}, 0, 4)
+	if err := cov.Set(objects.PropertyIdStopWhenFull, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := dev.Add(cov); err != nil {
+		t.Fatal(err)
+	}
+	dev.Tick()
+	for _, v := range []uint8{objects.BinaryInactive, objects.BinaryActive, objects.BinaryInactive} {
+		if err := bv.Set(objects.PropertyIdPresentValue, objects.Enumerated(v)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dev.Tick()
+	counts(cov, 4, 4)
+	if enable := cov.Get(objects.PropertyIdEnable); enable != false {
+		t.Errorf("Enable is %v once full, want false", enable)
+	}
+
+	want = []services.LogRecord{
+		{Timestamp: objects.DateTimeOf(now), LogStatus: objects.BitString{false, false, false}},
+		{Timestamp: objects.DateTimeOf(now), Value: objects.Enumerated(objects.BinaryInactive), StatusFlags: flags},
+		{Timestamp: objects.DateTimeOf(now), Value: objects.Enumerated(objects.BinaryActive), StatusFlags: flags},
+		{Timestamp: objects.DateTimeOf(now), LogStatus: objects.BitString{true, false, false}},
+	}
+	if diff := cmp.Diff(want, read(1, services.Range{})); diff != "" {
+		t.Errorf("COV log mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	dev := newTestDevice(t)
+	// Monday.
+	now := time.Date(2024, 3, 4, 6, 0, 0, 0, time.UTC)
+	dev.SetClock(func() time.Time { return now })
+	dev.SetLocation(time.UTC)
+
+	unspecified := objects.Unspecified
+	holidays := device.NewCalendar(0, "CAL-0", []interface{}{
+		objects.Date{Year: 124, Month: 3, Day: 5, Weekday: unspecified},
+		device.WeekNDay{Month: unspecified, WeekOfMonth: objects.WeekLast, DayOfWeek: 7},
+	})
+	sched := device.NewSchedule(0, "SCH-0", float32(15))
+	for _, o := range []*device.Object{holidays, sched} {
+		if err := dev.Add(o); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Properties are written by peers so that their datatypes go both ways.
+	write := func(propertyId uint32, dt device.Datatype, value interface{}) {
+		t.Helper()
+		objs, err := dt.Encode(value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := dev.WriteProperty(objects.ObjectTypeSchedule, 0, propertyId, objects.ArrayAll, objs, 0); err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(value, sched.Get(propertyId)); diff != "" {
+			t.Errorf("property %d mismatch (-want +got):\n%s", propertyId, diff)
+		}
+	}
+
+	workday := []device.TimeValue{
+		{Time: objects.Time{Hour: 7}, Value: float32(21)},
+		{Time: objects.Time{Hour: 18}, Value: nil},
+	}
+	week := []interface{}{workday, workday, workday, workday, workday, []device.TimeValue{}, []device.TimeValue{}}
+	write(objects.PropertyIdWeeklySchedule, device.ArrayOf(device.DailyScheduleType), week)
+	write(objects.PropertyIdExceptionSchedule, device.ArrayOf(device.SpecialEventType), []interface{}{
+		device.SpecialEvent{Period: holidays.Identifier, TimeValues: []device.TimeValue{{Value: float32(10)}}, Priority: 10},
+		device.SpecialEvent{
+			Period:     device.DateRange{Start: objects.Date{Year: 124, Month: 3, Day: 6, Weekday: 3}, End: objects.Date{Year: 124, Month: 3, Day: 7, Weekday: 4}},
+			TimeValues: []device.TimeValue{{Time: objects.Time{Hour: 12}, Value: float32(25)}},
+			Priority:   5,
+		},
+		device.SpecialEvent{
+			Period:     objects.Date{Year: unspecified, Month: objects.MonthOdd, Day: unspecified, Weekday: 3},
+			TimeValues: []device.TimeValue{{Time: objects.Time{Hour: 12}, Value: float32(30)}},
+			Priority:   6,
+		},
+	})
+	write(objects.PropertyIdListOfObjectPropertyRefs, device.ListOf(device.DeviceObjectPropertyReferenceType), []interface{}{
+		services.DeviceObjectPropertyReference{
+			Object:     objects.ObjectIdentifier{ObjectType: objects.ObjectTypeAnalogOutput},
+			PropertyId: objects.PropertyIdPresentValue,
+			ArrayIndex: objects.ArrayAll,
+			DeviceId:   objects.MaxInstance,
+		},
+	})
+	write(objects.PropertyIdPriorityForWriting, device.Unsigned, uint32(8))
+
+	remote := services.DeviceObjectPropertyReference{
+		Object:     objects.ObjectIdentifier{ObjectType: objects.ObjectTypeAnalogOutput},
+		PropertyId: objects.PropertyIdPresentValue,
+		ArrayIndex: objects.ArrayAll,
+		DeviceId:   4000,
+	}
+	if err := sched.Set(objects.PropertyIdListOfObjectPropertyRefs, []interface{}{remote}); err != device.ErrOptionalFunctionalityNotSupported {
+		t.Errorf("got %v referencing a remote device, want ErrOptionalFunctionalityNotSupported", err)
+	}
+
+	ao := dev.Lookup(objects.ObjectTypeAnalogOutput, 0)
+	var testcases = []struct {
+		description string
+		at          time.Time
+		want        float32
+	}{
+		{"before the first workday transition", time.Date(2024, 3, 4, 6, 0, 0, 0, time.UTC), 15},
+		{"on a workday", time.Date(2024, 3, 4, 7, 0, 0, 0, time.UTC), 21},
+		{"after a NULL transition", time.Date(2024, 3, 4, 18, 30, 0, 0, time.UTC), 15},
+		{"on a holiday of the calendar", time.Date(2024, 3, 5, 8, 0, 0, 0, time.UTC), 10},
+		{"on a range before its transition", time.Date(2024, 3, 6, 8, 0, 0, 0, time.UTC), 21},
+		{"on a range overriding a lower priority date", time.Date(2024, 3, 6, 12, 0, 0, 0, time.UTC), 25},
+		{"on a date matching odd months", time.Date(2024, 3, 13, 12, 0, 0, 0, time.UTC), 30},
+		{"on a weekend", time.Date(2024, 3, 16, 12, 0, 0, 0, time.UTC), 15},
+		{"on the last Sunday of the month", time.Date(2024, 3, 31, 12, 0, 0, 0, time.UTC), 10},
+	}
+	for _, c := range testcases {
+		now = c.at
+		dev.Tick()
+		if got := sched.Get(objects.PropertyIdPresentValue); got != c.want {
+			t.Errorf("%s: Present_Value is %v, want %v", c.description, got, c.want)
+		}
+		if got := ao.Get(objects.PropertyIdPresentValue); got != c.want {
+			t.Errorf("%s: AO Present_Value is %v, want %v", c.description, got, c.want)
+		}
+	}
+
+	if got := holidays.Get(objects.PropertyIdPresentValue); got != true {
+		t.Errorf("calendar Present_Value is %v on a holiday, want true", got)
+	}
+	priorities, _ := ao.Get(objects.PropertyIdPriorityArray).([]interface{})
+	if priorities[7] != float32(10) {
+		t.Errorf("AO priority 8 is %v, want 10", priorities[7])
+	}
+
+	// Outside of the Effective_Period, the Schedule_Default applies.
+	write(objects.PropertyIdEffectivePeriod, device.DateRangeType, device.DateRange{
+		Start: objects.Date{Year: 124, Month: 1, Day: 1, Weekday: 1},
+		End:   objects.Date{Year: 124, Month: 3, Day: 30, Weekday: 6},
+	})
+	if got := ao.Get(objects.PropertyIdPresentValue); got != float32(15) {
+		t.Errorf("AO Present_Value is %v outside of the Effective_Period, want 15", got)
+	}
+}
+
+func TestIntrinsicReporting(t *testing.T) {
+	dev := newTestDevice(t)
+	// Monday.
+	now := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	dev.SetClock(func() time.Time { return now })
+	dev.SetLocation(time.UTC)
+
+	var notifications []device.EventNotification
+	dev.OnEvent(func(n device.EventNotification) { notifications = append(notifications, n) })
+
+	nc := device.NewNotificationClass(3, "NC-3")
+	ai := device.NewAnalogInput(1, "AI-1", objects.UnitsDegreesCelsius)
+	bv := device.NewBinaryValue(2, "BV-2")
+	for _, o := range []*device.Object{nc, ai, bv} {
+		if err := dev.Add(o); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, o := range []*device.Object{ai, bv} {
+		if err := o.AddIntrinsicReporting(3); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := device.NewMultiStateOutput(4, "MO-4", []string{"a", "b"}).AddIntrinsicReporting(3); err != device.ErrEventsNotSupported {
+		t.Errorf("got %v adding intrinsic reporting to a Multi-state Output, want ErrEventsNotSupported", err)
+	}
+
+	allDays := objects.BitString{true, true, true, true, true, true, true}
+	operator := device.Destination{
+		ValidDays:   allDays,
+		FromTime:    objects.Time{},
+		ToTime:      objects.Time{Hour: 23, Minute: 59, Second: 59, Hundredths: 99},
+		Recipient:   services.Recipient{DeviceId: 77},
+		ProcessId:   9,
+		Confirmed:   true,
+		Transitions: objects.BitString{true, true, true},
+	}
+	nightShift := device.Destination{
+		ValidDays:   allDays,
+		FromTime:    objects.Time{Hour: 20},
+		ToTime:      objects.Time{Hour: 23, Minute: 59},
+		Recipient:   services.Recipient{Address: &services.Address{MAC: []byte{192, 168, 1, 20, 0xba, 0xc0}}},
+		Transitions: objects.BitString{true, false, false},
+	}
+	objs, err := device.ListOf(device.DestinationType).Encode([]interface{}{operator, nightShift})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dev.WriteProperty(objects.ObjectTypeNotificationClass, 3, objects.PropertyIdRecipientList, objects.ArrayAll, objs, 0); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff([]interface{}{operator, nightShift}, nc.Get(objects.PropertyIdRecipientList)); diff != "" {
+		t.Errorf("Recipient_List mismatch (-want +got):\n%s", diff)
+	}
+	if err := nc.Set(objects.PropertyIdPriority, []interface{}{uint32(10), uint32(20), uint32(30)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := nc.Set(objects.PropertyIdAckRequired, objects.BitString{true, false, false}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, pv := range []struct {
+		id    uint32
+		value interface{}
+	}{
+		{objects.PropertyIdPresentValue, float32(20)},
+		{objects.PropertyIdHighLimit, float32(80)},
+		{objects.PropertyIdLowLimit, float32(10)},
+		{objects.PropertyIdDeadband, float32(2)},
+		{objects.PropertyIdLimitEnable, objects.BitString{true, true}},
+		{objects.PropertyIdTimeDelay, uint32(5)},
+	} {
+		if err := ai.Set(pv.id, pv.value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	state := func(o *device.Object) uint8 {
+		t.Helper()
+		return uint8(o.Get(objects.PropertyIdEventState).(objects.Enumerated))
+	}
+	tick := func(seconds int) {
+		for i := 0; i < seconds; i++ {
+			now = now.Add(time.Second)
+			dev.Tick()
+		}
+	}
+
+	// Conditions must hold for Time_Delay before the transition.
+	ai.Set(objects.PropertyIdPresentValue, float32(85))
+	tick(4)
+	if state(ai) != objects.EventStateNormal || len(notifications) != 0 {
+		t.Fatalf("went to %d with %d notifications before Time_Delay elapsed", state(ai), len(notifications))
+	}
+	tick(1)
+	if state(ai) != objects.EventStateHighLimit {
+		t.Fatalf("got Event_State %d, want HIGH_LIMIT", state(ai))
+	}
+	if flags := ai.Get(objects.PropertyIdStatusFlags).(objects.BitString); !flags[objects.StatusFlagInAlarm] {
+		t.Error("Status_Flags aren't IN_ALARM")
+	}
+	if acked := ai.Get(objects.PropertyIdAckedTransitions).(objects.BitString); acked[objects.TransitionToOffnormal] {
+		t.Error("the transition to OFFNORMAL is acknowledged although Ack_Required")
+	}
+
+	stamp := services.TimeStamp{Choice: services.TimeStampDateTime, DateTime: objects.DateTimeOf(now)}
+	want := []device.EventNotification{{
+		Recipient: operator.Recipient,
+		Confirmed: true,
+		EventNotificationDec: services.EventNotificationDec{
+			ProcessId:         9,
+			DeviceId:          dev.Instance(),
+			Object:            ai.Identifier,
+			TimeStamp:         stamp,
+			NotificationClass: 3,
+			Priority:          10,
+			EventType:         objects.EventTypeOutOfRange,
+			NotifyType:        objects.NotifyTypeAlarm,
+			AckRequired:       true,
+			FromState:         objects.EventStateNormal,
+			ToState:           objects.EventStateHighLimit,
+			Parameters: services.OutOfRangeParameters{
+				ExceedingValue: 85,
+				StatusFlags:    objects.BitString{true, false, false, false},
+				Deadband:       2,
+				ExceededLimit:  80,
+			},
+		},
+	}}
+	if diff := cmp.Diff(want, notifications); diff != "" {
+		t.Errorf("notifications mismatch (-want +got):\n%s", diff)
+	}
+	stamps := ai.Get(objects.PropertyIdEventTimeStamps).([]interface{})
+	if diff := cmp.Diff(stamp, stamps[objects.TransitionToOffnormal]); diff != "" {
+		t.Errorf("Event_Time_Stamps mismatch (-want +got):\n%s", diff)
+	}
+	notifications = nil
+
+	// Going back within the limits by less than Deadband isn't enough.
+	ai.Set(objects.PropertyIdPresentValue, float32(79))
+	tick(10)
+	if state(ai) != objects.EventStateHighLimit {
+		t.Fatalf("got Event_State %d within Deadband, want HIGH_LIMIT", state(ai))
+	}
+	// Time_Delay_Normal is zero.
+	ai.Set(objects.PropertyIdPresentValue, float32(77))
+	if state(ai) != objects.EventStateNormal {
+		t.Fatalf("got Event_State %d, want NORMAL", state(ai))
+	}
+	if len(notifications) != 1 || notifications[0].Priority != 30 || notifications[0].AckRequired ||
+		notifications[0].Parameters.(services.OutOfRangeParameters).ExceededLimit != 80 {
+		t.Errorf("got notifications %+v returning to NORMAL", notifications)
+	}
+	notifications = nil
+
+	// Disabled transitions are stamped but not notified.
+	if err := bv.Set(objects.PropertyIdEventEnable, objects.BitString{false, true, true}); err != nil {
+		t.Fatal(err)
+	}
+	bv.Set(objects.PropertyIdPresentValue, objects.Enumerated(objects.BinaryActive))
+	if state(bv) != objects.EventStateOffnormal || len(notifications) != 0 {
+		t.Errorf("got Event_State %d and %d notifications, want OFFNORMAL and none", state(bv), len(notifications))
+	}
+	if acked := bv.Get(objects.PropertyIdAckedTransitions).(objects.BitString); !acked[objects.TransitionToOffnormal] {
+		t.Error("a transition never notified awaits acknowledgment")
+	}
+
+	// Recipients are only notified within their time window.
+	now = time.Date(2024, 3, 4, 21, 0, 0, 0, time.UTC)
+	bv.Set(objects.PropertyIdPresentValue, objects.Enumerated(objects.BinaryInactive))
+	if err := bv.Set(objects.PropertyIdEventEnable, objects.BitString{true, true, true}); err != nil {
+		t.Fatal(err)
+	}
+	bv.Set(objects.PropertyIdPresentValue, objects.Enumerated(objects.BinaryActive))
+	if len(notifications) != 3 {
+		t.Fatalf("got %d notifications, want 3", len(notifications))
+	}
+	if n := notifications[2]; n.Recipient.Address == nil || n.Confirmed || n.EventType != objects.EventTypeChangeOfState {
+		t.Errorf("got notification %+v for the night shift", n)
+	}
+	wantParameters := services.ChangeOfStateParameters{
+		NewState:    services.PropertyState{Choice: services.PropertyStateBinaryValue, Value: uint32(objects.BinaryActive)},
+		StatusFlags: objects.BitString{true, false, false, false},
+	}
+	if diff := cmp.Diff(wantParameters, notifications[2].Parameters); diff != "" {
+		t.Errorf("event values mismatch (-want +got):\n%s", diff)
+	}
+}
+
+func TestAlarms(t *testing.T) {
+	dev := newTestDevice(t)
+	now := time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC)
+	dev.SetClock(func() time.Time { return now })
+	dev.SetLocation(time.UTC)
+
+	var notifications []device.EventNotification
+	dev.OnEvent(func(n device.EventNotification) { notifications = append(notifications, n) })
+
+	nc := device.NewNotificationClass(3, "NC-3")
+	ai := device.NewAnalogInput(1, "AI-1", objects.UnitsDegreesCelsius)
+	bv := device.NewBinaryValue(2, "BV-2")
+	for _, o := range []*device.Object{nc, ai, bv} {
+		if err := dev.Add(o); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, o := range []*device.Object{ai, bv} {
+		if err := o.AddIntrinsicReporting(3); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	operator := device.Destination{
+		ValidDays:   objects.BitString{true, true, true, true, true, true, true},
+		ToTime:      objects.Time{Hour: 23, Minute: 59, Second: 59, Hundredths: 99},
+		Recipient:   services.Recipient{DeviceId: 77},
+		ProcessId:   9,
+		Confirmed:   true,
+		Transitions: objects.BitString{true, true, true},
+	}
+	for _, pv := range []struct {
+		o     *device.Object
+		id    uint32
+		value interface{}
+	}{
+		{nc, objects.PropertyIdRecipientList, []interface{}{operator}},
+		{nc, objects.PropertyIdPriority, []interface{}{uint32(10), uint32(20), uint32(30)}},
+		{nc, objects.PropertyIdAckRequired, objects.BitString{true, false, false}},
+		{ai, objects.PropertyIdHighLimit, float32(80)},
+		{ai, objects.PropertyIdLimitEnable, objects.BitString{false, true}},
+		{bv, objects.PropertyIdNotifyType, objects.Enumerated(objects.NotifyTypeEvent)},
+	} {
+		if err := pv.o.Set(pv.id, pv.value); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ai.Set(objects.PropertyIdPresentValue, float32(90))
+	bv.Set(objects.PropertyIdPresentValue, objects.Enumerated(objects.BinaryActive))
+	if len(notifications) != 2 {
+		t.Fatalf("got %d notifications, want 2", len(notifications))
+	}
+	alarm := notifications[0]
+	notifications = nil
+
+	// Only ALARMs are summarized by GetAlarmSummary.
+	wantAlarms := []services.AlarmSummary{{Object: ai.Identifier, AlarmState: objects.EventStateHighLimit, AckedTransitions: objects.BitString{false, true, true}}}
+	if diff := cmp.Diff(wantAlarms, dev.AlarmSummaries()); diff != "" {
+		t.Errorf("alarm summaries mismatch (-want +got):\n%s", diff)
+	}
+
+	summaries, more, err := dev.EventInformation(nil, 1476)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(summaries) != 2 || more {
+		t.Fatalf("got %d event summaries with more events %v, want 2 and no more", len(summaries), more)
+	}
+	wantSummary := services.EventSummary{
+		Object:           ai.Identifier,
+		EventState:       objects.EventStateHighLimit,
+		AckedTransitions: objects.BitString{false, true, true},
+		EventTimeStamps:  [3]services.TimeStamp{alarm.TimeStamp, summaries[0].EventTimeStamps[1], summaries[0].EventTimeStamps[2]},
+		NotifyType:       objects.NotifyTypeAlarm,
+		EventEnable:      objects.BitString{true, true, true},
+		EventPriorities:  [3]uint8{10, 20, 30},
+	}
+	if diff := cmp.Diff(wantSummary, summaries[0]); diff != "" {
+		t.Errorf("event summary mismatch (-want +got):\n%s", diff)
+	}
+
+	// Summaries not fitting are left for the next page.
+	length := 0
+	for _, obj := range services.EncEventSummary(summaries[0]) {
+		length += obj.MarshalLen()
+	}
+	page, more, err := dev.EventInformation(nil, length)
+	if err != nil || len(page) != 1 || !more {
+		t.Errorf("got %d event summaries with more events %v and error %v, want 1 and more", len(page), more, err)
+	}
+	page, more, err = dev.EventInformation(&ai.Identifier, length)
+	if err != nil || len(page) != 1 || page[0].Object != bv.Identifier || more {
+		t.Errorf("got event summaries %+v with more events %v and error %v following AI-1", page, more, err)
+	}
+	if _, _, err := dev.EventInformation(&objects.ObjectIdentifier{ObjectType: objects.ObjectTypeAnalogInput, InstanceNumber: 99}, length); err != device.ErrUnknownObject {
+		t.Errorf("got %v following an unknown object, want ErrUnknownObject", err)
+	}
+
+	wantSummaries := func(filter services.EnrollmentFilter, want ...objects.ObjectIdentifier) {
+		t.Helper()
+		got := []objects.ObjectIdentifier{}
+		for _, s := range dev.EnrollmentSummaries(filter) {
+			got = append(got, s.Object)
+		}
+		if diff := cmp.Diff(append([]objects.ObjectIdentifier{}, want...), got); diff != "" {
+			t.Errorf("enrollment summaries of %+v mismatch (-want +got):\n%s", filter, diff)
+		}
+	}
+	changeOfState, offnormal, normal := objects.EventTypeChangeOfState, services.EnrollmentStateOffnormal, services.EnrollmentStateNormal
+	otherClass := uint32(4)
+	wantSummaries(services.EnrollmentFilter{}, ai.Identifier, bv.Identifier)
+	wantSummaries(services.EnrollmentFilter{EventType: &changeOfState}, bv.Identifier)
+	wantSummaries(services.EnrollmentFilter{EventState: &offnormal}, ai.Identifier, bv.Identifier)
+	wantSummaries(services.EnrollmentFilter{EventState: &normal})
+	wantSummaries(services.EnrollmentFilter{Priority: &services.PriorityRange{Min: 0, Max: 5}})
+	wantSummaries(services.EnrollmentFilter{NotificationClass: &otherClass})
+	wantSummaries(services.EnrollmentFilter{Recipient: &services.Recipient{DeviceId: 77}, ProcessId: 9}, ai.Identifier, bv.Identifier)
+	wantSummaries(services.EnrollmentFilter{Recipient: &services.Recipient{DeviceId: 77}, ProcessId: 8})
+
+	// Acknowledgments must match the time stamp of the transition.
+	ack := services.ConfirmedAcknowledgeAlarmDec{
+		ProcessId:         alarm.ProcessId,
+		Object:            ai.Identifier,
+		EventState:        objects.EventStateHighLimit,
+		TimeStamp:         services.TimeStamp{Choice: services.TimeStampSequence, Sequence: 1},
+		Source:            "operator",
+		TimeOfAcknowledge: services.TimeStamp{Choice: services.TimeStampTime, Time: objects.Time{Hour: 9, Minute: 1}},
+	}
+	if err := dev.AcknowledgeAlarm(ack); err != device.ErrInvalidTimeStamp {
+		t.Errorf("got %v acknowledging with a wrong time stamp, want ErrInvalidTimeStamp", err)
+	}
+	ack.TimeStamp = alarm.TimeStamp
+	for i := 0; i < 2; i++ {
+		if err := dev.AcknowledgeAlarm(ack); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if acked := ai.Get(objects.PropertyIdAckedTransitions).(objects.BitString); !acked[objects.TransitionToOffnormal] {
+		t.Error("the transition to HIGH_LIMIT isn't acknowledged")
+	}
+
+	// The acknowledgment is notified once.
+	want := []device.EventNotification{{
+		Recipient: operator.Recipient,
+		Confirmed: true,
+		EventNotificationDec: services.EventNotificationDec{
+			ProcessId:         9,
+			DeviceId:          dev.Instance(),
+			Object:            ai.Identifier,
+			TimeStamp:         ack.TimeOfAcknowledge,
+			NotificationClass: 3,
+			Priority:          10,
+			EventType:         objects.EventTypeOutOfRange,
+			NotifyType:        objects.NotifyTypeAckNotification,
+			ToState:           objects.EventStateHighLimit,
+		},
+	}}
+	if diff := cmp.Diff(want, notifications); diff != "" {
+		t.Errorf("notifications mismatch (-want +got):\n%s", diff)
+	}
+	wantSummaries(services.EnrollmentFilter{Acknowledgment: services.EnrollmentNotAcked}, bv.Identifier)
+	wantSummaries(services.EnrollmentFilter{Acknowledgment: services.EnrollmentAcked}, ai.Identifier)
+
+	ack.Object = objects.ObjectIdentifier{ObjectType: objects.ObjectTypeAnalogOutput, InstanceNumber: 0}
+	if err := dev.AcknowledgeAlarm(ack); err != device.ErrEventsNotSupported {
+		t.Errorf("got %v acknowledging an object not reporting events, want ErrEventsNotSupported", err)
+	}
+	ack.Object.InstanceNumber = 99
+	if err := dev.AcknowledgeAlarm(ack); err != device.ErrUnknownObject {
+		t.Errorf("got %v acknowledging an unknown object, want ErrUnknownObject", err)
+	}
+}
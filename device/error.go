@@ -0,0 +1,46 @@
+package device
+
+import (
+	"errors"
+
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+)
+
+// Errors the object database reports back to peers.
+var (
+	ErrUnknownObject                     = &common.BACnetError{Class: objects.ErrorClassObject, Code: objects.ErrorCodeUnknownObject}
+	ErrUnknownProperty                   = &common.BACnetError{Class: objects.ErrorClassProperty, Code: objects.ErrorCodeUnknownProperty}
+	ErrObjectIdExists                    = &common.BACnetError{Class: objects.ErrorClassObject, Code: objects.ErrorCodeObjectIdentifierExists}
+	ErrWriteAccessDenied                 = &common.BACnetError{Class: objects.ErrorClassProperty, Code: objects.ErrorCodeWriteAccessDenied}
+	ErrInvalidDataType                   = &common.BACnetError{Class: objects.ErrorClassProperty, Code: objects.ErrorCodeInvalidDataType}
+	ErrValueOutOfRange                   = &common.BACnetError{Class: objects.ErrorClassProperty, Code: objects.ErrorCodeValueOutOfRange}
+	ErrPropertyIsNotAnArray              = &common.BACnetError{Class: objects.ErrorClassProperty, Code: objects.ErrorCodePropertyIsNotAnArray}
+	ErrInvalidArrayIndex                 = &common.BACnetError{Class: objects.ErrorClassProperty, Code: objects.ErrorCodeInvalidArrayIndex}
+	ErrDuplicateName                     = &common.BACnetError{Class: objects.ErrorClassProperty, Code: objects.ErrorCodeDuplicateName}
+	ErrPasswordFailure                   = &common.BACnetError{Class: objects.ErrorClassSecurity, Code: objects.ErrorCodePasswordFailure}
+	ErrConfigurationInProgress           = &common.BACnetError{Class: objects.ErrorClassDevice, Code: objects.ErrorCodeConfigurationInProgress}
+	ErrServiceRequestDenied              = &common.BACnetError{Class: objects.ErrorClassService, Code: objects.ErrorCodeServiceRequestDenied}
+	ErrOperationalProblem                = &common.BACnetError{Class: objects.ErrorClassDevice, Code: objects.ErrorCodeOperationalProblem}
+	ErrFileAccessDenied                  = &common.BACnetError{Class: objects.ErrorClassService, Code: objects.ErrorCodeFileAccessDenied}
+	ErrInvalidFileAccessMethod           = &common.BACnetError{Class: objects.ErrorClassService, Code: objects.ErrorCodeInvalidFileAccessMethod}
+	ErrInvalidFileStartPosition          = &common.BACnetError{Class: objects.ErrorClassService, Code: objects.ErrorCodeInvalidFileStartPosition}
+	ErrDynamicCreationNotSupported       = &common.BACnetError{Class: objects.ErrorClassObject, Code: objects.ErrorCodeDynamicCreationNotSupported}
+	ErrUnsupportedObjectType             = &common.BACnetError{Class: objects.ErrorClassObject, Code: objects.ErrorCodeUnsupportedObjectType}
+	ErrNoSpaceForObject                  = &common.BACnetError{Class: objects.ErrorClassResources, Code: objects.ErrorCodeNoSpaceForObject}
+	ErrObjectDeletionNotPermitted        = &common.BACnetError{Class: objects.ErrorClassObject, Code: objects.ErrorCodeObjectDeletionNotPermitted}
+	ErrPropertyIsNotAList                = &common.BACnetError{Class: objects.ErrorClassService, Code: objects.ErrorCodePropertyIsNotAList}
+	ErrListElementNotFound               = &common.BACnetError{Class: objects.ErrorClassService, Code: objects.ErrorCodeListElementNotFound}
+	ErrOptionalFunctionalityNotSupported = &common.BACnetError{Class: objects.ErrorClassService, Code: objects.ErrorCodeOptionalFunctionalityNotSup}
+	ErrInvalidTimeStamp                  = &common.BACnetError{Class: objects.ErrorClassService, Code: objects.ErrorCodeInvalidTimeStamp}
+)
+
+// asBACnetError returns the BACnetError err is or wraps, falling back to an Other error of
+// the Device class for any other error.
+func asBACnetError(err error) common.BACnetError {
+	var bErr *common.BACnetError
+	if errors.As(err, &bErr) {
+		return *bErr
+	}
+	return common.BACnetError{Class: objects.ErrorClassDevice, Code: objects.ErrorCodeOther}
+}
@@ -0,0 +1,422 @@
+package device
+
+import (
+	"time"
+
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/services"
+)
+
+// ErrEventsNotSupported is reported when adding intrinsic reporting to objects lacking an
+// event algorithm.
+var ErrEventsNotSupported = &common.BACnetError{Class: objects.ErrorClassObject, Code: objects.ErrorCodeOptionalFunctionalityNotSup}
+
+// EventNotification is an event notification due to a recipient on the Recipient_List of a
+// Notification Class.
+type EventNotification struct {
+	Recipient services.Recipient
+	Confirmed bool
+
+	services.EventNotificationDec
+}
+
+// event holds the intrinsic reporting state of an object.
+type event struct {
+	o *Object
+
+	eventType uint32
+	// detect returns the Event_State the object's conditions call for given the current one,
+	// and parameters the event values notifying a transition, once it's been carried out.
+	detect     func(state uint8) uint8
+	parameters func(from, to uint8) services.NotificationParameters
+
+	// pending is the Event_State the conditions have called for since pendingSince.
+	pending      uint8
+	pendingSince time.Time
+}
+
+// OnEvent sets the function receiving the event notifications due to the recipients of the
+// Notification Classes. It's called with the Device locked, so it must not block nor call
+// back into the Device.
+func (d *Device) OnEvent(notify func(EventNotification)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.notifyEvent = notify
+}
+
+// AddIntrinsicReporting adds the properties of intrinsic reporting to an analog object or to a
+// binary or multi-state input or value, whose transitions are then notified to the recipients
+// of the Notification Class numbered class. Analog objects go to HIGH_LIMIT and LOW_LIMIT as
+// their Present_Value goes past the limits enabled by Limit_Enable, returning to NORMAL once
+// it's back within them by Deadband. Binary and multi-state objects go to OFFNORMAL whilst
+// their Present_Value matches their Alarm_Value or one of their Alarm_Values. Conditions
+// must hold for Time_Delay seconds, or Time_Delay_Normal when returning to NORMAL.
+func (o *Object) AddIntrinsicReporting(class uint32) error {
+	defer o.lock()()
+
+	if o.event != nil {
+		return nil
+	}
+
+	e := &event{o: o}
+	switch o.Identifier.ObjectType {
+	case objects.ObjectTypeAnalogInput, objects.ObjectTypeAnalogOutput, objects.ObjectTypeAnalogValue:
+		o.addLimits()
+		e.eventType, e.detect, e.parameters = objects.EventTypeOutOfRange, e.outOfRange, e.outOfRangeParameters
+	case objects.ObjectTypeBinaryInput, objects.ObjectTypeBinaryValue:
+		o.add(&Property{
+			Identifier: objects.PropertyIdAlarmValue,
+			Datatype:   Enumerated,
+			Access:     AccessWritable,
+			Value:      objects.Enumerated(objects.BinaryActive),
+			Validate:   validateBinary,
+		})
+		e.eventType, e.detect, e.parameters = objects.EventTypeChangeOfState, e.changeOfState, e.changeOfStateParameters
+	case objects.ObjectTypeMultiStateInput, objects.ObjectTypeMultiStateValue:
+		o.add(&Property{
+			Identifier: objects.PropertyIdAlarmValues,
+			Datatype:   ListOf(Unsigned),
+			Access:     AccessWritable,
+			Value:      []interface{}{},
+			Validate:   o.validateStates,
+		})
+		e.eventType, e.detect, e.parameters = objects.EventTypeChangeOfState, e.changeOfState, e.changeOfStateParameters
+	default:
+		return ErrEventsNotSupported
+	}
+
+	o.add(&Property{
+		Identifier: objects.PropertyIdTimeDelay,
+		Datatype:   Unsigned,
+		Access:     AccessWritable,
+		Value:      uint32(0),
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdTimeDelayNormal,
+		Datatype:   Unsigned,
+		Access:     AccessWritable,
+		Value:      uint32(0),
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdNotificationClass,
+		Datatype:   Unsigned,
+		Access:     AccessWritable,
+		Value:      class,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdEventEnable,
+		Datatype:   BitString,
+		Access:     AccessWritable,
+		Value:      objects.BitString{true, true, true},
+		Validate:   validateTransitions,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdAckedTransitions,
+		Datatype:   BitString,
+		Value:      objects.BitString{true, true, true},
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdNotifyType,
+		Datatype:   Enumerated,
+		Access:     AccessWritable,
+		Value:      objects.Enumerated(objects.NotifyTypeAlarm),
+		Validate:   validateNotifyType,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdEventTimeStamps,
+		Datatype:   &Array{Element: TimeStampType, Size: 3},
+		Value:      []interface{}{unspecifiedStamp, unspecifiedStamp, unspecifiedStamp},
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdEventDetectionEnable,
+		Datatype:   Boolean,
+		Access:     AccessWritable,
+		Value:      true,
+	})
+
+	o.event = e
+	if o.device != nil && e.update() {
+		o.device.changed(o)
+	}
+
+	return nil
+}
+
+// unspecifiedStamp is the Event_Time_Stamp of transitions yet to happen.
+var unspecifiedStamp = services.TimeStamp{Choice: services.TimeStampDateTime, DateTime: unspecifiedDateTime}
+
+func (o *Object) addLimits() {
+	o.add(&Property{
+		Identifier: objects.PropertyIdHighLimit,
+		Datatype:   Real,
+		Access:     AccessWritable,
+		Value:      float32(0),
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdLowLimit,
+		Datatype:   Real,
+		Access:     AccessWritable,
+		Value:      float32(0),
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdDeadband,
+		Datatype:   Real,
+		Access:     AccessWritable,
+		Value:      float32(0),
+		Validate:   validatePositive,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdLimitEnable,
+		Datatype:   BitString,
+		Access:     AccessWritable,
+		Value:      objects.BitString{false, false},
+		Validate:   validateLimitEnable,
+	})
+}
+
+// tickEvents lets the objects reporting events carry out the transitions whose time delays
+// elapsed.
+func (d *Device) tickEvents() {
+	for _, o := range d.order {
+		if o.event != nil && o.event.update() {
+			d.changed(o)
+		}
+	}
+}
+
+// update carries out the transition the object's conditions call for once they held for the
+// time delay. It tells whether the Event_State changed.
+func (e *event) update() bool {
+	o := e.o
+
+	if enabled, _ := o.value(objects.PropertyIdEventDetectionEnable).(bool); !enabled {
+		e.pendingSince = time.Time{}
+		return false
+	}
+
+	state := e.state()
+	target := e.detect(state)
+	if target == state {
+		e.pendingSince = time.Time{}
+		return false
+	}
+
+	now := o.now()
+	if e.pendingSince.IsZero() || e.pending != target {
+		e.pending, e.pendingSince = target, now
+	}
+
+	delayId := objects.PropertyIdTimeDelay
+	if target == objects.EventStateNormal {
+		delayId = objects.PropertyIdTimeDelayNormal
+	}
+	delay, _ := o.value(delayId).(uint32)
+	if now.Sub(e.pendingSince) < time.Duration(delay)*time.Second {
+		return false
+	}
+
+	e.pendingSince = time.Time{}
+	e.transition(state, target)
+	return true
+}
+
+func (e *event) state() uint8 {
+	state, _ := e.o.value(objects.PropertyIdEventState).(objects.Enumerated)
+	return uint8(state)
+}
+
+// transition moves the object to a new Event_State, stamping the transition and notifying it
+// if it's enabled.
+func (e *event) transition(from, to uint8) {
+	o, d := e.o, e.o.device
+	kind := transitionOf(to)
+	now := o.localNow()
+
+	o.properties[objects.PropertyIdEventState].Value = objects.Enumerated(to)
+
+	stamps := append([]interface{}{}, o.value(objects.PropertyIdEventTimeStamps).([]interface{})...)
+	stamps[kind] = services.TimeStamp{Choice: services.TimeStampDateTime, DateTime: objects.DateTimeOf(now)}
+	o.properties[objects.PropertyIdEventTimeStamps].Value = stamps
+
+	acked := append(objects.BitString{}, o.value(objects.PropertyIdAckedTransitions).(objects.BitString)...)
+	acked[kind] = true
+	defer func() { o.properties[objects.PropertyIdAckedTransitions].Value = acked }()
+
+	if enable, _ := o.value(objects.PropertyIdEventEnable).(objects.BitString); !enable[kind] {
+		return
+	}
+	class, _ := o.value(objects.PropertyIdNotificationClass).(uint32)
+	nc := d.notificationClass(class)
+	if nc == nil {
+		return
+	}
+
+	ackRequired := nc.value(objects.PropertyIdAckRequired).(objects.BitString)[kind]
+	acked[kind] = !ackRequired
+
+	notifyType, _ := o.value(objects.PropertyIdNotifyType).(objects.Enumerated)
+	e.notify(nc, kind, now, services.EventNotificationDec{
+		TimeStamp:   stamps[kind].(services.TimeStamp),
+		NotifyType:  uint8(notifyType),
+		AckRequired: ackRequired,
+		FromState:   from,
+		ToState:     to,
+		Parameters:  e.parameters(from, to),
+	})
+}
+
+// notify sends an event notification about a transition of the given kind to the recipients
+// of the Notification Class nc, filling in what every notification of the object shares.
+func (e *event) notify(nc *Object, kind uint8, now time.Time, dec services.EventNotificationDec) {
+	o, d := e.o, e.o.device
+	if d.notifyEvent == nil {
+		return
+	}
+
+	dec.DeviceId = d.Instance()
+	dec.Object = o.Identifier
+	dec.NotificationClass, _ = nc.value(objects.PropertyIdNotificationClass).(uint32)
+	dec.Priority = priorities(nc)[kind]
+	dec.EventType = e.eventType
+
+	recipients, _ := nc.value(objects.PropertyIdRecipientList).([]interface{})
+	for _, r := range recipients {
+		dest := r.(Destination)
+		if !dest.notifies(kind, now) {
+			continue
+		}
+		dec.ProcessId = dest.ProcessId
+		d.notifyEvent(EventNotification{Recipient: dest.Recipient, Confirmed: dest.Confirmed, EventNotificationDec: dec})
+	}
+}
+
+// transitionOf returns the kind of transition leading to an Event_State.
+func transitionOf(state uint8) uint8 {
+	switch state {
+	case objects.EventStateNormal:
+		return objects.TransitionToNormal
+	case objects.EventStateFault:
+		return objects.TransitionToFault
+	}
+	return objects.TransitionToOffnormal
+}
+
+// outOfRange is the OUT_OF_RANGE event algorithm.
+func (e *event) outOfRange(state uint8) uint8 {
+	o := e.o
+	pv, _ := o.value(objects.PropertyIdPresentValue).(float32)
+	high, _ := o.value(objects.PropertyIdHighLimit).(float32)
+	low, _ := o.value(objects.PropertyIdLowLimit).(float32)
+	deadband, _ := o.value(objects.PropertyIdDeadband).(float32)
+	enable, _ := o.value(objects.PropertyIdLimitEnable).(objects.BitString)
+	lowEnabled, highEnabled := enable[objects.LimitEnableLowLimit], enable[objects.LimitEnableHighLimit]
+
+	switch {
+	case highEnabled && pv > high && state != objects.EventStateHighLimit:
+		return objects.EventStateHighLimit
+	case lowEnabled && pv < low && state != objects.EventStateLowLimit:
+		return objects.EventStateLowLimit
+	case state == objects.EventStateHighLimit && (!highEnabled || pv < high-deadband):
+		return objects.EventStateNormal
+	case state == objects.EventStateLowLimit && (!lowEnabled || pv > low+deadband):
+		return objects.EventStateNormal
+	}
+	return state
+}
+
+func (e *event) outOfRangeParameters(from, to uint8) services.NotificationParameters {
+	o := e.o
+	p := services.OutOfRangeParameters{StatusFlags: o.statusFlags().(objects.BitString)}
+	p.ExceedingValue, _ = o.value(objects.PropertyIdPresentValue).(float32)
+	p.Deadband, _ = o.value(objects.PropertyIdDeadband).(float32)
+
+	limitId := objects.PropertyIdHighLimit
+	if to == objects.EventStateLowLimit || to == objects.EventStateNormal && from == objects.EventStateLowLimit {
+		limitId = objects.PropertyIdLowLimit
+	}
+	p.ExceededLimit, _ = o.value(limitId).(float32)
+
+	return p
+}
+
+// changeOfState is the CHANGE_OF_STATE event algorithm.
+func (e *event) changeOfState(state uint8) uint8 {
+	o := e.o
+	pv := o.value(objects.PropertyIdPresentValue)
+
+	alarm := false
+	if alarmValue, ok := o.properties[objects.PropertyIdAlarmValue]; ok {
+		alarm = pv == alarmValue.Value
+	} else {
+		values, _ := o.value(objects.PropertyIdAlarmValues).([]interface{})
+		for _, v := range values {
+			alarm = alarm || pv == v
+		}
+	}
+
+	if alarm {
+		return objects.EventStateOffnormal
+	}
+	return objects.EventStateNormal
+}
+
+func (e *event) changeOfStateParameters(from, to uint8) services.NotificationParameters {
+	o := e.o
+	p := services.ChangeOfStateParameters{StatusFlags: o.statusFlags().(objects.BitString)}
+
+	switch pv := o.value(objects.PropertyIdPresentValue).(type) {
+	case objects.Enumerated:
+		p.NewState = services.PropertyState{Choice: services.PropertyStateBinaryValue, Value: uint32(pv)}
+	case uint32:
+		p.NewState = services.PropertyState{Choice: services.PropertyStateUnsignedValue, Value: pv}
+	}
+
+	return p
+}
+
+func (o *Object) validateStates(value interface{}) error {
+	values, _ := value.([]interface{})
+	for _, v := range values {
+		if err := o.validateState(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateNotifyType(value interface{}) error {
+	if v, _ := value.(objects.Enumerated); uint8(v) > objects.NotifyTypeEvent {
+		return ErrValueOutOfRange
+	}
+	return nil
+}
+
+func validateLimitEnable(value interface{}) error {
+	if bits, _ := value.(objects.BitString); len(bits) != 2 {
+		return ErrValueOutOfRange
+	}
+	return nil
+}
+
+type timeStampType struct{}
+
+// TimeStampType is a BACnetTimeStamp whose values are services.TimeStamp.
+var TimeStampType Datatype = timeStampType{}
+
+func (timeStampType) Encode(value interface{}) ([]objects.APDUPayload, error) {
+	ts, ok := value.(services.TimeStamp)
+	if !ok {
+		return nil, ErrInvalidDataType
+	}
+	return services.EncTimeStamp(ts), nil
+}
+
+func (timeStampType) Decode(rawPayloads []objects.APDUPayload) (interface{}, int, error) {
+	ts, next, err := services.DecTimeStamp(rawPayloads, 0)
+	if err != nil {
+		return nil, 0, ErrInvalidDataType
+	}
+	return ts, next, nil
+}
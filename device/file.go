@@ -0,0 +1,497 @@
+package device
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ulbios/bacnet/objects"
+)
+
+// file holds the data of a File object, either as a stream of octets or as records.
+type file struct {
+	o *Object
+
+	data    io.ReaderAt
+	records [][]byte
+	stream  bool
+
+	modified time.Time
+}
+
+// NewFile creates a stream access File object reading its data off r, which must tell its
+// size by implementing Size() int64, like *bytes.Reader, or Stat() (os.FileInfo, error),
+// like *os.File. The File can be written to if r implements io.WriterAt as well, and
+// truncated by writing its File_Size if r implements Truncate(int64) error on top of that.
+func NewFile(instance uint32, name string, fileType string, r io.ReaderAt) *Object {
+	o, f := newFile(instance, name, fileType, true)
+	f.data = r
+
+	_, writable := r.(io.WriterAt)
+	o.properties[objects.PropertyIdReadOnly].Value = !writable
+	if fi, err := statData(r); err == nil && !fi.ModTime().IsZero() {
+		f.modified = fi.ModTime()
+	}
+
+	size := o.properties[objects.PropertyIdFileSize]
+	size.compute = f.size
+	if _, ok := r.(truncater); ok && writable {
+		size.Access = AccessWritable
+		size.write = f.truncate
+	}
+
+	return o
+}
+
+// NewPathFile creates a stream access File object whose data lives in the file at path,
+// its Object_Name being the base name of path. The file is opened on every access so that
+// others can replace or grow it, and created on the first write unless readOnly.
+func NewPathFile(instance uint32, path string, fileType string, readOnly bool) *Object {
+	p := &pathFile{path: path}
+	if readOnly {
+		return NewFile(instance, filepath.Base(path), fileType, readOnlyData{p})
+	}
+	return NewFile(instance, filepath.Base(path), fileType, p)
+}
+
+// NewDirectoryFiles creates File objects for the regular files found in dir, numbering
+// them from instance on in the order of their names.
+func NewDirectoryFiles(dir string, instance uint32, fileType string, readOnly bool) ([]*Object, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []*Object{}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() {
+			continue
+		}
+		files = append(files, NewPathFile(instance, filepath.Join(dir, entry.Name()), fileType, readOnly))
+		instance++
+	}
+	return files, nil
+}
+
+// NewRecordFile creates a record access File object holding its records in memory. Writing
+// its Record_Count truncates it.
+func NewRecordFile(instance uint32, name string, fileType string, records [][]byte) *Object {
+	o, f := newFile(instance, name, fileType, false)
+	for _, record := range records {
+		f.records = append(f.records, append([]byte{}, record...))
+	}
+
+	o.properties[objects.PropertyIdFileSize].compute = f.recordsSize
+	o.add(&Property{
+		Identifier: objects.PropertyIdRecordCount,
+		Datatype:   Unsigned,
+		Required:   true,
+		Access:     AccessWritable,
+		compute:    func() interface{} { return uint32(len(f.records)) },
+		write:      f.truncateRecords,
+	})
+
+	return o
+}
+
+func newFile(instance uint32, name string, fileType string, stream bool) (*Object, *file) {
+	o := newObject(objects.ObjectTypeFile, instance, name)
+	f := &file{o: o, stream: stream, modified: time.Now()}
+	o.file = f
+
+	method := objects.FileAccessRecord
+	if stream {
+		method = objects.FileAccessStream
+	}
+
+	o.add(&Property{
+		Identifier: objects.PropertyIdFileType,
+		Datatype:   CharacterString,
+		Required:   true,
+		Value:      fileType,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdFileSize,
+		Datatype:   Unsigned,
+		Required:   true,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdModificationDate,
+		Datatype:   DateTime,
+		Required:   true,
+		compute:    func() interface{} { return objects.DateTimeOf(f.modified) },
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdArchive,
+		Datatype:   Boolean,
+		Required:   true,
+		Access:     AccessWritable,
+		Value:      false,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdReadOnly,
+		Datatype:   Boolean,
+		Required:   true,
+		Value:      false,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdFileAccessMethod,
+		Datatype:   Enumerated,
+		Required:   true,
+		Value:      objects.Enumerated(method),
+	})
+
+	return o, f
+}
+
+// ReadStream reads up to count octets off a stream access File from position on, telling
+// whether the end of the file was reached. No more than maxLen octets are read, whatever
+// the count.
+func (o *Object) ReadStream(position int32, count uint32, maxLen int) ([]byte, bool, error) {
+	defer o.lock()()
+
+	f, err := o.fileAccess(true)
+	if err != nil {
+		return nil, false, err
+	}
+
+	size, err := dataSize(f.data)
+	if err != nil {
+		return nil, false, ErrOperationalProblem
+	}
+	if position < 0 || int64(position) > size {
+		return nil, false, ErrInvalidFileStartPosition
+	}
+
+	n := size - int64(position)
+	if int64(count) < n {
+		n = int64(count)
+	}
+	if int64(maxLen) < n {
+		n = int64(maxLen)
+	}
+	if n < 0 {
+		n = 0
+	}
+	data := make([]byte, n)
+	if read, err := f.data.ReadAt(data, int64(position)); err != nil && !(errors.Is(err, io.EOF) && read == len(data)) {
+		return nil, false, ErrOperationalProblem
+	}
+
+	return data, int64(position)+n == size, nil
+}
+
+// WriteStream writes data to a stream access File at position, or at its end if position
+// is -1, returning the position it was written at.
+func (o *Object) WriteStream(position int32, data []byte) (int32, error) {
+	defer o.lock()()
+
+	f, err := o.fileAccess(true)
+	if err != nil {
+		return 0, err
+	}
+	w, ok := f.data.(io.WriterAt)
+	if !ok || o.value(objects.PropertyIdReadOnly) == true {
+		return 0, ErrFileAccessDenied
+	}
+
+	size, err := dataSize(f.data)
+	if err != nil {
+		return 0, ErrOperationalProblem
+	}
+	switch {
+	case position == -1:
+		position = int32(size)
+	case position < 0 || int64(position) > size:
+		return 0, ErrInvalidFileStartPosition
+	}
+
+	if _, err := w.WriteAt(data, int64(position)); err != nil {
+		return 0, ErrOperationalProblem
+	}
+	f.written()
+
+	return position, nil
+}
+
+// ReadRecords reads up to count records off a record access File from record start on,
+// telling whether the end of the file was reached. The records read are cut down to those
+// whose encoding as octet strings fits in maxLen octets.
+func (o *Object) ReadRecords(start int32, count uint32, maxLen int) ([][]byte, bool, error) {
+	defer o.lock()()
+
+	f, err := o.fileAccess(false)
+	if err != nil {
+		return nil, false, err
+	}
+	if start < 0 || int(start) > len(f.records) {
+		return nil, false, ErrInvalidFileStartPosition
+	}
+
+	end := len(f.records)
+	if int(count) < end-int(start) {
+		end = int(start) + int(count)
+	}
+	records := make([][]byte, 0, end-int(start))
+	for i, record := range f.records[start:end] {
+		maxLen -= objects.EncOctetString(record).MarshalLen()
+		if maxLen < 0 {
+			end = int(start) + i
+			break
+		}
+		records = append(records, append([]byte{}, record...))
+	}
+
+	return records, end == len(f.records), nil
+}
+
+// WriteRecords replaces the records of a record access File from record start on, or
+// appends them if start is -1, returning the record they were written from.
+func (o *Object) WriteRecords(start int32, records [][]byte) (int32, error) {
+	defer o.lock()()
+
+	f, err := o.fileAccess(false)
+	if err != nil {
+		return 0, err
+	}
+	if o.value(objects.PropertyIdReadOnly) == true {
+		return 0, ErrFileAccessDenied
+	}
+
+	switch {
+	case start == -1:
+		start = int32(len(f.records))
+	case start < 0 || int(start) > len(f.records):
+		return 0, ErrInvalidFileStartPosition
+	}
+
+	for i, record := range records {
+		record = append([]byte{}, record...)
+		if n := int(start) + i; n < len(f.records) {
+			f.records[n] = record
+		} else {
+			f.records = append(f.records, record)
+		}
+	}
+	f.written()
+
+	return start, nil
+}
+
+// fileAccess returns the data of a File object accessed with the given method. Accessing
+// a Configuration_File keeps the backup or restore being performed going.
+func (o *Object) fileAccess(stream bool) (*file, error) {
+	if o.file == nil {
+		return nil, ErrUnknownObject
+	}
+	if o.file.stream != stream {
+		return nil, ErrInvalidFileAccessMethod
+	}
+	if o.device != nil {
+		o.device.configurationAccessed(o.Identifier)
+	}
+	return o.file, nil
+}
+
+// written updates the properties tracking changes to the File.
+func (f *file) written() {
+	f.modified = f.o.now()
+	f.o.properties[objects.PropertyIdArchive].Value = false
+}
+
+func (f *file) size() interface{} {
+	size, err := dataSize(f.data)
+	if err != nil {
+		return uint32(0)
+	}
+	return uint32(size)
+}
+
+func (f *file) recordsSize() interface{} {
+	size := 0
+	for _, record := range f.records {
+		size += len(record)
+	}
+	return uint32(size)
+}
+
+func (f *file) truncate(value interface{}, priority uint8) error {
+	if f.o.properties[objects.PropertyIdReadOnly].Value == true {
+		return ErrWriteAccessDenied
+	}
+	if err := f.data.(truncater).Truncate(int64(value.(uint32))); err != nil {
+		return ErrOperationalProblem
+	}
+	f.written()
+	return nil
+}
+
+func (f *file) truncateRecords(value interface{}, priority uint8) error {
+	count := int(value.(uint32))
+	if count > len(f.records) {
+		return ErrValueOutOfRange
+	}
+	if f.o.properties[objects.PropertyIdReadOnly].Value == true {
+		return ErrWriteAccessDenied
+	}
+	f.records = f.records[:count]
+	f.written()
+	return nil
+}
+
+type truncater interface {
+	Truncate(size int64) error
+}
+
+// dataSize tells the size of data as NewFile describes.
+func dataSize(data io.ReaderAt) (int64, error) {
+	if s, ok := data.(interface{ Size() int64 }); ok {
+		return s.Size(), nil
+	}
+	fi, err := statData(data)
+	if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
+
+func statData(data io.ReaderAt) (os.FileInfo, error) {
+	s, ok := data.(interface{ Stat() (os.FileInfo, error) })
+	if !ok {
+		return nil, errors.New("file data can't tell its size")
+	}
+	return s.Stat()
+}
+
+// FileBuffer holds the data of a writable File object in memory. The zero value is an
+// empty buffer ready to use.
+type FileBuffer struct {
+	mu   sync.Mutex
+	data []byte
+}
+
+// NewFileBuffer creates a FileBuffer holding a copy of data.
+func NewFileBuffer(data []byte) *FileBuffer {
+	return &FileBuffer{data: append([]byte{}, data...)}
+}
+
+// Bytes returns a copy of the data held.
+func (b *FileBuffer) Bytes() []byte {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return append([]byte{}, b.data...)
+}
+
+func (b *FileBuffer) Size() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return int64(len(b.data))
+}
+
+func (b *FileBuffer) ReadAt(p []byte, off int64) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if off >= int64(len(b.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, b.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (b *FileBuffer) WriteAt(p []byte, off int64) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if end := off + int64(len(p)); end > int64(len(b.data)) {
+		b.data = append(b.data, make([]byte, end-int64(len(b.data)))...)
+	}
+	return copy(b.data[off:], p), nil
+}
+
+func (b *FileBuffer) Truncate(size int64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if size <= int64(len(b.data)) {
+		b.data = b.data[:size]
+	} else {
+		b.data = append(b.data, make([]byte, size-int64(len(b.data)))...)
+	}
+	return nil
+}
+
+// pathFile is the data of a file on disk, opened on every access.
+type pathFile struct {
+	path string
+}
+
+func (p *pathFile) Stat() (os.FileInfo, error) {
+	fi, err := os.Stat(p.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return emptyFileInfo{name: filepath.Base(p.path)}, nil
+	}
+	return fi, err
+}
+
+func (p *pathFile) ReadAt(b []byte, off int64) (int, error) {
+	f, err := os.Open(p.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, io.EOF
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	return f.ReadAt(b, off)
+}
+
+func (p *pathFile) WriteAt(b []byte, off int64) (int, error) {
+	f, err := os.OpenFile(p.path, os.O_WRONLY|os.O_CREATE, 0o644)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := f.WriteAt(b, off)
+	if cErr := f.Close(); err == nil {
+		err = cErr
+	}
+	return n, err
+}
+
+func (p *pathFile) Truncate(size int64) error {
+	err := os.Truncate(p.path, size)
+	if errors.Is(err, os.ErrNotExist) && size == 0 {
+		return nil
+	}
+	return err
+}
+
+// readOnlyData hides the io.WriterAt of the data of read-only files.
+type readOnlyData struct {
+	p *pathFile
+}
+
+func (r readOnlyData) ReadAt(b []byte, off int64) (int, error) { return r.p.ReadAt(b, off) }
+func (r readOnlyData) Stat() (os.FileInfo, error)              { return r.p.Stat() }
+
+// emptyFileInfo describes a file yet to be created.
+type emptyFileInfo struct {
+	name string
+}
+
+func (fi emptyFileInfo) Name() string       { return fi.name }
+func (fi emptyFileInfo) Size() int64        { return 0 }
+func (fi emptyFileInfo) Mode() os.FileMode  { return 0 }
+func (fi emptyFileInfo) ModTime() time.Time { return time.Time{} }
+func (fi emptyFileInfo) IsDir() bool        { return false }
+func (fi emptyFileInfo) Sys() interface{}   { return nil }
@@ -0,0 +1,122 @@
+package device
+
+import (
+	"reflect"
+
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+)
+
+// AddListElement adds elements to a writable List property, or to the List at arrayIndex of
+// an Array of them, skipping those already on it. Either every element is added or none
+// is. Errors are reported as *common.ChangeListError, naming the element at fault if any.
+func (d *Device) AddListElement(objectType uint16, instance uint32, propertyId uint32, arrayIndex uint32, elements []objects.APDUPayload) error {
+	return d.changeList(objectType, instance, propertyId, arrayIndex, elements, true)
+}
+
+// RemoveListElement removes elements from a writable List property, or from the List at
+// arrayIndex of an Array of them. Either every element is removed or none is, failing with
+// ErrListElementNotFound if one isn't on the list. Errors are reported as
+// *common.ChangeListError, naming the element at fault if any.
+func (d *Device) RemoveListElement(objectType uint16, instance uint32, propertyId uint32, arrayIndex uint32, elements []objects.APDUPayload) error {
+	return d.changeList(objectType, instance, propertyId, arrayIndex, elements, false)
+}
+
+func (d *Device) changeList(objectType uint16, instance uint32, propertyId uint32, arrayIndex uint32, rawPayloads []objects.APDUPayload, add bool) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	o := d.lookup(objectType, instance)
+	if o == nil {
+		return changeListError(ErrUnknownObject, 0)
+	}
+	return o.changeList(propertyId, arrayIndex, rawPayloads, add)
+}
+
+func (o *Object) changeList(propertyId uint32, arrayIndex uint32, rawPayloads []objects.APDUPayload, add bool) error {
+	p, ok := o.properties[propertyId]
+	if !ok {
+		return changeListError(ErrUnknownProperty, 0)
+	}
+	if err := o.writable(p); err != nil {
+		return changeListError(err, 0)
+	}
+
+	list, current, err := listOf(p, arrayIndex)
+	if err != nil {
+		return changeListError(err, 0)
+	}
+
+	changed := append([]interface{}{}, current...)
+	for i, offset := 0, 0; offset < len(rawPayloads); i++ {
+		element, n, err := list.Element.Decode(rawPayloads[offset:])
+		if err != nil {
+			return changeListError(err, uint32(i+1))
+		}
+		offset += n
+
+		at := indexOf(changed, element)
+		switch {
+		case add && at < 0:
+			changed = append(changed, element)
+		case !add && at < 0:
+			return changeListError(ErrListElementNotFound, uint32(i+1))
+		case !add:
+			changed = append(changed[:at], changed[at+1:]...)
+		}
+	}
+
+	value := interface{}(changed)
+	if arrayIndex != objects.ArrayAll {
+		elements := append([]interface{}{}, p.current().([]interface{})...)
+		elements[arrayIndex-1] = changed
+		value = elements
+	}
+
+	if err := o.store(p, value, 0); err != nil {
+		return changeListError(err, 0)
+	}
+	return nil
+}
+
+// listOf returns the datatype and elements of a List property or of the List at arrayIndex
+// of an Array property.
+func listOf(p *Property, arrayIndex uint32) (*List, []interface{}, error) {
+	if arrayIndex == objects.ArrayAll {
+		list, ok := p.Datatype.(*List)
+		if !ok {
+			return nil, nil, ErrPropertyIsNotAList
+		}
+		current, _ := p.current().([]interface{})
+		return list, current, nil
+	}
+
+	array, ok := p.Datatype.(*Array)
+	if !ok {
+		return nil, nil, ErrPropertyIsNotAnArray
+	}
+	list, ok := array.Element.(*List)
+	if !ok {
+		return nil, nil, ErrPropertyIsNotAList
+	}
+
+	elements, _ := p.current().([]interface{})
+	if arrayIndex == 0 || int(arrayIndex) > len(elements) {
+		return nil, nil, ErrInvalidArrayIndex
+	}
+	current, _ := elements[arrayIndex-1].([]interface{})
+	return list, current, nil
+}
+
+func indexOf(elements []interface{}, element interface{}) int {
+	for i, e := range elements {
+		if reflect.DeepEqual(e, element) {
+			return i
+		}
+	}
+	return -1
+}
+
+func changeListError(err error, element uint32) *common.ChangeListError {
+	return &common.ChangeListError{BACnetError: asBACnetError(err), FirstFailedElement: element}
+}
@@ -0,0 +1,65 @@
+package device
+
+import (
+	"github.com/ulbios/bacnet/objects"
+)
+
+// NewMultiStateInput creates a Multi-state Input object with a state per entry of
+// stateText. Its Present_Value is read-only unless it's Out_Of_Service.
+func NewMultiStateInput(instance uint32, name string, stateText []string) *Object {
+	return newMultiState(objects.ObjectTypeMultiStateInput, instance, name, stateText, AccessWritableOutOfService)
+}
+
+// NewMultiStateOutput creates a Multi-state Output object with a state per entry of stateText.
+func NewMultiStateOutput(instance uint32, name string, stateText []string) *Object {
+	o := newMultiState(objects.ObjectTypeMultiStateOutput, instance, name, stateText, AccessWritable)
+	o.makeCommandable(o.properties[objects.PropertyIdPresentValue])
+	return o
+}
+
+// NewMultiStateValue creates a Multi-state Value object with a state per entry of stateText.
+// Call MakeCommandable to command it.
+func NewMultiStateValue(instance uint32, name string, stateText []string) *Object {
+	return newMultiState(objects.ObjectTypeMultiStateValue, instance, name, stateText, AccessWritable)
+}
+
+func newMultiState(objectType uint16, instance uint32, name string, stateText []string, access uint8) *Object {
+	o := newObject(objectType, instance, name)
+
+	texts := make([]interface{}, 0, len(stateText))
+	for _, text := range stateText {
+		texts = append(texts, text)
+	}
+
+	o.add(&Property{
+		Identifier: objects.PropertyIdPresentValue,
+		Datatype:   Unsigned,
+		Required:   true,
+		Access:     access,
+		Value:      uint32(1),
+		Validate:   o.validateState,
+	})
+	o.addStatus()
+	o.add(&Property{
+		Identifier: objects.PropertyIdNumberOfStates,
+		Datatype:   Unsigned,
+		Required:   true,
+		Value:      uint32(len(stateText)),
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdStateText,
+		Datatype:   &Array{Element: CharacterString, Size: len(stateText)},
+		Access:     AccessWritable,
+		Value:      texts,
+	})
+
+	return o
+}
+
+func (o *Object) validateState(value interface{}) error {
+	states, _ := o.value(objects.PropertyIdNumberOfStates).(uint32)
+	if v, _ := value.(uint32); v < 1 || v > states {
+		return ErrValueOutOfRange
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package device
+
+import (
+	"time"
+
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/services"
+)
+
+// Destination is a BACnetDestination: a recipient on the Recipient_List of a Notification
+// Class along with when and what it's notified of.
+type Destination struct {
+	// ValidDays holds a bit per day of the week, Monday first, telling the days the recipient
+	// is notified on.
+	ValidDays objects.BitString
+	// FromTime and ToTime bound the times of the day the recipient is notified at.
+	FromTime  objects.Time
+	ToTime    objects.Time
+	Recipient services.Recipient
+	ProcessId uint32
+	Confirmed bool
+	// Transitions tells whether transitions to OFFNORMAL, FAULT and NORMAL are notified.
+	Transitions objects.BitString
+}
+
+// NewNotificationClass creates a Notification Class object whose Notification_Class is its
+// instance. Its transitions have the lowest priority, 255, and don't require acknowledgment.
+// Event notifications go to the recipients on its Recipient_List.
+func NewNotificationClass(instance uint32, name string) *Object {
+	o := newObject(objects.ObjectTypeNotificationClass, instance, name)
+
+	o.add(&Property{
+		Identifier: objects.PropertyIdDescription,
+		Datatype:   CharacterString,
+		Access:     AccessWritable,
+		Value:      "",
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdNotificationClass,
+		Datatype:   Unsigned,
+		Required:   true,
+		Value:      instance,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdPriority,
+		Datatype:   &Array{Element: Unsigned, Size: 3},
+		Required:   true,
+		Access:     AccessWritable,
+		Value:      []interface{}{uint32(255), uint32(255), uint32(255)},
+		Validate:   validatePriorities,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdAckRequired,
+		Datatype:   BitString,
+		Required:   true,
+		Access:     AccessWritable,
+		Value:      make(objects.BitString, 3),
+		Validate:   validateTransitions,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdRecipientList,
+		Datatype:   ListOf(DestinationType),
+		Required:   true,
+		Access:     AccessWritable,
+		Value:      []interface{}{},
+	})
+
+	return o
+}
+
+// notificationClass returns the Notification Class object with the given Notification_Class.
+func (d *Device) notificationClass(class uint32) *Object {
+	for _, o := range d.order {
+		if o.Identifier.ObjectType != objects.ObjectTypeNotificationClass {
+			continue
+		}
+		if c, _ := o.value(objects.PropertyIdNotificationClass).(uint32); c == class {
+			return o
+		}
+	}
+	return nil
+}
+
+// priorities returns the priorities of the transitions to OFFNORMAL, FAULT and NORMAL
+// notified through a Notification Class, which are the lowest without one.
+func priorities(nc *Object) [3]uint8 {
+	p := [3]uint8{255, 255, 255}
+	if nc == nil {
+		return p
+	}
+	values, _ := nc.value(objects.PropertyIdPriority).([]interface{})
+	for i := 0; i < len(values) && i < len(p); i++ {
+		priority, _ := values[i].(uint32)
+		p[i] = uint8(priority)
+	}
+	return p
+}
+
+// notifies tells whether the recipient is notified of a transition at the given local time.
+func (dest Destination) notifies(transition uint8, now time.Time) bool {
+	day := (int(now.Weekday()) + 6) % 7
+	if day >= len(dest.ValidDays) || !dest.ValidDays[day] {
+		return false
+	}
+	if int(transition) >= len(dest.Transitions) || !dest.Transitions[transition] {
+		return false
+	}
+
+	t := timeOfDay(objects.TimeOf(now))
+	return t >= timeOfDay(dest.FromTime) && t <= timeOfDay(dest.ToTime)
+}
+
+func validatePriorities(value interface{}) error {
+	priorities, _ := value.([]interface{})
+	for _, p := range priorities {
+		if p.(uint32) > 255 {
+			return ErrValueOutOfRange
+		}
+	}
+	return nil
+}
+
+// validateTransitions checks bit strings holding a bit per kind of transition.
+func validateTransitions(value interface{}) error {
+	if bits, _ := value.(objects.BitString); len(bits) != 3 {
+		return ErrValueOutOfRange
+	}
+	return nil
+}
+
+type destinationType struct{}
+
+// DestinationType is a BACnetDestination whose values are Destination.
+var DestinationType Datatype = destinationType{}
+
+func (destinationType) Encode(value interface{}) ([]objects.APDUPayload, error) {
+	dest, ok := value.(Destination)
+	if !ok {
+		return nil, ErrInvalidDataType
+	}
+
+	objs := []objects.APDUPayload{objects.EncBitString(dest.ValidDays), objects.EncTime(dest.FromTime), objects.EncTime(dest.ToTime)}
+	objs = append(objs, services.EncRecipient(dest.Recipient)...)
+	objs = append(objs, objects.EncUnsignedInteger(dest.ProcessId), objects.EncBoolean(dest.Confirmed), objects.EncBitString(dest.Transitions))
+
+	return objs, nil
+}
+
+func (destinationType) Decode(rawPayloads []objects.APDUPayload) (interface{}, int, error) {
+	dest := Destination{}
+
+	if len(rawPayloads) < 7 {
+		return nil, 0, ErrInvalidDataType
+	}
+
+	var err error
+	if dest.ValidDays, err = objects.DecBitString(rawPayloads[0]); err != nil || len(dest.ValidDays) != 7 {
+		return nil, 0, ErrInvalidDataType
+	}
+	if dest.FromTime, err = objects.DecTime(rawPayloads[1]); err != nil {
+		return nil, 0, ErrInvalidDataType
+	}
+	if dest.ToTime, err = objects.DecTime(rawPayloads[2]); err != nil {
+		return nil, 0, ErrInvalidDataType
+	}
+
+	recipient, next, err := services.DecRecipient(rawPayloads, 3)
+	if err != nil || next+3 > len(rawPayloads) {
+		return nil, 0, ErrInvalidDataType
+	}
+	dest.Recipient = recipient
+
+	if dest.ProcessId, err = objects.DecUnisgnedInteger(rawPayloads[next]); err != nil {
+		return nil, 0, ErrInvalidDataType
+	}
+	if dest.Confirmed, err = objects.DecBoolean(rawPayloads[next+1]); err != nil {
+		return nil, 0, ErrInvalidDataType
+	}
+	if dest.Transitions, err = objects.DecBitString(rawPayloads[next+2]); err != nil || len(dest.Transitions) != 3 {
+		return nil, 0, ErrInvalidDataType
+	}
+
+	return dest, next + 3, nil
+}
@@ -0,0 +1,422 @@
+package device
+
+import (
+	"time"
+
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/services"
+)
+
+// Write access rules for properties.
+const (
+	AccessReadOnly uint8 = iota
+	AccessWritable
+	// AccessWritableOutOfService only lets peers write whilst Out_Of_Service is TRUE.
+	AccessWritableOutOfService
+)
+
+// Property is a property of an Object together with its metadata.
+type Property struct {
+	Identifier uint32
+	Datatype   Datatype
+	Required   bool
+	Access     uint8
+	Value      interface{}
+
+	// Validate, if set, vets values before they are stored.
+	Validate func(value interface{}) error
+
+	// compute, if set, provides the value instead of Value.
+	compute func() interface{}
+	// write, if set, carries out writes instead of just storing the value.
+	write func(value interface{}, priority uint8) error
+	// log, if set, provides the records of logs for ReadRange to read by sequence number
+	// and by time. The value of the property is then a List of their values.
+	log func() []logRecord
+}
+
+// Object is a BACnet object living on a Device.
+type Object struct {
+	Identifier objects.ObjectIdentifier
+
+	device *Device
+	// creating is the Device CreateObject is writing the initial values of the Object for.
+	// It only lends its clock and instance, the Object being added once they're all written.
+	creating   *Device
+	properties map[uint32]*Property
+	order      []uint32
+	// file holds the data of File objects.
+	file *file
+	// trendLog holds the buffer of Trend Log objects.
+	trendLog *trendLog
+	// schedule holds the evaluation state of Schedule objects.
+	schedule *schedule
+	// event holds the intrinsic reporting state of objects reporting events.
+	event *event
+}
+
+func newObject(objectType uint16, instance uint32, name string) *Object {
+	o := &Object{
+		Identifier: objects.ObjectIdentifier{ObjectType: objectType, InstanceNumber: instance},
+		properties: map[uint32]*Property{},
+	}
+
+	o.add(&Property{
+		Identifier: objects.PropertyIdObjectIdentifier,
+		Datatype:   ObjectIdentifier,
+		Required:   true,
+		Value:      o.Identifier,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdObjectName,
+		Datatype:   CharacterString,
+		Required:   true,
+		Access:     AccessWritable,
+		Value:      name,
+		Validate:   o.validateName,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdObjectType,
+		Datatype:   Enumerated,
+		Required:   true,
+		Value:      objects.Enumerated(objectType),
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdPropertyList,
+		Datatype:   ArrayOf(Enumerated),
+		Required:   true,
+		compute:    o.propertyList,
+	})
+
+	return o
+}
+
+func (o *Object) add(p *Property) {
+	if _, ok := o.properties[p.Identifier]; !ok {
+		o.order = append(o.order, p.Identifier)
+	}
+	o.properties[p.Identifier] = p
+}
+
+// lock grabs the lock of the Device the Object lives on, if any.
+func (o *Object) lock() func() {
+	if o.device == nil {
+		return func() {}
+	}
+	o.device.mu.Lock()
+	return o.device.mu.Unlock
+}
+
+// AddProperty adds an optional property to the Object or replaces an existing one.
+func (o *Object) AddProperty(p *Property) error {
+	if _, err := p.Datatype.Encode(p.Value); err != nil && p.compute == nil {
+		return err
+	}
+
+	defer o.lock()()
+
+	o.add(p)
+
+	return nil
+}
+
+// Property returns the property with the given identifier or nil if it isn't present.
+func (o *Object) Property(propertyId uint32) *Property {
+	defer o.lock()()
+
+	return o.properties[propertyId]
+}
+
+// Name returns the Object_Name of the Object.
+func (o *Object) Name() string {
+	name, _ := o.Get(objects.PropertyIdObjectName).(string)
+	return name
+}
+
+// Get returns the value of a property or nil if it isn't present.
+func (o *Object) Get(propertyId uint32) interface{} {
+	defer o.lock()()
+
+	return o.value(propertyId)
+}
+
+// Set changes the value of a property on behalf of the local application, which is why
+// write access rules don't apply. The value must match the property's datatype though.
+func (o *Object) Set(propertyId uint32, value interface{}) error {
+	defer o.lock()()
+
+	p, ok := o.properties[propertyId]
+	if !ok || (p.compute != nil && p.write == nil) {
+		return ErrUnknownProperty
+	}
+
+	if _, err := p.Datatype.Encode(value); err != nil {
+		return err
+	}
+
+	return o.store(p, value, 0)
+}
+
+// host returns the Device the Object lives on or is being created on, if any.
+func (o *Object) host() *Device {
+	if o.device != nil {
+		return o.device
+	}
+	return o.creating
+}
+
+// now returns the time according to the clock of the Device the Object lives on.
+func (o *Object) now() time.Time {
+	d := o.host()
+	if d == nil {
+		return time.Now()
+	}
+	return d.clock()
+}
+
+// localNow returns the local time of the Device the Object lives on.
+func (o *Object) localNow() time.Time {
+	d := o.host()
+	if d == nil {
+		return time.Now()
+	}
+	return d.now()
+}
+
+// local tells whether a reference points to a property of an object on the Device the
+// Object lives on.
+func (o *Object) local(ref services.DeviceObjectPropertyReference) bool {
+	d := o.host()
+	return ref.DeviceId == objects.MaxInstance || (d != nil && ref.DeviceId == d.Instance())
+}
+
+func (o *Object) has(propertyId uint32) bool {
+	_, ok := o.properties[propertyId]
+	return ok
+}
+
+func (o *Object) value(propertyId uint32) interface{} {
+	p, ok := o.properties[propertyId]
+	if !ok {
+		return nil
+	}
+	return p.current()
+}
+
+func (p *Property) current() interface{} {
+	if p.compute != nil {
+		return p.compute()
+	}
+	return p.Value
+}
+
+func (o *Object) read(propertyId uint32, arrayIndex uint32) ([]objects.APDUPayload, error) {
+	p, ok := o.properties[propertyId]
+	if !ok {
+		return nil, ErrUnknownProperty
+	}
+
+	value := p.current()
+	if arrayIndex == objects.ArrayAll {
+		return p.Datatype.Encode(value)
+	}
+
+	array, ok := p.Datatype.(*Array)
+	if !ok {
+		return nil, ErrPropertyIsNotAnArray
+	}
+
+	elements, _ := value.([]interface{})
+	if arrayIndex == 0 {
+		return []objects.APDUPayload{objects.EncUnsignedInteger(uint32(len(elements)))}, nil
+	}
+	if int(arrayIndex) > len(elements) {
+		return nil, ErrInvalidArrayIndex
+	}
+
+	return array.Element.Encode(elements[arrayIndex-1])
+}
+
+func (o *Object) write(propertyId uint32, arrayIndex uint32, rawPayloads []objects.APDUPayload, priority uint8) error {
+	p, ok := o.properties[propertyId]
+	if !ok {
+		return ErrUnknownProperty
+	}
+
+	if err := o.writable(p); err != nil {
+		return err
+	}
+
+	if arrayIndex == objects.ArrayAll {
+		value, err := decodeAll(p.Datatype, rawPayloads)
+		if err != nil {
+			return err
+		}
+		return o.store(p, value, priority)
+	}
+
+	array, ok := p.Datatype.(*Array)
+	if !ok {
+		return ErrPropertyIsNotAnArray
+	}
+
+	current, _ := p.current().([]interface{})
+	if arrayIndex == 0 {
+		// Resizing arrays isn't supported.
+		return ErrWriteAccessDenied
+	}
+	if int(arrayIndex) > len(current) {
+		return ErrInvalidArrayIndex
+	}
+
+	element, err := decodeAll(array.Element, rawPayloads)
+	if err != nil {
+		return err
+	}
+
+	elements := append([]interface{}{}, current...)
+	elements[arrayIndex-1] = element
+
+	return o.store(p, elements, priority)
+}
+
+// writable tells whether peers may change a property.
+func (o *Object) writable(p *Property) error {
+	switch p.Access {
+	case AccessReadOnly:
+		return ErrWriteAccessDenied
+	case AccessWritableOutOfService:
+		if oos, _ := o.value(objects.PropertyIdOutOfService).(bool); !oos {
+			return ErrWriteAccessDenied
+		}
+	}
+	return nil
+}
+
+// store saves a value which has already been checked against the property's datatype.
+func (o *Object) store(p *Property, value interface{}, priority uint8) error {
+	// NULL relinquishes commands and is never checked against the property's range.
+	if p.Validate != nil && value != nil {
+		if err := p.Validate(value); err != nil {
+			return err
+		}
+	}
+
+	if p.write != nil {
+		if err := p.write(value, priority); err != nil {
+			return err
+		}
+	} else {
+		p.Value = value
+	}
+
+	if o.device != nil {
+		if p.Identifier == objects.PropertyIdObjectName {
+			o.device.revision++
+		}
+		o.device.changed(o)
+	}
+
+	return nil
+}
+
+// expand turns references to ALL, REQUIRED and OPTIONAL into the properties they stand for.
+func (o *Object) expand(ref services.PropertyReference) []services.PropertyReference {
+	var match func(p *Property) bool
+	switch ref.PropertyId {
+	case objects.PropertyIdAll:
+		match = func(p *Property) bool { return true }
+	case objects.PropertyIdRequired:
+		match = func(p *Property) bool { return p.Required }
+	case objects.PropertyIdOptional:
+		match = func(p *Property) bool { return !p.Required }
+	default:
+		return []services.PropertyReference{ref}
+	}
+
+	refs := []services.PropertyReference{}
+	for _, propertyId := range o.order {
+		if match(o.properties[propertyId]) {
+			refs = append(refs, services.PropertyReference{PropertyId: propertyId, ArrayIndex: objects.ArrayAll})
+		}
+	}
+	return refs
+}
+
+func (o *Object) propertyList() interface{} {
+	list := []interface{}{}
+	for _, propertyId := range o.order {
+		switch propertyId {
+		case objects.PropertyIdObjectIdentifier, objects.PropertyIdObjectName,
+			objects.PropertyIdObjectType, objects.PropertyIdPropertyList:
+			continue
+		}
+		list = append(list, objects.Enumerated(propertyId))
+	}
+	return list
+}
+
+func (o *Object) validateName(value interface{}) error {
+	name, _ := value.(string)
+	if name == "" {
+		return ErrValueOutOfRange
+	}
+
+	if o.device == nil {
+		return nil
+	}
+
+	for _, other := range o.device.order {
+		if other != o && other.value(objects.PropertyIdObjectName) == name {
+			return ErrDuplicateName
+		}
+	}
+
+	return nil
+}
+
+// addStatus adds the properties every input, output and value object has.
+func (o *Object) addStatus() {
+	o.add(&Property{
+		Identifier: objects.PropertyIdStatusFlags,
+		Datatype:   BitString,
+		Required:   true,
+		compute:    o.statusFlags,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdEventState,
+		Datatype:   Enumerated,
+		Required:   true,
+		Value:      objects.Enumerated(objects.EventStateNormal),
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdOutOfService,
+		Datatype:   Boolean,
+		Required:   true,
+		Access:     AccessWritable,
+		Value:      false,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdDescription,
+		Datatype:   CharacterString,
+		Access:     AccessWritable,
+		Value:      "",
+	})
+}
+
+func (o *Object) statusFlags() interface{} {
+	flags := make(objects.BitString, 4)
+
+	if state, ok := o.value(objects.PropertyIdEventState).(objects.Enumerated); ok {
+		flags[objects.StatusFlagInAlarm] = uint8(state) != objects.EventStateNormal
+		flags[objects.StatusFlagFault] = uint8(state) == objects.EventStateFault
+	}
+	if reliability, ok := o.value(objects.PropertyIdReliability).(objects.Enumerated); ok {
+		flags[objects.StatusFlagFault] = flags[objects.StatusFlagFault] || uint8(reliability) != objects.ReliabilityNoFaultDetected
+	}
+	if oos, ok := o.value(objects.PropertyIdOutOfService).(bool); ok {
+		flags[objects.StatusFlagOutOfService] = oos
+	}
+
+	return flags
+}
@@ -0,0 +1,233 @@
+package device
+
+import (
+	"time"
+
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/services"
+)
+
+// logRecord is an item of a list whose items carry a sequence number and a timestamp, such
+// as the Log_Buffer of Trend Logs.
+type logRecord struct {
+	sequence  uint32
+	timestamp time.Time
+	value     interface{}
+}
+
+// ReadRange reads a range of the items of a List or Array property, or of the List at
+// arrayIndex of an Array of them. Reading by sequence number or by time is only possible on
+// logs, whose items carry both. The items read are cut down to those fitting in maxLen
+// octets, dropping the ones furthest from the reference, in which case MoreItems is set.
+func (d *Device) ReadRange(objectType uint16, instance uint32, propertyId uint32, arrayIndex uint32, rng services.Range, maxLen int) (services.RangeResult, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	o := d.lookup(objectType, instance)
+	if o == nil {
+		return services.RangeResult{}, ErrUnknownObject
+	}
+	p, ok := o.properties[propertyId]
+	if !ok {
+		return services.RangeResult{}, ErrUnknownProperty
+	}
+
+	if p.log != nil {
+		if arrayIndex != objects.ArrayAll {
+			return services.RangeResult{}, ErrPropertyIsNotAnArray
+		}
+		return readLog(p, p.log(), rng, d.location, maxLen)
+	}
+
+	element, items, err := itemsOf(p, arrayIndex)
+	if err != nil {
+		return services.RangeResult{}, err
+	}
+	if rng.Type == services.RangeBySequenceNumber || rng.Type == services.RangeByTime {
+		return services.RangeResult{}, ErrOptionalFunctionalityNotSupported
+	}
+
+	first, last := selectPosition(len(items), rng)
+	return encodeRange(items[first:last], first == 0, last == len(items), rng.Count < 0, maxLen,
+		func(item interface{}) ([]objects.APDUPayload, error) { return element.Encode(item) })
+}
+
+// itemsOf returns the element datatype and the items of a List or Array property, or of the
+// List at arrayIndex of an Array of them.
+func itemsOf(p *Property, arrayIndex uint32) (Datatype, []interface{}, error) {
+	if arrayIndex != objects.ArrayAll {
+		list, items, err := listOf(p, arrayIndex)
+		if err != nil {
+			return nil, nil, err
+		}
+		return list.Element, items, nil
+	}
+
+	items, _ := p.current().([]interface{})
+	switch dt := p.Datatype.(type) {
+	case *List:
+		return dt.Element, items, nil
+	case *Array:
+		return dt.Element, items, nil
+	}
+	return nil, nil, ErrPropertyIsNotAList
+}
+
+func readLog(p *Property, records []logRecord, rng services.Range, loc *time.Location, maxLen int) (services.RangeResult, error) {
+	var element Datatype = Any
+	if list, ok := p.Datatype.(*List); ok {
+		element = list.Element
+	}
+
+	var first, last int
+	switch rng.Type {
+	case services.RangeBySequenceNumber:
+		first, last = selectSequence(records, rng)
+	case services.RangeByTime:
+		first, last = selectTime(records, rng.Time.Date.In(rng.Time.Time, loc), rng.Count)
+	default:
+		first, last = selectPosition(len(records), rng)
+	}
+
+	items := make([]interface{}, 0, last-first)
+	for _, r := range records[first:last] {
+		items = append(items, r)
+	}
+	result, err := encodeRange(items, first == 0, last == len(records), rng.Count < 0, maxLen,
+		func(item interface{}) ([]objects.APDUPayload, error) { return element.Encode(item.(logRecord).value) })
+	if err != nil {
+		return result, err
+	}
+
+	// Only reads by sequence number and by time tell the sequence number of the first item.
+	if result.ItemCount > 0 && rng.Type != services.RangeByPosition && rng.Type != services.RangeAll {
+		skipped := len(items) - int(result.ItemCount)
+		if rng.Count >= 0 {
+			skipped = 0
+		}
+		result.FirstSequenceNumber = items[skipped].(logRecord).sequence
+	}
+	return result, nil
+}
+
+// selectPosition returns the bounds of the items of a list of n items a range by position
+// stands for.
+func selectPosition(n int, rng services.Range) (int, int) {
+	if rng.Type == services.RangeAll {
+		return 0, n
+	}
+	ref := int(rng.Reference)
+	if ref < 1 || ref > n || rng.Count == 0 {
+		return 0, 0
+	}
+
+	first, last := ref-1, ref-1+int(rng.Count)
+	if rng.Count < 0 {
+		first, last = ref+int(rng.Count), ref
+	}
+	return clamp(first, n), clamp(last, n)
+}
+
+// selectSequence returns the bounds of the records whose sequence numbers a range by
+// sequence number stands for.
+func selectSequence(records []logRecord, rng services.Range) (int, int) {
+	lo, hi := int64(rng.Reference), int64(rng.Reference)+int64(rng.Count)-1
+	if rng.Count < 0 {
+		lo, hi = int64(rng.Reference)+int64(rng.Count)+1, int64(rng.Reference)
+	}
+
+	first, last := len(records), len(records)
+	for i, r := range records {
+		seq := int64(r.sequence)
+		if seq >= lo && first == len(records) {
+			first = i
+		}
+		if seq > hi {
+			last = i
+			break
+		}
+	}
+	if first > last || rng.Count == 0 {
+		return 0, 0
+	}
+	return first, last
+}
+
+// selectTime returns the bounds of the count records following the reference time, or
+// preceding it for negative counts.
+func selectTime(records []logRecord, ref time.Time, count int32) (int, int) {
+	if count > 0 {
+		for i, r := range records {
+			if r.timestamp.After(ref) {
+				return i, clamp(i+int(count), len(records))
+			}
+		}
+		return 0, 0
+	}
+	for i := len(records) - 1; i >= 0; i-- {
+		if records[i].timestamp.Before(ref) {
+			return clamp(i+1+int(count), len(records)), i + 1
+		}
+	}
+	return 0, 0
+}
+
+// encodeRange encodes the items read as long as they fit in maxLen octets. Reads backwards
+// keep the items nearest to the end rather than to the beginning.
+func encodeRange(items []interface{}, firstItem, lastItem, backwards bool, maxLen int, encode func(interface{}) ([]objects.APDUPayload, error)) (services.RangeResult, error) {
+	encoded := make([][]objects.APDUPayload, len(items))
+	for i, item := range items {
+		objs, err := encode(item)
+		if err != nil {
+			return services.RangeResult{}, err
+		}
+		encoded[i] = objs
+	}
+
+	first, last := 0, 0
+	length := 0
+	for n := 0; n < len(encoded); n++ {
+		i := n
+		if backwards {
+			i = len(encoded) - 1 - n
+		}
+		itemLen := 0
+		for _, obj := range encoded[i] {
+			itemLen += obj.MarshalLen()
+		}
+		if length+itemLen > maxLen {
+			break
+		}
+		length += itemLen
+		if backwards {
+			first, last = i, len(encoded)
+		} else {
+			first, last = 0, i+1
+		}
+	}
+
+	result := services.RangeResult{
+		ItemCount: uint32(last - first),
+		ItemData:  []objects.APDUPayload{},
+		MoreItems: last-first < len(encoded),
+	}
+	if result.ItemCount == 0 {
+		return result, nil
+	}
+	result.FirstItem = firstItem && first == 0
+	result.LastItem = lastItem && last == len(encoded)
+	for _, objs := range encoded[first:last] {
+		result.ItemData = append(result.ItemData, objs...)
+	}
+	return result, nil
+}
+
+func clamp(i, n int) int {
+	switch {
+	case i < 0:
+		return 0
+	case i > n:
+		return n
+	}
+	return i
+}
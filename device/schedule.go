@@ -0,0 +1,410 @@
+package device
+
+import (
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/services"
+)
+
+// TimeValue is a BACnetTimeValue: the value a schedule takes from Time on. NULL values
+// relinquish the schedule to the events of lower priority or to Schedule_Default.
+type TimeValue struct {
+	Time  objects.Time
+	Value interface{}
+}
+
+// SpecialEvent is a BACnetSpecialEvent of an Exception_Schedule. It applies on the days
+// its Period matches, which is an objects.Date, DateRange or WeekNDay, or the
+// objects.ObjectIdentifier of a Calendar on the same Device. Priorities run from 1, the
+// highest, to 16.
+type SpecialEvent struct {
+	Period     interface{}
+	TimeValues []TimeValue
+	Priority   uint32
+}
+
+// schedule holds the evaluation state of a Schedule object.
+type schedule struct {
+	o *Object
+
+	// written tells the Present_Value was written to the properties referenced since the
+	// Schedule was last out of service or they last changed.
+	written bool
+}
+
+// NewSchedule creates a Schedule object taking scheduleDefault as its Schedule_Default. Its
+// Present_Value follows its Exception_Schedule and Weekly_Schedule as evaluated on the
+// local time of its Device, so tests can drive it through Device.SetClock, and it's written
+// to the properties on its List_Of_Object_Property_References whenever it changes. Only
+// properties of objects on the same Device can be referenced.
+func NewSchedule(instance uint32, name string, scheduleDefault interface{}) *Object {
+	o := newObject(objects.ObjectTypeSchedule, instance, name)
+	s := &schedule{o: o}
+	o.schedule = s
+
+	week := make([]interface{}, 7)
+	for i := range week {
+		week[i] = []TimeValue{}
+	}
+	always := objects.Date{Year: objects.Unspecified, Month: objects.Unspecified, Day: objects.Unspecified, Weekday: objects.Unspecified}
+
+	o.add(&Property{
+		Identifier: objects.PropertyIdPresentValue,
+		Datatype:   Any,
+		Required:   true,
+		Access:     AccessWritableOutOfService,
+		Value:      scheduleDefault,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdEffectivePeriod,
+		Datatype:   DateRangeType,
+		Required:   true,
+		Access:     AccessWritable,
+		Value:      DateRange{Start: always, End: always},
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdWeeklySchedule,
+		Datatype:   &Array{Element: DailyScheduleType, Size: 7},
+		Access:     AccessWritable,
+		Value:      week,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdExceptionSchedule,
+		Datatype:   ArrayOf(SpecialEventType),
+		Access:     AccessWritable,
+		Value:      []interface{}{},
+		Validate:   validateSpecialEvents,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdScheduleDefault,
+		Datatype:   Any,
+		Required:   true,
+		Access:     AccessWritable,
+		Value:      scheduleDefault,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdListOfObjectPropertyRefs,
+		Datatype:   ListOf(DeviceObjectPropertyReferenceType),
+		Required:   true,
+		Access:     AccessWritable,
+		Value:      []interface{}{},
+		Validate:   o.validateReferences,
+		write:      s.rewrite(objects.PropertyIdListOfObjectPropertyRefs),
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdPriorityForWriting,
+		Datatype:   Unsigned,
+		Required:   true,
+		Access:     AccessWritable,
+		Value:      uint32(MinPriority),
+		Validate:   validatePriority,
+		write:      s.rewrite(objects.PropertyIdPriorityForWriting),
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdReliability,
+		Datatype:   Enumerated,
+		Required:   true,
+		Value:      objects.Enumerated(objects.ReliabilityNoFaultDetected),
+	})
+	o.addStatus()
+
+	return o
+}
+
+// tickSchedules lets the Schedules of the Device move on to the values due.
+func (d *Device) tickSchedules() {
+	for _, o := range d.order {
+		if o.schedule != nil {
+			o.schedule.update()
+		}
+	}
+}
+
+// update evaluates the Schedule and writes its Present_Value to the properties referenced
+// if it changed. Schedules out of service are left alone.
+func (s *schedule) update() {
+	d := s.o.device
+	if d == nil {
+		return
+	}
+	if oos, _ := s.o.value(objects.PropertyIdOutOfService).(bool); oos {
+		s.written = false
+		return
+	}
+
+	pv := s.o.properties[objects.PropertyIdPresentValue]
+	value := s.evaluate(d.now())
+	if s.written && reflect.DeepEqual(value, pv.Value) {
+		return
+	}
+	pv.Value = value
+	s.written = true
+	// COV subscribers and Trend Logs catch the transition right away. Evaluating the Schedule
+	// again on the way finds it up to date.
+	d.changed(s.o)
+
+	priority, _ := s.o.value(objects.PropertyIdPriorityForWriting).(uint32)
+	refs, _ := s.o.value(objects.PropertyIdListOfObjectPropertyRefs).([]interface{})
+	for _, r := range refs {
+		ref := r.(services.DeviceObjectPropertyReference)
+		target := d.lookup(ref.Object.ObjectType, ref.Object.InstanceNumber)
+		obj, err := objects.EncValue(value)
+		if target == nil || err != nil {
+			continue
+		}
+		// Failed writes aren't retried until the value changes again.
+		_ = target.write(ref.PropertyId, ref.ArrayIndex, []objects.APDUPayload{obj}, uint8(priority))
+	}
+}
+
+// rewrite stores the value of a property telling where or how the Present_Value is written,
+// which has it written again.
+func (s *schedule) rewrite(propertyId uint32) func(value interface{}, priority uint8) error {
+	return func(value interface{}, priority uint8) error {
+		s.o.properties[propertyId].Value = value
+		s.written = false
+		return nil
+	}
+}
+
+// evaluate returns the value the Schedule takes at now: that of the highest priority special
+// event in effect, or else that of the Weekly_Schedule, or else the Schedule_Default. The
+// Schedule_Default applies outside of the Effective_Period as well.
+func (s *schedule) evaluate(now time.Time) interface{} {
+	o := s.o
+	fallback := o.value(objects.PropertyIdScheduleDefault)
+	if period, ok := o.value(objects.PropertyIdEffectivePeriod).(DateRange); ok && !period.matches(now) {
+		return fallback
+	}
+
+	var value interface{}
+	best := uint32(MinPriority) + 1
+	events, _ := o.value(objects.PropertyIdExceptionSchedule).([]interface{})
+	for _, e := range events {
+		event := e.(SpecialEvent)
+		if event.Priority >= best || !s.covers(event.Period, now) {
+			continue
+		}
+		if v, ok := valueAt(event.TimeValues, now); ok {
+			value, best = v, event.Priority
+		}
+	}
+	if best <= uint32(MinPriority) {
+		return value
+	}
+
+	week, _ := o.value(objects.PropertyIdWeeklySchedule).([]interface{})
+	if day := objects.DateOf(now).Weekday; len(week) == 7 {
+		if v, ok := valueAt(week[day-1].([]TimeValue), now); ok {
+			return v
+		}
+	}
+	return fallback
+}
+
+// covers tells whether the period of a special event matches the day of now.
+func (s *schedule) covers(period interface{}, now time.Time) bool {
+	id, ok := period.(objects.ObjectIdentifier)
+	if !ok {
+		return matchEntry(period, now)
+	}
+
+	calendar := s.o.device.lookup(id.ObjectType, id.InstanceNumber)
+	return calendar != nil && id.ObjectType == objects.ObjectTypeCalendar && calendar.calendarMatches(now)
+}
+
+// valueAt returns the value of the latest time value not after the time of day of now. It
+// tells there's none if it's NULL or no time value is due yet.
+func valueAt(timeValues []TimeValue, now time.Time) (interface{}, bool) {
+	sorted := append([]TimeValue{}, timeValues...)
+	sort.SliceStable(sorted, func(i, j int) bool { return timeOfDay(sorted[i].Time) < timeOfDay(sorted[j].Time) })
+
+	due := timeOfDay(objects.TimeOf(now))
+	var value interface{}
+	found := false
+	for _, tv := range sorted {
+		if timeOfDay(tv.Time) > due {
+			break
+		}
+		value, found = tv.Value, true
+	}
+	return value, found && value != nil
+}
+
+// timeOfDay returns the hundredths of seconds since midnight of a Time, taking unspecified
+// fields as zero.
+func timeOfDay(t objects.Time) int {
+	field := func(v uint8) int {
+		if v == objects.Unspecified {
+			return 0
+		}
+		return int(v)
+	}
+	return ((field(t.Hour)*60+field(t.Minute))*60+field(t.Second))*100 + field(t.Hundredths)
+}
+
+func validateSpecialEvents(value interface{}) error {
+	events, _ := value.([]interface{})
+	for _, e := range events {
+		if event := e.(SpecialEvent); event.Priority < uint32(MaxPriority) || event.Priority > uint32(MinPriority) {
+			return ErrValueOutOfRange
+		}
+	}
+	return nil
+}
+
+func validatePriority(value interface{}) error {
+	if priority := value.(uint32); priority < uint32(MaxPriority) || priority > uint32(MinPriority) {
+		return ErrValueOutOfRange
+	}
+	return nil
+}
+
+// validateReferences only lets Schedules write to properties of objects on their own Device.
+func (o *Object) validateReferences(value interface{}) error {
+	refs, _ := value.([]interface{})
+	for _, r := range refs {
+		if !o.local(r.(services.DeviceObjectPropertyReference)) {
+			return ErrOptionalFunctionalityNotSupported
+		}
+	}
+	return nil
+}
+
+type dailyScheduleType struct{}
+
+// DailyScheduleType is a BACnetDailySchedule whose values are []TimeValue.
+var DailyScheduleType Datatype = dailyScheduleType{}
+
+func (dailyScheduleType) Encode(value interface{}) ([]objects.APDUPayload, error) {
+	timeValues, ok := value.([]TimeValue)
+	if !ok {
+		return nil, ErrInvalidDataType
+	}
+	objs, err := encodeTimeValues(timeValues)
+	if err != nil {
+		return nil, err
+	}
+	return objects.EncEnclosed(0, objs...), nil
+}
+
+func (dailyScheduleType) Decode(rawPayloads []objects.APDUPayload) (interface{}, int, error) {
+	enclosed, next, err := objects.DecEnclosed(rawPayloads, 0, 0)
+	if err != nil {
+		return nil, 0, ErrInvalidDataType
+	}
+	timeValues, err := decodeTimeValues(enclosed)
+	if err != nil {
+		return nil, 0, err
+	}
+	return timeValues, next, nil
+}
+
+func encodeTimeValues(timeValues []TimeValue) ([]objects.APDUPayload, error) {
+	objs := []objects.APDUPayload{}
+	for _, tv := range timeValues {
+		obj, err := objects.EncValue(tv.Value)
+		if err != nil {
+			return nil, ErrInvalidDataType
+		}
+		objs = append(objs, objects.EncTime(tv.Time), obj)
+	}
+	return objs, nil
+}
+
+func decodeTimeValues(rawPayloads []objects.APDUPayload) ([]TimeValue, error) {
+	if len(rawPayloads)%2 != 0 {
+		return nil, ErrInvalidDataType
+	}
+
+	timeValues := []TimeValue{}
+	for i := 0; i < len(rawPayloads); i += 2 {
+		t, _, err := Time.Decode(rawPayloads[i : i+1])
+		if err != nil {
+			return nil, err
+		}
+		value, _, err := Any.Decode(rawPayloads[i+1 : i+2])
+		if err != nil {
+			return nil, err
+		}
+		timeValues = append(timeValues, TimeValue{Time: t.(objects.Time), Value: value})
+	}
+	return timeValues, nil
+}
+
+type specialEventType struct{}
+
+// SpecialEventType is a BACnetSpecialEvent whose values are SpecialEvent.
+var SpecialEventType Datatype = specialEventType{}
+
+func (specialEventType) Encode(value interface{}) ([]objects.APDUPayload, error) {
+	event, ok := value.(SpecialEvent)
+	if !ok {
+		return nil, ErrInvalidDataType
+	}
+
+	var objs []objects.APDUPayload
+	if id, ok := event.Period.(objects.ObjectIdentifier); ok {
+		objs = []objects.APDUPayload{objects.EncObjectIdentifier(true, 1, id.ObjectType, id.InstanceNumber)}
+	} else {
+		entry, err := CalendarEntryType.Encode(event.Period)
+		if err != nil {
+			return nil, err
+		}
+		objs = objects.EncEnclosed(0, entry...)
+	}
+
+	timeValues, err := encodeTimeValues(event.TimeValues)
+	if err != nil {
+		return nil, err
+	}
+	objs = append(objs, objects.EncEnclosed(2, timeValues...)...)
+	objs = append(objs, objects.EncContext(3, objects.EncUnsignedInteger(event.Priority)))
+
+	return objs, nil
+}
+
+func (specialEventType) Decode(rawPayloads []objects.APDUPayload) (interface{}, int, error) {
+	if len(rawPayloads) == 0 {
+		return nil, 0, ErrInvalidDataType
+	}
+
+	event := SpecialEvent{}
+	next := 0
+	if objects.IsContextTag(rawPayloads[0], 1) {
+		id, err := objects.DecObjectIdentifier(rawPayloads[0])
+		if err != nil {
+			return nil, 0, ErrInvalidDataType
+		}
+		event.Period, next = id, 1
+	} else {
+		enclosed, n, err := objects.DecEnclosed(rawPayloads, 0, 0)
+		if err != nil {
+			return nil, 0, ErrInvalidDataType
+		}
+		if event.Period, err = decodeAll(CalendarEntryType, enclosed); err != nil {
+			return nil, 0, err
+		}
+		next = n
+	}
+
+	enclosed, next, err := objects.DecEnclosed(rawPayloads, next, 2)
+	if err != nil {
+		return nil, 0, ErrInvalidDataType
+	}
+	if event.TimeValues, err = decodeTimeValues(enclosed); err != nil {
+		return nil, 0, err
+	}
+
+	if next >= len(rawPayloads) || !objects.IsContextTag(rawPayloads[next], 3) {
+		return nil, 0, ErrInvalidDataType
+	}
+	if event.Priority, err = objects.DecUnisgnedInteger(rawPayloads[next]); err != nil {
+		return nil, 0, ErrInvalidDataType
+	}
+
+	return event, next + 1, nil
+}
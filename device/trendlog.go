@@ -0,0 +1,503 @@
+package device
+
+import (
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/services"
+)
+
+// BufferReady tells a Trend Log gathered Notification_Threshold records since it last did.
+// The records logged in between are those with sequence numbers past PreviousNotification
+// up to CurrentNotification.
+type BufferReady struct {
+	Log                  objects.ObjectIdentifier
+	PreviousNotification uint32
+	CurrentNotification  uint32
+}
+
+// unspecifiedDateTime is a DateTime whose every field is unspecified.
+var unspecifiedDateTime = objects.DateTime{
+	Date: objects.Date{Year: objects.Unspecified, Month: objects.Unspecified, Day: objects.Unspecified, Weekday: objects.Unspecified},
+	Time: objects.Time{Hour: objects.Unspecified, Minute: objects.Unspecified, Second: objects.Unspecified, Hundredths: objects.Unspecified},
+}
+
+// trendLog holds the Log_Buffer of a Trend Log along with its logging state.
+type trendLog struct {
+	o *Object
+
+	// records is the Log_Buffer, oldest first, and total the Total_Record_Count.
+	records []logRecord
+	total   uint32
+	// logging tells whether the log was enabled and within its time window when last checked.
+	logging bool
+	// next is when the next polled record is due.
+	next time.Time
+	// last is the last record COV logs took.
+	last *services.LogRecord
+
+	lastNotify        uint32
+	sinceNotification uint32
+}
+
+// NewTrendLog creates an enabled Trend Log recording the referenced property of a local object
+// every interval, or on every change of value if interval is zero, on a buffer holding up to
+// bufferSize records. Peers can purge it by writing 0 to its Record_Count and resize it by
+// writing its Buffer_Size whilst it's disabled.
+func NewTrendLog(instance uint32, name string, ref services.DeviceObjectPropertyReference, interval time.Duration, bufferSize uint32) *Object {
+	o := newObject(objects.ObjectTypeTrendLog, instance, name)
+	l := &trendLog{o: o}
+	o.trendLog = l
+
+	loggingType := objects.LoggingTypePolled
+	if interval == 0 {
+		loggingType = objects.LoggingTypeCOV
+	}
+
+	o.add(&Property{
+		Identifier: objects.PropertyIdDescription,
+		Datatype:   CharacterString,
+		Access:     AccessWritable,
+		Value:      "",
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdEnable,
+		Datatype:   Boolean,
+		Required:   true,
+		Access:     AccessWritable,
+		Value:      true,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdStartTime,
+		Datatype:   DateTime,
+		Access:     AccessWritable,
+		Value:      unspecifiedDateTime,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdStopTime,
+		Datatype:   DateTime,
+		Access:     AccessWritable,
+		Value:      unspecifiedDateTime,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdLogDeviceObjectProperty,
+		Datatype:   DeviceObjectPropertyReferenceType,
+		Access:     AccessWritable,
+		Value:      ref,
+		Validate:   l.validateReference,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdLogInterval,
+		Datatype:   Unsigned,
+		Access:     AccessWritable,
+		Value:      uint32(interval / (10 * time.Millisecond)),
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdClientCOVIncrement,
+		Datatype:   Choice(Real, Null),
+		Access:     AccessWritable,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdStopWhenFull,
+		Datatype:   Boolean,
+		Required:   true,
+		Access:     AccessWritable,
+		Value:      false,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdBufferSize,
+		Datatype:   Unsigned,
+		Required:   true,
+		Access:     AccessWritable,
+		Value:      bufferSize,
+		write:      l.resize,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdLogBuffer,
+		Datatype:   ListOf(LogRecordType),
+		Required:   true,
+		compute:    l.logBuffer,
+		log:        func() []logRecord { return l.records },
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdRecordCount,
+		Datatype:   Unsigned,
+		Required:   true,
+		Access:     AccessWritable,
+		compute:    func() interface{} { return uint32(len(l.records)) },
+		write:      l.purge,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdTotalRecordCount,
+		Datatype:   Unsigned,
+		Required:   true,
+		compute:    func() interface{} { return l.total },
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdLoggingType,
+		Datatype:   Enumerated,
+		Required:   true,
+		Access:     AccessWritable,
+		Value:      objects.Enumerated(loggingType),
+		Validate:   validateLoggingType,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdTrigger,
+		Datatype:   Boolean,
+		Access:     AccessWritable,
+		Value:      false,
+		write:      l.trigger,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdStatusFlags,
+		Datatype:   BitString,
+		Required:   true,
+		compute:    o.statusFlags,
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdEventState,
+		Datatype:   Enumerated,
+		Required:   true,
+		Value:      objects.Enumerated(objects.EventStateNormal),
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdNotificationThreshold,
+		Datatype:   Unsigned,
+		Access:     AccessWritable,
+		Value:      uint32(0),
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdRecordsSinceNotification,
+		Datatype:   Unsigned,
+		compute:    func() interface{} { return l.sinceNotification },
+	})
+	o.add(&Property{
+		Identifier: objects.PropertyIdLastNotifyRecord,
+		Datatype:   Unsigned,
+		compute:    func() interface{} { return l.lastNotify },
+	})
+
+	return o
+}
+
+// OnBufferReady sets the function receiving the buffer ready notifications of the Trend Logs,
+// which are due whenever one gathers Notification_Threshold records. Thresholds of zero
+// disable them. It's called with the Device locked, so it must not block nor call back into
+// the Device. Neither the Device nor the Server deliver them on their own: sending them, as
+// BUFFER_READY event notifications with services.BufferReadyParameters for instance, is up
+// to the application.
+func (d *Device) OnBufferReady(notify func(BufferReady)) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.bufferReady = notify
+}
+
+// tickLogs lets the Trend Logs of the Device take their polled records.
+func (d *Device) tickLogs() {
+	for _, o := range d.order {
+		if o.trendLog != nil {
+			o.trendLog.update()
+		}
+	}
+}
+
+// logChanged lets the Trend Logs of the Device catch up with changes to o, be it one of them
+// or an object they log.
+func (d *Device) logChanged(o *Object) {
+	for _, l := range d.order {
+		if l.trendLog == nil {
+			continue
+		}
+		ref, _ := l.value(objects.PropertyIdLogDeviceObjectProperty).(services.DeviceObjectPropertyReference)
+		if l == o || ref.Object == o.Identifier {
+			l.trendLog.update()
+		}
+	}
+}
+
+// update takes the records due: log status changes, polled records once their interval
+// elapses and COV records once the value logged moves.
+func (l *trendLog) update() {
+	d := l.o.device
+	if d == nil {
+		return
+	}
+
+	logging := l.active(d.now())
+	if logging != l.logging {
+		l.logging = logging
+		l.next, l.last = time.Time{}, nil
+		l.logStatus(false)
+	}
+	if !logging {
+		return
+	}
+
+	loggingType, _ := l.o.value(objects.PropertyIdLoggingType).(objects.Enumerated)
+	switch uint8(loggingType) {
+	case objects.LoggingTypePolled:
+		interval, _ := l.o.value(objects.PropertyIdLogInterval).(uint32)
+		now := d.clock()
+		if interval == 0 || now.Before(l.next) {
+			return
+		}
+		l.next = now.Add(time.Duration(interval) * 10 * time.Millisecond)
+		l.sample(false)
+	case objects.LoggingTypeCOV:
+		l.sample(true)
+	}
+}
+
+// active tells whether the log is enabled and now lies between its Start_Time and Stop_Time.
+func (l *trendLog) active(now time.Time) bool {
+	if enable, _ := l.o.value(objects.PropertyIdEnable).(bool); !enable {
+		return false
+	}
+
+	loc := l.o.device.location
+	if start, ok := l.o.value(objects.PropertyIdStartTime).(objects.DateTime); ok && specified(start) &&
+		now.Before(start.Date.In(start.Time, loc)) {
+		return false
+	}
+	if stop, ok := l.o.value(objects.PropertyIdStopTime).(objects.DateTime); ok && specified(stop) &&
+		!now.Before(stop.Date.In(stop.Time, loc)) {
+		return false
+	}
+	return true
+}
+
+// specified tells whether a DateTime names a single moment, the day of the week aside.
+func specified(dt objects.DateTime) bool {
+	for _, field := range []uint8{dt.Date.Year, dt.Date.Month, dt.Date.Day,
+		dt.Time.Hour, dt.Time.Minute, dt.Time.Second, dt.Time.Hundredths} {
+		if field == objects.Unspecified {
+			return false
+		}
+	}
+	return true
+}
+
+// sample records the current value of the property logged. COV logs only record it if it
+// moved or the Status_Flags of its object changed since the last record.
+func (l *trendLog) sample(cov bool) {
+	d := l.o.device
+	ref, _ := l.o.value(objects.PropertyIdLogDeviceObjectProperty).(services.DeviceObjectPropertyReference)
+
+	r := services.LogRecord{}
+	value, flags, err := l.monitored(ref)
+	if err != nil {
+		failure := asBACnetError(err)
+		r.Failure = &failure
+	} else {
+		r.Value, r.StatusFlags = value, flags
+	}
+
+	if cov {
+		if l.last != nil && !l.moved(d.lookup(ref.Object.ObjectType, ref.Object.InstanceNumber), r) {
+			return
+		}
+		l.last = &r
+	}
+
+	l.append(r)
+}
+
+// monitored reads the property logged along with the Status_Flags of its object.
+func (l *trendLog) monitored(ref services.DeviceObjectPropertyReference) (interface{}, objects.BitString, error) {
+	o := l.o.device.lookup(ref.Object.ObjectType, ref.Object.InstanceNumber)
+	if o == nil {
+		return nil, nil, ErrUnknownObject
+	}
+	if !o.has(ref.PropertyId) {
+		return nil, nil, ErrUnknownProperty
+	}
+
+	value := o.value(ref.PropertyId)
+	if ref.ArrayIndex != objects.ArrayAll {
+		if _, ok := o.properties[ref.PropertyId].Datatype.(*Array); !ok {
+			return nil, nil, ErrPropertyIsNotAnArray
+		}
+		elements, _ := value.([]interface{})
+		if ref.ArrayIndex == 0 {
+			value = uint32(len(elements))
+		} else if int(ref.ArrayIndex) > len(elements) {
+			return nil, nil, ErrInvalidArrayIndex
+		} else {
+			value = elements[ref.ArrayIndex-1]
+		}
+	}
+
+	flags, _ := o.value(objects.PropertyIdStatusFlags).(objects.BitString)
+	return value, flags, nil
+}
+
+// moved tells whether a record differs enough from the last one taken by a COV log. Reals
+// must move by the Client_COV_Increment, or else by the COV_Increment of their object.
+func (l *trendLog) moved(o *Object, r services.LogRecord) bool {
+	if r.Failure != nil || l.last.Failure != nil {
+		return !reflect.DeepEqual(r.Failure, l.last.Failure)
+	}
+	if !reflect.DeepEqual(r.StatusFlags, l.last.StatusFlags) {
+		return true
+	}
+
+	increment, _ := l.o.value(objects.PropertyIdClientCOVIncrement).(float32)
+	return changedBy(o, increment, r.Value, l.last.Value)
+}
+
+// logStatus records the log being enabled or disabled, as well as purges of its buffer.
+func (l *trendLog) logStatus(purged bool) {
+	status := make(objects.BitString, 3)
+	status[services.LogStatusLogDisabled] = !l.logging
+	status[services.LogStatusBufferPurged] = purged
+	l.append(services.LogRecord{LogStatus: status})
+}
+
+// append adds a record to the buffer, dropping the oldest one if it's full. Logs that must
+// stop when full are disabled instead, the record filling their buffer telling they are.
+func (l *trendLog) append(r services.LogRecord) {
+	now := l.o.device.now()
+	r.Timestamp = objects.DateTimeOf(now)
+
+	size, _ := l.o.value(objects.PropertyIdBufferSize).(uint32)
+	if size == 0 {
+		return
+	}
+	stop, _ := l.o.value(objects.PropertyIdStopWhenFull).(bool)
+	if stop && r.LogStatus == nil && uint32(len(l.records)) >= size-1 {
+		l.stop()
+		return
+	}
+
+	if l.total == math.MaxUint32 {
+		l.total = 0
+	}
+	l.total++
+	if uint32(len(l.records)) >= size {
+		l.records = append(l.records[:0], l.records[uint32(len(l.records))-size+1:]...)
+	}
+	l.records = append(l.records, logRecord{sequence: l.total, timestamp: now, value: r})
+
+	l.notify()
+
+	if stop && r.LogStatus == nil && uint32(len(l.records)) == size-1 {
+		l.stop()
+	}
+}
+
+// stop disables the log once its buffer is full.
+func (l *trendLog) stop() {
+	l.o.properties[objects.PropertyIdEnable].Value = false
+	l.logging = false
+	l.logStatus(false)
+}
+
+// notify issues a buffer ready notification once Notification_Threshold records gathered.
+func (l *trendLog) notify() {
+	l.sinceNotification++
+
+	threshold, _ := l.o.value(objects.PropertyIdNotificationThreshold).(uint32)
+	if threshold == 0 || l.sinceNotification < threshold {
+		return
+	}
+
+	n := BufferReady{Log: l.o.Identifier, PreviousNotification: l.lastNotify, CurrentNotification: l.total}
+	l.lastNotify, l.sinceNotification = l.total, 0
+
+	if d := l.o.device; d.bufferReady != nil {
+		d.bufferReady(n)
+	}
+}
+
+func (l *trendLog) logBuffer() interface{} {
+	list := make([]interface{}, 0, len(l.records))
+	for _, r := range l.records {
+		list = append(list, r.value)
+	}
+	return list
+}
+
+// resize changes the Buffer_Size of a disabled log, dropping the oldest records that no
+// longer fit.
+func (l *trendLog) resize(value interface{}, priority uint8) error {
+	if enable, _ := l.o.value(objects.PropertyIdEnable).(bool); enable {
+		return ErrWriteAccessDenied
+	}
+
+	size := value.(uint32)
+	if uint32(len(l.records)) > size {
+		l.records = append(l.records[:0], l.records[uint32(len(l.records))-size:]...)
+	}
+	l.o.properties[objects.PropertyIdBufferSize].Value = size
+	return nil
+}
+
+// purge empties the buffer when 0 is written to Record_Count, recording it did.
+func (l *trendLog) purge(value interface{}, priority uint8) error {
+	if value.(uint32) != 0 {
+		return ErrValueOutOfRange
+	}
+
+	l.records = nil
+	if l.o.device != nil {
+		l.logStatus(true)
+	}
+	return nil
+}
+
+// trigger takes a record right away when TRUE is written to the Trigger of a triggered log.
+// The Trigger goes back to FALSE once the record is taken.
+func (l *trendLog) trigger(value interface{}, priority uint8) error {
+	if !value.(bool) {
+		return nil
+	}
+
+	loggingType, _ := l.o.value(objects.PropertyIdLoggingType).(objects.Enumerated)
+	if uint8(loggingType) != objects.LoggingTypeTriggered {
+		return ErrValueOutOfRange
+	}
+	if d := l.o.device; d != nil && l.active(d.now()) {
+		l.sample(false)
+	}
+	return nil
+}
+
+// validateReference only lets Trend Logs log properties of objects on their own Device.
+func (l *trendLog) validateReference(value interface{}) error {
+	if !l.o.local(value.(services.DeviceObjectPropertyReference)) {
+		return ErrOptionalFunctionalityNotSupported
+	}
+	return nil
+}
+
+func validateLoggingType(value interface{}) error {
+	if uint8(value.(objects.Enumerated)) > objects.LoggingTypeTriggered {
+		return ErrValueOutOfRange
+	}
+	return nil
+}
+
+type logRecordType struct{}
+
+// LogRecordType encodes the BACnetLogRecords on the Log_Buffer of Trend Logs, whose values
+// are services.LogRecord.
+var LogRecordType Datatype = logRecordType{}
+
+func (logRecordType) Encode(value interface{}) ([]objects.APDUPayload, error) {
+	r, ok := value.(services.LogRecord)
+	if !ok {
+		return nil, ErrInvalidDataType
+	}
+	objs, err := services.EncLogRecord(r)
+	if err != nil {
+		return nil, ErrInvalidDataType
+	}
+	return objs, nil
+}
+
+func (logRecordType) Decode(rawPayloads []objects.APDUPayload) (interface{}, int, error) {
+	return nil, 0, ErrWriteAccessDenied
+}
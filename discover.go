@@ -0,0 +1,170 @@
+package bacnet
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+	"github.com/ulbios/bacnet/services"
+)
+
+// DefaultDiscoveryWindow is how long I-Am replies are awaited for after a Who-Is by default.
+const DefaultDiscoveryWindow = 3 * time.Second
+
+// Discoverer finds the devices reachable by a Client and binds them in its Devices table.
+// It learns from every I-Am the Client receives, so bindings are kept up to date by the
+// unsolicited I-Am devices broadcast as well.
+type Discoverer struct {
+	// Window is how long I-Am replies are awaited for after each Who-Is.
+	Window time.Duration
+	// ChunkSize, when non-zero, splits discoveries into Who-Is covering that many instances
+	// at most so that the devices of large sites don't all answer at once.
+	ChunkSize uint32
+
+	client *Client
+}
+
+// NewDiscoverer creates a Discoverer binding devices in the Devices table of c.
+func NewDiscoverer(c *Client) *Discoverer {
+	d := &Discoverer{Window: DefaultDiscoveryWindow, client: c}
+	c.HandleUnconfirmed(services.ServiceUnconfirmedIAm, d.iAm)
+	return d
+}
+
+func (d *Discoverer) iAm(msg plumbing.BACnet, src net.Addr) {
+	dec, err := msg.(*services.UnconfirmedIAm).Decode()
+	if err != nil {
+		return
+	}
+
+	addr, ok := src.(*Address)
+	if !ok {
+		addr = &Address{Addr: src}
+	}
+
+	d.client.Devices.Bind(DeviceInfo{
+		DeviceId:     dec.DeviceId,
+		Address:      *addr,
+		MaxAPDU:      dec.MaxAPDULength,
+		Segmentation: dec.SegmentationSupported,
+		VendorId:     dec.VendorId,
+		LastSeen:     time.Now(),
+	})
+}
+
+// Discover broadcasts Who-Is for the instances from low to high and returns the devices
+// that answered within the Window following each of them, ordered by instance. If ctx is
+// done first, the devices found so far are returned along with its error.
+func (d *Discoverer) Discover(ctx context.Context, low, high uint32) ([]DeviceInfo, error) {
+	if high > objects.MaxInstance {
+		high = objects.MaxInstance
+	}
+
+	var mu sync.Mutex
+	found := map[uint32]bool{}
+
+	stop := d.client.watch(services.ServiceUnconfirmedIAm, func(msg plumbing.BACnet, src net.Addr) {
+		dec, err := msg.(*services.UnconfirmedIAm).Decode()
+		if err != nil || dec.DeviceId < low || dec.DeviceId > high {
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		found[dec.DeviceId] = true
+	})
+	defer stop()
+
+	var err error
+	for from := uint64(low); from <= uint64(high) && err == nil; {
+		to := uint64(high)
+		if d.ChunkSize != 0 && from+uint64(d.ChunkSize)-1 < to {
+			to = from + uint64(d.ChunkSize) - 1
+		}
+
+		if err = d.whoIs(uint32(from), uint32(to)); err == nil {
+			err = d.wait(ctx)
+		}
+		from = to + 1
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	devices := []DeviceInfo{}
+	for _, info := range d.client.Devices.Devices() {
+		if found[info.DeviceId] {
+			devices = append(devices, info)
+		}
+	}
+	return devices, err
+}
+
+// Find returns the binding of a device, looking for it with a Who-Is if it isn't bound yet.
+// It returns common.ErrUnknownDevice if the device doesn't answer within the Window.
+func (d *Discoverer) Find(ctx context.Context, deviceId uint32) (DeviceInfo, error) {
+	if info, ok := d.client.Devices.Lookup(deviceId); ok {
+		return info, nil
+	}
+
+	answered := make(chan struct{}, 1)
+	stop := d.client.watch(services.ServiceUnconfirmedIAm, func(msg plumbing.BACnet, src net.Addr) {
+		if dec, err := msg.(*services.UnconfirmedIAm).Decode(); err == nil && dec.DeviceId == deviceId {
+			select {
+			case answered <- struct{}{}:
+			default:
+			}
+		}
+	})
+	defer stop()
+
+	if err := d.whoIs(deviceId, deviceId); err != nil {
+		return DeviceInfo{}, err
+	}
+
+	timer := time.NewTimer(d.Window)
+	defer timer.Stop()
+
+	select {
+	case <-answered:
+	case <-timer.C:
+	case <-ctx.Done():
+		return DeviceInfo{}, ctx.Err()
+	case <-d.client.closed:
+		return DeviceInfo{}, common.ErrClosed
+	}
+
+	// Watchers run after the handlers, so the binding is already there if it answered.
+	if info, ok := d.client.Devices.Lookup(deviceId); ok {
+		return info, nil
+	}
+	return DeviceInfo{}, common.ErrUnknownDevice
+}
+
+// whoIs broadcasts a Who-Is to every network, leaving the limits out if it covers them all.
+func (d *Discoverer) whoIs(low, high uint32) error {
+	u := services.NewUnconfirmedWhoIs(plumbing.NewBVLC(plumbing.BVLCFuncBroadcast), newGlobalBroadcastNPDU())
+	if low != 0 || high != objects.MaxInstance {
+		u.APDU.Objects = services.WhoIsObjects(low, high)
+	}
+	return d.client.Broadcast(u)
+}
+
+func (d *Discoverer) wait(ctx context.Context) error {
+	timer := time.NewTimer(d.Window)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-d.client.closed:
+		return common.ErrClosed
+	}
+}
@@ -1,6 +1,9 @@
 package bacnet
 
 import (
+	"time"
+
+	"github.com/ulbios/bacnet/objects"
 	"github.com/ulbios/bacnet/plumbing"
 	"github.com/ulbios/bacnet/services"
 )
@@ -34,7 +37,7 @@ func NewIAm(deviceId uint32, vendorId uint16) ([]byte, error) {
 	return u.MarshalBinary()
 }
 
-func NewCACK(service uint8, objectType uint16, instN uint32, propertyId uint8, value float32) ([]byte, error) {
+func NewCACK(service uint8, objectType uint16, instN uint32, propertyId uint32, value float32) ([]byte, error) {
 	bvlc := plumbing.NewBVLC(plumbing.BVLCFuncUnicast)
 	npdu := plumbing.NewNPDU(false, false, false, false)
 
@@ -78,7 +81,7 @@ func NewError(service, errorClass, errorCode uint8) ([]byte, error) {
 	return e.MarshalBinary()
 }
 
-func NewReadProperty(objectType uint16, instanceNumber uint32, propertyId uint8) ([]byte, error) {
+func NewReadProperty(objectType uint16, instanceNumber uint32, propertyId uint32) ([]byte, error) {
 	bvlc := plumbing.NewBVLC(plumbing.BVLCFuncUnicast)
 	npdu := plumbing.NewNPDU(false, false, false, true)
 
@@ -87,14 +90,14 @@ func NewReadProperty(objectType uint16, instanceNumber uint32, propertyId uint8)
 	c.APDU.Service = services.ServiceConfirmedReadProperty
 	c.APDU.MaxSize = 5
 	c.APDU.InvokeID = 1
-	c.APDU.Objects = services.ConfirmedReadPropertyObjects(objectType, instanceNumber, propertyId)
+	c.APDU.Objects = services.ConfirmedReadPropertyObjects(objectType, instanceNumber, propertyId, objects.ArrayAll)
 
 	c.SetLength()
 
 	return c.MarshalBinary()
 }
 
-func NewWriteProperty(objectType uint16, instanceNumber uint32, propertyId uint8, value float32) ([]byte, error) {
+func NewWriteProperty(objectType uint16, instanceNumber uint32, propertyId uint32, value float32) ([]byte, error) {
 	bvlc := plumbing.NewBVLC(plumbing.BVLCFuncUnicast)
 	npdu := plumbing.NewNPDU(false, false, false, true)
 
@@ -103,9 +106,188 @@ func NewWriteProperty(objectType uint16, instanceNumber uint32, propertyId uint8
 	c.APDU.Service = services.ServiceConfirmedWriteProperty
 	c.APDU.MaxSize = 5
 	c.APDU.InvokeID = 1
-	c.APDU.Objects = services.ConfirmedWritePropertyObjects(objectType, instanceNumber, propertyId, value)
+	c.APDU.Objects = services.ConfirmedWritePropertyObjects(
+		objectType, instanceNumber, propertyId, objects.ArrayAll, []objects.APDUPayload{objects.EncReal(value)}, 16)
+
+	c.SetLength()
+
+	return c.MarshalBinary()
+}
+
+func NewSubscribeCOV(processId uint32, objectType uint16, instanceNumber uint32, confirmed bool, lifetime uint32) ([]byte, error) {
+	bvlc := plumbing.NewBVLC(plumbing.BVLCFuncUnicast)
+	npdu := plumbing.NewNPDU(false, false, false, true)
+
+	c := services.NewConfirmedSubscribeCOV(bvlc, npdu)
+
+	c.APDU.MaxSize = 5
+	c.APDU.InvokeID = 1
+	c.APDU.Objects = services.ConfirmedSubscribeCOVObjects(processId, objectType, instanceNumber, confirmed, lifetime)
+
+	c.SetLength()
+
+	return c.MarshalBinary()
+}
+
+func NewReadPropertyMultiple(specs []services.ReadAccessSpecification) ([]byte, error) {
+	bvlc := plumbing.NewBVLC(plumbing.BVLCFuncUnicast)
+	npdu := plumbing.NewNPDU(false, false, false, true)
+
+	c := services.NewConfirmedReadPropertyMultiple(bvlc, npdu)
+
+	c.APDU.MaxSize = 5
+	c.APDU.InvokeID = 1
+	c.APDU.Objects = services.ConfirmedReadPropertyMultipleObjects(specs)
+
+	c.SetLength()
+
+	return c.MarshalBinary()
+}
+
+func NewWritePropertyMultiple(specs []services.WriteAccessSpecification) ([]byte, error) {
+	bvlc := plumbing.NewBVLC(plumbing.BVLCFuncUnicast)
+	npdu := plumbing.NewNPDU(false, false, false, true)
+
+	c := services.NewConfirmedWritePropertyMultiple(bvlc, npdu)
+
+	c.APDU.MaxSize = 5
+	c.APDU.InvokeID = 1
+	c.APDU.Objects = services.ConfirmedWritePropertyMultipleObjects(specs)
+
+	c.SetLength()
+
+	return c.MarshalBinary()
+}
+
+func NewWhoisRange(low, high uint32) ([]byte, error) {
+	bvlc := plumbing.NewBVLC(plumbing.BVLCFuncBroadcast)
+	npdu := plumbing.NewNPDU(false, false, false, false)
+	u := services.NewUnconfirmedWhoIs(bvlc, npdu)
+	u.APDU.Objects = services.WhoIsObjects(low, high)
+	u.SetLength()
+	return u.MarshalBinary()
+}
+
+func NewWhoHas(low, high uint32, objectType uint16, instN uint32) ([]byte, error) {
+	bvlc := plumbing.NewBVLC(plumbing.BVLCFuncBroadcast)
+	npdu := plumbing.NewNPDU(false, false, false, false)
+	u := services.NewUnconfirmedWhoHas(bvlc, npdu)
+	u.APDU.Objects = services.WhoHasObjects(low, high, objectType, instN)
+	u.SetLength()
+	return u.MarshalBinary()
+}
+
+func NewWhoHasName(low, high uint32, name string) ([]byte, error) {
+	bvlc := plumbing.NewBVLC(plumbing.BVLCFuncBroadcast)
+	npdu := plumbing.NewNPDU(false, false, false, false)
+	u := services.NewUnconfirmedWhoHas(bvlc, npdu)
+	u.APDU.Objects = services.WhoHasNameObjects(low, high, name)
+	u.SetLength()
+	return u.MarshalBinary()
+}
+
+func NewTimeSynchronization(t time.Time) ([]byte, error) {
+	bvlc := plumbing.NewBVLC(plumbing.BVLCFuncBroadcast)
+	npdu := plumbing.NewNPDU(false, false, false, false)
+	u := services.NewUnconfirmedTimeSynchronization(bvlc, npdu)
+	u.APDU.Objects = services.TimeSynchronizationObjects(objects.DateTimeOf(t))
+	u.SetLength()
+	return u.MarshalBinary()
+}
+
+func NewUTCTimeSynchronization(t time.Time) ([]byte, error) {
+	bvlc := plumbing.NewBVLC(plumbing.BVLCFuncBroadcast)
+	npdu := plumbing.NewNPDU(false, false, false, false)
+	u := services.NewUnconfirmedUTCTimeSynchronization(bvlc, npdu)
+	u.APDU.Objects = services.TimeSynchronizationObjects(objects.DateTimeOf(t.UTC()))
+	u.SetLength()
+	return u.MarshalBinary()
+}
+
+func NewDeviceCommunicationControl(duration uint16, state uint8, password string) ([]byte, error) {
+	bvlc := plumbing.NewBVLC(plumbing.BVLCFuncUnicast)
+	npdu := plumbing.NewNPDU(false, false, false, true)
+	c := services.NewConfirmedDeviceCommunicationControl(bvlc, npdu)
+	c.APDU.MaxSize = 5
+	c.APDU.InvokeID = 1
+	c.APDU.Objects = services.ConfirmedDeviceCommunicationControlObjects(duration, state, password)
+	c.SetLength()
+	return c.MarshalBinary()
+}
+
+func NewReinitializeDevice(state uint8, password string) ([]byte, error) {
+	bvlc := plumbing.NewBVLC(plumbing.BVLCFuncUnicast)
+	npdu := plumbing.NewNPDU(false, false, false, true)
+	c := services.NewConfirmedReinitializeDevice(bvlc, npdu)
+	c.APDU.MaxSize = 5
+	c.APDU.InvokeID = 1
+	c.APDU.Objects = services.ConfirmedReinitializeDeviceObjects(state, password)
+	c.SetLength()
+	return c.MarshalBinary()
+}
+
+func NewAtomicReadFile(instance uint32, start int32, count uint32) ([]byte, error) {
+	bvlc := plumbing.NewBVLC(plumbing.BVLCFuncUnicast)
+	npdu := plumbing.NewNPDU(false, false, false, true)
+	c := services.NewConfirmedAtomicReadFile(bvlc, npdu)
+	c.APDU.MaxSize = 5
+	c.APDU.InvokeID = 1
+	c.APDU.Objects = services.ConfirmedAtomicReadFileObjects(instance, true, start, count)
+	c.SetLength()
+	return c.MarshalBinary()
+}
+
+func NewAtomicWriteFile(instance uint32, start int32, data []byte) ([]byte, error) {
+	bvlc := plumbing.NewBVLC(plumbing.BVLCFuncUnicast)
+	npdu := plumbing.NewNPDU(false, false, false, true)
+	c := services.NewConfirmedAtomicWriteFile(bvlc, npdu)
+	c.APDU.MaxSize = 5
+	c.APDU.InvokeID = 1
+	c.APDU.Objects = services.ConfirmedAtomicWriteFileStreamObjects(instance, start, data)
+	c.SetLength()
+	return c.MarshalBinary()
+}
 
+func NewCreateObject(objectType uint16, instance uint32, values []services.PropertyValue) ([]byte, error) {
+	bvlc := plumbing.NewBVLC(plumbing.BVLCFuncUnicast)
+	npdu := plumbing.NewNPDU(false, false, false, true)
+	c := services.NewConfirmedCreateObject(bvlc, npdu)
+	c.APDU.MaxSize = 5
+	c.APDU.InvokeID = 1
+	c.APDU.Objects = services.ConfirmedCreateObjectObjects(objectType, instance, values)
 	c.SetLength()
+	return c.MarshalBinary()
+}
 
+func NewDeleteObject(objectType uint16, instance uint32) ([]byte, error) {
+	bvlc := plumbing.NewBVLC(plumbing.BVLCFuncUnicast)
+	npdu := plumbing.NewNPDU(false, false, false, true)
+	c := services.NewConfirmedDeleteObject(bvlc, npdu)
+	c.APDU.MaxSize = 5
+	c.APDU.InvokeID = 1
+	c.APDU.Objects = services.ConfirmedDeleteObjectObjects(objectType, instance)
+	c.SetLength()
+	return c.MarshalBinary()
+}
+
+func NewAddListElement(objectType uint16, instance uint32, propertyId uint32, elements []objects.APDUPayload) ([]byte, error) {
+	bvlc := plumbing.NewBVLC(plumbing.BVLCFuncUnicast)
+	npdu := plumbing.NewNPDU(false, false, false, true)
+	c := services.NewConfirmedAddListElement(bvlc, npdu)
+	c.APDU.MaxSize = 5
+	c.APDU.InvokeID = 1
+	c.APDU.Objects = services.ConfirmedListElementObjects(objectType, instance, propertyId, objects.ArrayAll, elements)
+	c.SetLength()
+	return c.MarshalBinary()
+}
+
+func NewRemoveListElement(objectType uint16, instance uint32, propertyId uint32, elements []objects.APDUPayload) ([]byte, error) {
+	bvlc := plumbing.NewBVLC(plumbing.BVLCFuncUnicast)
+	npdu := plumbing.NewNPDU(false, false, false, true)
+	c := services.NewConfirmedRemoveListElement(bvlc, npdu)
+	c.APDU.MaxSize = 5
+	c.APDU.InvokeID = 1
+	c.APDU.Objects = services.ConfirmedListElementObjects(objectType, instance, propertyId, objects.ArrayAll, elements)
+	c.SetLength()
 	return c.MarshalBinary()
 }
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/ulbios/bacnet"
+	"github.com/ulbios/bacnet/objects"
+)
+
+func init() {
+	DiscoverCmd.Flags().Uint32Var(&dLow, "low", 0, "Lowest device instance to look for.")
+	DiscoverCmd.Flags().Uint32Var(&dHigh, "high", objects.MaxInstance, "Highest device instance to look for.")
+	DiscoverCmd.Flags().Uint32Var(&dChunk, "chunk", 0, "Instances covered by each WhoIs, being 0 the whole range at once.")
+	DiscoverCmd.Flags().IntVar(&dWindow, "window", 3, "Time, in seconds, IAm replies are awaited for after each WhoIs.")
+}
+
+var (
+	dLow    uint32
+	dHigh   uint32
+	dChunk  uint32
+	dWindow int
+
+	DiscoverCmd = &cobra.Command{
+		Use:   "discover",
+		Short: "Discover the devices on the network.",
+		Long: "This command broadcasts WhoIs requests to the remote address, optionally splitting\n" +
+			"the instance range in chunks, and lists every device answering with an IAm.",
+		Args: argValidation,
+		Run:  DiscoverExample,
+	}
+)
+
+func DiscoverExample(cmd *cobra.Command, args []string) {
+	remoteUDPAddr, err := net.ResolveUDPAddr("udp", rAddr)
+	if err != nil {
+		log.Fatalf("Failed to resolve UDP address: %s", err)
+	}
+
+	listenConn, err := net.ListenPacket("udp", bAddr)
+	if err != nil {
+		log.Fatalf("failed to begin listening for packets: %v\n", err)
+	}
+
+	c := bacnet.NewClient(listenConn)
+	c.BroadcastAddr = remoteUDPAddr
+	defer c.Close()
+	go c.Run()
+
+	d := bacnet.NewDiscoverer(c)
+	d.ChunkSize = dChunk
+	d.Window = time.Duration(dWindow) * time.Second
+
+	devices, err := d.Discover(context.Background(), dLow, dHigh)
+	if err != nil {
+		log.Fatalf("discovery failed: %v\n", err)
+	}
+
+	for _, info := range devices {
+		log.Printf(
+			"device %d at %s:\n\tMax. APDU Length: %d\n\tSegmentation support: %d\n\tVendor ID: %d\n",
+			info.DeviceId, &info.Address, info.MaxAPDU, info.Segmentation, info.VendorId,
+		)
+	}
+	log.Printf("found %d devices\n", len(devices))
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/ulbios/bacnet"
+)
+
+func init() {
+	FileClientCmd.Flags().Uint32Var(&fDeviceId, "device-id", 0, "Instance of the device holding the file.")
+	FileClientCmd.Flags().Uint32Var(&fInstanceId, "instance-id", 0, "Instance of the File object.")
+	FileClientCmd.Flags().StringVar(&fDownload, "download", "", "Path to save the file to.")
+	FileClientCmd.Flags().StringVar(&fUpload, "upload", "", "Path of the file to upload, replacing the File content.")
+}
+
+var (
+	fDeviceId   uint32
+	fInstanceId uint32
+	fDownload   string
+	fUpload     string
+
+	FileClientCmd = &cobra.Command{
+		Use:   "file",
+		Short: "Download or upload a File object.",
+		Long: "This command locates the device with a WhoIs sent to the remote address and then\n" +
+			"downloads or uploads the content of one of its File objects with AtomicReadFile or\n" +
+			"AtomicWriteFile requests sized to the device's max APDU.",
+		Args: argValidation,
+		Run:  FileClientExample,
+	}
+)
+
+func FileClientExample(cmd *cobra.Command, args []string) {
+	if (fDownload == "") == (fUpload == "") {
+		log.Fatalf("either --download or --upload must be given")
+	}
+
+	remoteUDPAddr, err := net.ResolveUDPAddr("udp", rAddr)
+	if err != nil {
+		log.Fatalf("Failed to resolve UDP address: %s", err)
+	}
+
+	listenConn, err := net.ListenPacket("udp", bAddr)
+	if err != nil {
+		log.Fatalf("failed to begin listening for packets: %v\n", err)
+	}
+
+	c := bacnet.NewClient(listenConn)
+	c.BroadcastAddr = remoteUDPAddr
+	defer c.Close()
+	go c.Run()
+
+	ctx := context.Background()
+	if _, err := bacnet.NewDiscoverer(c).Find(ctx, fDeviceId); err != nil {
+		log.Fatalf("failed to find device %d: %v\n", fDeviceId, err)
+	}
+
+	if fDownload != "" {
+		f, err := os.Create(fDownload)
+		if err != nil {
+			log.Fatalf("failed to create %s: %v\n", fDownload, err)
+		}
+		defer f.Close()
+
+		n, err := c.DownloadFile(ctx, fDeviceId, fInstanceId, f)
+		if err != nil {
+			log.Fatalf("download failed after %d octets: %v\n", n, err)
+		}
+		log.Printf("downloaded %d octets to %s\n", n, fDownload)
+		return
+	}
+
+	f, err := os.Open(fUpload)
+	if err != nil {
+		log.Fatalf("failed to open %s: %v\n", fUpload, err)
+	}
+	defer f.Close()
+
+	n, err := c.UploadFile(ctx, fDeviceId, fInstanceId, f)
+	if err != nil {
+		log.Fatalf("upload failed after %d octets: %v\n", n, err)
+	}
+	log.Printf("uploaded %d octets from %s\n", n, fUpload)
+}
@@ -14,6 +14,8 @@ func init() {
 	// Add the different sub-commands
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(whoIsCmd)
+	rootCmd.AddCommand(DiscoverCmd)
+	rootCmd.AddCommand(FileClientCmd)
 	rootCmd.AddCommand(IAmCmd)
 	rootCmd.AddCommand(ReadPropertyServerCmd)
 	rootCmd.AddCommand(ReadPropertyClientCmd)
@@ -0,0 +1,184 @@
+package bacnet
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/device"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+	"github.com/ulbios/bacnet/services"
+)
+
+// Encoded lengths of what surrounds the data of a stream access in AtomicReadFile replies
+// and AtomicWriteFile requests: the end of file flag or the file identifier, the tags
+// enclosing the access, the start position and the header of the octet string.
+const (
+	fileReadOverhead  = 1 + 2 + 5 + 4
+	fileWriteOverhead = 5 + 2 + 5 + 4
+)
+
+// fileReadRecordsOverhead is the encoded length of what surrounds the records of a record
+// access in AtomicReadFile replies: the end of file flag, the tags enclosing the access, the
+// start record and the record count.
+const fileReadRecordsOverhead = 1 + 2 + 5 + 5
+
+// DownloadFile reads the whole of a stream access File object off a device bound in
+// Devices into w. It's read in chunks whose replies fit in a single APDU of the size the
+// device accepts. It returns the number of octets read.
+func (c *Client) DownloadFile(ctx context.Context, deviceId uint32, instance uint32, w io.Writer) (int64, error) {
+	info, ok := c.Devices.Lookup(deviceId)
+	if !ok {
+		return 0, common.ErrUnknownDevice
+	}
+	chunk := info.maxAPDU() - apduHeaderLen(plumbing.ComplexAck) - fileReadOverhead
+
+	var n int64
+	for {
+		req := services.NewConfirmedAtomicReadFile(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+		req.APDU.Objects = services.ConfirmedAtomicReadFileObjects(instance, true, int32(n), uint32(chunk))
+
+		reply, err := c.RequestDevice(ctx, deviceId, req)
+		if err != nil {
+			return n, err
+		}
+		cACK, ok := reply.(*services.ComplexACK)
+		if !ok {
+			return n, common.ErrWrongStructure
+		}
+		dec, err := cACK.DecodeAtomicReadFile()
+		if err != nil {
+			return n, err
+		}
+		if !dec.Stream || dec.Start != int32(n) {
+			return n, common.ErrWrongStructure
+		}
+
+		written, err := w.Write(dec.Data)
+		n += int64(written)
+		if err != nil {
+			return n, err
+		}
+
+		if dec.EndOfFile {
+			return n, nil
+		}
+		// Devices returning nothing short of the end of file would keep us here forever.
+		if len(dec.Data) == 0 {
+			return n, common.ErrWrongStructure
+		}
+	}
+}
+
+// UploadFile replaces the content of a stream access File object of a device bound in
+// Devices with what's read from r. The File is emptied first by writing 0 to its File_Size,
+// then written in chunks whose requests fit in a single APDU of the size the device accepts.
+// It returns the number of octets written.
+func (c *Client) UploadFile(ctx context.Context, deviceId uint32, instance uint32, r io.Reader) (int64, error) {
+	info, ok := c.Devices.Lookup(deviceId)
+	if !ok {
+		return 0, common.ErrUnknownDevice
+	}
+	chunk := info.maxAPDU() - apduHeaderLen(plumbing.ConfirmedReq) - fileWriteOverhead
+
+	truncate := services.NewConfirmedWriteProperty(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+	truncate.APDU.Objects = services.ConfirmedWritePropertyObjects(objects.ObjectTypeFile, instance, objects.PropertyIdFileSize,
+		objects.ArrayAll, []objects.APDUPayload{objects.EncUnsignedInteger(0)}, 0)
+	if _, err := c.RequestDevice(ctx, deviceId, truncate); err != nil {
+		return 0, err
+	}
+
+	var n int64
+	buf := make([]byte, chunk)
+	for {
+		read, rErr := io.ReadFull(r, buf)
+		if read > 0 {
+			req := services.NewConfirmedAtomicWriteFile(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+			req.APDU.Objects = services.ConfirmedAtomicWriteFileStreamObjects(instance, int32(n), buf[:read])
+
+			reply, err := c.RequestDevice(ctx, deviceId, req)
+			if err != nil {
+				return n, err
+			}
+			cACK, ok := reply.(*services.ComplexACK)
+			if !ok {
+				return n, common.ErrWrongStructure
+			}
+			dec, err := cACK.DecodeAtomicWriteFile()
+			if err != nil {
+				return n, err
+			}
+			if !dec.Stream || dec.Start != int32(n) {
+				return n, common.ErrWrongStructure
+			}
+			n += int64(read)
+		}
+
+		switch {
+		case errors.Is(rErr, io.EOF), errors.Is(rErr, io.ErrUnexpectedEOF):
+			return n, nil
+		case rErr != nil:
+			return n, rErr
+		}
+	}
+}
+
+func (s *Server) atomicReadFile(msg plumbing.BACnet, src net.Addr) ([]objects.APDUPayload, error) {
+	c := msg.(*services.ConfirmedAtomicReadFile)
+	req, err := c.Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	// Only as much as the requester takes in a single APDU is read.
+	maxLen := plumbing.DecMaxAPDU(c.APDU.MaxSize)
+	if maxLen > plumbing.MaxAPDULengthIP {
+		maxLen = plumbing.MaxAPDULengthIP
+	}
+	maxLen -= apduHeaderLen(plumbing.ComplexAck)
+
+	o := s.Device.Lookup(objects.ObjectTypeFile, req.InstanceId)
+	if o == nil {
+		return nil, device.ErrUnknownObject
+	}
+
+	if req.Stream {
+		data, eof, err := o.ReadStream(req.Start, req.Count, maxLen-fileReadOverhead)
+		if err != nil {
+			return nil, err
+		}
+		return services.AtomicReadFileACKStreamObjects(eof, req.Start, data), nil
+	}
+
+	records, eof, err := o.ReadRecords(req.Start, req.Count, maxLen-fileReadRecordsOverhead)
+	if err != nil {
+		return nil, err
+	}
+	return services.AtomicReadFileACKRecordObjects(eof, req.Start, records), nil
+}
+
+func (s *Server) atomicWriteFile(msg plumbing.BACnet, src net.Addr) ([]objects.APDUPayload, error) {
+	req, err := msg.(*services.ConfirmedAtomicWriteFile).Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	o := s.Device.Lookup(objects.ObjectTypeFile, req.InstanceId)
+	if o == nil {
+		return nil, device.ErrUnknownObject
+	}
+
+	var start int32
+	if req.Stream {
+		start, err = o.WriteStream(req.Start, req.Data)
+	} else {
+		start, err = o.WriteRecords(req.Start, req.Records)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return services.AtomicWriteFileACKObjects(req.Stream, start), nil
+}
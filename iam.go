@@ -0,0 +1,101 @@
+package bacnet
+
+import (
+	"math/rand"
+	"net"
+	"time"
+
+	"github.com/ulbios/bacnet/device"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+	"github.com/ulbios/bacnet/services"
+)
+
+// globalBroadcastNet is the DNET addressing every network.
+const globalBroadcastNet = 0xFFFF
+
+// IAmResponder answers the Who-Is requests meant for a device with an I-Am.
+type IAmResponder struct {
+	// MaxDelay bounds the random delay replies are held back for, which spreads the I-Am
+	// of the devices of large sites over time. Replies are sent right away when zero.
+	MaxDelay time.Duration
+	// Unicast answers Who-Is received by unicast with a unicast I-Am. Otherwise I-Am are
+	// globally broadcast as the standard requires, letting every device on every network
+	// learn about the binding.
+	Unicast bool
+
+	client *Client
+	device *device.Device
+}
+
+// NewIAmResponder creates an IAmResponder answering for dev through c. Register its
+// ServeWhoIs method as the Who-Is handler of c to put it to work.
+func NewIAmResponder(c *Client, dev *device.Device) *IAmResponder {
+	return &IAmResponder{client: c, device: dev}
+}
+
+// Answers tells whether the device falls within the range of a Who-Is.
+func (r *IAmResponder) Answers(req services.UnconfirmedWhoIsDec) bool {
+	return req.Includes(r.device.Instance())
+}
+
+// Announce broadcasts an I-Am, as devices are expected to do when they start up.
+func (r *IAmResponder) Announce() error {
+	return r.client.Broadcast(r.iAm(true))
+}
+
+// ServeWhoIs answers a Who-Is if the device falls within its range.
+func (r *IAmResponder) ServeWhoIs(msg plumbing.BACnet, src net.Addr) {
+	whoIs := msg.(*services.UnconfirmedWhoIs)
+
+	req, err := whoIs.Decode()
+	if err != nil || !r.Answers(req) {
+		return
+	}
+
+	unicast := r.Unicast && whoIs.BVLC.Function == plumbing.BVLCFuncUnicast
+	reply := func() {
+		// Answering Who-Is is allowed even while initiation is disabled.
+		if unicast {
+			r.client.send(src, r.iAm(false))
+		} else {
+			r.client.broadcast(r.iAm(true))
+		}
+	}
+
+	after(r.MaxDelay, reply)
+}
+
+// after calls f after a random delay of up to max, or right away if max is zero.
+func after(max time.Duration, f func()) {
+	if max <= 0 {
+		f()
+		return
+	}
+	time.AfterFunc(time.Duration(rand.Int63n(int64(max))), f)
+}
+
+// newGlobalBroadcastNPDU creates an NPDU addressed to every network.
+func newGlobalBroadcastNPDU() *plumbing.NPDU {
+	npdu := plumbing.NewNPDU(false, false, false, false)
+	npdu.SetDestination(globalBroadcastNet, nil)
+	return npdu
+}
+
+// iAm creates an I-Am for the device, addressed to every network when global is set.
+func (r *IAmResponder) iAm(global bool) *services.UnconfirmedIAm {
+	segmentation, _ := r.device.Object().Get(objects.PropertyIdSegmentationSupported).(objects.Enumerated)
+	maxAPDU, _ := r.device.Object().Get(objects.PropertyIdMaxAPDULengthAccepted).(uint32)
+	vendorId, _ := r.device.Object().Get(objects.PropertyIdVendorIdentifier).(uint32)
+
+	npdu := plumbing.NewNPDU(false, false, false, false)
+	if global {
+		npdu = newGlobalBroadcastNPDU()
+	}
+
+	u := services.NewUnconfirmedIAm(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), npdu)
+	u.APDU.Objects = services.IAmObjects(r.device.Instance(), uint16(maxAPDU), uint8(segmentation), uint16(vendorId))
+	u.SetLength()
+
+	return u
+}
@@ -21,19 +21,368 @@ const (
 
 // Be sure to check ../bacnet-stack/src/bacnet/bacenum.h for more!
 const (
-	ObjectTypeAnalogInput  uint16 = 0
-	ObjectTypeAnalogOutput uint16 = 1
-	ObjectTypeDevice       uint16 = 8
+	ObjectTypeAnalogInput       uint16 = 0
+	ObjectTypeAnalogOutput      uint16 = 1
+	ObjectTypeAnalogValue       uint16 = 2
+	ObjectTypeBinaryInput       uint16 = 3
+	ObjectTypeBinaryOutput      uint16 = 4
+	ObjectTypeBinaryValue       uint16 = 5
+	ObjectTypeCalendar          uint16 = 6
+	ObjectTypeCommand           uint16 = 7
+	ObjectTypeDevice            uint16 = 8
+	ObjectTypeEventEnrollment   uint16 = 9
+	ObjectTypeFile              uint16 = 10
+	ObjectTypeGroup             uint16 = 11
+	ObjectTypeLoop              uint16 = 12
+	ObjectTypeMultiStateInput   uint16 = 13
+	ObjectTypeMultiStateOutput  uint16 = 14
+	ObjectTypeNotificationClass uint16 = 15
+	ObjectTypeProgram           uint16 = 16
+	ObjectTypeSchedule          uint16 = 17
+	ObjectTypeAveraging         uint16 = 18
+	ObjectTypeMultiStateValue   uint16 = 19
+	ObjectTypeTrendLog          uint16 = 20
+
+	// MaxObjectType is the number of object types defined by the standard.
+	MaxObjectType uint16 = 60
+
+	// MaxInstance is both the highest instance number and the wildcard one.
+	MaxInstance uint32 = 0x3FFFFF
+)
+
+const (
+	PropertyIdAckedTransitions              uint32 = 0
+	PropertyIdAckRequired                   uint32 = 1
+	PropertyIdActiveText                    uint32 = 4
+	PropertyIdAlarmValue                    uint32 = 6
+	PropertyIdAlarmValues                   uint32 = 7
+	PropertyIdAll                           uint32 = 8
+	PropertyIdAPDUSegmentTimeout            uint32 = 10
+	PropertyIdAPDUTimeout                   uint32 = 11
+	PropertyIdApplicationSoftwareVersion    uint32 = 12
+	PropertyIdArchive                       uint32 = 13
+	PropertyIdChangeOfStateCount            uint32 = 15
+	PropertyIdChangeOfStateTime             uint32 = 16
+	PropertyIdNotificationClass             uint32 = 17
+	PropertyIdCOVIncrement                  uint32 = 22
+	PropertyIdDateList                      uint32 = 23
+	PropertyIdDaylightSavingsStatus         uint32 = 24
+	PropertyIdDeadband                      uint32 = 25
+	PropertyIdDescription                   uint32 = 28
+	PropertyIdDeviceAddressBinding          uint32 = 30
+	PropertyIdDeviceType                    uint32 = 31
+	PropertyIdEffectivePeriod               uint32 = 32
+	PropertyIdEventEnable                   uint32 = 35
+	PropertyIdEventState                    uint32 = 36
+	PropertyIdEventType                     uint32 = 37
+	PropertyIdExceptionSchedule             uint32 = 38
+	PropertyIdFaultValues                   uint32 = 39
+	PropertyIdFeedbackValue                 uint32 = 40
+	PropertyIdFileAccessMethod              uint32 = 41
+	PropertyIdFileSize                      uint32 = 42
+	PropertyIdFileType                      uint32 = 43
+	PropertyIdFirmwareRevision              uint32 = 44
+	PropertyIdHighLimit                     uint32 = 45
+	PropertyIdInactiveText                  uint32 = 46
+	PropertyIdLimitEnable                   uint32 = 52
+	PropertyIdListOfObjectPropertyRefs      uint32 = 54
+	PropertyIdLocalDate                     uint32 = 56
+	PropertyIdLocalTime                     uint32 = 57
+	PropertyIdLocation                      uint32 = 58
+	PropertyIdLowLimit                      uint32 = 59
+	PropertyIdMaxAPDULengthAccepted         uint32 = 62
+	PropertyIdMaxPresValue                  uint32 = 65
+	PropertyIdMinimumOffTime                uint32 = 66
+	PropertyIdMinimumOnTime                 uint32 = 67
+	PropertyIdMinPresValue                  uint32 = 69
+	PropertyIdModelName                     uint32 = 70
+	PropertyIdModificationDate              uint32 = 71
+	PropertyIdNotifyType                    uint32 = 72
+	PropertyIdNumberOfAPDURetries           uint32 = 73
+	PropertyIdNumberOfStates                uint32 = 74
+	PropertyIdObjectIdentifier              uint32 = 75
+	PropertyIdObjectList                    uint32 = 76
+	PropertyIdObjectName                    uint32 = 77
+	PropertyIdObjectType                    uint32 = 79
+	PropertyIdOptional                      uint32 = 80
+	PropertyIdOutOfService                  uint32 = 81
+	PropertyIdPolarity                      uint32 = 84
+	PropertyIdPresentValue                  uint32 = 85
+	PropertyIdPriority                      uint32 = 86
+	PropertyIdPriorityArray                 uint32 = 87
+	PropertyIdPriorityForWriting            uint32 = 88
+	PropertyIdProtocolObjectTypesSupported  uint32 = 96
+	PropertyIdProtocolServicesSupported     uint32 = 97
+	PropertyIdProtocolVersion               uint32 = 98
+	PropertyIdReadOnly                      uint32 = 99
+	PropertyIdRecipientList                 uint32 = 102
+	PropertyIdReliability                   uint32 = 103
+	PropertyIdRelinquishDefault             uint32 = 104
+	PropertyIdRequired                      uint32 = 105
+	PropertyIdResolution                    uint32 = 106
+	PropertyIdSegmentationSupported         uint32 = 107
+	PropertyIdStateText                     uint32 = 110
+	PropertyIdStatusFlags                   uint32 = 111
+	PropertyIdSystemStatus                  uint32 = 112
+	PropertyIdTimeDelay                     uint32 = 113
+	PropertyIdTimeSynchronizationRecipients uint32 = 116
+	PropertyIdUnits                         uint32 = 117
+	PropertyIdUpdateInterval                uint32 = 118
+	PropertyIdUTCOffset                     uint32 = 119
+	PropertyIdVendorIdentifier              uint32 = 120
+	PropertyIdVendorName                    uint32 = 121
+	PropertyIdWeeklySchedule                uint32 = 123
+	PropertyIdBufferSize                    uint32 = 126
+	PropertyIdClientCOVIncrement            uint32 = 127
+	PropertyIdEventTimeStamps               uint32 = 130
+	PropertyIdLogBuffer                     uint32 = 131
+	PropertyIdLogDeviceObjectProperty       uint32 = 132
+	PropertyIdEnable                        uint32 = 133
+	PropertyIdLogInterval                   uint32 = 134
+	PropertyIdNotificationThreshold         uint32 = 137
+	PropertyIdProtocolRevision              uint32 = 139
+	PropertyIdRecordsSinceNotification      uint32 = 140
+	PropertyIdRecordCount                   uint32 = 141
+	PropertyIdStartTime                     uint32 = 142
+	PropertyIdStopTime                      uint32 = 143
+	PropertyIdStopWhenFull                  uint32 = 144
+	PropertyIdTotalRecordCount              uint32 = 145
+	PropertyIdActiveCOVSubscriptions        uint32 = 152
+	PropertyIdBackupFailureTimeout          uint32 = 153
+	PropertyIdConfigurationFiles            uint32 = 154
+	PropertyIdDatabaseRevision              uint32 = 155
+	PropertyIdLastRestoreTime               uint32 = 157
+	PropertyIdMaxSegmentsAccepted           uint32 = 167
+	PropertyIdLastNotifyRecord              uint32 = 173
+	PropertyIdScheduleDefault               uint32 = 174
+	PropertyIdLoggingType                   uint32 = 197
+	PropertyIdTimeSynchronizationInterval   uint32 = 204
+	PropertyIdTrigger                       uint32 = 205
+	PropertyIdUTCTimeSynchronizationRecips  uint32 = 206
+	PropertyIdBackupAndRestoreState         uint32 = 338
+	PropertyIdBackupPreparationTime         uint32 = 339
+	PropertyIdRestoreCompletionTime         uint32 = 340
+	PropertyIdRestorePreparationTime        uint32 = 341
+	PropertyIdEventDetectionEnable          uint32 = 353
+	PropertyIdTimeDelayNormal               uint32 = 356
+	PropertyIdPropertyList                  uint32 = 371
+	PropertyIdCurrentCommandPriority        uint32 = 431
+)
+
+const (
+	ErrorClassDevice        uint8 = 0
+	ErrorClassObject        uint8 = 1
+	ErrorClassProperty      uint8 = 2
+	ErrorClassResources     uint8 = 3
+	ErrorClassSecurity      uint8 = 4
+	ErrorClassService       uint8 = 5
+	ErrorClassVT            uint8 = 6
+	ErrorClassCommunication uint8 = 7
+
+	ErrorCodeOther                       uint8 = 0
+	ErrorCodeConfigurationInProgress     uint8 = 2
+	ErrorCodeDeviceBusy                  uint8 = 3
+	ErrorCodeDynamicCreationNotSupported uint8 = 4
+	ErrorCodeFileAccessDenied            uint8 = 5
+	ErrorCodeInconsistentParameters      uint8 = 7
+	ErrorCodeInvalidDataType             uint8 = 9
+	ErrorCodeInvalidFileAccessMethod     uint8 = 10
+	ErrorCodeInvalidFileStartPosition    uint8 = 11
+	ErrorCodeInvalidParameterDataType    uint8 = 13
+	ErrorCodeInvalidTimeStamp            uint8 = 14
+	ErrorCodeMissingRequiredParameter    uint8 = 16
+	ErrorCodeNoObjectsOfSpecifiedType    uint8 = 17
+	ErrorCodeNoSpaceForObject            uint8 = 18
+	ErrorCodeNoSpaceToAddListElement     uint8 = 19
+	ErrorCodeNoSpaceToWriteProperty      uint8 = 20
+	ErrorCodePropertyIsNotAList          uint8 = 22
+	ErrorCodeObjectDeletionNotPermitted  uint8 = 23
+	ErrorCodeObjectIdentifierExists      uint8 = 24
+	ErrorCodeOperationalProblem          uint8 = 25
+	ErrorCodePasswordFailure             uint8 = 26
+	ErrorCodeReadAccessDenied            uint8 = 27
+	ErrorCodeServiceRequestDenied        uint8 = 29
+	ErrorCodeTimeout                     uint8 = 30
+	ErrorCodeUnknownObject               uint8 = 31
+	ErrorCodeUnknownProperty             uint8 = 32
+	ErrorCodeUnsupportedObjectType       uint8 = 36
+	ErrorCodeValueOutOfRange             uint8 = 37
+	ErrorCodeWriteAccessDenied           uint8 = 40
+	ErrorCodeCharacterSetNotSupported    uint8 = 41
+	ErrorCodeInvalidArrayIndex           uint8 = 42
+	ErrorCodeCOVSubscriptionFailed       uint8 = 43
+	ErrorCodeNotCOVProperty              uint8 = 44
+	ErrorCodeOptionalFunctionalityNotSup uint8 = 45
+	ErrorCodeDatatypeNotSupported        uint8 = 47
+	ErrorCodeDuplicateName               uint8 = 48
+	ErrorCodeDuplicateObjectId           uint8 = 49
+	ErrorCodePropertyIsNotAnArray        uint8 = 50
+	ErrorCodeInvalidTag                  uint8 = 57
+	ErrorCodeInvalidEventState           uint8 = 73
+	ErrorCodeNoAlarmConfigured           uint8 = 74
+	ErrorCodeUnknownSubscription         uint8 = 79
+	ErrorCodeParameterOutOfRange         uint8 = 80
+	ErrorCodeListElementNotFound         uint8 = 81
+	ErrorCodeBusy                        uint8 = 82
+	ErrorCodeCommunicationDisabled       uint8 = 83
+	ErrorCodeSuccess                     uint8 = 84
+)
+
+// Values of the Segmentation_Supported property.
+const (
+	SegmentationBoth uint8 = iota
+	SegmentationTransmit
+	SegmentationReceive
+	SegmentationNone
+)
+
+// Communication states set by DeviceCommunicationControl.
+const (
+	CommunicationEnable uint8 = iota
+	CommunicationDisable
+	CommunicationDisableInitiation
 )
 
+// Values of the File_Access_Method property.
 const (
-	PropertyIdPresentValue uint8 = 85
+	FileAccessRecord uint8 = iota
+	FileAccessStream
 )
 
+// States ReinitializeDevice requests bring devices to.
 const (
-	ErrorClassObject  uint8 = 1
-	ErrorClassService uint8 = 5
+	ReinitializedStateColdstart uint8 = iota
+	ReinitializedStateWarmstart
+	ReinitializedStateStartBackup
+	ReinitializedStateEndBackup
+	ReinitializedStateStartRestore
+	ReinitializedStateEndRestore
+	ReinitializedStateAbortRestore
+	ReinitializedStateActivateChanges
+)
 
-	ErrorCodeUnknownObject        uint8 = 31
-	ErrorCodeServiceRequestDenied uint8 = 29
+// Values of the Backup_And_Restore_State property.
+const (
+	BackupStateIdle uint8 = iota
+	BackupStatePreparingForBackup
+	BackupStatePreparingForRestore
+	BackupStatePerformingABackup
+	BackupStatePerformingARestore
+	BackupStateBackupFailure
+	BackupStateRestoreFailure
+)
+
+// Values of the System_Status property.
+const (
+	SystemStatusOperational uint8 = iota
+	SystemStatusOperationalReadOnly
+	SystemStatusDownloadRequired
+	SystemStatusDownloadInProgress
+	SystemStatusNonOperational
+	SystemStatusBackupInProgress
+)
+
+// Values of the Logging_Type property.
+const (
+	LoggingTypePolled uint8 = iota
+	LoggingTypeCOV
+	LoggingTypeTriggered
+)
+
+// Values of the Event_State property.
+const (
+	EventStateNormal uint8 = iota
+	EventStateFault
+	EventStateOffnormal
+	EventStateHighLimit
+	EventStateLowLimit
+	EventStateLifeSafetyAlarm
+)
+
+// Values of the Event_Type property.
+const (
+	EventTypeChangeOfBitstring       uint32 = 0
+	EventTypeChangeOfState           uint32 = 1
+	EventTypeChangeOfValue           uint32 = 2
+	EventTypeCommandFailure          uint32 = 3
+	EventTypeFloatingLimit           uint32 = 4
+	EventTypeOutOfRange              uint32 = 5
+	EventTypeChangeOfLifeSafety      uint32 = 8
+	EventTypeExtended                uint32 = 9
+	EventTypeBufferReady             uint32 = 10
+	EventTypeUnsignedRange           uint32 = 11
+	EventTypeAccessEvent             uint32 = 13
+	EventTypeDoubleOutOfRange        uint32 = 14
+	EventTypeSignedOutOfRange        uint32 = 15
+	EventTypeUnsignedOutOfRange      uint32 = 16
+	EventTypeChangeOfCharacterstring uint32 = 17
+	EventTypeChangeOfStatusFlags     uint32 = 18
+	EventTypeChangeOfReliability     uint32 = 19
+	EventTypeNone                    uint32 = 20
+	EventTypeChangeOfDiscreteValue   uint32 = 21
+	EventTypeChangeOfTimer           uint32 = 22
+)
+
+// Values of the Notify_Type property.
+const (
+	NotifyTypeAlarm uint8 = iota
+	NotifyTypeEvent
+	NotifyTypeAckNotification
+)
+
+// Bits of the Event_Enable, Acked_Transitions and Ack_Required properties, of the transitions
+// of BACnetDestinations and indexes, minus one, of the Event_Time_Stamps.
+const (
+	TransitionToOffnormal uint8 = iota
+	TransitionToFault
+	TransitionToNormal
+)
+
+// Bits of the Limit_Enable property.
+const (
+	LimitEnableLowLimit uint8 = iota
+	LimitEnableHighLimit
+)
+
+// Values of the Reliability property.
+const (
+	ReliabilityNoFaultDetected uint8 = 0
+	ReliabilityOverRange       uint8 = 2
+	ReliabilityUnderRange      uint8 = 3
+	ReliabilityCommunication   uint8 = 12
+)
+
+// Bits of the Status_Flags property.
+const (
+	StatusFlagInAlarm uint8 = iota
+	StatusFlagFault
+	StatusFlagOverridden
+	StatusFlagOutOfService
+)
+
+// Values of binary Present_Values and of the Polarity property.
+const (
+	BinaryInactive uint8 = 0
+	BinaryActive   uint8 = 1
+
+	PolarityNormal  uint8 = 0
+	PolarityReverse uint8 = 1
+)
+
+// A handful of the values the Units property can take.
+const (
+	UnitsSquareMeters            uint32 = 0
+	UnitsAmperes                 uint32 = 3
+	UnitsVolts                   uint32 = 5
+	UnitsKilowattHours           uint32 = 19
+	UnitsPercentRelativeHumidity uint32 = 29
+	UnitsKilowatts               uint32 = 48
+	UnitsPascals                 uint32 = 53
+	UnitsDegreesCelsius          uint32 = 62
+	UnitsDegreesFahrenheit       uint32 = 64
+	UnitsMinutes                 uint32 = 72
+	UnitsSeconds                 uint32 = 73
+	UnitsLitersPerSecond         uint32 = 87
+	UnitsNoUnits                 uint32 = 95
+	UnitsPercent                 uint32 = 98
 )
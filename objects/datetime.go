@@ -0,0 +1,160 @@
+package objects
+
+import (
+	"time"
+
+	"github.com/ulbios/bacnet/common"
+)
+
+// Unspecified marks a Date or Time field as a wildcard matching any value.
+const Unspecified uint8 = 0xFF
+
+// Special values of the month and day of Dates and of the fields of BACnetWeekNDays matching
+// several of them at once.
+const (
+	MonthOdd  uint8 = 13
+	MonthEven uint8 = 14
+
+	DayLast uint8 = 32
+	DayOdd  uint8 = 33
+	DayEven uint8 = 34
+
+	// WeekLast matches the last 7 days of the month, WeekLast+1 the 7 days before them and
+	// so on up to WeekLast+3.
+	WeekLast uint8 = 6
+)
+
+// Date is a BACnet date. Year is the number of years since 1900 and Weekday runs
+// from 1 (Monday) to 7 (Sunday). Any field can be set to Unspecified.
+type Date struct {
+	Year    uint8
+	Month   uint8
+	Day     uint8
+	Weekday uint8
+}
+
+// Time is a BACnet time of day. Any field can be set to Unspecified.
+type Time struct {
+	Hour       uint8
+	Minute     uint8
+	Second     uint8
+	Hundredths uint8
+}
+
+// DateOf returns the Date corresponding to the given time.
+func DateOf(t time.Time) Date {
+	weekday := uint8(t.Weekday())
+	if weekday == 0 {
+		weekday = 7
+	}
+
+	return Date{
+		Year:    uint8(t.Year() - 1900),
+		Month:   uint8(t.Month()),
+		Day:     uint8(t.Day()),
+		Weekday: weekday,
+	}
+}
+
+// TimeOf returns the Time corresponding to the given time.
+func TimeOf(t time.Time) Time {
+	return Time{
+		Hour:       uint8(t.Hour()),
+		Minute:     uint8(t.Minute()),
+		Second:     uint8(t.Second()),
+		Hundredths: uint8(t.Nanosecond() / int(10*time.Millisecond)),
+	}
+}
+
+// In returns the moment both the Date and the Time refer to on the given location. Unspecified
+// fields are taken as zero.
+func (d Date) In(t Time, loc *time.Location) time.Time {
+	field := func(v uint8, def int) int {
+		if v == Unspecified {
+			return def
+		}
+		return int(v)
+	}
+
+	return time.Date(
+		1900+field(d.Year, 0), time.Month(field(d.Month, 1)), field(d.Day, 1),
+		field(t.Hour, 0), field(t.Minute, 0), field(t.Second, 0),
+		field(t.Hundredths, 0)*int(10*time.Millisecond), loc,
+	)
+}
+
+func DecDate(rawPayload APDUPayload) (Date, error) {
+	rawObject, err := decObject(rawPayload, TagDate)
+	if err != nil {
+		return Date{}, err
+	}
+
+	if rawObject.Length != 4 {
+		return Date{}, common.ErrWrongStructure
+	}
+
+	return Date{
+		Year:    rawObject.Data[0],
+		Month:   rawObject.Data[1],
+		Day:     rawObject.Data[2],
+		Weekday: rawObject.Data[3],
+	}, nil
+}
+
+func EncDate(value Date) *Object {
+	return NewObject(TagDate, false, []byte{value.Year, value.Month, value.Day, value.Weekday})
+}
+
+func DecTime(rawPayload APDUPayload) (Time, error) {
+	rawObject, err := decObject(rawPayload, TagTime)
+	if err != nil {
+		return Time{}, err
+	}
+
+	if rawObject.Length != 4 {
+		return Time{}, common.ErrWrongStructure
+	}
+
+	return Time{
+		Hour:       rawObject.Data[0],
+		Minute:     rawObject.Data[1],
+		Second:     rawObject.Data[2],
+		Hundredths: rawObject.Data[3],
+	}, nil
+}
+
+func EncTime(value Time) *Object {
+	return NewObject(TagTime, false, []byte{value.Hour, value.Minute, value.Second, value.Hundredths})
+}
+
+// DateTime is a BACnetDateTime: a Date and a Time encoded one after the other.
+type DateTime struct {
+	Date Date
+	Time Time
+}
+
+// DateTimeOf returns the DateTime corresponding to the given time.
+func DateTimeOf(t time.Time) DateTime {
+	return DateTime{Date: DateOf(t), Time: TimeOf(t)}
+}
+
+func DecDateTime(rawPayloads []APDUPayload) (DateTime, error) {
+	if len(rawPayloads) != 2 {
+		return DateTime{}, common.ErrWrongObjectCount
+	}
+
+	date, err := DecDate(rawPayloads[0])
+	if err != nil {
+		return DateTime{}, err
+	}
+	t, err := DecTime(rawPayloads[1])
+	if err != nil {
+		return DateTime{}, err
+	}
+
+	return DateTime{Date: date, Time: t}, nil
+}
+
+func EncDateTime(value DateTime) []APDUPayload {
+	return []APDUPayload{EncDate(value.Date), EncTime(value.Time)}
+}
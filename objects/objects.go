@@ -1,6 +1,8 @@
 package objects
 
 import (
+	"encoding/binary"
+
 	"github.com/ulbios/bacnet/common"
 )
 
@@ -11,24 +13,55 @@ type APDUPayload interface {
 	MarshalLen() int
 }
 
-// Object is an object in APDU.
+// Object is an object in APDU. Bear in mind application tagged booleans carry
+// their value on the Length field and hence have no Data at all.
 type Object struct {
 	TagNumber uint8
 	TagClass  bool
-	Length    uint8
+	Length    uint32
 	Data      []byte
 }
 
 // NewObject creates an Object.
 func NewObject(number uint8, class bool, data []byte) *Object {
-	return &Object{
+	o := &Object{
 		TagNumber: number,
 		TagClass:  class,
-		Data:      data,
+		Length:    uint32(len(data)),
+	}
+	if len(data) > 0 {
+		o.Data = data
 	}
+	return o
 }
 
-const objLenMin int = 2
+const objLenMin int = 1
+
+// Values of the length/value/type field signaling an extended length.
+const (
+	lvtExtended      uint8 = 5
+	lvtExtended16    uint8 = 254
+	lvtExtended32    uint8 = 255
+	lvtExtendedMin8  int   = 5
+	lvtExtendedMin16 int   = 254
+	lvtExtendedMin32 int   = 65536
+)
+
+// Tag numbers from tagExtended on don't fit in the initial octet, which holds tagExtended
+// instead, and follow it in an octet of their own.
+const tagExtended uint8 = 15
+
+// tagLen returns the length of the tag number encoding.
+func tagLen(tagN uint8) int {
+	if tagN >= tagExtended {
+		return 2
+	}
+	return 1
+}
+
+func (o *Object) isAppBoolean() bool {
+	return !o.TagClass && o.TagNumber == TagBoolean
+}
 
 // UnmarshalBinary sets the values retrieved from byte sequence in a Object frame.
 func (o *Object) UnmarshalBinary(b []byte) error {
@@ -37,13 +70,51 @@ func (o *Object) UnmarshalBinary(b []byte) error {
 	}
 	o.TagNumber = b[0] >> 4
 	o.TagClass = common.IntToBool(int(b[0]) & 0x8 >> 3)
-	o.Length = b[0] & 0x7
+	o.Length = uint32(b[0] & 0x7)
+	o.Data = nil
+
+	if o.isAppBoolean() {
+		return nil
+	}
+
+	offset := 1
+	if o.TagNumber == tagExtended {
+		if len(b) < offset+1 {
+			return common.ErrTooShortToParse
+		}
+		o.TagNumber = b[offset]
+		offset++
+	}
+	if uint8(o.Length) == lvtExtended {
+		if len(b) < offset+1 {
+			return common.ErrTooShortToParse
+		}
+		switch b[offset] {
+		case lvtExtended16:
+			if len(b) < offset+3 {
+				return common.ErrTooShortToParse
+			}
+			o.Length = uint32(binary.BigEndian.Uint16(b[offset+1:]))
+			offset += 3
+		case lvtExtended32:
+			if len(b) < offset+5 {
+				return common.ErrTooShortToParse
+			}
+			o.Length = binary.BigEndian.Uint32(b[offset+1:])
+			offset += 5
+		default:
+			o.Length = uint32(b[offset])
+			offset++
+		}
+	}
 
-	if l := len(b); l < int(o.Length) {
+	if l := len(b); l < offset+int(o.Length) {
 		return common.ErrTooShortToParse
 	}
 
-	o.Data = b[1:o.Length]
+	if o.Length > 0 {
+		o.Data = b[offset : offset+int(o.Length)]
+	}
 
 	return nil
 }
@@ -63,14 +134,59 @@ func (o *Object) MarshalTo(b []byte) error {
 	if len(b) < o.MarshalLen() {
 		return common.ErrTooShortToMarshalBinary
 	}
-	b[0] = o.TagNumber<<4 | uint8(common.BoolToInt(o.TagClass))<<3 | o.Length
+	b[0] = o.TagNumber<<4 | uint8(common.BoolToInt(o.TagClass))<<3
+
+	if o.isAppBoolean() {
+		b[0] |= uint8(o.Length) & 0x1
+		return nil
+	}
+
+	offset := 1
+	if o.TagNumber >= tagExtended {
+		b[0] = tagExtended<<4 | b[0]&0xF
+		b[offset] = o.TagNumber
+		offset++
+	}
+	switch l := int(o.Length); {
+	case l < lvtExtendedMin8:
+		b[0] |= uint8(l)
+	case l < lvtExtendedMin16:
+		b[0] |= lvtExtended
+		b[offset] = uint8(l)
+		offset++
+	case l < lvtExtendedMin32:
+		b[0] |= lvtExtended
+		b[offset] = lvtExtended16
+		binary.BigEndian.PutUint16(b[offset+1:], uint16(l))
+		offset += 3
+	default:
+		b[0] |= lvtExtended
+		b[offset] = lvtExtended32
+		binary.BigEndian.PutUint32(b[offset+1:], uint32(l))
+		offset += 5
+	}
+
 	if o.Length > 0 {
-		copy(b[1:o.Length+1], o.Data)
+		copy(b[offset:offset+int(o.Length)], o.Data)
 	}
 	return nil
 }
 
 // MarshalLen returns the serial length of Object.
 func (o *Object) MarshalLen() int {
-	return 1 + int(o.Length)
+	if o.isAppBoolean() {
+		return 1
+	}
+
+	l := tagLen(o.TagNumber) + int(o.Length)
+	switch {
+	case int(o.Length) < lvtExtendedMin8:
+	case int(o.Length) < lvtExtendedMin16:
+		l += 1
+	case int(o.Length) < lvtExtendedMin32:
+		l += 3
+	default:
+		l += 5
+	}
+	return l
 }
@@ -31,7 +31,7 @@ func DecObjectIdentifier(rawPayload APDUPayload) (ObjectIdentifier, error) {
 	}
 
 	joinedData := binary.BigEndian.Uint32(rawObject.Data)
-	decObjectId.ObjectType = uint16(joinedData & (uint32(0xFFC) << 20) >> 20)
+	decObjectId.ObjectType = uint16(joinedData >> 22)
 	decObjectId.InstanceNumber = uint32(joinedData & 0x3FFFFF)
 
 	return decObjectId, nil
@@ -46,7 +46,7 @@ func EncObjectIdentifier(contextTag bool, tagN uint8, objType uint16, instN uint
 	newObj.TagNumber = tagN
 	newObj.TagClass = contextTag
 	newObj.Data = data
-	newObj.Length = uint8(len(data))
+	newObj.Length = uint32(len(data))
 
 	return &newObj
 }
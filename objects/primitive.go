@@ -3,93 +3,138 @@ package objects
 import (
 	"encoding/binary"
 	"math"
+	"unicode/utf8"
 
 	"github.com/ulbios/bacnet/common"
 )
 
-func DecUnisgnedInteger(rawPayload APDUPayload) (uint32, error) {
+// Character sets for Character String values.
+const (
+	CharacterSetUTF8 uint8 = 0
+)
+
+// BitString is a sequence of bits where index 0 is the leftmost (i.e. first) bit.
+type BitString []bool
+
+// decObject checks the payload is an Object holding the given application tag. Context
+// tagged Objects are let through as their tag number is defined by the enclosing service.
+func decObject(rawPayload APDUPayload, tag uint8) (*Object, error) {
 	rawObject, ok := rawPayload.(*Object)
 	if !ok {
-		return 0, common.ErrWrongPayload
+		return nil, common.ErrWrongPayload
 	}
 
-	if rawObject.TagNumber != TagUnsignedInteger || rawObject.TagClass {
-		return 0, common.ErrWrongStructure
+	if !rawObject.TagClass && rawObject.TagNumber != tag {
+		return nil, common.ErrWrongStructure
 	}
 
-	switch rawObject.Length {
+	return rawObject, nil
+}
+
+func decUnsigned(data []byte) (uint32, error) {
+	switch len(data) {
 	case 1:
-		return uint32(rawObject.Data[0]), nil
+		return uint32(data[0]), nil
 	case 2:
-		return uint32(binary.BigEndian.Uint16(rawObject.Data)), nil
+		return uint32(binary.BigEndian.Uint16(data)), nil
 	case 3:
-		return uint32(uint16(uint32(rawObject.Data[0])<<16) | binary.BigEndian.Uint16(rawObject.Data[1:])), nil
+		return uint32(data[0])<<16 | uint32(binary.BigEndian.Uint16(data[1:])), nil
 	case 4:
-		return binary.BigEndian.Uint32(rawObject.Data), nil
+		return binary.BigEndian.Uint32(data), nil
 	}
 
 	return 0, common.ErrNotImplemented
 }
 
-func EncUnsignedInteger16(value uint16) *Object {
-	newObj := Object{}
+func encUnsigned(value uint32) []byte {
+	switch {
+	case value < 0x100:
+		return []byte{uint8(value)}
+	case value < 0x10000:
+		data := make([]byte, 2)
+		binary.BigEndian.PutUint16(data, uint16(value))
+		return data
+	case value < 0x1000000:
+		return []byte{uint8(value >> 16), uint8(value >> 8), uint8(value)}
+	}
+
+	data := make([]byte, 4)
+	binary.BigEndian.PutUint32(data, value)
+	return data
+}
 
-	data := make([]byte, 2)
-	binary.BigEndian.PutUint16(data[:], value)
+func DecUnisgnedInteger(rawPayload APDUPayload) (uint32, error) {
+	rawObject, err := decObject(rawPayload, TagUnsignedInteger)
+	if err != nil {
+		return 0, err
+	}
 
-	newObj.TagNumber = TagUnsignedInteger
-	newObj.TagClass = false
-	newObj.Data = data
-	newObj.Length = uint8(len(data))
+	return decUnsigned(rawObject.Data)
+}
 
-	return &newObj
+func EncUnsignedInteger16(value uint16) *Object {
+	return EncUnsignedInteger(uint32(value))
 }
 
-func DecEnumerated(rawPayload APDUPayload) (uint32, error) {
-	rawObject, ok := rawPayload.(*Object)
-	if !ok {
-		return 0, common.ErrWrongPayload
+func EncUnsignedInteger(value uint32) *Object {
+	return NewObject(TagUnsignedInteger, false, encUnsigned(value))
+}
+
+func DecSignedInteger(rawPayload APDUPayload) (int32, error) {
+	rawObject, err := decObject(rawPayload, TagSignedInteger)
+	if err != nil {
+		return 0, err
 	}
 
-	if rawObject.TagNumber != TagEnumerated || rawObject.TagClass {
-		return 0, common.ErrWrongStructure
+	if len(rawObject.Data) == 0 || len(rawObject.Data) > 4 {
+		return 0, common.ErrNotImplemented
 	}
 
-	switch rawObject.Length {
-	case 1:
-		return uint32(rawObject.Data[0]), nil
-	case 2:
-		return uint32(binary.BigEndian.Uint16(rawObject.Data)), nil
-	case 3:
-		return uint32(uint16(uint32(rawObject.Data[0])<<16) | binary.BigEndian.Uint16(rawObject.Data[1:])), nil
-	case 4:
-		return binary.BigEndian.Uint32(rawObject.Data), nil
+	// Sign extend the most significant octet.
+	value := int32(int8(rawObject.Data[0]))
+	for _, octet := range rawObject.Data[1:] {
+		value = value<<8 | int32(octet)
 	}
 
-	return 0, common.ErrNotImplemented
+	return value, nil
 }
 
-func EncEnumerated(value uint8) *Object {
-	newObj := Object{}
+func EncSignedInteger(value int32) *Object {
+	data := make([]byte, 4)
+	binary.BigEndian.PutUint32(data, uint32(value))
+
+	// Drop redundant sign octets.
+	for len(data) > 1 {
+		if (data[0] == 0x00 && data[1]&0x80 == 0) || (data[0] == 0xFF && data[1]&0x80 != 0) {
+			data = data[1:]
+			continue
+		}
+		break
+	}
+
+	return NewObject(TagSignedInteger, false, data)
+}
 
-	data := make([]byte, 1)
-	data[0] = value
+func DecEnumerated(rawPayload APDUPayload) (uint32, error) {
+	rawObject, err := decObject(rawPayload, TagEnumerated)
+	if err != nil {
+		return 0, err
+	}
 
-	newObj.TagNumber = TagEnumerated
-	newObj.TagClass = false
-	newObj.Data = data
-	newObj.Length = uint8(len(data))
+	return decUnsigned(rawObject.Data)
+}
 
-	return &newObj
+func EncEnumerated(value uint32) *Object {
+	return NewObject(TagEnumerated, false, encUnsigned(value))
 }
 
 func DecReal(rawPayload APDUPayload) (float32, error) {
-	rawObject, ok := rawPayload.(*Object)
-	if !ok {
-		return 0, common.ErrWrongPayload
+	rawObject, err := decObject(rawPayload, TagReal)
+	if err != nil {
+		return 0, err
 	}
 
-	if rawObject.TagNumber != TagReal {
+	if rawObject.Length != 4 {
 		return 0, common.ErrWrongStructure
 	}
 
@@ -97,39 +142,155 @@ func DecReal(rawPayload APDUPayload) (float32, error) {
 }
 
 func EncReal(value float32) *Object {
-	newObj := Object{}
-
 	data := make([]byte, 4)
 	binary.BigEndian.PutUint32(data[:], math.Float32bits(value))
 
-	newObj.TagNumber = TagReal
-	newObj.TagClass = false
-	newObj.Data = data
-	newObj.Length = uint8(len(data))
+	return NewObject(TagReal, false, data)
+}
+
+func DecDouble(rawPayload APDUPayload) (float64, error) {
+	rawObject, err := decObject(rawPayload, TagDouble)
+	if err != nil {
+		return 0, err
+	}
+
+	if rawObject.Length != 8 {
+		return 0, common.ErrWrongStructure
+	}
+
+	return math.Float64frombits(binary.BigEndian.Uint64(rawObject.Data)), nil
+}
+
+func EncDouble(value float64) *Object {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint64(data[:], math.Float64bits(value))
 
-	return &newObj
+	return NewObject(TagDouble, false, data)
 }
 
 func DecNull(rawPayload APDUPayload) (bool, error) {
-	rawObject, ok := rawPayload.(*Object)
-	if !ok {
-		return false, common.ErrWrongPayload
+	rawObject, err := decObject(rawPayload, TagNull)
+	if err != nil {
+		return false, err
+	}
+
+	return rawObject.Length == 0, nil
+}
+
+func EncNull() *Object {
+	return NewObject(TagNull, false, nil)
+}
+
+func DecBoolean(rawPayload APDUPayload) (bool, error) {
+	rawObject, err := decObject(rawPayload, TagBoolean)
+	if err != nil {
+		return false, err
+	}
+
+	// Application tagged booleans carry the value on the length field.
+	if !rawObject.TagClass {
+		return rawObject.Length == 1, nil
 	}
 
-	if rawObject.TagNumber != TagReal {
+	if rawObject.Length != 1 {
 		return false, common.ErrWrongStructure
 	}
 
-	return rawObject.TagNumber == TagNull && !rawObject.TagClass && rawObject.Length == 0, nil
+	return rawObject.Data[0] == 1, nil
 }
 
-func EncNull() *Object {
-	newObj := Object{}
+func EncBoolean(value bool) *Object {
+	return &Object{
+		TagNumber: TagBoolean,
+		TagClass:  false,
+		Length:    uint32(common.BoolToInt(value)),
+	}
+}
+
+func DecOctetString(rawPayload APDUPayload) ([]byte, error) {
+	rawObject, err := decObject(rawPayload, TagOctetString)
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte{}, rawObject.Data...), nil
+}
+
+func EncOctetString(value []byte) *Object {
+	return NewObject(TagOctetString, false, append([]byte{}, value...))
+}
+
+func DecCharacterString(rawPayload APDUPayload) (string, error) {
+	rawObject, err := decObject(rawPayload, TagCharacterString)
+	if err != nil {
+		return "", err
+	}
 
-	newObj.TagNumber = TagNull
-	newObj.TagClass = false
-	newObj.Data = nil
-	newObj.Length = 0
+	if rawObject.Length < 1 {
+		return "", common.ErrWrongStructure
+	}
+
+	if rawObject.Data[0] != CharacterSetUTF8 || !utf8.Valid(rawObject.Data[1:]) {
+		return "", common.ErrNotImplemented
+	}
 
-	return &newObj
+	return string(rawObject.Data[1:]), nil
+}
+
+func EncCharacterString(value string) *Object {
+	data := make([]byte, 1+len(value))
+	data[0] = CharacterSetUTF8
+	copy(data[1:], value)
+
+	return NewObject(TagCharacterString, false, data)
+}
+
+func DecBitString(rawPayload APDUPayload) (BitString, error) {
+	rawObject, err := decObject(rawPayload, TagBitString)
+	if err != nil {
+		return nil, err
+	}
+
+	if rawObject.Length < 1 || rawObject.Data[0] > 7 {
+		return nil, common.ErrWrongStructure
+	}
+
+	nBits := int(rawObject.Length-1)*8 - int(rawObject.Data[0])
+	if nBits < 0 {
+		return nil, common.ErrWrongStructure
+	}
+
+	bits := make(BitString, nBits)
+	for i := range bits {
+		bits[i] = rawObject.Data[1+i/8]&(0x80>>(i%8)) != 0
+	}
+
+	return bits, nil
+}
+
+func EncBitString(value BitString) *Object {
+	data := make([]byte, 1+(len(value)+7)/8)
+	data[0] = uint8((8 - len(value)%8) % 8)
+
+	for i, bit := range value {
+		if bit {
+			data[1+i/8] |= 0x80 >> (i % 8)
+		}
+	}
+
+	return NewObject(TagBitString, false, data)
+}
+
+// EncContext turns an application tagged Object into one context tagged with tagN.
+func EncContext(tagN uint8, appObject *Object) *Object {
+	if appObject.isAppBoolean() {
+		return NewObject(tagN, true, []byte{uint8(appObject.Length)})
+	}
+
+	return &Object{
+		TagNumber: tagN,
+		TagClass:  true,
+		Length:    appObject.Length,
+		Data:      appObject.Data,
+	}
 }
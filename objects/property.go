@@ -4,35 +4,39 @@ import (
 	"github.com/ulbios/bacnet/common"
 )
 
-func DecPropertyIdentifier(rawPayload APDUPayload) (uint8, error) {
+// ArrayAll is the array index signaling a request refers to the whole array.
+const ArrayAll uint32 = 0xFFFFFFFF
+
+func DecPropertyIdentifier(rawPayload APDUPayload) (uint32, error) {
 	rawObject, ok := rawPayload.(*Object)
 	if !ok {
 		return 0, common.ErrWrongPayload
 	}
 
-	switch rawObject.TagClass {
-	case true:
-		if rawObject.Length != 1 {
-			return 0, common.ErrWrongStructure
-		}
-	case false:
-		if rawObject.Length != 1 || !rawObject.TagClass {
-			return 0, common.ErrWrongStructure
-		}
+	if !rawObject.TagClass && rawObject.TagNumber != TagEnumerated {
+		return 0, common.ErrWrongStructure
 	}
 
-	return rawObject.Data[0], nil
+	return decUnsigned(rawObject.Data)
+}
+
+func EncPropertyIdentifier(contextTag bool, tagN uint8, propId uint32) *Object {
+	return NewObject(tagN, contextTag, encUnsigned(propId))
 }
 
-func EncPropertyIdentifier(contextTag bool, tagN uint8, propId uint8) *Object {
-	newObj := Object{}
-	data := make([]byte, 1)
-	data[0] = propId
+func DecArrayIndex(rawPayload APDUPayload) (uint32, error) {
+	rawObject, ok := rawPayload.(*Object)
+	if !ok {
+		return 0, common.ErrWrongPayload
+	}
+
+	if !rawObject.TagClass {
+		return 0, common.ErrWrongStructure
+	}
 
-	newObj.TagNumber = tagN
-	newObj.TagClass = contextTag
-	newObj.Data = data
-	newObj.Length = uint8(len(data))
+	return decUnsigned(rawObject.Data)
+}
 
-	return &newObj
+func EncArrayIndex(tagN uint8, index uint32) *Object {
+	return NewObject(tagN, true, encUnsigned(index))
 }
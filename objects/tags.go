@@ -26,8 +26,11 @@ func (n *NamedTag) UnmarshalBinary(b []byte) error {
 	n.TagClass = common.IntToBool(int(b[0]) & 0x8 >> 3)
 	n.Name = b[0] & 0x7
 
-	if l := len(b); l < 1 {
-		return common.ErrTooShortToParse
+	if n.TagNumber == tagExtended {
+		if l := len(b); l < 2 {
+			return common.ErrTooShortToParse
+		}
+		n.TagNumber = b[1]
 	}
 
 	return nil
@@ -47,12 +50,16 @@ func (n *NamedTag) MarshalTo(b []byte) error {
 		return common.ErrTooShortToMarshalBinary
 	}
 	b[0] = n.TagNumber<<4 | uint8(common.BoolToInt(n.TagClass))<<3 | n.Name
+	if n.TagNumber >= tagExtended {
+		b[0] = tagExtended<<4 | b[0]&0xF
+		b[1] = n.TagNumber
+	}
 
 	return nil
 }
 
 func (n *NamedTag) MarshalLen() int {
-	return 1
+	return tagLen(n.TagNumber)
 }
 
 func DecOpeningTab(rawPayload APDUPayload) (bool, error) {
@@ -90,3 +97,89 @@ func EncClosingTag(tagN uint8) *NamedTag {
 
 	return &cTag
 }
+
+// IsOpeningTag checks whether the payload is the opening tag tagN.
+func IsOpeningTag(rawPayload APDUPayload, tagN uint8) bool {
+	rawTag, ok := rawPayload.(*NamedTag)
+	return ok && rawTag.TagClass && rawTag.Name == 0x6 && rawTag.TagNumber == tagN
+}
+
+// IsClosingTag checks whether the payload is the closing tag tagN.
+func IsClosingTag(rawPayload APDUPayload, tagN uint8) bool {
+	rawTag, ok := rawPayload.(*NamedTag)
+	return ok && rawTag.TagClass && rawTag.Name == 0x7 && rawTag.TagNumber == tagN
+}
+
+// IsContextTag checks whether the payload is a primitive value context tagged with tagN.
+func IsContextTag(rawPayload APDUPayload, tagN uint8) bool {
+	rawObject, ok := rawPayload.(*Object)
+	return ok && rawObject.TagClass && rawObject.TagNumber == tagN
+}
+
+// DecEnclosed returns the payloads found between the opening tag tagN sitting at position
+// i and its matching closing tag, together with the position right after the latter.
+func DecEnclosed(rawPayloads []APDUPayload, i int, tagN uint8) ([]APDUPayload, int, error) {
+	if i >= len(rawPayloads) || !IsOpeningTag(rawPayloads[i], tagN) {
+		return nil, i, common.ErrWrongStructure
+	}
+
+	depth := 0
+	for j := i + 1; j < len(rawPayloads); j++ {
+		rawTag, ok := rawPayloads[j].(*NamedTag)
+		if !ok {
+			continue
+		}
+		switch rawTag.Name {
+		case 0x6:
+			depth++
+		case 0x7:
+			if depth == 0 {
+				if rawTag.TagNumber != tagN {
+					return nil, i, common.ErrWrongStructure
+				}
+				return rawPayloads[i+1 : j], j + 1, nil
+			}
+			depth--
+		}
+	}
+
+	return nil, i, common.ErrWrongStructure
+}
+
+// EncEnclosed wraps the given payloads between the opening and closing tags tagN.
+func EncEnclosed(tagN uint8, rawPayloads ...APDUPayload) []APDUPayload {
+	objs := make([]APDUPayload, 0, len(rawPayloads)+2)
+
+	objs = append(objs, EncOpeningTag(tagN))
+	objs = append(objs, rawPayloads...)
+	objs = append(objs, EncClosingTag(tagN))
+
+	return objs
+}
+
+// DecPayloads splits a byte sequence into the tagged payloads it's made of. Opening and
+// closing tags are returned as NamedTags whilst everything else becomes an Object.
+func DecPayloads(b []byte) ([]APDUPayload, error) {
+	objs := []APDUPayload{}
+
+	for offset := 0; offset < len(b); {
+		if b[offset]&0x8 != 0 && (b[offset]&0x7 == 0x6 || b[offset]&0x7 == 0x7) {
+			n := NamedTag{}
+			if err := n.UnmarshalBinary(b[offset:]); err != nil {
+				return nil, err
+			}
+			objs = append(objs, &n)
+			offset += n.MarshalLen()
+			continue
+		}
+
+		o := Object{}
+		if err := o.UnmarshalBinary(b[offset:]); err != nil {
+			return nil, err
+		}
+		objs = append(objs, &o)
+		offset += o.MarshalLen()
+	}
+
+	return objs, nil
+}
@@ -0,0 +1,127 @@
+package objects
+
+import (
+	"github.com/ulbios/bacnet/common"
+)
+
+// Enumerated is the Go counterpart of BACnet's Enumerated application type so that it
+// can be told apart from Unsigned Integers.
+type Enumerated uint32
+
+// EncValue encodes a Go value as an application tagged Object. The following mapping applies:
+//
+//	nil              -> Null
+//	bool             -> Boolean
+//	uint32           -> Unsigned Integer
+//	int32            -> Signed Integer
+//	float32          -> Real
+//	float64          -> Double
+//	[]byte           -> Octet String
+//	string           -> Character String
+//	BitString        -> Bit String
+//	Enumerated       -> Enumerated
+//	Date             -> Date
+//	Time             -> Time
+//	ObjectIdentifier -> BACnetObjectIdentifier
+func EncValue(value interface{}) (*Object, error) {
+	switch v := value.(type) {
+	case nil:
+		return EncNull(), nil
+	case bool:
+		return EncBoolean(v), nil
+	case uint32:
+		return EncUnsignedInteger(v), nil
+	case int32:
+		return EncSignedInteger(v), nil
+	case float32:
+		return EncReal(v), nil
+	case float64:
+		return EncDouble(v), nil
+	case []byte:
+		return EncOctetString(v), nil
+	case string:
+		return EncCharacterString(v), nil
+	case BitString:
+		return EncBitString(v), nil
+	case Enumerated:
+		return EncEnumerated(uint32(v)), nil
+	case Date:
+		return EncDate(v), nil
+	case Time:
+		return EncTime(v), nil
+	case ObjectIdentifier:
+		return EncObjectIdentifier(false, TagBACnetObjectIdentifier, v.ObjectType, v.InstanceNumber), nil
+	}
+
+	return nil, common.ErrNotImplemented
+}
+
+// DecValue decodes an application tagged Object into the Go type listed on EncValue.
+func DecValue(rawPayload APDUPayload) (interface{}, error) {
+	rawObject, ok := rawPayload.(*Object)
+	if !ok {
+		return nil, common.ErrWrongPayload
+	}
+
+	if rawObject.TagClass {
+		return nil, common.ErrWrongStructure
+	}
+
+	switch rawObject.TagNumber {
+	case TagNull:
+		return nil, nil
+	case TagBoolean:
+		return DecBoolean(rawObject)
+	case TagUnsignedInteger:
+		return DecUnisgnedInteger(rawObject)
+	case TagSignedInteger:
+		return DecSignedInteger(rawObject)
+	case TagReal:
+		return DecReal(rawObject)
+	case TagDouble:
+		return DecDouble(rawObject)
+	case TagOctetString:
+		return DecOctetString(rawObject)
+	case TagCharacterString:
+		return DecCharacterString(rawObject)
+	case TagBitString:
+		return DecBitString(rawObject)
+	case TagEnumerated:
+		v, err := DecEnumerated(rawObject)
+		return Enumerated(v), err
+	case TagDate:
+		return DecDate(rawObject)
+	case TagTime:
+		return DecTime(rawObject)
+	case TagBACnetObjectIdentifier:
+		return DecObjectIdentifier(rawObject)
+	}
+
+	return nil, common.ErrNotImplemented
+}
+
+// EncValues encodes a list of Go values with EncValue.
+func EncValues(values ...interface{}) ([]APDUPayload, error) {
+	objs := make([]APDUPayload, 0, len(values))
+	for _, value := range values {
+		obj, err := EncValue(value)
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, obj)
+	}
+	return objs, nil
+}
+
+// DecValues decodes a list of application tagged Objects with DecValue.
+func DecValues(rawPayloads []APDUPayload) ([]interface{}, error) {
+	values := make([]interface{}, 0, len(rawPayloads))
+	for _, rawPayload := range rawPayloads {
+		value, err := DecValue(rawPayload)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
@@ -12,6 +12,17 @@ func combine(t, s uint8) uint16 {
 	return uint16(t)<<8 | uint16(s)
 }
 
+// apduHeaderLen returns the length of the fixed part of an APDU of the given type.
+func apduHeaderLen(t uint8) int {
+	switch t {
+	case plumbing.ConfirmedReq:
+		return 4
+	case plumbing.ComplexAck, plumbing.SimpleAck, plumbing.Error, plumbing.Reject, plumbing.Abort:
+		return 3
+	}
+	return 2
+}
+
 // Parse decodes the given bytes.
 func Parse(b []byte) (plumbing.BACnet, error) {
 	if len(b) < bacnetLenMin {
@@ -34,14 +45,18 @@ func Parse(b []byte) (plumbing.BACnet, error) {
 	}
 	offset += npdu.MarshalLen()
 
+	if len(b) <= offset || len(b) < offset+apduHeaderLen(b[offset]>>4) {
+		return nil, common.ErrTooShortToParse
+	}
+
 	var c uint16
 	switch b[offset] >> 4 & 0xFF {
 	case plumbing.UnConfirmedReq:
 		c = combine(b[offset], b[offset+1])
 	case plumbing.ConfirmedReq:
-		c = combine(b[offset], b[offset+3]) // We need to skip the PDU flags and the InvokeID
-	case plumbing.ComplexAck, plumbing.SimpleAck, plumbing.Error:
-		c = combine(b[offset], 0) // We need to skip the PDU flags and the InvokeID
+		c = combine(b[offset]&0xF0, b[offset+3]) // We need to skip the PDU flags and the InvokeID
+	case plumbing.ComplexAck, plumbing.SimpleAck, plumbing.Error, plumbing.Reject, plumbing.Abort:
+		c = combine(b[offset]&0xF0, 0) // We need to skip the PDU flags and the InvokeID
 	}
 
 	switch c {
@@ -49,16 +64,76 @@ func Parse(b []byte) (plumbing.BACnet, error) {
 		bacnet = services.NewUnconfirmedWhoIs(&bvlc, &npdu)
 	case combine(plumbing.UnConfirmedReq<<4, services.ServiceUnconfirmedIAm):
 		bacnet = services.NewUnconfirmedIAm(&bvlc, &npdu)
+	case combine(plumbing.UnConfirmedReq<<4, services.ServiceUnconfirmedWhoHas):
+		bacnet = services.NewUnconfirmedWhoHas(&bvlc, &npdu)
+	case combine(plumbing.UnConfirmedReq<<4, services.ServiceUnconfirmedIHave):
+		bacnet = services.NewUnconfirmedIHave(&bvlc, &npdu)
+	case combine(plumbing.UnConfirmedReq<<4, services.ServiceUnconfirmedTimeSync):
+		bacnet = services.NewUnconfirmedTimeSynchronization(&bvlc, &npdu)
+	case combine(plumbing.UnConfirmedReq<<4, services.ServiceUnconfirmedUTCTimeSync):
+		bacnet = services.NewUnconfirmedUTCTimeSynchronization(&bvlc, &npdu)
+	case combine(plumbing.UnConfirmedReq<<4, services.ServiceUnconfirmedCOVNotification):
+		bacnet = services.NewUnconfirmedCOVNotification(&bvlc, &npdu)
+	case combine(plumbing.UnConfirmedReq<<4, services.ServiceUnconfirmedCOVNotificationMultiple):
+		bacnet = services.NewUnconfirmedCOVNotificationMultiple(&bvlc, &npdu)
+	case combine(plumbing.UnConfirmedReq<<4, services.ServiceUnconfirmedEventNotification):
+		bacnet = services.NewUnconfirmedEventNotification(&bvlc, &npdu)
+	case combine(plumbing.ConfirmedReq<<4, services.ServiceConfirmedSubscribeCOV):
+		bacnet = services.NewConfirmedSubscribeCOV(&bvlc, &npdu)
+	case combine(plumbing.ConfirmedReq<<4, services.ServiceConfirmedCOVNotification):
+		bacnet = services.NewConfirmedCOVNotification(&bvlc, &npdu)
+	case combine(plumbing.ConfirmedReq<<4, services.ServiceConfirmedSubscribeCOVProperty):
+		bacnet = services.NewConfirmedSubscribeCOVProperty(&bvlc, &npdu)
+	case combine(plumbing.ConfirmedReq<<4, services.ServiceConfirmedSubscribeCOVPropertyMultiple):
+		bacnet = services.NewConfirmedSubscribeCOVPropertyMultiple(&bvlc, &npdu)
+	case combine(plumbing.ConfirmedReq<<4, services.ServiceConfirmedCOVNotificationMultiple):
+		bacnet = services.NewConfirmedCOVNotificationMultiple(&bvlc, &npdu)
+	case combine(plumbing.ConfirmedReq<<4, services.ServiceConfirmedEventNotification):
+		bacnet = services.NewConfirmedEventNotification(&bvlc, &npdu)
+	case combine(plumbing.ConfirmedReq<<4, services.ServiceConfirmedAcknowledgeAlarm):
+		bacnet = services.NewConfirmedAcknowledgeAlarm(&bvlc, &npdu)
+	case combine(plumbing.ConfirmedReq<<4, services.ServiceConfirmedGetEventInformation):
+		bacnet = services.NewConfirmedGetEventInformation(&bvlc, &npdu)
+	case combine(plumbing.ConfirmedReq<<4, services.ServiceConfirmedGetAlarmSummary):
+		bacnet = services.NewConfirmedGetAlarmSummary(&bvlc, &npdu)
+	case combine(plumbing.ConfirmedReq<<4, services.ServiceConfirmedGetEnrollmentSummary):
+		bacnet = services.NewConfirmedGetEnrollmentSummary(&bvlc, &npdu)
 	case combine(plumbing.ConfirmedReq<<4, services.ServiceConfirmedReadProperty):
 		bacnet = services.NewConfirmedReadProperty(&bvlc, &npdu)
 	case combine(plumbing.ConfirmedReq<<4, services.ServiceConfirmedWriteProperty):
 		bacnet = services.NewConfirmedWriteProperty(&bvlc, &npdu)
+	case combine(plumbing.ConfirmedReq<<4, services.ServiceConfirmedReadPropMultiple):
+		bacnet = services.NewConfirmedReadPropertyMultiple(&bvlc, &npdu)
+	case combine(plumbing.ConfirmedReq<<4, services.ServiceConfirmedWritePropMultiple):
+		bacnet = services.NewConfirmedWritePropertyMultiple(&bvlc, &npdu)
+	case combine(plumbing.ConfirmedReq<<4, services.ServiceConfirmedDeviceCommunicationControl):
+		bacnet = services.NewConfirmedDeviceCommunicationControl(&bvlc, &npdu)
+	case combine(plumbing.ConfirmedReq<<4, services.ServiceConfirmedAtomicReadFile):
+		bacnet = services.NewConfirmedAtomicReadFile(&bvlc, &npdu)
+	case combine(plumbing.ConfirmedReq<<4, services.ServiceConfirmedAtomicWriteFile):
+		bacnet = services.NewConfirmedAtomicWriteFile(&bvlc, &npdu)
+	case combine(plumbing.ConfirmedReq<<4, services.ServiceConfirmedReinitializeDevice):
+		bacnet = services.NewConfirmedReinitializeDevice(&bvlc, &npdu)
+	case combine(plumbing.ConfirmedReq<<4, services.ServiceConfirmedCreateObject):
+		bacnet = services.NewConfirmedCreateObject(&bvlc, &npdu)
+	case combine(plumbing.ConfirmedReq<<4, services.ServiceConfirmedDeleteObject):
+		bacnet = services.NewConfirmedDeleteObject(&bvlc, &npdu)
+	case combine(plumbing.ConfirmedReq<<4, services.ServiceConfirmedAddListElement):
+		bacnet = services.NewConfirmedAddListElement(&bvlc, &npdu)
+	case combine(plumbing.ConfirmedReq<<4, services.ServiceConfirmedRemoveListElement):
+		bacnet = services.NewConfirmedRemoveListElement(&bvlc, &npdu)
+	case combine(plumbing.ConfirmedReq<<4, services.ServiceConfirmedReadRange):
+		bacnet = services.NewConfirmedReadRange(&bvlc, &npdu)
 	case combine(plumbing.ComplexAck<<4, 0):
 		bacnet = services.NewComplexACK(&bvlc, &npdu)
 	case combine(plumbing.SimpleAck<<4, 0):
 		bacnet = services.NewSimpleACK(&bvlc, &npdu)
 	case combine(plumbing.Error<<4, 0):
 		bacnet = services.NewError(&bvlc, &npdu)
+	case combine(plumbing.Reject<<4, 0):
+		bacnet = services.NewReject(&bvlc, &npdu)
+	case combine(plumbing.Abort<<4, 0):
+		bacnet = services.NewAbort(&bvlc, &npdu)
 	default:
 		return nil, common.ErrNotImplemented
 	}
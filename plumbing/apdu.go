@@ -27,7 +27,7 @@ func NewAPDU(t, s uint8, objs []objects.APDUPayload) *APDU {
 
 // UnmarshalBinary sets the values retrieved from byte sequence in a APDU frame.
 func (a *APDU) UnmarshalBinary(b []byte) error {
-	if l := len(b); l < a.MarshalLen() {
+	if l := len(b); l < apduLenMin {
 		return common.ErrTooShortToParse
 	}
 
@@ -40,92 +40,36 @@ func (a *APDU) UnmarshalBinary(b []byte) error {
 	case UnConfirmedReq:
 		a.Service = b[offset]
 		offset++
-		if len(b) > 2 {
-			objs := []objects.APDUPayload{}
-			for {
-				o := objects.Object{
-					TagNumber: b[offset] >> 4,
-					TagClass:  common.IntToBool(int(b[offset]) & 0x8 >> 3),
-					Length:    b[offset] & 0x7,
-				}
-
-				o.Data = b[offset+1 : offset+int(o.Length)+1]
-				objs = append(objs, &o)
-				offset += int(o.Length) + 1
-
-				if offset >= len(b) {
-					break
-				}
-			}
-			a.Objects = objs
-		}
 	case ConfirmedReq:
+		if l := len(b); l < 4 {
+			return common.ErrTooShortToParse
+		}
+		a.MaxSeg = b[offset] >> 4 & 0x7
+		a.MaxSize = b[offset] & 0xF
 		offset++
 		a.InvokeID = b[offset]
 		offset++
 		a.Service = b[offset]
 		offset++
-		if len(b) > 2 {
-			objs := []objects.APDUPayload{}
-			for {
-				o := objects.Object{
-					TagNumber: b[offset] >> 4,
-					TagClass:  common.IntToBool(int(b[offset]) & 0x8 >> 3),
-					Length:    b[offset] & 0x7,
-				}
-
-				// Drop tags so that they don't get in the way!
-				if b[offset] == objects.TagOpening || b[offset] == objects.TagClosing {
-					offset++
-					if offset >= len(b) {
-						break
-					}
-					continue
-				}
-
-				o.Data = b[offset+1 : offset+int(o.Length)+1]
-				objs = append(objs, &o)
-				offset += int(o.Length) + 1
-
-				if offset >= len(b) {
-					break
-				}
-			}
-			a.Objects = objs
+	case ComplexAck, SimpleAck, Error, Reject, Abort:
+		if l := len(b); l < 3 {
+			return common.ErrTooShortToParse
 		}
-	case ComplexAck, SimpleAck, Error:
 		a.InvokeID = b[offset]
 		offset++
 		a.Service = b[offset]
 		offset++
-		if len(b) > 3 {
-			objs := []objects.APDUPayload{}
-			for {
-				o := objects.Object{
-					TagNumber: b[offset] >> 4,
-					TagClass:  common.IntToBool(int(b[offset]) & 0x8 >> 3),
-					Length:    b[offset] & 0x7,
-				}
-
-				// Drop tags so that they don't get in the way!
-				if b[offset] == objects.TagOpening || b[offset] == objects.TagClosing {
-					offset++
-					if offset >= len(b) {
-						break
-					}
-					continue
-				}
-
-				o.Data = b[offset+1 : offset+int(o.Length)+1]
-				objs = append(objs, &o)
-				offset += int(o.Length) + 1
+	default:
+		return common.ErrNotImplemented
+	}
 
-				if offset >= len(b) {
-					break
-				}
-			}
-			a.Objects = objs
+	a.Objects = nil
+	if offset < len(b) {
+		objs, err := objects.DecPayloads(b[offset:])
+		if err != nil {
+			return err
 		}
+		a.Objects = objs
 	}
 
 	return nil
@@ -160,12 +104,12 @@ func (a *APDU) MarshalTo(b []byte) error {
 				}
 			}
 		}
-	case ComplexAck, SimpleAck, Error:
+	case ComplexAck, SimpleAck, Error, Reject, Abort:
 		b[offset] = a.InvokeID
 		offset++
 		b[offset] = a.Service
 		offset++
-		if a.MarshalLen() > 4 {
+		if a.MarshalLen() > 3 {
 			for _, o := range a.Objects {
 				ob, err := o.MarshalBinary()
 				if err != nil {
@@ -207,13 +151,15 @@ func (a *APDU) MarshalTo(b []byte) error {
 	return nil
 }
 
+const apduLenMin = 2
+
 // MarshalLen returns the serial length of APDU.
 func (a *APDU) MarshalLen() int {
 	var l int = 0
 	switch a.Type {
 	case ConfirmedReq:
 		l += 4
-	case ComplexAck, SimpleAck, Error:
+	case ComplexAck, SimpleAck, Error, Reject, Abort:
 		l += 3
 	case UnConfirmedReq:
 		l += 2
@@ -231,3 +177,8 @@ func (a *APDU) SetAPDUFlags(sa, moreSegments, segmentedReq bool) {
 		common.BoolToInt(sa)<<1 | common.BoolToInt(moreSegments)<<2 | common.BoolToInt(segmentedReq)<<3,
 	)
 }
+
+// GetAPDU returns the APDU itself so that it can be reached through the BACnet interface.
+func (a *APDU) GetAPDU() *APDU {
+	return a
+}
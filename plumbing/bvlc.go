@@ -70,3 +70,8 @@ func (bvlc *BVLC) MarshalTo(b []byte) error {
 	binary.BigEndian.PutUint16(b[2:4], bvlc.Length)
 	return nil
 }
+
+// GetBVLC returns the BVLC itself so that it can be reached through the BACnet interface.
+func (bvlc *BVLC) GetBVLC() *BVLC {
+	return bvlc
+}
@@ -18,3 +18,57 @@ const (
 	MoreSegments
 	SegmentedRequest
 )
+
+// Reasons carried on Reject PDUs.
+const (
+	RejectReasonOther uint8 = iota
+	RejectReasonBufferOverflow
+	RejectReasonInconsistentParameters
+	RejectReasonInvalidParameterDataType
+	RejectReasonInvalidTag
+	RejectReasonMissingRequiredParameter
+	RejectReasonParameterOutOfRange
+	RejectReasonTooManyArguments
+	RejectReasonUndefinedEnumeration
+	RejectReasonUnrecognizedService
+)
+
+// Reasons carried on Abort PDUs.
+const (
+	AbortReasonOther uint8 = iota
+	AbortReasonBufferOverflow
+	AbortReasonInvalidAPDUInThisState
+	AbortReasonPreemptedByHigherPriorityTask
+	AbortReasonSegmentationNotSupported
+	AbortReasonSecurityError
+	AbortReasonInsufficientSecurity
+	AbortReasonWindowSizeOutOfRange
+	AbortReasonApplicationExceededReplyTime
+	AbortReasonOutOfResources
+	AbortReasonTSMTimeout
+	AbortReasonAPDUTooLong
+)
+
+// MaxAPDULengthIP is the largest APDU BACnet/IP can carry.
+const MaxAPDULengthIP = 1476
+
+var maxAPDULengths = []int{50, 128, 206, 480, 1024, 1476}
+
+// DecMaxAPDU returns the length in octets encoded on the MaxSize field of confirmed requests.
+func DecMaxAPDU(maxSize uint8) int {
+	if int(maxSize) >= len(maxAPDULengths) {
+		return maxAPDULengths[0]
+	}
+	return maxAPDULengths[maxSize]
+}
+
+// EncMaxAPDU returns the MaxSize field value for the largest APDU not exceeding length octets.
+func EncMaxAPDU(length int) uint8 {
+	var maxSize uint8
+	for i, l := range maxAPDULengths {
+		if l <= length {
+			maxSize = uint8(i)
+		}
+	}
+	return maxSize
+}
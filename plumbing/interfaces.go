@@ -12,10 +12,14 @@ type Message interface {
 	MarshalLen() int
 }
 
-// BACnet is an interface defines BACnet messages.
+// BACnet is an interface defines BACnet messages. Every message is made up of a
+// BVLC, an NPDU and an APDU which can be retrieved with the Get* methods.
 type BACnet interface {
 	MarshalBinary() ([]byte, error)
 	MarshalTo([]byte) error
 	UnmarshalBinary([]byte) error
 	MarshalLen() int
+	GetBVLC() *BVLC
+	GetNPDU() *NPDU
+	GetAPDU() *APDU
 }
@@ -6,12 +6,22 @@ import (
 	"github.com/ulbios/bacnet/common"
 )
 
+// NPDU control bits flagging the presence of the destination and source specifiers.
+const (
+	npduDstSpecifier = 1 << 5
+	npduSrcSpecifier = 1 << 3
+)
+
 // NPDU is a Network Protocol Data Units.
 type NPDU struct {
 	Version uint8
 	Control uint8
 	DNET    uint16
 	DLEN    uint8
+	DADR    []byte
+	SNET    uint16
+	SLEN    uint8
+	SADR    []byte
 	Hop     uint8
 }
 
@@ -32,17 +42,82 @@ func (n *NPDU) SetControlFlags(nsduContain bool, dstSpecifier bool, srcSpecifier
 	)
 }
 
+// HasDestination tells whether the NPDU carries a destination network.
+func (n *NPDU) HasDestination() bool {
+	return n.Control&npduDstSpecifier != 0
+}
+
+// HasSource tells whether the NPDU carries a source network.
+func (n *NPDU) HasSource() bool {
+	return n.Control&npduSrcSpecifier != 0
+}
+
+// SetDestination addresses the NPDU to the device with MAC address dadr on network dnet, an
+// empty dadr broadcasting on it. Routers may forward it up to 255 times.
+func (n *NPDU) SetDestination(dnet uint16, dadr []byte) {
+	n.Control |= npduDstSpecifier
+	n.DNET = dnet
+	n.DLEN = uint8(len(dadr))
+	n.DADR = dadr
+	n.Hop = 0xFF
+}
+
+// SetSource records the network and MAC address the NPDU originates from.
+func (n *NPDU) SetSource(snet uint16, sadr []byte) {
+	n.Control |= npduSrcSpecifier
+	n.SNET = snet
+	n.SLEN = uint8(len(sadr))
+	n.SADR = sadr
+}
+
 // UnmarshalBinary sets the values retrieved from byte sequence in a NPDU frame.
 func (n *NPDU) UnmarshalBinary(b []byte) error {
-	if l := len(b); l < n.MarshalLen() {
+	if len(b) < npduLenMin {
 		return common.ErrTooShortToParse
 	}
 	n.Version = b[0]
 	n.Control = b[1]
-	if flagDNET := n.Control & 0x20 >> 5; flagDNET == 1 {
-		n.DNET = binary.BigEndian.Uint16(b[2:4])
-		n.DLEN = b[4]
-		n.Hop = b[5]
+
+	offset := npduLenMin
+	if n.HasDestination() {
+		if len(b) < offset+3 {
+			return common.ErrTooShortToParse
+		}
+		n.DNET = binary.BigEndian.Uint16(b[offset:])
+		n.DLEN = b[offset+2]
+		offset += 3
+
+		if len(b) < offset+int(n.DLEN) {
+			return common.ErrTooShortToParse
+		}
+		n.DADR = nil
+		if n.DLEN > 0 {
+			n.DADR = append([]byte{}, b[offset:offset+int(n.DLEN)]...)
+		}
+		offset += int(n.DLEN)
+	}
+	if n.HasSource() {
+		if len(b) < offset+3 {
+			return common.ErrTooShortToParse
+		}
+		n.SNET = binary.BigEndian.Uint16(b[offset:])
+		n.SLEN = b[offset+2]
+		offset += 3
+
+		if len(b) < offset+int(n.SLEN) {
+			return common.ErrTooShortToParse
+		}
+		n.SADR = nil
+		if n.SLEN > 0 {
+			n.SADR = append([]byte{}, b[offset:offset+int(n.SLEN)]...)
+		}
+		offset += int(n.SLEN)
+	}
+	if n.HasDestination() {
+		if len(b) < offset+1 {
+			return common.ErrTooShortToParse
+		}
+		n.Hop = b[offset]
 	}
 
 	return nil
@@ -55,10 +130,22 @@ func (n *NPDU) MarshalTo(b []byte) error {
 	}
 	b[0] = n.Version
 	b[1] = n.Control
-	if flagDNET := n.Control & 0x20 >> 5; flagDNET == 1 {
-		binary.BigEndian.PutUint16(b[2:4], n.DNET)
-		b[4] = n.DLEN
-		b[5] = n.Hop
+
+	offset := npduLenMin
+	if n.HasDestination() {
+		binary.BigEndian.PutUint16(b[offset:], n.DNET)
+		b[offset+2] = n.DLEN
+		offset += 3
+		offset += copy(b[offset:], n.DADR[:n.DLEN])
+	}
+	if n.HasSource() {
+		binary.BigEndian.PutUint16(b[offset:], n.SNET)
+		b[offset+2] = n.SLEN
+		offset += 3
+		offset += copy(b[offset:], n.SADR[:n.SLEN])
+	}
+	if n.HasDestination() {
+		b[offset] = n.Hop
 	}
 	return nil
 }
@@ -67,9 +154,17 @@ const npduLenMin = 2
 
 // MarshalLen returns the serial length of NPDU.
 func (n *NPDU) MarshalLen() int {
-	flagDNET := n.Control & 0x20 >> 5
-	if flagDNET == 1 {
-		return npduLenMin + 4
+	l := npduLenMin
+	if n.HasDestination() {
+		l += 3 + int(n.DLEN) + 1
+	}
+	if n.HasSource() {
+		l += 3 + int(n.SLEN)
 	}
-	return npduLenMin
+	return l
+}
+
+// GetNPDU returns the NPDU itself so that it can be reached through the BACnet interface.
+func (n *NPDU) GetNPDU() *NPDU {
+	return n
 }
@@ -0,0 +1,78 @@
+package plumbing
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestNPDU(t *testing.T) {
+	for _, tc := range []struct {
+		description string
+		npdu        *NPDU
+		serialized  []byte
+	}{
+		{
+			description: "Local",
+			npdu:        NewNPDU(false, false, false, true),
+			serialized:  []byte{0x01, 0x04},
+		},
+		{
+			description: "Global broadcast",
+			npdu:        &NPDU{Version: 1, Control: 0x20, DNET: 0xFFFF, Hop: 0xFF},
+			serialized:  []byte{0x01, 0x20, 0xff, 0xff, 0x00, 0xff},
+		},
+		{
+			description: "Routed destination",
+			npdu:        &NPDU{Version: 1, Control: 0x24, DNET: 5, DLEN: 1, DADR: []byte{0x0a}, Hop: 0xFF},
+			serialized:  []byte{0x01, 0x24, 0x00, 0x05, 0x01, 0x0a, 0xff},
+		},
+		{
+			description: "Routed source",
+			npdu:        &NPDU{Version: 1, Control: 0x08, SNET: 5, SLEN: 2, SADR: []byte{0x0a, 0x0b}},
+			serialized:  []byte{0x01, 0x08, 0x00, 0x05, 0x02, 0x0a, 0x0b},
+		},
+		{
+			description: "Routed destination and source",
+			npdu: &NPDU{
+				Version: 1, Control: 0x28,
+				DNET: 7, DLEN: 1, DADR: []byte{0x01},
+				SNET: 5, SLEN: 1, SADR: []byte{0x0a},
+				Hop: 0xFE,
+			},
+			serialized: []byte{0x01, 0x28, 0x00, 0x07, 0x01, 0x01, 0x00, 0x05, 0x01, 0x0a, 0xfe},
+		},
+	} {
+		t.Run(tc.description, func(t *testing.T) {
+			if l := tc.npdu.MarshalLen(); l != len(tc.serialized) {
+				t.Errorf("got length %d, want %d", l, len(tc.serialized))
+			}
+
+			b := make([]byte, tc.npdu.MarshalLen())
+			if err := tc.npdu.MarshalTo(b); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(b, tc.serialized) {
+				t.Errorf("serialized as %x, want %x", b, tc.serialized)
+			}
+
+			var n NPDU
+			if err := n.UnmarshalBinary(tc.serialized); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(&n, tc.npdu) {
+				t.Errorf("decoded as %+v, want %+v", n, *tc.npdu)
+			}
+
+			if err := n.UnmarshalBinary(tc.serialized[:len(tc.serialized)-1]); err == nil && len(tc.serialized) > npduLenMin {
+				t.Errorf("decoded a truncated NPDU")
+			}
+		})
+	}
+
+	n := NewNPDU(false, false, false, true)
+	n.SetDestination(5, []byte{0x0a})
+	if !n.HasDestination() || n.HasSource() || n.Control != 0x24 || n.Hop != 0xFF {
+		t.Errorf("unexpected NPDU %+v after setting its destination", *n)
+	}
+}
@@ -0,0 +1,157 @@
+package plumbing
+
+import (
+	"bytes"
+	"net"
+	"sync"
+	"time"
+)
+
+// Default values for the APDU_Timeout and Number_Of_APDU_Retries device properties.
+const (
+	DefaultAPDUTimeout = 3 * time.Second
+	DefaultAPDURetries = 3
+)
+
+// Server transaction states. Check Clause 5.4.5 of the standard for the details.
+const (
+	TransactionIdle uint8 = iota
+	TransactionAwaitResponse
+	TransactionResponded
+)
+
+// TransactionKey identifies a confirmed request received from a peer.
+type TransactionKey struct {
+	Peer     string
+	InvokeID uint8
+	Service  uint8
+}
+
+type serverTransaction struct {
+	state    uint8
+	request  []byte
+	response []byte
+	expires  time.Time
+}
+
+// ServerTSM is the transaction state machine of the responding BACnet user. It detects
+// retries of confirmed requests so that they are not executed twice: while a request is
+// being served its duplicates are dropped and, once answered, the cached response is
+// sent back instead. Requests reusing the invoke ID of a remembered transaction are only
+// taken as retries if they are identical, as busy clients may run through every invoke ID
+// within the window.
+type ServerTSM struct {
+	Window time.Duration
+
+	mu           sync.Mutex
+	transactions map[TransactionKey]*serverTransaction
+	now          func() time.Time
+}
+
+// NewServerTSM creates a ServerTSM remembering transactions for the given window. A
+// zero window defaults to the time a client takes to give up on a request.
+func NewServerTSM(window time.Duration) *ServerTSM {
+	if window == 0 {
+		window = DefaultAPDUTimeout * (DefaultAPDURetries + 1)
+	}
+	return &ServerTSM{
+		Window:       window,
+		transactions: map[TransactionKey]*serverTransaction{},
+		now:          time.Now,
+	}
+}
+
+// NewTransactionKey creates the TransactionKey of the APDU received from peer.
+func NewTransactionKey(peer net.Addr, a *APDU) TransactionKey {
+	return TransactionKey{
+		Peer:     peer.String(),
+		InvokeID: a.InvokeID,
+		Service:  a.Service,
+	}
+}
+
+// Begin registers the reception of the given APDU. It returns the state the transaction
+// was in together with the cached response, if any. Callers should only serve requests
+// for which TransactionIdle is returned. Unconfirmed requests are never tracked.
+func (t *ServerTSM) Begin(peer net.Addr, a *APDU) (uint8, []byte) {
+	if a.Type != ConfirmedReq {
+		return TransactionIdle, nil
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	t.expire(now)
+
+	key := NewTransactionKey(peer, a)
+	request := marshalAPDU(a)
+	if tr, ok := t.transactions[key]; ok && bytes.Equal(tr.request, request) {
+		return tr.state, tr.response
+	}
+
+	t.transactions[key] = &serverTransaction{
+		state:   TransactionAwaitResponse,
+		request: request,
+		expires: now.Add(t.Window),
+	}
+
+	return TransactionIdle, nil
+}
+
+// Complete stores the response sent back for the given APDU so that it can be resent
+// if the request is retried within the window.
+func (t *ServerTSM) Complete(peer net.Addr, a *APDU, response []byte) {
+	if a.Type != ConfirmedReq {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	key := NewTransactionKey(peer, a)
+	request := marshalAPDU(a)
+	t.transactions[key] = &serverTransaction{
+		state:    TransactionResponded,
+		request:  request,
+		response: append([]byte(nil), response...),
+		expires:  now.Add(t.Window),
+	}
+}
+
+// Abandon forgets about the given APDU so that a retry is served again. It should be
+// called when a request could not be answered.
+func (t *ServerTSM) Abandon(peer net.Addr, a *APDU) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.transactions, NewTransactionKey(peer, a))
+}
+
+// Len returns the number of transactions being tracked.
+func (t *ServerTSM) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.expire(t.now())
+
+	return len(t.transactions)
+}
+
+func (t *ServerTSM) expire(now time.Time) {
+	for key, tr := range t.transactions {
+		if !now.Before(tr.expires) {
+			delete(t.transactions, key)
+		}
+	}
+}
+
+// marshalAPDU serializes a request so that its retries can be told apart from new requests.
+func marshalAPDU(a *APDU) []byte {
+	b := make([]byte, a.MarshalLen())
+	if err := a.MarshalTo(b); err != nil {
+		return nil
+	}
+	return b
+}
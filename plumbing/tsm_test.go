@@ -0,0 +1,59 @@
+package plumbing
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/ulbios/bacnet/objects"
+)
+
+func TestServerTSM(t *testing.T) {
+	now := time.Unix(0, 0)
+	tsm := NewServerTSM(time.Second)
+	tsm.now = func() time.Time { return now }
+
+	peer := &net.UDPAddr{IP: net.IPv4(10, 0, 123, 2), Port: 47808}
+	req := &APDU{Type: ConfirmedReq, InvokeID: 7, Service: 15}
+	resp := []byte{0x81, 0x0a, 0x00, 0x09, 0x01, 0x00, 0x20, 0x07, 0x0f}
+
+	if state, _ := tsm.Begin(peer, req); state != TransactionIdle {
+		t.Fatalf("new request reported as %d", state)
+	}
+	if state, _ := tsm.Begin(peer, req); state != TransactionAwaitResponse {
+		t.Fatalf("retry of an in-flight request reported as %d", state)
+	}
+
+	tsm.Complete(peer, req, resp)
+	state, cached := tsm.Begin(peer, req)
+	if state != TransactionResponded {
+		t.Fatalf("retry of an answered request reported as %d", state)
+	}
+	if string(cached) != string(resp) {
+		t.Errorf("cached response differs: want %x got %x", resp, cached)
+	}
+
+	reused := &APDU{Type: ConfirmedReq, InvokeID: 7, Service: 15, Objects: []objects.APDUPayload{objects.EncUnsignedInteger(1)}}
+	if state, _ := tsm.Begin(peer, reused); state != TransactionIdle {
+		t.Errorf("a different request reusing the invoke ID reported as %d", state)
+	}
+
+	other := &APDU{Type: ConfirmedReq, InvokeID: 7, Service: 12}
+	if state, _ := tsm.Begin(peer, other); state != TransactionIdle {
+		t.Errorf("a different service shares the transaction: %d", state)
+	}
+
+	unconfirmed := &APDU{Type: UnConfirmedReq, Service: 8}
+	tsm.Begin(peer, unconfirmed)
+	if state, _ := tsm.Begin(peer, unconfirmed); state != TransactionIdle {
+		t.Errorf("unconfirmed requests are being tracked: %d", state)
+	}
+
+	now = now.Add(time.Second)
+	if n := tsm.Len(); n != 0 {
+		t.Errorf("%d transactions survived the window", n)
+	}
+	if state, _ := tsm.Begin(peer, req); state != TransactionIdle {
+		t.Errorf("expired request reported as %d", state)
+	}
+}
@@ -0,0 +1,139 @@
+package bacnet
+
+import (
+	"context"
+	"math"
+	"net"
+
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+	"github.com/ulbios/bacnet/services"
+)
+
+// readRangeOverhead is the longest encoding of what surrounds the item data of ReadRange
+// ACKs: the object, property and array index, the result flags, the item count, the tags
+// enclosing the items and the first sequence number.
+const readRangeOverhead = 5 + 5 + 5 + 3 + 5 + 2 + 5
+
+// LogIterator pages through every record of a log, such as the Log_Buffer of a Trend Log,
+// of a device bound in Devices. Records are read by sequence number, so pages don't skip
+// nor repeat records when the log moves on between requests. The device sends as many
+// records as fit in a single APDU on each page.
+type LogIterator struct {
+	c          *Client
+	deviceId   uint32
+	objectType uint16
+	instance   uint32
+	propertyId uint32
+
+	page services.RangeResult
+	// next is the sequence number of the record following the current page, 0 before
+	// the first one.
+	next uint32
+	done bool
+	err  error
+}
+
+// IterateLog returns a LogIterator over the records of a log property.
+func (c *Client) IterateLog(deviceId uint32, objectType uint16, instance uint32, propertyId uint32) *LogIterator {
+	return &LogIterator{c: c, deviceId: deviceId, objectType: objectType, instance: instance, propertyId: propertyId}
+}
+
+// Next reads the next page of records. It returns false once every record was read or
+// reading failed, which Err tells apart.
+func (it *LogIterator) Next(ctx context.Context) bool {
+	if it.done {
+		return false
+	}
+
+	rng := services.Range{Type: services.RangeBySequenceNumber, Reference: it.next, Count: math.MaxInt16}
+	if it.next == 0 {
+		// Reads by position don't tell the sequence number of the first record.
+		rng = services.RangeFromFirst(math.MaxInt16)
+	}
+
+	req := services.NewConfirmedReadRange(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+	req.APDU.Objects = services.ConfirmedReadRangeObjects(it.objectType, it.instance, it.propertyId, objects.ArrayAll, rng)
+
+	reply, err := it.c.RequestDevice(ctx, it.deviceId, req)
+	if err != nil {
+		return it.fail(err)
+	}
+	cACK, ok := reply.(*services.ComplexACK)
+	if !ok {
+		return it.fail(common.ErrWrongStructure)
+	}
+	dec, err := cACK.DecodeReadRange()
+	if err != nil {
+		return it.fail(err)
+	}
+
+	if dec.ItemCount == 0 {
+		it.done = true
+		return false
+	}
+	if dec.FirstSequenceNumber == 0 || (it.next != 0 && dec.FirstSequenceNumber != it.next) {
+		return it.fail(common.ErrWrongStructure)
+	}
+
+	it.page = dec.RangeResult
+	it.next = dec.FirstSequenceNumber + dec.ItemCount
+	it.done = dec.LastItem && !dec.MoreItems
+	return true
+}
+
+// Records returns the records of the current page as they were encoded.
+func (it *LogIterator) Records() []objects.APDUPayload {
+	return it.page.ItemData
+}
+
+// LogRecords decodes the records of the current page, which must be BACnetLogRecords like
+// those on the Log_Buffer of Trend Logs.
+func (it *LogIterator) LogRecords() ([]services.LogRecord, error) {
+	return services.DecLogRecords(it.page.ItemData)
+}
+
+// Count returns the number of records on the current page.
+func (it *LogIterator) Count() uint32 {
+	return it.page.ItemCount
+}
+
+// SequenceNumber returns the sequence number of the first record on the current page.
+func (it *LogIterator) SequenceNumber() uint32 {
+	return it.page.FirstSequenceNumber
+}
+
+// Err returns the error reading failed with, if any.
+func (it *LogIterator) Err() error {
+	return it.err
+}
+
+func (it *LogIterator) fail(err error) bool {
+	it.err = err
+	it.done = true
+	return false
+}
+
+func (s *Server) readRange(msg plumbing.BACnet, src net.Addr) ([]objects.APDUPayload, error) {
+	c := msg.(*services.ConfirmedReadRange)
+	req, err := c.Decode()
+	if err != nil {
+		return nil, err
+	}
+	if req.Range.Type != services.RangeAll && req.Range.Count == 0 {
+		return nil, &common.RejectError{Reason: plumbing.RejectReasonParameterOutOfRange}
+	}
+
+	maxLen := plumbing.DecMaxAPDU(c.APDU.MaxSize)
+	if maxLen > plumbing.MaxAPDULengthIP {
+		maxLen = plumbing.MaxAPDULengthIP
+	}
+	maxLen -= apduHeaderLen(plumbing.ComplexAck) + readRangeOverhead
+
+	result, err := s.Device.ReadRange(req.ObjectType, req.InstanceId, req.PropertyId, req.ArrayIndex, req.Range, maxLen)
+	if err != nil {
+		return nil, err
+	}
+	return services.ReadRangeACKObjects(req.ObjectType, req.InstanceId, req.PropertyId, req.ArrayIndex, result), nil
+}
@@ -0,0 +1,114 @@
+package bacnet
+
+import (
+	"errors"
+	"net"
+
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/device"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+	"github.com/ulbios/bacnet/services"
+)
+
+// Reinitializer carries out the ReinitializeDevice requests a Server accepts on behalf of
+// its application, the Server keeping track of the Backup_And_Restore_State of the Device.
+// Methods are called on the read loop once the password is checked, and requests are only
+// acknowledged once they return. Errors other than *common.BACnetError are reported as
+// device errors of class other.
+type Reinitializer interface {
+	// Reinitialize restarts the application for objects.ReinitializedStateColdstart and
+	// objects.ReinitializedStateWarmstart, which should be deferred until the request is
+	// acknowledged, or applies pending changes for objects.ReinitializedStateActivateChanges.
+	Reinitialize(state uint8) error
+	// StartBackup prepares a backup, returning the File objects to read the configuration off.
+	StartBackup() ([]objects.ObjectIdentifier, error)
+	// EndBackup wraps up a backup.
+	EndBackup() error
+	// StartRestore prepares a restore, returning the File objects to write the configuration to.
+	StartRestore() ([]objects.ObjectIdentifier, error)
+	// EndRestore applies the configuration restored.
+	EndRestore() error
+	// AbortRestore discards the configuration restored so far.
+	AbortRestore() error
+}
+
+func (s *Server) reinitializeDevice(msg plumbing.BACnet, src net.Addr) ([]objects.APDUPayload, error) {
+	req, err := msg.(*services.ConfirmedReinitializeDevice).Decode()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Device.CheckPassword(req.Password); err != nil {
+		return nil, err
+	}
+
+	r := s.Reinitializer
+	switch req.State {
+	case objects.ReinitializedStateColdstart, objects.ReinitializedStateWarmstart, objects.ReinitializedStateActivateChanges:
+		if r != nil {
+			if err := r.Reinitialize(req.State); err != nil {
+				return nil, reinitializerError(err)
+			}
+		}
+		// Restarting brings communication back.
+		if req.State != objects.ReinitializedStateActivateChanges {
+			s.Device.SetCommunicationState(objects.CommunicationEnable, 0)
+		}
+		return nil, nil
+	}
+
+	if r == nil {
+		return nil, device.ErrServiceRequestDenied
+	}
+
+	switch req.State {
+	case objects.ReinitializedStateStartBackup:
+		return nil, s.startBackup(s.Device.StartBackup, r.StartBackup)
+	case objects.ReinitializedStateStartRestore:
+		return nil, s.startBackup(s.Device.StartRestore, r.StartRestore)
+	case objects.ReinitializedStateEndBackup:
+		return nil, s.endBackup(objects.BackupStatePerformingABackup, r.EndBackup, s.Device.EndBackup)
+	case objects.ReinitializedStateEndRestore:
+		return nil, s.endBackup(objects.BackupStatePerformingARestore, r.EndRestore, s.Device.EndRestore)
+	default:
+		return nil, s.endBackup(objects.BackupStatePerformingARestore, r.AbortRestore, s.Device.AbortRestore)
+	}
+}
+
+// startBackup moves the Device to preparing for a backup or restore, has the application
+// prepare it and moves the Device to performing it, or to failure if preparing fails.
+func (s *Server) startBackup(start func() error, prepare func() ([]objects.ObjectIdentifier, error)) error {
+	if err := start(); err != nil {
+		return err
+	}
+
+	files, err := prepare()
+	if err != nil {
+		s.Device.FailBackup()
+		return reinitializerError(err)
+	}
+	s.Device.BackupPrepared(files)
+	return nil
+}
+
+// endBackup has the application end the backup or restore being performed before the
+// Device leaves it, which fails if the application does.
+func (s *Server) endBackup(performing uint8, end func() error, leave func() error) error {
+	if s.Device.BackupState() != performing {
+		return device.ErrServiceRequestDenied
+	}
+
+	if err := end(); err != nil {
+		s.Device.FailBackup()
+		return reinitializerError(err)
+	}
+	return leave()
+}
+
+func reinitializerError(err error) error {
+	var bErr *common.BACnetError
+	if errors.As(err, &bErr) {
+		return err
+	}
+	return &common.BACnetError{Class: objects.ErrorClassDevice, Code: objects.ErrorCodeOther}
+}
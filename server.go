@@ -0,0 +1,429 @@
+package bacnet
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ulbios/bacnet/device"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+	"github.com/ulbios/bacnet/services"
+)
+
+const tickInterval = time.Second
+
+// Server exposes a device.Device over BACnet/IP, answering ReadProperty, ReadPropertyMultiple,
+// ReadRange, WriteProperty, WritePropertyMultiple, AddListElement, RemoveListElement,
+// CreateObject, DeleteObject, AtomicReadFile, AtomicWriteFile, Who-Is and Who-Has requests
+// out of the box and setting the time of the Device on time synchronizations. Objects can
+// only be created and deleted for the types the Device has a device.Factory for.
+// ReinitializeDevice requests, backups and restores included, are carried out by the
+// Reinitializer. Being a Client as well, it can initiate requests of its own. The event
+// notifications of the Device go to the recipients of its Notification Classes, recipients
+// given by device instance being reached through the bindings of the Client or bound by the
+// Discoverer first, and its alarms are acknowledged and summarized through AcknowledgeAlarm,
+// GetEventInformation, GetAlarmSummary and GetEnrollmentSummary.
+//
+// DeviceCommunicationControl is enforced: while communication is disabled, requests other
+// than DeviceCommunicationControl and ReinitializeDevice are dropped, and whenever it isn't
+// enabled, requests the Server would initiate fail with common.ErrCommunicationDisabled.
+type Server struct {
+	*Client
+
+	Device *device.Device
+	// IAm answers the Who-Is requests meant for the Device.
+	IAm *IAmResponder
+	// IHave answers the Who-Has requests looking for objects of the Device.
+	IHave *IHaveResponder
+	// Reinitializer, if set, carries out ReinitializeDevice requests. Without it, restarts
+	// only bring communication back and backups and restores are denied.
+	Reinitializer Reinitializer
+	// Discoverer binds the recipients of event notifications given by device instance
+	// that aren't bound yet.
+	Discoverer *Discoverer
+	// EventFailed, if set, is called on a goroutine of its own with the event notifications
+	// that couldn't be delivered, common.ErrUnknownDevice telling those whose recipient
+	// couldn't be bound apart.
+	EventFailed func(n device.EventNotification, err error)
+
+	// lookups holds the event notifications waiting for their recipients to be bound, by
+	// device instance.
+	lookupsMu sync.Mutex
+	lookups   map[uint32][]device.EventNotification
+}
+
+// NewServer creates a Server answering requests for dev received on conn.
+func NewServer(conn net.PacketConn, dev *device.Device) *Server {
+	s := &Server{
+		Client:  NewClient(conn),
+		Device:  dev,
+		lookups: map[uint32][]device.EventNotification{},
+	}
+	s.IAm = NewIAmResponder(s.Client, dev)
+	s.IHave = NewIHaveResponder(s.Client, dev)
+	s.Discoverer = NewDiscoverer(s.Client)
+
+	s.HandleConfirmed(services.ServiceConfirmedReadProperty, s.readProperty)
+	s.HandleConfirmed(services.ServiceConfirmedReadPropMultiple, s.readPropertyMultiple)
+	s.HandleConfirmed(services.ServiceConfirmedReadRange, s.readRange)
+	s.HandleConfirmed(services.ServiceConfirmedWriteProperty, s.writeProperty)
+	s.HandleConfirmed(services.ServiceConfirmedWritePropMultiple, s.writePropertyMultiple)
+	s.HandleConfirmed(services.ServiceConfirmedCreateObject, s.createObject)
+	s.HandleConfirmed(services.ServiceConfirmedDeleteObject, s.deleteObject)
+	s.HandleConfirmed(services.ServiceConfirmedAddListElement, s.addListElement)
+	s.HandleConfirmed(services.ServiceConfirmedRemoveListElement, s.removeListElement)
+	s.HandleConfirmed(services.ServiceConfirmedSubscribeCOV, s.subscribeCOV)
+	s.HandleConfirmed(services.ServiceConfirmedSubscribeCOVProperty, s.subscribeCOVProperty)
+	s.HandleConfirmed(services.ServiceConfirmedDeviceCommunicationControl, s.deviceCommunicationControl)
+	s.HandleConfirmed(services.ServiceConfirmedReinitializeDevice, s.reinitializeDevice)
+	s.HandleConfirmed(services.ServiceConfirmedAtomicReadFile, s.atomicReadFile)
+	s.HandleConfirmed(services.ServiceConfirmedAtomicWriteFile, s.atomicWriteFile)
+	s.HandleConfirmed(services.ServiceConfirmedAcknowledgeAlarm, s.acknowledgeAlarm)
+	s.HandleConfirmed(services.ServiceConfirmedGetEventInformation, s.getEventInformation)
+	s.HandleConfirmed(services.ServiceConfirmedGetAlarmSummary, s.getAlarmSummary)
+	s.HandleConfirmed(services.ServiceConfirmedGetEnrollmentSummary, s.getEnrollmentSummary)
+	s.HandleUnconfirmed(services.ServiceUnconfirmedWhoIs, s.IAm.ServeWhoIs)
+	s.HandleUnconfirmed(services.ServiceUnconfirmedWhoHas, s.IHave.ServeWhoHas)
+	s.HandleUnconfirmed(services.ServiceUnconfirmedTimeSync, s.timeSynchronization)
+	s.HandleUnconfirmed(services.ServiceUnconfirmedUTCTimeSync, s.utcTimeSynchronization)
+	s.Device.SetServiceSupported(services.ServiceSupportedBit(false, services.ServiceUnconfirmedIHave), true)
+	s.Device.SetServiceSupported(services.ServiceSupportedBit(false, services.ServiceUnconfirmedIAm), true)
+	s.Device.SetServiceSupported(services.ServiceSupportedBit(true, services.ServiceConfirmedCOVNotification), true)
+	s.Device.SetServiceSupported(services.ServiceSupportedBit(false, services.ServiceUnconfirmedCOVNotification), true)
+	s.Device.SetServiceSupported(services.ServiceSupportedBit(true, services.ServiceConfirmedEventNotification), true)
+	s.Device.SetServiceSupported(services.ServiceSupportedBit(false, services.ServiceUnconfirmedEventNotification), true)
+	s.Device.OnCOV(s.notifyCOV)
+	s.Device.OnEvent(s.notifyEvent)
+	s.communication = dev.CommunicationState
+
+	return s
+}
+
+// HandleConfirmed registers the handler for a confirmed service, replacing any previous one,
+// and flags the service on the Protocol_Services_Supported of the Device.
+func (s *Server) HandleConfirmed(service uint8, h ConfirmedHandler) {
+	s.Client.HandleConfirmed(service, h)
+	s.Device.SetServiceSupported(services.ServiceSupportedBit(true, service), h != nil)
+}
+
+// HandleUnconfirmed registers an additional handler for an unconfirmed service and flags
+// the service on the Protocol_Services_Supported of the Device.
+func (s *Server) HandleUnconfirmed(service uint8, h UnconfirmedHandler) {
+	s.Client.HandleUnconfirmed(service, h)
+	s.Device.SetServiceSupported(services.ServiceSupportedBit(false, service), true)
+}
+
+// Serve reads and serves incoming requests until the Server is closed.
+func (s *Server) Serve() error {
+	go s.tick()
+	return s.Run()
+}
+
+// tick drives the time dependent behaviour of the Device until the Server is closed.
+func (s *Server) tick() {
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.Device.Tick()
+		case <-s.closed:
+			return
+		}
+	}
+}
+
+func (s *Server) readProperty(msg plumbing.BACnet, src net.Addr) ([]objects.APDUPayload, error) {
+	req, err := msg.(*services.ConfirmedReadProperty).Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := s.Device.ReadProperty(req.ObjectType, req.InstanceId, req.PropertyId, req.ArrayIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	// Wildcard reads of the Device object must be answered with its actual identifier.
+	if req.ObjectType == objects.ObjectTypeDevice && req.InstanceId == objects.MaxInstance {
+		req.InstanceId = s.Device.Instance()
+	}
+
+	return services.ComplexACKPropertyObjects(req.ObjectType, req.InstanceId, req.PropertyId, req.ArrayIndex, values), nil
+}
+
+func (s *Server) readPropertyMultiple(msg plumbing.BACnet, src net.Addr) ([]objects.APDUPayload, error) {
+	req, err := msg.(*services.ConfirmedReadPropertyMultiple).Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	return services.ReadPropertyMultipleACKObjects(s.Device.ReadPropertyMultiple(req.Specifications)), nil
+}
+
+func (s *Server) writeProperty(msg plumbing.BACnet, src net.Addr) ([]objects.APDUPayload, error) {
+	req, err := msg.(*services.ConfirmedWriteProperty).Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, s.Device.WriteProperty(req.ObjectType, req.InstanceId, req.PropertyId, req.ArrayIndex, req.Values, req.Priority)
+}
+
+func (s *Server) writePropertyMultiple(msg plumbing.BACnet, src net.Addr) ([]objects.APDUPayload, error) {
+	req, err := msg.(*services.ConfirmedWritePropertyMultiple).Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, s.Device.WritePropertyMultiple(req.Specifications)
+}
+
+func (s *Server) addListElement(msg plumbing.BACnet, src net.Addr) ([]objects.APDUPayload, error) {
+	req, err := msg.(*services.ConfirmedAddListElement).Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, s.Device.AddListElement(req.ObjectType, req.InstanceId, req.PropertyId, req.ArrayIndex, req.Elements)
+}
+
+func (s *Server) removeListElement(msg plumbing.BACnet, src net.Addr) ([]objects.APDUPayload, error) {
+	req, err := msg.(*services.ConfirmedRemoveListElement).Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, s.Device.RemoveListElement(req.ObjectType, req.InstanceId, req.PropertyId, req.ArrayIndex, req.Elements)
+}
+
+func (s *Server) createObject(msg plumbing.BACnet, src net.Addr) ([]objects.APDUPayload, error) {
+	req, err := msg.(*services.ConfirmedCreateObject).Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := s.Device.CreateObject(req.ObjectType, req.InstanceId, req.InitialValues)
+	if err != nil {
+		return nil, err
+	}
+	return services.CreateObjectACKObjects(id.ObjectType, id.InstanceNumber), nil
+}
+
+func (s *Server) deleteObject(msg plumbing.BACnet, src net.Addr) ([]objects.APDUPayload, error) {
+	req, err := msg.(*services.ConfirmedDeleteObject).Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, s.Device.DeleteObject(req.ObjectType, req.InstanceId)
+}
+
+func (s *Server) subscribeCOV(msg plumbing.BACnet, src net.Addr) ([]objects.APDUPayload, error) {
+	req, err := msg.(*services.ConfirmedSubscribeCOV).Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	sub := device.COVSubscription{
+		Recipient:  src,
+		ProcessId:  req.ProcessId,
+		Object:     objects.ObjectIdentifier{ObjectType: req.ObjectType, InstanceNumber: req.InstanceId},
+		PropertyId: objects.PropertyIdAll,
+		ArrayIndex: objects.ArrayAll,
+		Confirmed:  req.Confirmed,
+		Lifetime:   time.Duration(req.Lifetime) * time.Second,
+	}
+
+	if req.Cancel {
+		s.Device.UnsubscribeCOV(sub)
+		return nil, nil
+	}
+
+	return nil, s.Device.SubscribeCOV(sub)
+}
+
+func (s *Server) subscribeCOVProperty(msg plumbing.BACnet, src net.Addr) ([]objects.APDUPayload, error) {
+	req, err := msg.(*services.ConfirmedSubscribeCOVProperty).Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	sub := device.COVSubscription{
+		Recipient:  src,
+		ProcessId:  req.ProcessId,
+		Object:     objects.ObjectIdentifier{ObjectType: req.ObjectType, InstanceNumber: req.InstanceId},
+		PropertyId: req.Property.PropertyId,
+		ArrayIndex: req.Property.ArrayIndex,
+		Confirmed:  req.Confirmed,
+		Lifetime:   time.Duration(req.Lifetime) * time.Second,
+	}
+	if req.Increment != nil {
+		sub.Increment = *req.Increment
+	}
+
+	if req.Cancel {
+		s.Device.UnsubscribeCOV(sub)
+		return nil, nil
+	}
+
+	return nil, s.Device.SubscribeCOV(sub)
+}
+
+func (s *Server) deviceCommunicationControl(msg plumbing.BACnet, src net.Addr) ([]objects.APDUPayload, error) {
+	dec, err := msg.(*services.ConfirmedDeviceCommunicationControl).Decode()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Device.CheckPassword(dec.Password); err != nil {
+		return nil, err
+	}
+
+	s.Device.SetCommunicationState(dec.State, time.Duration(dec.Duration)*time.Minute)
+	return nil, nil
+}
+
+func (s *Server) timeSynchronization(msg plumbing.BACnet, src net.Addr) {
+	dec, err := msg.(*services.UnconfirmedTimeSynchronization).Decode()
+	if err != nil {
+		return
+	}
+
+	s.Device.SetTime(dec.DateTime.Date.In(dec.DateTime.Time, s.Device.Location()))
+}
+
+func (s *Server) utcTimeSynchronization(msg plumbing.BACnet, src net.Addr) {
+	dec, err := msg.(*services.UnconfirmedUTCTimeSynchronization).Decode()
+	if err != nil {
+		return
+	}
+
+	s.Device.SetTime(dec.DateTime.Date.In(dec.DateTime.Time, time.UTC))
+}
+
+// notifyCOV sends a COV notification. It runs with the Device locked, which is why
+// confirmed notifications are sent on their own goroutines.
+func (s *Server) notifyCOV(n device.COVNotification) {
+	bvlc := plumbing.NewBVLC(plumbing.BVLCFuncUnicast)
+	objs := services.COVNotificationObjects(
+		n.ProcessId, n.DeviceId, n.Object.ObjectType, n.Object.InstanceNumber, n.TimeRemaining, n.Values)
+
+	if !n.Confirmed {
+		u := services.NewUnconfirmedCOVNotification(bvlc, plumbing.NewNPDU(false, false, false, false))
+		u.APDU.Objects = objs
+		s.Send(n.Recipient, u)
+		return
+	}
+
+	// Requests are retransmitted until the subscriber acknowledges them or they time out.
+	c := services.NewConfirmedCOVNotification(bvlc, plumbing.NewNPDU(false, false, false, true))
+	c.APDU.Objects = objs
+	go s.Request(context.Background(), n.Recipient, c)
+}
+
+// notifyEvent sends an event notification. Like notifyCOV, it runs with the Device locked,
+// so recipients given by device instance that aren't bound yet are looked for on goroutines
+// of their own, the notifications due to them meanwhile waiting for the same lookup.
+func (s *Server) notifyEvent(n device.EventNotification) {
+	if n.Recipient.Address == nil {
+		if info, ok := s.Devices.Lookup(n.Recipient.DeviceId); ok {
+			s.sendEvent(n, &info.Address, false)
+			return
+		}
+
+		s.lookupsMu.Lock()
+		defer s.lookupsMu.Unlock()
+
+		pending, ok := s.lookups[n.Recipient.DeviceId]
+		s.lookups[n.Recipient.DeviceId] = append(pending, n)
+		if !ok {
+			go s.lookUpRecipient(n.Recipient.DeviceId)
+		}
+		return
+	}
+
+	dst, broadcast := s.recipientAddr(n.Recipient.Address)
+	if dst == nil {
+		return
+	}
+	s.sendEvent(n, dst, broadcast)
+}
+
+// lookUpRecipient binds a recipient given by device instance and sends it the event
+// notifications waiting for it.
+func (s *Server) lookUpRecipient(deviceId uint32) {
+	info, err := s.Discoverer.Find(context.Background(), deviceId)
+
+	s.lookupsMu.Lock()
+	pending := s.lookups[deviceId]
+	delete(s.lookups, deviceId)
+	s.lookupsMu.Unlock()
+
+	for _, n := range pending {
+		if err != nil {
+			s.eventFailed(n, err)
+			continue
+		}
+		s.sendEvent(n, &info.Address, false)
+	}
+}
+
+// sendEvent sends an event notification to dst. Confirmed requests can't be broadcast, so
+// confirmed notifications are sent unconfirmed when they must be.
+func (s *Server) sendEvent(n device.EventNotification, dst net.Addr, broadcast bool) {
+	bvlc := plumbing.NewBVLC(plumbing.BVLCFuncUnicast)
+	if broadcast {
+		bvlc.Function = plumbing.BVLCFuncBroadcast
+	}
+	objs := services.EventNotificationObjects(n.EventNotificationDec)
+
+	if !n.Confirmed || broadcast {
+		u := services.NewUnconfirmedEventNotification(bvlc, plumbing.NewNPDU(false, false, false, false))
+		u.APDU.Objects = objs
+		if err := s.Send(dst, u); err != nil {
+			s.eventFailed(n, err)
+		}
+		return
+	}
+
+	c := services.NewConfirmedEventNotification(bvlc, plumbing.NewNPDU(false, false, false, true))
+	c.APDU.Objects = objs
+	go func() {
+		if _, err := s.Request(context.Background(), dst, c); err != nil {
+			s.eventFailed(n, err)
+		}
+	}()
+}
+
+func (s *Server) eventFailed(n device.EventNotification, err error) {
+	if s.EventFailed != nil {
+		go s.EventFailed(n, err)
+	}
+}
+
+// recipientAddr returns where to send messages for a BACnetAddress, or nil if it isn't a
+// BACnet/IP one, and whether they must be broadcast. Devices on remote networks are reached
+// through the router they're bound to, or by broadcasting to every router otherwise.
+func (s *Server) recipientAddr(a *services.Address) (net.Addr, bool) {
+	if a.Net != 0 {
+		for _, info := range s.Devices.Devices() {
+			if len(a.MAC) != 0 && info.Address.Net == a.Net && bytes.Equal(info.Address.MAC, a.MAC) {
+				return &info.Address, false
+			}
+		}
+		return &Address{Addr: s.broadcastAddr(), Net: a.Net, MAC: a.MAC}, true
+	}
+
+	switch len(a.MAC) {
+	case 0:
+		return s.broadcastAddr(), true
+	case 6:
+		return &net.UDPAddr{IP: net.IP(append([]byte{}, a.MAC[:4]...)), Port: int(binary.BigEndian.Uint16(a.MAC[4:]))}, false
+	}
+	return nil, false
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/plumbing"
+)
+
+// Abort is a BACnet message.
+type Abort struct {
+	*plumbing.BVLC
+	*plumbing.NPDU
+	*plumbing.APDU
+}
+
+type AbortDec struct {
+	Reason uint8
+}
+
+func NewAbort(bvlc *plumbing.BVLC, npdu *plumbing.NPDU) *Abort {
+	a := &Abort{
+		BVLC: bvlc,
+		NPDU: npdu,
+		APDU: plumbing.NewAPDU(plumbing.Abort, plumbing.AbortReasonOther, nil),
+	}
+	a.SetLength()
+
+	return a
+}
+
+func (a *Abort) UnmarshalBinary(b []byte) error {
+	if l := len(b); l < a.MarshalLen() {
+		return common.ErrTooShortToParse
+	}
+
+	var offset int = 0
+	if err := a.BVLC.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += a.BVLC.MarshalLen()
+
+	if err := a.NPDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += a.NPDU.MarshalLen()
+
+	if err := a.APDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+
+	return nil
+}
+
+func (a *Abort) MarshalBinary() ([]byte, error) {
+	b := make([]byte, a.MarshalLen())
+	if err := a.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (a *Abort) MarshalTo(b []byte) error {
+	if len(b) < a.MarshalLen() {
+		return common.ErrTooShortToMarshalBinary
+	}
+	var offset = 0
+	if err := a.BVLC.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += a.BVLC.MarshalLen()
+
+	if err := a.NPDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += a.NPDU.MarshalLen()
+
+	if err := a.APDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (a *Abort) MarshalLen() int {
+	l := a.BVLC.MarshalLen()
+	l += a.NPDU.MarshalLen()
+	l += a.APDU.MarshalLen()
+
+	return l
+}
+
+func (a *Abort) SetLength() {
+	a.BVLC.Length = uint16(a.MarshalLen())
+}
+
+// Decode returns the abort reason, which travels where the service choice goes.
+func (a *Abort) Decode() (AbortDec, error) {
+	return AbortDec{Reason: a.APDU.Service}, nil
+}
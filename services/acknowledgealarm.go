@@ -0,0 +1,132 @@
+package services
+
+import (
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+)
+
+// ConfirmedAcknowledgeAlarm is a BACnet message. It's acknowledged with a SimpleACK.
+type ConfirmedAcknowledgeAlarm struct {
+	*plumbing.BVLC
+	*plumbing.NPDU
+	*plumbing.APDU
+}
+
+type ConfirmedAcknowledgeAlarmDec struct {
+	ProcessId uint32
+	Object    objects.ObjectIdentifier
+	// EventState is the Event_State the acknowledged transition led to.
+	EventState uint8
+	// TimeStamp is the time stamp of the acknowledged transition.
+	TimeStamp         TimeStamp
+	Source            string
+	TimeOfAcknowledge TimeStamp
+}
+
+// ConfirmedAcknowledgeAlarmObjects creates the AcknowledgeAlarm request objects.
+func ConfirmedAcknowledgeAlarmObjects(processId uint32, objectType uint16, instN uint32, eventState uint8, timeStamp TimeStamp, source string, timeOfAcknowledge TimeStamp) []objects.APDUPayload {
+	objs := make([]objects.APDUPayload, 0, 12)
+
+	objs = append(objs, objects.EncContext(0, objects.EncUnsignedInteger(processId)))
+	objs = append(objs, objects.EncObjectIdentifier(true, 1, objectType, instN))
+	objs = append(objs, objects.EncContext(2, objects.EncEnumerated(uint32(eventState))))
+	objs = append(objs, objects.EncEnclosed(3, EncTimeStamp(timeStamp)...)...)
+	objs = append(objs, objects.EncContext(4, objects.EncCharacterString(source)))
+	objs = append(objs, objects.EncEnclosed(5, EncTimeStamp(timeOfAcknowledge)...)...)
+
+	return objs
+}
+
+func NewConfirmedAcknowledgeAlarm(bvlc *plumbing.BVLC, npdu *plumbing.NPDU) *ConfirmedAcknowledgeAlarm {
+	c := &ConfirmedAcknowledgeAlarm{
+		BVLC: bvlc,
+		NPDU: npdu,
+		APDU: plumbing.NewAPDU(plumbing.ConfirmedReq, ServiceConfirmedAcknowledgeAlarm, nil),
+	}
+	c.SetLength()
+
+	return c
+}
+
+func (c *ConfirmedAcknowledgeAlarm) UnmarshalBinary(b []byte) error {
+	if l := len(b); l < c.MarshalLen() {
+		return common.ErrTooShortToParse
+	}
+
+	var offset int = 0
+	if err := c.BVLC.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+
+	return nil
+}
+
+func (c *ConfirmedAcknowledgeAlarm) MarshalBinary() ([]byte, error) {
+	b := make([]byte, c.MarshalLen())
+	if err := c.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (c *ConfirmedAcknowledgeAlarm) MarshalTo(b []byte) error {
+	if len(b) < c.MarshalLen() {
+		return common.ErrTooShortToMarshalBinary
+	}
+	var offset = 0
+	if err := c.BVLC.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *ConfirmedAcknowledgeAlarm) MarshalLen() int {
+	l := c.BVLC.MarshalLen()
+	l += c.NPDU.MarshalLen()
+	l += c.APDU.MarshalLen()
+
+	return l
+}
+
+func (c *ConfirmedAcknowledgeAlarm) SetLength() {
+	c.BVLC.Length = uint16(c.MarshalLen())
+}
+
+func (c *ConfirmedAcknowledgeAlarm) Decode() (ConfirmedAcknowledgeAlarmDec, error) {
+	decAA := ConfirmedAcknowledgeAlarmDec{}
+
+	r := &contextReader{rawPayloads: c.APDU.Objects}
+	decAA.ProcessId = r.unsigned(0)
+	decAA.Object = r.objectIdentifier(1)
+	decAA.EventState = uint8(r.enumerated(2))
+	decAA.TimeStamp = r.timeStamp(3)
+	decAA.Source = r.characterString(4)
+	decAA.TimeOfAcknowledge = r.timeStamp(5)
+
+	if err := r.done(); err != nil {
+		return decAA, err
+	}
+	return decAA, nil
+}
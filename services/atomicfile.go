@@ -0,0 +1,471 @@
+package services
+
+import (
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+)
+
+// ConfirmedAtomicReadFile is a BACnet message. It's answered with a ComplexACK carrying
+// the data read, which DecodeAtomicReadFile decodes.
+type ConfirmedAtomicReadFile struct {
+	*plumbing.BVLC
+	*plumbing.NPDU
+	*plumbing.APDU
+}
+
+// ConfirmedAtomicWriteFile is a BACnet message. It's answered with a ComplexACK carrying
+// where the data was written, which DecodeAtomicWriteFile decodes.
+type ConfirmedAtomicWriteFile struct {
+	*plumbing.BVLC
+	*plumbing.NPDU
+	*plumbing.APDU
+}
+
+type ConfirmedAtomicReadFileDec struct {
+	InstanceId uint32
+	// Stream tells stream access apart from record access.
+	Stream bool
+	// Start is the position of the first octet, or the number of the first record, to read.
+	Start int32
+	// Count is the number of octets, or records, requested.
+	Count uint32
+}
+
+type AtomicReadFileACKDec struct {
+	EndOfFile bool
+	Stream    bool
+	Start     int32
+	// Data holds the octets read with stream access.
+	Data []byte
+	// Records holds the records read with record access.
+	Records [][]byte
+}
+
+type ConfirmedAtomicWriteFileDec struct {
+	InstanceId uint32
+	Stream     bool
+	// Start is the position of the first octet, or the number of the first record, to
+	// write. It's -1 to append to the file.
+	Start int32
+	// Data holds the octets to write with stream access.
+	Data []byte
+	// Records holds the records to write with record access.
+	Records [][]byte
+}
+
+type AtomicWriteFileACKDec struct {
+	Stream bool
+	// Start is where the data was actually written.
+	Start int32
+}
+
+// ConfirmedAtomicReadFileObjects creates the AtomicReadFile request objects.
+func ConfirmedAtomicReadFileObjects(instance uint32, stream bool, start int32, count uint32) []objects.APDUPayload {
+	objs := []objects.APDUPayload{
+		objects.EncObjectIdentifier(false, objects.TagBACnetObjectIdentifier, objects.ObjectTypeFile, instance),
+	}
+	return append(objs, objects.EncEnclosed(accessMethodTag(stream),
+		objects.EncSignedInteger(start),
+		objects.EncUnsignedInteger(count),
+	)...)
+}
+
+// AtomicReadFileACKStreamObjects creates the AtomicReadFile-ACK objects of a stream access read.
+func AtomicReadFileACKStreamObjects(endOfFile bool, start int32, data []byte) []objects.APDUPayload {
+	objs := []objects.APDUPayload{objects.EncBoolean(endOfFile)}
+	return append(objs, objects.EncEnclosed(0,
+		objects.EncSignedInteger(start),
+		objects.EncOctetString(data),
+	)...)
+}
+
+// AtomicReadFileACKRecordObjects creates the AtomicReadFile-ACK objects of a record access read.
+func AtomicReadFileACKRecordObjects(endOfFile bool, start int32, records [][]byte) []objects.APDUPayload {
+	objs := []objects.APDUPayload{objects.EncBoolean(endOfFile)}
+	return append(objs, objects.EncEnclosed(1, encRecords(start, records)...)...)
+}
+
+// ConfirmedAtomicWriteFileStreamObjects creates the AtomicWriteFile request objects of a
+// stream access write.
+func ConfirmedAtomicWriteFileStreamObjects(instance uint32, start int32, data []byte) []objects.APDUPayload {
+	objs := []objects.APDUPayload{
+		objects.EncObjectIdentifier(false, objects.TagBACnetObjectIdentifier, objects.ObjectTypeFile, instance),
+	}
+	return append(objs, objects.EncEnclosed(0,
+		objects.EncSignedInteger(start),
+		objects.EncOctetString(data),
+	)...)
+}
+
+// ConfirmedAtomicWriteFileRecordObjects creates the AtomicWriteFile request objects of a
+// record access write.
+func ConfirmedAtomicWriteFileRecordObjects(instance uint32, start int32, records [][]byte) []objects.APDUPayload {
+	objs := []objects.APDUPayload{
+		objects.EncObjectIdentifier(false, objects.TagBACnetObjectIdentifier, objects.ObjectTypeFile, instance),
+	}
+	return append(objs, objects.EncEnclosed(1, encRecords(start, records)...)...)
+}
+
+// AtomicWriteFileACKObjects creates the AtomicWriteFile-ACK objects.
+func AtomicWriteFileACKObjects(stream bool, start int32) []objects.APDUPayload {
+	return []objects.APDUPayload{objects.EncContext(accessMethodTag(stream), objects.EncSignedInteger(start))}
+}
+
+func accessMethodTag(stream bool) uint8 {
+	if stream {
+		return 0
+	}
+	return 1
+}
+
+func encRecords(start int32, records [][]byte) []objects.APDUPayload {
+	objs := []objects.APDUPayload{
+		objects.EncSignedInteger(start),
+		objects.EncUnsignedInteger(uint32(len(records))),
+	}
+	for _, record := range records {
+		objs = append(objs, objects.EncOctetString(record))
+	}
+	return objs
+}
+
+func NewConfirmedAtomicReadFile(bvlc *plumbing.BVLC, npdu *plumbing.NPDU) *ConfirmedAtomicReadFile {
+	c := &ConfirmedAtomicReadFile{
+		BVLC: bvlc,
+		NPDU: npdu,
+		APDU: plumbing.NewAPDU(plumbing.ConfirmedReq, ServiceConfirmedAtomicReadFile, nil),
+	}
+	c.SetLength()
+
+	return c
+}
+
+func (c *ConfirmedAtomicReadFile) UnmarshalBinary(b []byte) error {
+	if l := len(b); l < c.MarshalLen() {
+		return common.ErrTooShortToParse
+	}
+
+	var offset int = 0
+	if err := c.BVLC.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+
+	return nil
+}
+
+func (c *ConfirmedAtomicReadFile) MarshalBinary() ([]byte, error) {
+	b := make([]byte, c.MarshalLen())
+	if err := c.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (c *ConfirmedAtomicReadFile) MarshalTo(b []byte) error {
+	if len(b) < c.MarshalLen() {
+		return common.ErrTooShortToMarshalBinary
+	}
+	var offset = 0
+	if err := c.BVLC.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *ConfirmedAtomicReadFile) MarshalLen() int {
+	l := c.BVLC.MarshalLen()
+	l += c.NPDU.MarshalLen()
+	l += c.APDU.MarshalLen()
+
+	return l
+}
+
+func (c *ConfirmedAtomicReadFile) SetLength() {
+	c.BVLC.Length = uint16(c.MarshalLen())
+}
+
+func (c *ConfirmedAtomicReadFile) Decode() (ConfirmedAtomicReadFileDec, error) {
+	decARF := ConfirmedAtomicReadFileDec{}
+
+	objs := c.APDU.Objects
+	if len(objs) != 5 {
+		return decARF, common.ErrWrongObjectCount
+	}
+
+	var err error
+	if decARF.InstanceId, err = decFileIdentifier(objs[0]); err != nil {
+		return decARF, err
+	}
+
+	stream := objects.IsOpeningTag(objs[1], 0)
+	if !stream && !objects.IsOpeningTag(objs[1], 1) {
+		return decARF, common.ErrWrongStructure
+	}
+	decARF.Stream = stream
+
+	access, _, err := objects.DecEnclosed(objs, 1, accessMethodTag(stream))
+	if err != nil {
+		return decARF, err
+	}
+	if len(access) != 2 {
+		return decARF, common.ErrWrongObjectCount
+	}
+	if decARF.Start, err = objects.DecSignedInteger(access[0]); err != nil {
+		return decARF, err
+	}
+	if decARF.Count, err = objects.DecUnisgnedInteger(access[1]); err != nil {
+		return decARF, err
+	}
+
+	return decARF, nil
+}
+
+// DecodeAtomicReadFile decodes the objects of an AtomicReadFile-ACK.
+func (c *ComplexACK) DecodeAtomicReadFile() (AtomicReadFileACKDec, error) {
+	decARF := AtomicReadFileACKDec{}
+
+	objs := c.APDU.Objects
+	if len(objs) < 4 {
+		return decARF, common.ErrWrongObjectCount
+	}
+
+	var err error
+	if decARF.EndOfFile, err = objects.DecBoolean(objs[0]); err != nil {
+		return decARF, err
+	}
+
+	stream := objects.IsOpeningTag(objs[1], 0)
+	if !stream && !objects.IsOpeningTag(objs[1], 1) {
+		return decARF, common.ErrWrongStructure
+	}
+	decARF.Stream = stream
+
+	access, offset, err := objects.DecEnclosed(objs, 1, accessMethodTag(stream))
+	if err != nil {
+		return decARF, err
+	}
+	if offset != len(objs) {
+		return decARF, common.ErrWrongStructure
+	}
+
+	if stream {
+		if len(access) != 2 {
+			return decARF, common.ErrWrongObjectCount
+		}
+		if decARF.Start, err = objects.DecSignedInteger(access[0]); err != nil {
+			return decARF, err
+		}
+		if decARF.Data, err = objects.DecOctetString(access[1]); err != nil {
+			return decARF, err
+		}
+		return decARF, nil
+	}
+
+	decARF.Start, decARF.Records, err = decRecords(access)
+	return decARF, err
+}
+
+func NewConfirmedAtomicWriteFile(bvlc *plumbing.BVLC, npdu *plumbing.NPDU) *ConfirmedAtomicWriteFile {
+	c := &ConfirmedAtomicWriteFile{
+		BVLC: bvlc,
+		NPDU: npdu,
+		APDU: plumbing.NewAPDU(plumbing.ConfirmedReq, ServiceConfirmedAtomicWriteFile, nil),
+	}
+	c.SetLength()
+
+	return c
+}
+
+func (c *ConfirmedAtomicWriteFile) UnmarshalBinary(b []byte) error {
+	if l := len(b); l < c.MarshalLen() {
+		return common.ErrTooShortToParse
+	}
+
+	var offset int = 0
+	if err := c.BVLC.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+
+	return nil
+}
+
+func (c *ConfirmedAtomicWriteFile) MarshalBinary() ([]byte, error) {
+	b := make([]byte, c.MarshalLen())
+	if err := c.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (c *ConfirmedAtomicWriteFile) MarshalTo(b []byte) error {
+	if len(b) < c.MarshalLen() {
+		return common.ErrTooShortToMarshalBinary
+	}
+	var offset = 0
+	if err := c.BVLC.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *ConfirmedAtomicWriteFile) MarshalLen() int {
+	l := c.BVLC.MarshalLen()
+	l += c.NPDU.MarshalLen()
+	l += c.APDU.MarshalLen()
+
+	return l
+}
+
+func (c *ConfirmedAtomicWriteFile) SetLength() {
+	c.BVLC.Length = uint16(c.MarshalLen())
+}
+
+func (c *ConfirmedAtomicWriteFile) Decode() (ConfirmedAtomicWriteFileDec, error) {
+	decAWF := ConfirmedAtomicWriteFileDec{}
+
+	objs := c.APDU.Objects
+	if len(objs) < 4 {
+		return decAWF, common.ErrWrongObjectCount
+	}
+
+	var err error
+	if decAWF.InstanceId, err = decFileIdentifier(objs[0]); err != nil {
+		return decAWF, err
+	}
+
+	stream := objects.IsOpeningTag(objs[1], 0)
+	if !stream && !objects.IsOpeningTag(objs[1], 1) {
+		return decAWF, common.ErrWrongStructure
+	}
+	decAWF.Stream = stream
+
+	access, offset, err := objects.DecEnclosed(objs, 1, accessMethodTag(stream))
+	if err != nil {
+		return decAWF, err
+	}
+	if offset != len(objs) {
+		return decAWF, common.ErrWrongStructure
+	}
+
+	if stream {
+		if len(access) != 2 {
+			return decAWF, common.ErrWrongObjectCount
+		}
+		if decAWF.Start, err = objects.DecSignedInteger(access[0]); err != nil {
+			return decAWF, err
+		}
+		if decAWF.Data, err = objects.DecOctetString(access[1]); err != nil {
+			return decAWF, err
+		}
+		return decAWF, nil
+	}
+
+	decAWF.Start, decAWF.Records, err = decRecords(access)
+	return decAWF, err
+}
+
+// DecodeAtomicWriteFile decodes the objects of an AtomicWriteFile-ACK.
+func (c *ComplexACK) DecodeAtomicWriteFile() (AtomicWriteFileACKDec, error) {
+	decAWF := AtomicWriteFileACKDec{}
+
+	objs := c.APDU.Objects
+	if len(objs) != 1 {
+		return decAWF, common.ErrWrongObjectCount
+	}
+
+	decAWF.Stream = objects.IsContextTag(objs[0], 0)
+	if !decAWF.Stream && !objects.IsContextTag(objs[0], 1) {
+		return decAWF, common.ErrWrongStructure
+	}
+
+	var err error
+	decAWF.Start, err = objects.DecSignedInteger(objs[0])
+	return decAWF, err
+}
+
+// decFileIdentifier decodes the identifier of the File object a request is meant for.
+func decFileIdentifier(rawPayload objects.APDUPayload) (uint32, error) {
+	if rawObject, ok := rawPayload.(*objects.Object); !ok || rawObject.TagClass {
+		return 0, common.ErrWrongStructure
+	}
+	id, err := objects.DecObjectIdentifier(rawPayload)
+	if err != nil {
+		return 0, err
+	}
+	if id.ObjectType != objects.ObjectTypeFile {
+		return 0, common.ErrWrongStructure
+	}
+	return id.InstanceNumber, nil
+}
+
+// decRecords decodes the start record, record count and records of a record access.
+func decRecords(rawPayloads []objects.APDUPayload) (int32, [][]byte, error) {
+	if len(rawPayloads) < 2 {
+		return 0, nil, common.ErrWrongObjectCount
+	}
+
+	start, err := objects.DecSignedInteger(rawPayloads[0])
+	if err != nil {
+		return 0, nil, err
+	}
+	count, err := objects.DecUnisgnedInteger(rawPayloads[1])
+	if err != nil {
+		return 0, nil, err
+	}
+	if int(count) != len(rawPayloads)-2 {
+		return 0, nil, common.ErrWrongObjectCount
+	}
+
+	records := make([][]byte, 0, count)
+	for _, rawPayload := range rawPayloads[2:] {
+		record, err := objects.DecOctetString(rawPayload)
+		if err != nil {
+			return 0, nil, err
+		}
+		records = append(records, record)
+	}
+	return start, records, nil
+}
@@ -6,7 +6,7 @@ import (
 	"github.com/ulbios/bacnet/plumbing"
 )
 
-// UnconfirmedIAm is a BACnet message.
+// ComplexACK is a BACnet message.
 type ComplexACK struct {
 	*plumbing.BVLC
 	*plumbing.NPDU
@@ -16,18 +16,27 @@ type ComplexACK struct {
 type ComplexACKDec struct {
 	ObjectType   uint16
 	InstanceId   uint32
-	PropertyId   uint8
+	PropertyId   uint32
+	ArrayIndex   uint32
 	PresentValue float32
+	Values       []objects.APDUPayload
 }
 
-func ComplexACKObjects(objectType uint16, instN uint32, propertyId uint8, value float32) []objects.APDUPayload {
-	objs := make([]objects.APDUPayload, 5)
+func ComplexACKObjects(objectType uint16, instN uint32, propertyId uint32, value float32) []objects.APDUPayload {
+	return ComplexACKPropertyObjects(objectType, instN, propertyId, objects.ArrayAll, []objects.APDUPayload{objects.EncReal(value)})
+}
+
+// ComplexACKPropertyObjects creates the ReadProperty-ACK objects for an arbitrary list of
+// encoded values. Pass objects.ArrayAll as the arrayIndex to leave it out.
+func ComplexACKPropertyObjects(objectType uint16, instN uint32, propertyId uint32, arrayIndex uint32, values []objects.APDUPayload) []objects.APDUPayload {
+	objs := make([]objects.APDUPayload, 0, 5+len(values))
 
-	objs[0] = objects.EncObjectIdentifier(true, 0, objectType, instN)
-	objs[1] = objects.EncPropertyIdentifier(true, 1, propertyId)
-	objs[2] = objects.EncOpeningTag(3)
-	objs[3] = objects.EncReal(value)
-	objs[4] = objects.EncClosingTag(3)
+	objs = append(objs, objects.EncObjectIdentifier(true, 0, objectType, instN))
+	objs = append(objs, objects.EncPropertyIdentifier(true, 1, propertyId))
+	if arrayIndex != objects.ArrayAll {
+		objs = append(objs, objects.EncArrayIndex(2, arrayIndex))
+	}
+	objs = append(objs, objects.EncEnclosed(3, values...)...)
 
 	return objs
 }
@@ -36,9 +45,7 @@ func NewComplexACK(bvlc *plumbing.BVLC, npdu *plumbing.NPDU) *ComplexACK {
 	c := &ComplexACK{
 		BVLC: bvlc,
 		NPDU: npdu,
-		// TODO: Consider to implement parameter struct to an argment of New functions.
-		APDU: plumbing.NewAPDU(plumbing.ComplexAck, ServiceConfirmedReadProperty, ComplexACKObjects(
-			objects.ObjectTypeAnalogOutput, 1, objects.PropertyIdPresentValue, 0)),
+		APDU: plumbing.NewAPDU(plumbing.ComplexAck, ServiceConfirmedReadProperty, nil),
 	}
 	c.SetLength()
 
@@ -111,32 +118,46 @@ func (u *ComplexACK) SetLength() {
 }
 
 func (c *ComplexACK) Decode() (ComplexACKDec, error) {
-	decCACK := ComplexACKDec{}
+	decCACK := ComplexACKDec{ArrayIndex: objects.ArrayAll}
+
+	if len(c.APDU.Objects) < 4 {
+		return decCACK, common.ErrWrongObjectCount
+	}
+
+	objId, err := objects.DecObjectIdentifier(c.APDU.Objects[0])
+	if err != nil {
+		return decCACK, err
+	}
+	decCACK.ObjectType = objId.ObjectType
+	decCACK.InstanceId = objId.InstanceNumber
+
+	propId, err := objects.DecPropertyIdentifier(c.APDU.Objects[1])
+	if err != nil {
+		return decCACK, err
+	}
+	decCACK.PropertyId = propId
 
-	if len(c.APDU.Objects) != 3 {
+	offset := 2
+	if objects.IsContextTag(c.APDU.Objects[offset], 2) {
+		arrayIndex, err := objects.DecArrayIndex(c.APDU.Objects[offset])
+		if err != nil {
+			return decCACK, err
+		}
+		decCACK.ArrayIndex = arrayIndex
+		offset++
+	}
+
+	values, offset, err := objects.DecEnclosed(c.APDU.Objects, offset, 3)
+	if err != nil {
+		return decCACK, err
+	}
+	if offset != len(c.APDU.Objects) {
 		return decCACK, common.ErrWrongObjectCount
 	}
+	decCACK.Values = values
 
-	for i, obj := range c.APDU.Objects {
-		switch i {
-		case 0:
-			objId, err := objects.DecObjectIdentifier(obj)
-			if err != nil {
-				return decCACK, err
-			}
-			decCACK.ObjectType = objId.ObjectType
-			decCACK.InstanceId = objId.InstanceNumber
-		case 1:
-			propId, err := objects.DecPropertyIdentifier(obj)
-			if err != nil {
-				return decCACK, err
-			}
-			decCACK.PropertyId = propId
-		case 2:
-			value, err := objects.DecReal(obj)
-			if err != nil {
-				return decCACK, err
-			}
+	if len(values) == 1 {
+		if value, err := objects.DecReal(values[0]); err == nil {
 			decCACK.PresentValue = value
 		}
 	}
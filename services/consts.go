@@ -13,6 +13,7 @@ const (
 	ServiceUnconfirmedWhoIs
 	ServiceUnconfirmedUTCTimeSync
 	ServiceUnconfirmedWriteGroup
+	ServiceUnconfirmedCOVNotificationMultiple
 )
 
 // Services in APDU of which type is confirmed request.
@@ -43,4 +44,53 @@ const (
 	ServiceConfirmedVTData
 	ServiceConfirmedAuthenticate
 	ServiceConfirmedRequestKey
+	ServiceConfirmedReadRange
+	ServiceConfirmedLifeSafetyOperation
+	ServiceConfirmedSubscribeCOVProperty
+	ServiceConfirmedGetEventInformation
+	ServiceConfirmedSubscribeCOVPropertyMultiple
+	ServiceConfirmedCOVNotificationMultiple
 )
+
+// Bits of the services on the Protocol_Services_Supported bit string. Confirmed services
+// up to RequestKey sit on the bit matching their service choice.
+var confirmedServiceBits = map[uint8]int{
+	ServiceConfirmedReadRange:                    35,
+	ServiceConfirmedLifeSafetyOperation:          37,
+	ServiceConfirmedSubscribeCOVProperty:         38,
+	ServiceConfirmedGetEventInformation:          39,
+	ServiceConfirmedSubscribeCOVPropertyMultiple: 41,
+	ServiceConfirmedCOVNotificationMultiple:      42,
+}
+
+var unconfirmedServiceBits = map[uint8]int{
+	ServiceUnconfirmedIAm:                     26,
+	ServiceUnconfirmedIHave:                   27,
+	ServiceUnconfirmedCOVNotification:         28,
+	ServiceUnconfirmedEventNotification:       29,
+	ServiceUnconfirmedPrivateTransfer:         30,
+	ServiceUnconfirmedTextMessage:             31,
+	ServiceUnconfirmedTimeSync:                32,
+	ServiceUnconfirmedWhoHas:                  33,
+	ServiceUnconfirmedWhoIs:                   34,
+	ServiceUnconfirmedUTCTimeSync:             36,
+	ServiceUnconfirmedWriteGroup:              40,
+	ServiceUnconfirmedCOVNotificationMultiple: 43,
+}
+
+// ServiceSupportedBit returns the bit of a service on the Protocol_Services_Supported bit
+// string or -1 if it's unknown.
+func ServiceSupportedBit(confirmed bool, service uint8) int {
+	if confirmed && service <= ServiceConfirmedRequestKey {
+		return int(service)
+	}
+
+	bits := unconfirmedServiceBits
+	if confirmed {
+		bits = confirmedServiceBits
+	}
+	if bit, ok := bits[service]; ok {
+		return bit
+	}
+	return -1
+}
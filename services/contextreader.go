@@ -0,0 +1,193 @@
+package services
+
+import (
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+)
+
+// contextReader decodes context tagged values in order, keeping the first error it comes
+// across so that a run of values can be decoded before checking it. It's used by the
+// services whose parameters are mostly context tagged primitives.
+type contextReader struct {
+	rawPayloads []objects.APDUPayload
+	i           int
+	err         error
+}
+
+func (r *contextReader) fail(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// has tells whether the next payload is context tagged with tagN.
+func (r *contextReader) has(tagN uint8) bool {
+	return r.err == nil && r.i < len(r.rawPayloads) && objects.IsContextTag(r.rawPayloads[r.i], tagN)
+}
+
+// hasEnclosed tells whether the next payload opens context tag tagN.
+func (r *contextReader) hasEnclosed(tagN uint8) bool {
+	return r.err == nil && r.i < len(r.rawPayloads) && objects.IsOpeningTag(r.rawPayloads[r.i], tagN)
+}
+
+// next returns the next payload if it's context tagged with tagN.
+func (r *contextReader) next(tagN uint8) objects.APDUPayload {
+	if !r.has(tagN) {
+		r.fail(common.ErrWrongStructure)
+		return nil
+	}
+	r.i++
+	return r.rawPayloads[r.i-1]
+}
+
+func (r *contextReader) enclosed(tagN uint8) []objects.APDUPayload {
+	if r.err != nil {
+		return nil
+	}
+	values, next, err := objects.DecEnclosed(r.rawPayloads, r.i, tagN)
+	if err != nil {
+		r.fail(err)
+		return nil
+	}
+	r.i = next
+	return values
+}
+
+func (r *contextReader) boolean(tagN uint8) bool {
+	if obj := r.next(tagN); obj != nil {
+		v, err := objects.DecBoolean(obj)
+		r.fail(err)
+		return v
+	}
+	return false
+}
+
+func (r *contextReader) objectIdentifier(tagN uint8) objects.ObjectIdentifier {
+	if obj := r.next(tagN); obj != nil {
+		v, err := objects.DecObjectIdentifier(obj)
+		r.fail(err)
+		return v
+	}
+	return objects.ObjectIdentifier{}
+}
+
+func (r *contextReader) unsigned(tagN uint8) uint32 {
+	if obj := r.next(tagN); obj != nil {
+		v, err := objects.DecUnisgnedInteger(obj)
+		r.fail(err)
+		return v
+	}
+	return 0
+}
+
+func (r *contextReader) signed(tagN uint8) int32 {
+	if obj := r.next(tagN); obj != nil {
+		v, err := objects.DecSignedInteger(obj)
+		r.fail(err)
+		return v
+	}
+	return 0
+}
+
+func (r *contextReader) enumerated(tagN uint8) uint32 {
+	if obj := r.next(tagN); obj != nil {
+		v, err := objects.DecEnumerated(obj)
+		r.fail(err)
+		return v
+	}
+	return 0
+}
+
+func (r *contextReader) real(tagN uint8) float32 {
+	if obj := r.next(tagN); obj != nil {
+		v, err := objects.DecReal(obj)
+		r.fail(err)
+		return v
+	}
+	return 0
+}
+
+func (r *contextReader) double(tagN uint8) float64 {
+	if obj := r.next(tagN); obj != nil {
+		v, err := objects.DecDouble(obj)
+		r.fail(err)
+		return v
+	}
+	return 0
+}
+
+func (r *contextReader) characterString(tagN uint8) string {
+	if obj := r.next(tagN); obj != nil {
+		v, err := objects.DecCharacterString(obj)
+		r.fail(err)
+		return v
+	}
+	return ""
+}
+
+func (r *contextReader) bitString(tagN uint8) objects.BitString {
+	if obj := r.next(tagN); obj != nil {
+		v, err := objects.DecBitString(obj)
+		r.fail(err)
+		return v
+	}
+	return nil
+}
+
+func (r *contextReader) dateTime(tagN uint8) objects.DateTime {
+	values := r.enclosed(tagN)
+	if r.err != nil {
+		return objects.DateTime{}
+	}
+	v, err := objects.DecDateTime(values)
+	r.fail(err)
+	return v
+}
+
+func (r *contextReader) timeStamp(tagN uint8) TimeStamp {
+	values := r.enclosed(tagN)
+	if r.err != nil {
+		return TimeStamp{}
+	}
+	v, next, err := DecTimeStamp(values, 0)
+	if err == nil && next != len(values) {
+		err = common.ErrWrongStructure
+	}
+	r.fail(err)
+	return v
+}
+
+func (r *contextReader) propertyState(tagN uint8) PropertyState {
+	values := r.enclosed(tagN)
+	if r.err != nil {
+		return PropertyState{}
+	}
+	if len(values) != 1 {
+		r.fail(common.ErrWrongStructure)
+		return PropertyState{}
+	}
+	v, err := DecPropertyState(values[0])
+	r.fail(err)
+	return v
+}
+
+func (r *contextReader) propertyValues(tagN uint8) []PropertyValue {
+	if r.err != nil {
+		return nil
+	}
+	values, next, err := DecPropertyValues(r.rawPayloads, r.i, tagN)
+	if err != nil {
+		r.fail(err)
+		return nil
+	}
+	r.i = next
+	return values
+}
+
+// done returns the first error come across, if any, or an error if values are left over.
+func (r *contextReader) done() error {
+	if r.err == nil && r.i != len(r.rawPayloads) {
+		return common.ErrWrongObjectCount
+	}
+	return r.err
+}
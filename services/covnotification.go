@@ -0,0 +1,256 @@
+package services
+
+import (
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+)
+
+// ConfirmedCOVNotification is a BACnet message. It's acknowledged with a SimpleACK.
+type ConfirmedCOVNotification struct {
+	*plumbing.BVLC
+	*plumbing.NPDU
+	*plumbing.APDU
+}
+
+// UnconfirmedCOVNotification is a BACnet message.
+type UnconfirmedCOVNotification struct {
+	*plumbing.BVLC
+	*plumbing.NPDU
+	*plumbing.APDU
+}
+
+// COVNotificationDec is shared by both confirmed and unconfirmed COV notifications.
+type COVNotificationDec struct {
+	ProcessId  uint32
+	DeviceId   uint32
+	ObjectType uint16
+	InstanceId uint32
+	// TimeRemaining is the subscription's remaining lifetime in seconds.
+	TimeRemaining uint32
+	Values        []PropertyValue
+}
+
+// COVNotificationObjects creates the objects of both confirmed and unconfirmed COV notifications.
+func COVNotificationObjects(processId uint32, deviceId uint32, objectType uint16, instN uint32, timeRemaining uint32, values []PropertyValue) []objects.APDUPayload {
+	objs := make([]objects.APDUPayload, 0, 6)
+
+	objs = append(objs, objects.EncContext(0, objects.EncUnsignedInteger(processId)))
+	objs = append(objs, objects.EncObjectIdentifier(true, 1, objects.ObjectTypeDevice, deviceId))
+	objs = append(objs, objects.EncObjectIdentifier(true, 2, objectType, instN))
+	objs = append(objs, objects.EncContext(3, objects.EncUnsignedInteger(timeRemaining)))
+	objs = append(objs, EncPropertyValues(4, values)...)
+
+	return objs
+}
+
+func NewConfirmedCOVNotification(bvlc *plumbing.BVLC, npdu *plumbing.NPDU) *ConfirmedCOVNotification {
+	c := &ConfirmedCOVNotification{
+		BVLC: bvlc,
+		NPDU: npdu,
+		APDU: plumbing.NewAPDU(plumbing.ConfirmedReq, ServiceConfirmedCOVNotification, nil),
+	}
+	c.SetLength()
+
+	return c
+}
+
+func NewUnconfirmedCOVNotification(bvlc *plumbing.BVLC, npdu *plumbing.NPDU) *UnconfirmedCOVNotification {
+	u := &UnconfirmedCOVNotification{
+		BVLC: bvlc,
+		NPDU: npdu,
+		APDU: plumbing.NewAPDU(plumbing.UnConfirmedReq, ServiceUnconfirmedCOVNotification, nil),
+	}
+	u.SetLength()
+
+	return u
+}
+
+func (c *ConfirmedCOVNotification) UnmarshalBinary(b []byte) error {
+	if l := len(b); l < c.MarshalLen() {
+		return common.ErrTooShortToParse
+	}
+
+	var offset int = 0
+	if err := c.BVLC.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+
+	return nil
+}
+
+func (c *ConfirmedCOVNotification) MarshalBinary() ([]byte, error) {
+	b := make([]byte, c.MarshalLen())
+	if err := c.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (c *ConfirmedCOVNotification) MarshalTo(b []byte) error {
+	if len(b) < c.MarshalLen() {
+		return common.ErrTooShortToMarshalBinary
+	}
+	var offset = 0
+	if err := c.BVLC.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *ConfirmedCOVNotification) MarshalLen() int {
+	l := c.BVLC.MarshalLen()
+	l += c.NPDU.MarshalLen()
+	l += c.APDU.MarshalLen()
+
+	return l
+}
+
+func (c *ConfirmedCOVNotification) SetLength() {
+	c.BVLC.Length = uint16(c.MarshalLen())
+}
+
+func (c *ConfirmedCOVNotification) Decode() (COVNotificationDec, error) {
+	return decCOVNotification(c.APDU.Objects)
+}
+
+func (u *UnconfirmedCOVNotification) UnmarshalBinary(b []byte) error {
+	if l := len(b); l < u.MarshalLen() {
+		return common.ErrTooShortToParse
+	}
+
+	var offset int = 0
+	if err := u.BVLC.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += u.BVLC.MarshalLen()
+
+	if err := u.NPDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += u.NPDU.MarshalLen()
+
+	if err := u.APDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+
+	return nil
+}
+
+func (u *UnconfirmedCOVNotification) MarshalBinary() ([]byte, error) {
+	b := make([]byte, u.MarshalLen())
+	if err := u.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (u *UnconfirmedCOVNotification) MarshalTo(b []byte) error {
+	if len(b) < u.MarshalLen() {
+		return common.ErrTooShortToMarshalBinary
+	}
+	var offset = 0
+	if err := u.BVLC.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += u.BVLC.MarshalLen()
+
+	if err := u.NPDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += u.NPDU.MarshalLen()
+
+	if err := u.APDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u *UnconfirmedCOVNotification) MarshalLen() int {
+	l := u.BVLC.MarshalLen()
+	l += u.NPDU.MarshalLen()
+	l += u.APDU.MarshalLen()
+
+	return l
+}
+
+func (u *UnconfirmedCOVNotification) SetLength() {
+	u.BVLC.Length = uint16(u.MarshalLen())
+}
+
+func (u *UnconfirmedCOVNotification) Decode() (COVNotificationDec, error) {
+	return decCOVNotification(u.APDU.Objects)
+}
+
+func decCOVNotification(rawPayloads []objects.APDUPayload) (COVNotificationDec, error) {
+	decCOV := COVNotificationDec{}
+
+	if len(rawPayloads) < 6 {
+		return decCOV, common.ErrWrongObjectCount
+	}
+
+	for i, obj := range rawPayloads[:4] {
+		if !objects.IsContextTag(obj, uint8(i)) {
+			return decCOV, common.ErrWrongStructure
+		}
+	}
+
+	processId, err := objects.DecUnisgnedInteger(rawPayloads[0])
+	if err != nil {
+		return decCOV, err
+	}
+	decCOV.ProcessId = processId
+
+	devId, err := objects.DecObjectIdentifier(rawPayloads[1])
+	if err != nil {
+		return decCOV, err
+	}
+	decCOV.DeviceId = devId.InstanceNumber
+
+	objId, err := objects.DecObjectIdentifier(rawPayloads[2])
+	if err != nil {
+		return decCOV, err
+	}
+	decCOV.ObjectType = objId.ObjectType
+	decCOV.InstanceId = objId.InstanceNumber
+
+	timeRemaining, err := objects.DecUnisgnedInteger(rawPayloads[3])
+	if err != nil {
+		return decCOV, err
+	}
+	decCOV.TimeRemaining = timeRemaining
+
+	values, offset, err := DecPropertyValues(rawPayloads, 4, 4)
+	if err != nil {
+		return decCOV, err
+	}
+	decCOV.Values = values
+
+	if offset != len(rawPayloads) {
+		return decCOV, common.ErrWrongObjectCount
+	}
+
+	return decCOV, nil
+}
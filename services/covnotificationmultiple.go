@@ -0,0 +1,348 @@
+package services
+
+import (
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+)
+
+// ConfirmedCOVNotificationMultiple is a BACnet message. It's acknowledged with a SimpleACK.
+type ConfirmedCOVNotificationMultiple struct {
+	*plumbing.BVLC
+	*plumbing.NPDU
+	*plumbing.APDU
+}
+
+// UnconfirmedCOVNotificationMultiple is a BACnet message.
+type UnconfirmedCOVNotificationMultiple struct {
+	*plumbing.BVLC
+	*plumbing.NPDU
+	*plumbing.APDU
+}
+
+// COVPropertyValue is a changed property reported by a COV notification multiple.
+type COVPropertyValue struct {
+	PropertyId uint32
+	// ArrayIndex is objects.ArrayAll when the whole property is meant.
+	ArrayIndex uint32
+	Values     []objects.APDUPayload
+	// TimeOfChange is nil unless the subscription asked for timestamps.
+	TimeOfChange *objects.Time
+}
+
+// COVObjectNotification groups the changed properties of an object.
+type COVObjectNotification struct {
+	ObjectType uint16
+	InstanceId uint32
+	Values     []COVPropertyValue
+}
+
+type COVNotificationMultipleDec struct {
+	ProcessId     uint32
+	DeviceId      uint32
+	TimeRemaining uint32
+	// Timestamp is nil when not present.
+	Timestamp     *objects.DateTime
+	Notifications []COVObjectNotification
+}
+
+// COVNotificationMultipleObjects creates the objects of both confirmed and unconfirmed COV
+// notifications multiple. A nil timestamp is left out.
+func COVNotificationMultipleObjects(processId uint32, deviceId uint32, timeRemaining uint32, timestamp *objects.DateTime, notifications []COVObjectNotification) []objects.APDUPayload {
+	objs := make([]objects.APDUPayload, 0, 9)
+
+	objs = append(objs, objects.EncContext(0, objects.EncUnsignedInteger(processId)))
+	objs = append(objs, objects.EncObjectIdentifier(true, 1, objects.ObjectTypeDevice, deviceId))
+	objs = append(objs, objects.EncContext(2, objects.EncUnsignedInteger(timeRemaining)))
+	if timestamp != nil {
+		objs = append(objs, objects.EncEnclosed(3, objects.EncDateTime(*timestamp)...)...)
+	}
+
+	list := []objects.APDUPayload{}
+	for _, n := range notifications {
+		list = append(list, objects.EncObjectIdentifier(true, 0, n.ObjectType, n.InstanceId))
+
+		values := []objects.APDUPayload{}
+		for _, v := range n.Values {
+			values = append(values, objects.EncPropertyIdentifier(true, 0, v.PropertyId))
+			if v.ArrayIndex != objects.ArrayAll {
+				values = append(values, objects.EncArrayIndex(1, v.ArrayIndex))
+			}
+			values = append(values, objects.EncEnclosed(2, v.Values...)...)
+			if v.TimeOfChange != nil {
+				values = append(values, objects.EncContext(3, objects.EncTime(*v.TimeOfChange)))
+			}
+		}
+		list = append(list, objects.EncEnclosed(1, values...)...)
+	}
+	objs = append(objs, objects.EncEnclosed(4, list...)...)
+
+	return objs
+}
+
+func NewConfirmedCOVNotificationMultiple(bvlc *plumbing.BVLC, npdu *plumbing.NPDU) *ConfirmedCOVNotificationMultiple {
+	c := &ConfirmedCOVNotificationMultiple{
+		BVLC: bvlc,
+		NPDU: npdu,
+		APDU: plumbing.NewAPDU(plumbing.ConfirmedReq, ServiceConfirmedCOVNotificationMultiple, nil),
+	}
+	c.SetLength()
+
+	return c
+}
+
+func NewUnconfirmedCOVNotificationMultiple(bvlc *plumbing.BVLC, npdu *plumbing.NPDU) *UnconfirmedCOVNotificationMultiple {
+	u := &UnconfirmedCOVNotificationMultiple{
+		BVLC: bvlc,
+		NPDU: npdu,
+		APDU: plumbing.NewAPDU(plumbing.UnConfirmedReq, ServiceUnconfirmedCOVNotificationMultiple, nil),
+	}
+	u.SetLength()
+
+	return u
+}
+
+func (c *ConfirmedCOVNotificationMultiple) UnmarshalBinary(b []byte) error {
+	if l := len(b); l < c.MarshalLen() {
+		return common.ErrTooShortToParse
+	}
+
+	var offset int = 0
+	if err := c.BVLC.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+
+	return nil
+}
+
+func (c *ConfirmedCOVNotificationMultiple) MarshalBinary() ([]byte, error) {
+	b := make([]byte, c.MarshalLen())
+	if err := c.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (c *ConfirmedCOVNotificationMultiple) MarshalTo(b []byte) error {
+	if len(b) < c.MarshalLen() {
+		return common.ErrTooShortToMarshalBinary
+	}
+	var offset = 0
+	if err := c.BVLC.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *ConfirmedCOVNotificationMultiple) MarshalLen() int {
+	l := c.BVLC.MarshalLen()
+	l += c.NPDU.MarshalLen()
+	l += c.APDU.MarshalLen()
+
+	return l
+}
+
+func (c *ConfirmedCOVNotificationMultiple) SetLength() {
+	c.BVLC.Length = uint16(c.MarshalLen())
+}
+
+func (c *ConfirmedCOVNotificationMultiple) Decode() (COVNotificationMultipleDec, error) {
+	return decCOVNotificationMultiple(c.APDU.Objects)
+}
+
+func (u *UnconfirmedCOVNotificationMultiple) UnmarshalBinary(b []byte) error {
+	if l := len(b); l < u.MarshalLen() {
+		return common.ErrTooShortToParse
+	}
+
+	var offset int = 0
+	if err := u.BVLC.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += u.BVLC.MarshalLen()
+
+	if err := u.NPDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += u.NPDU.MarshalLen()
+
+	if err := u.APDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+
+	return nil
+}
+
+func (u *UnconfirmedCOVNotificationMultiple) MarshalBinary() ([]byte, error) {
+	b := make([]byte, u.MarshalLen())
+	if err := u.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (u *UnconfirmedCOVNotificationMultiple) MarshalTo(b []byte) error {
+	if len(b) < u.MarshalLen() {
+		return common.ErrTooShortToMarshalBinary
+	}
+	var offset = 0
+	if err := u.BVLC.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += u.BVLC.MarshalLen()
+
+	if err := u.NPDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += u.NPDU.MarshalLen()
+
+	if err := u.APDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u *UnconfirmedCOVNotificationMultiple) MarshalLen() int {
+	l := u.BVLC.MarshalLen()
+	l += u.NPDU.MarshalLen()
+	l += u.APDU.MarshalLen()
+
+	return l
+}
+
+func (u *UnconfirmedCOVNotificationMultiple) SetLength() {
+	u.BVLC.Length = uint16(u.MarshalLen())
+}
+
+func (u *UnconfirmedCOVNotificationMultiple) Decode() (COVNotificationMultipleDec, error) {
+	return decCOVNotificationMultiple(u.APDU.Objects)
+}
+
+func decCOVNotificationMultiple(rawPayloads []objects.APDUPayload) (COVNotificationMultipleDec, error) {
+	decCOV := COVNotificationMultipleDec{}
+
+	if len(rawPayloads) < 5 {
+		return decCOV, common.ErrWrongObjectCount
+	}
+
+	for i, obj := range rawPayloads[:3] {
+		if !objects.IsContextTag(obj, uint8(i)) {
+			return decCOV, common.ErrWrongStructure
+		}
+	}
+
+	processId, err := objects.DecUnisgnedInteger(rawPayloads[0])
+	if err != nil {
+		return decCOV, err
+	}
+	decCOV.ProcessId = processId
+
+	devId, err := objects.DecObjectIdentifier(rawPayloads[1])
+	if err != nil {
+		return decCOV, err
+	}
+	decCOV.DeviceId = devId.InstanceNumber
+
+	timeRemaining, err := objects.DecUnisgnedInteger(rawPayloads[2])
+	if err != nil {
+		return decCOV, err
+	}
+	decCOV.TimeRemaining = timeRemaining
+
+	offset := 3
+	if objects.IsOpeningTag(rawPayloads[offset], 3) {
+		var rawTimestamp []objects.APDUPayload
+		if rawTimestamp, offset, err = objects.DecEnclosed(rawPayloads, offset, 3); err != nil {
+			return decCOV, err
+		}
+		timestamp, err := objects.DecDateTime(rawTimestamp)
+		if err != nil {
+			return decCOV, err
+		}
+		decCOV.Timestamp = &timestamp
+	}
+
+	list, offset, err := objects.DecEnclosed(rawPayloads, offset, 4)
+	if err != nil {
+		return decCOV, err
+	}
+	if offset != len(rawPayloads) {
+		return decCOV, common.ErrWrongObjectCount
+	}
+
+	decCOV.Notifications = []COVObjectNotification{}
+	for i := 0; i < len(list); {
+		if !objects.IsContextTag(list[i], 0) {
+			return decCOV, common.ErrWrongStructure
+		}
+		objId, err := objects.DecObjectIdentifier(list[i])
+		if err != nil {
+			return decCOV, err
+		}
+		n := COVObjectNotification{ObjectType: objId.ObjectType, InstanceId: objId.InstanceNumber}
+
+		var values []objects.APDUPayload
+		if values, i, err = objects.DecEnclosed(list, i+1, 1); err != nil {
+			return decCOV, err
+		}
+
+		for j := 0; j < len(values); {
+			if !objects.IsContextTag(values[j], 0) {
+				return decCOV, common.ErrWrongStructure
+			}
+			v := COVPropertyValue{ArrayIndex: objects.ArrayAll}
+			if v.PropertyId, err = objects.DecPropertyIdentifier(values[j]); err != nil {
+				return decCOV, err
+			}
+			j++
+
+			if j < len(values) && objects.IsContextTag(values[j], 1) {
+				if v.ArrayIndex, err = objects.DecArrayIndex(values[j]); err != nil {
+					return decCOV, err
+				}
+				j++
+			}
+
+			if v.Values, j, err = objects.DecEnclosed(values, j, 2); err != nil {
+				return decCOV, err
+			}
+
+			if j < len(values) && objects.IsContextTag(values[j], 3) {
+				timeOfChange, err := objects.DecTime(values[j])
+				if err != nil {
+					return decCOV, err
+				}
+				v.TimeOfChange = &timeOfChange
+				j++
+			}
+
+			n.Values = append(n.Values, v)
+		}
+
+		decCOV.Notifications = append(decCOV.Notifications, n)
+	}
+
+	return decCOV, nil
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+)
+
+// ConfirmedCreateObject is a BACnet message. It's acknowledged with a ComplexACK carrying
+// the identifier of the object created.
+type ConfirmedCreateObject struct {
+	*plumbing.BVLC
+	*plumbing.NPDU
+	*plumbing.APDU
+}
+
+type ConfirmedCreateObjectDec struct {
+	ObjectType uint16
+	// InstanceId is objects.MaxInstance when the object was specified by its type alone,
+	// leaving the device to pick the instance number.
+	InstanceId    uint32
+	InitialValues []PropertyValue
+}
+
+// CreateObjectErrorDec is a CreateObject-Error: the error the creation ran into and the
+// 1-based position of the initial value causing it, 0 if it wasn't caused by any.
+type CreateObjectErrorDec struct {
+	ErrorClass         uint8
+	ErrorCode          uint8
+	FirstFailedElement uint32
+}
+
+// ConfirmedCreateObjectObjects creates the CreateObject request objects. Pass
+// objects.MaxInstance as the instance to specify the object by its type alone. The list of
+// initial values is left out when empty.
+func ConfirmedCreateObjectObjects(objectType uint16, instN uint32, values []PropertyValue) []objects.APDUPayload {
+	var specifier objects.APDUPayload
+	if instN == objects.MaxInstance {
+		specifier = objects.EncContext(0, objects.EncEnumerated(uint32(objectType)))
+	} else {
+		specifier = objects.EncObjectIdentifier(true, 1, objectType, instN)
+	}
+
+	objs := objects.EncEnclosed(0, specifier)
+	if len(values) > 0 {
+		objs = append(objs, EncPropertyValues(1, values)...)
+	}
+
+	return objs
+}
+
+// CreateObjectACKObjects creates the objects of a ComplexACK answering a CreateObject request.
+func CreateObjectACKObjects(objectType uint16, instN uint32) []objects.APDUPayload {
+	return []objects.APDUPayload{objects.EncObjectIdentifier(false, objects.TagBACnetObjectIdentifier, objectType, instN)}
+}
+
+// CreateObjectErrorObjects creates the CreateObject-Error objects.
+func CreateObjectErrorObjects(errClass, errCode uint8, firstFailedElement uint32) []objects.APDUPayload {
+	return failedElementErrorObjects(errClass, errCode, firstFailedElement)
+}
+
+func NewConfirmedCreateObject(bvlc *plumbing.BVLC, npdu *plumbing.NPDU) *ConfirmedCreateObject {
+	c := &ConfirmedCreateObject{
+		BVLC: bvlc,
+		NPDU: npdu,
+		APDU: plumbing.NewAPDU(plumbing.ConfirmedReq, ServiceConfirmedCreateObject, nil),
+	}
+	c.SetLength()
+
+	return c
+}
+
+func (c *ConfirmedCreateObject) UnmarshalBinary(b []byte) error {
+	if l := len(b); l < c.MarshalLen() {
+		return common.ErrTooShortToParse
+	}
+
+	var offset int = 0
+	if err := c.BVLC.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+
+	return nil
+}
+
+func (c *ConfirmedCreateObject) MarshalBinary() ([]byte, error) {
+	b := make([]byte, c.MarshalLen())
+	if err := c.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (c *ConfirmedCreateObject) MarshalTo(b []byte) error {
+	if len(b) < c.MarshalLen() {
+		return common.ErrTooShortToMarshalBinary
+	}
+	var offset = 0
+	if err := c.BVLC.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *ConfirmedCreateObject) MarshalLen() int {
+	l := c.BVLC.MarshalLen()
+	l += c.NPDU.MarshalLen()
+	l += c.APDU.MarshalLen()
+
+	return l
+}
+
+func (c *ConfirmedCreateObject) SetLength() {
+	c.BVLC.Length = uint16(c.MarshalLen())
+}
+
+func (c *ConfirmedCreateObject) Decode() (ConfirmedCreateObjectDec, error) {
+	decCO := ConfirmedCreateObjectDec{}
+
+	specifier, offset, err := objects.DecEnclosed(c.APDU.Objects, 0, 0)
+	if err != nil {
+		return decCO, err
+	}
+	if len(specifier) != 1 {
+		return decCO, common.ErrWrongObjectCount
+	}
+
+	switch {
+	case objects.IsContextTag(specifier[0], 0):
+		objectType, err := objects.DecEnumerated(specifier[0])
+		if err != nil {
+			return decCO, err
+		}
+		// Object types, proprietary ones included, take 10 bits in object identifiers.
+		if objectType >= 1<<10 {
+			return decCO, common.ErrTooBigValue
+		}
+		decCO.ObjectType = uint16(objectType)
+		decCO.InstanceId = objects.MaxInstance
+	case objects.IsContextTag(specifier[0], 1):
+		objId, err := objects.DecObjectIdentifier(specifier[0])
+		if err != nil {
+			return decCO, err
+		}
+		decCO.ObjectType = objId.ObjectType
+		decCO.InstanceId = objId.InstanceNumber
+	default:
+		return decCO, common.ErrWrongStructure
+	}
+
+	if offset == len(c.APDU.Objects) {
+		return decCO, nil
+	}
+
+	if decCO.InitialValues, offset, err = DecPropertyValues(c.APDU.Objects, offset, 1); err != nil {
+		return decCO, err
+	}
+	if offset != len(c.APDU.Objects) {
+		return decCO, common.ErrWrongObjectCount
+	}
+
+	return decCO, nil
+}
+
+// DecodeCreateObject decodes the identifier of the object a CreateObject request created.
+func (c *ComplexACK) DecodeCreateObject() (objects.ObjectIdentifier, error) {
+	objs := c.APDU.Objects
+	if len(objs) != 1 {
+		return objects.ObjectIdentifier{}, common.ErrWrongObjectCount
+	}
+	if rawObject, ok := objs[0].(*objects.Object); !ok || rawObject.TagClass {
+		return objects.ObjectIdentifier{}, common.ErrWrongStructure
+	}
+
+	return objects.DecObjectIdentifier(objs[0])
+}
+
+// DecodeCreateObject decodes the objects of a CreateObject-Error.
+func (e *Error) DecodeCreateObject() (CreateObjectErrorDec, error) {
+	bErr, element, err := e.decFailedElement()
+	if err != nil {
+		return CreateObjectErrorDec{}, err
+	}
+	return CreateObjectErrorDec{ErrorClass: bErr.Class, ErrorCode: bErr.Code, FirstFailedElement: element}, nil
+}
@@ -0,0 +1,163 @@
+package services
+
+import (
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+)
+
+// ConfirmedDeviceCommunicationControl is a BACnet message. It's acknowledged with a SimpleACK.
+type ConfirmedDeviceCommunicationControl struct {
+	*plumbing.BVLC
+	*plumbing.NPDU
+	*plumbing.APDU
+}
+
+type ConfirmedDeviceCommunicationControlDec struct {
+	// Duration is in minutes, with 0 meaning the state holds until changed again.
+	Duration uint16
+	// State is one of objects.CommunicationEnable, objects.CommunicationDisable and
+	// objects.CommunicationDisableInitiation.
+	State    uint8
+	Password string
+}
+
+// ConfirmedDeviceCommunicationControlObjects creates the DeviceCommunicationControl request
+// objects. A zero duration and an empty password are left out.
+func ConfirmedDeviceCommunicationControlObjects(duration uint16, state uint8, password string) []objects.APDUPayload {
+	objs := make([]objects.APDUPayload, 0, 3)
+
+	if duration != 0 {
+		objs = append(objs, objects.EncContext(0, objects.EncUnsignedInteger16(duration)))
+	}
+	objs = append(objs, objects.EncContext(1, objects.EncEnumerated(uint32(state))))
+	if password != "" {
+		objs = append(objs, objects.EncContext(2, objects.EncCharacterString(password)))
+	}
+
+	return objs
+}
+
+func NewConfirmedDeviceCommunicationControl(bvlc *plumbing.BVLC, npdu *plumbing.NPDU) *ConfirmedDeviceCommunicationControl {
+	c := &ConfirmedDeviceCommunicationControl{
+		BVLC: bvlc,
+		NPDU: npdu,
+		APDU: plumbing.NewAPDU(plumbing.ConfirmedReq, ServiceConfirmedDeviceCommunicationControl, nil),
+	}
+	c.SetLength()
+
+	return c
+}
+
+func (c *ConfirmedDeviceCommunicationControl) UnmarshalBinary(b []byte) error {
+	if l := len(b); l < c.MarshalLen() {
+		return common.ErrTooShortToParse
+	}
+
+	var offset int = 0
+	if err := c.BVLC.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+
+	return nil
+}
+
+func (c *ConfirmedDeviceCommunicationControl) MarshalBinary() ([]byte, error) {
+	b := make([]byte, c.MarshalLen())
+	if err := c.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (c *ConfirmedDeviceCommunicationControl) MarshalTo(b []byte) error {
+	if len(b) < c.MarshalLen() {
+		return common.ErrTooShortToMarshalBinary
+	}
+	var offset = 0
+	if err := c.BVLC.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *ConfirmedDeviceCommunicationControl) MarshalLen() int {
+	l := c.BVLC.MarshalLen()
+	l += c.NPDU.MarshalLen()
+	l += c.APDU.MarshalLen()
+
+	return l
+}
+
+func (c *ConfirmedDeviceCommunicationControl) SetLength() {
+	c.BVLC.Length = uint16(c.MarshalLen())
+}
+
+func (c *ConfirmedDeviceCommunicationControl) Decode() (ConfirmedDeviceCommunicationControlDec, error) {
+	decDCC := ConfirmedDeviceCommunicationControlDec{}
+
+	objs := c.APDU.Objects
+	if len(objs) < 1 || len(objs) > 3 {
+		return decDCC, common.ErrWrongObjectCount
+	}
+
+	offset := 0
+	if objects.IsContextTag(objs[offset], 0) {
+		duration, err := objects.DecUnisgnedInteger(objs[offset])
+		if err != nil {
+			return decDCC, err
+		}
+		if duration > 0xFFFF {
+			return decDCC, common.ErrTooBigValue
+		}
+		decDCC.Duration = uint16(duration)
+		offset++
+	}
+
+	if offset >= len(objs) || !objects.IsContextTag(objs[offset], 1) {
+		return decDCC, common.ErrWrongStructure
+	}
+	state, err := objects.DecEnumerated(objs[offset])
+	if err != nil {
+		return decDCC, err
+	}
+	if state > uint32(objects.CommunicationDisableInitiation) {
+		return decDCC, common.ErrUndefinedEnumeration
+	}
+	decDCC.State = uint8(state)
+	offset++
+
+	if offset < len(objs) && objects.IsContextTag(objs[offset], 2) {
+		if decDCC.Password, err = objects.DecCharacterString(objs[offset]); err != nil {
+			return decDCC, err
+		}
+		offset++
+	}
+
+	if offset != len(objs) {
+		return decDCC, common.ErrWrongStructure
+	}
+
+	return decDCC, nil
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+)
+
+// ConfirmedDeleteObject is a BACnet message. It's acknowledged with a SimpleACK.
+type ConfirmedDeleteObject struct {
+	*plumbing.BVLC
+	*plumbing.NPDU
+	*plumbing.APDU
+}
+
+type ConfirmedDeleteObjectDec struct {
+	ObjectType uint16
+	InstanceId uint32
+}
+
+// ConfirmedDeleteObjectObjects creates the DeleteObject request objects.
+func ConfirmedDeleteObjectObjects(objectType uint16, instN uint32) []objects.APDUPayload {
+	return []objects.APDUPayload{objects.EncObjectIdentifier(false, objects.TagBACnetObjectIdentifier, objectType, instN)}
+}
+
+func NewConfirmedDeleteObject(bvlc *plumbing.BVLC, npdu *plumbing.NPDU) *ConfirmedDeleteObject {
+	c := &ConfirmedDeleteObject{
+		BVLC: bvlc,
+		NPDU: npdu,
+		APDU: plumbing.NewAPDU(plumbing.ConfirmedReq, ServiceConfirmedDeleteObject, nil),
+	}
+	c.SetLength()
+
+	return c
+}
+
+func (c *ConfirmedDeleteObject) UnmarshalBinary(b []byte) error {
+	if l := len(b); l < c.MarshalLen() {
+		return common.ErrTooShortToParse
+	}
+
+	var offset int = 0
+	if err := c.BVLC.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+
+	return nil
+}
+
+func (c *ConfirmedDeleteObject) MarshalBinary() ([]byte, error) {
+	b := make([]byte, c.MarshalLen())
+	if err := c.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (c *ConfirmedDeleteObject) MarshalTo(b []byte) error {
+	if len(b) < c.MarshalLen() {
+		return common.ErrTooShortToMarshalBinary
+	}
+	var offset = 0
+	if err := c.BVLC.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *ConfirmedDeleteObject) MarshalLen() int {
+	l := c.BVLC.MarshalLen()
+	l += c.NPDU.MarshalLen()
+	l += c.APDU.MarshalLen()
+
+	return l
+}
+
+func (c *ConfirmedDeleteObject) SetLength() {
+	c.BVLC.Length = uint16(c.MarshalLen())
+}
+
+func (c *ConfirmedDeleteObject) Decode() (ConfirmedDeleteObjectDec, error) {
+	decDO := ConfirmedDeleteObjectDec{}
+
+	objs := c.APDU.Objects
+	if len(objs) != 1 {
+		return decDO, common.ErrWrongObjectCount
+	}
+	if rawObject, ok := objs[0].(*objects.Object); !ok || rawObject.TagClass {
+		return decDO, common.ErrWrongStructure
+	}
+
+	objId, err := objects.DecObjectIdentifier(objs[0])
+	if err != nil {
+		return decDO, err
+	}
+	decDO.ObjectType = objId.ObjectType
+	decDO.InstanceId = objId.InstanceNumber
+
+	return decDO, nil
+}
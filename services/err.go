@@ -22,8 +22,8 @@ type ErrorDec struct {
 func ErrorObjects(errClass, errCode uint8) []objects.APDUPayload {
 	objs := make([]objects.APDUPayload, 2)
 
-	objs[0] = objects.EncEnumerated(errClass)
-	objs[1] = objects.EncEnumerated(errCode)
+	objs[0] = objects.EncEnumerated(uint32(errClass))
+	objs[1] = objects.EncEnumerated(uint32(errCode))
 
 	return objs
 }
@@ -137,3 +137,36 @@ func (e *Error) Decode() (ErrorDec, error) {
 
 	return decErr, nil
 }
+
+// failedElementErrorObjects creates the objects of the Error PDUs naming the 1-based position
+// of the element a request failed at, such as CreateObject-Error and ChangeList-Error.
+func failedElementErrorObjects(errClass, errCode uint8, firstFailedElement uint32) []objects.APDUPayload {
+	objs := objects.EncEnclosed(0, ErrorObjects(errClass, errCode)...)
+	return append(objs, objects.EncContext(1, objects.EncUnsignedInteger(firstFailedElement)))
+}
+
+// decFailedElement decodes the objects of the Error PDUs naming the element a request
+// failed at.
+func (e *Error) decFailedElement() (*common.BACnetError, uint32, error) {
+	rawErr, offset, err := objects.DecEnclosed(e.APDU.Objects, 0, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+	bErr, err := decBACnetError(rawErr)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if offset+1 != len(e.APDU.Objects) {
+		return nil, 0, common.ErrWrongObjectCount
+	}
+	if !objects.IsContextTag(e.APDU.Objects[offset], 1) {
+		return nil, 0, common.ErrWrongStructure
+	}
+	element, err := objects.DecUnisgnedInteger(e.APDU.Objects[offset])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return bErr, element, nil
+}
@@ -0,0 +1,396 @@
+package services
+
+import (
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+)
+
+// ConfirmedEventNotification is a BACnet message. It's acknowledged with a SimpleACK.
+type ConfirmedEventNotification struct {
+	*plumbing.BVLC
+	*plumbing.NPDU
+	*plumbing.APDU
+}
+
+// UnconfirmedEventNotification is a BACnet message.
+type UnconfirmedEventNotification struct {
+	*plumbing.BVLC
+	*plumbing.NPDU
+	*plumbing.APDU
+}
+
+// EventNotificationDec is shared by both confirmed and unconfirmed event notifications.
+type EventNotificationDec struct {
+	ProcessId uint32
+	// DeviceId is the instance of the device initiating the notification.
+	DeviceId          uint32
+	Object            objects.ObjectIdentifier
+	TimeStamp         TimeStamp
+	NotificationClass uint32
+	Priority          uint8
+	EventType         uint32
+	// MessageText is left out when empty.
+	MessageText string
+	NotifyType  uint8
+	// AckRequired, FromState and Parameters are left out of ack notifications.
+	AckRequired bool
+	FromState   uint8
+	ToState     uint8
+	Parameters  NotificationParameters
+}
+
+// A handful of the choices of BACnetPropertyStates.
+const (
+	PropertyStateBoolean       uint8 = 0
+	PropertyStateBinaryValue   uint8 = 1
+	PropertyStateEventType     uint8 = 2
+	PropertyStatePolarity      uint8 = 3
+	PropertyStateReliability   uint8 = 7
+	PropertyStateEventState    uint8 = 8
+	PropertyStateSystemStatus  uint8 = 9
+	PropertyStateUnits         uint8 = 10
+	PropertyStateUnsignedValue uint8 = 11
+)
+
+// PropertyState is a BACnetPropertyStates: a value of the kind told by Choice. Booleans are
+// 0 or 1, unsigned values are unsigned integers and everything else is enumerated.
+type PropertyState struct {
+	Choice uint8
+	Value  uint32
+}
+
+// EncPropertyState encodes a BACnetPropertyStates.
+func EncPropertyState(s PropertyState) *objects.Object {
+	switch s.Choice {
+	case PropertyStateBoolean:
+		return objects.EncContext(s.Choice, objects.EncBoolean(s.Value != 0))
+	case PropertyStateUnsignedValue:
+		return objects.EncContext(s.Choice, objects.EncUnsignedInteger(s.Value))
+	}
+	return objects.EncContext(s.Choice, objects.EncEnumerated(s.Value))
+}
+
+// DecPropertyState decodes a BACnetPropertyStates.
+func DecPropertyState(rawPayload objects.APDUPayload) (PropertyState, error) {
+	s := PropertyState{}
+
+	obj, ok := rawPayload.(*objects.Object)
+	if !ok || !obj.TagClass {
+		return s, common.ErrWrongStructure
+	}
+	s.Choice = obj.TagNumber
+
+	var err error
+	switch s.Choice {
+	case PropertyStateBoolean:
+		var b bool
+		if b, err = objects.DecBoolean(obj); b {
+			s.Value = 1
+		}
+	case PropertyStateUnsignedValue:
+		s.Value, err = objects.DecUnisgnedInteger(obj)
+	default:
+		s.Value, err = objects.DecEnumerated(obj)
+	}
+
+	return s, err
+}
+
+// EventNotificationObjects creates the objects of both confirmed and unconfirmed event
+// notifications.
+func EventNotificationObjects(n EventNotificationDec) []objects.APDUPayload {
+	objs := make([]objects.APDUPayload, 0, 16)
+
+	objs = append(objs, objects.EncContext(0, objects.EncUnsignedInteger(n.ProcessId)))
+	objs = append(objs, objects.EncObjectIdentifier(true, 1, objects.ObjectTypeDevice, n.DeviceId))
+	objs = append(objs, objects.EncObjectIdentifier(true, 2, n.Object.ObjectType, n.Object.InstanceNumber))
+	objs = append(objs, objects.EncEnclosed(3, EncTimeStamp(n.TimeStamp)...)...)
+	objs = append(objs, objects.EncContext(4, objects.EncUnsignedInteger(n.NotificationClass)))
+	objs = append(objs, objects.EncContext(5, objects.EncUnsignedInteger(uint32(n.Priority))))
+	objs = append(objs, objects.EncContext(6, objects.EncEnumerated(n.EventType)))
+	if n.MessageText != "" {
+		objs = append(objs, objects.EncContext(7, objects.EncCharacterString(n.MessageText)))
+	}
+	objs = append(objs, objects.EncContext(8, objects.EncEnumerated(uint32(n.NotifyType))))
+
+	ack := n.NotifyType == objects.NotifyTypeAckNotification
+	if !ack {
+		objs = append(objs, objects.EncContext(9, objects.EncBoolean(n.AckRequired)))
+		objs = append(objs, objects.EncContext(10, objects.EncEnumerated(uint32(n.FromState))))
+	}
+	objs = append(objs, objects.EncContext(11, objects.EncEnumerated(uint32(n.ToState))))
+	if !ack && n.Parameters != nil {
+		objs = append(objs, objects.EncEnclosed(12, n.Parameters.encode()...)...)
+	}
+
+	return objs
+}
+
+func NewConfirmedEventNotification(bvlc *plumbing.BVLC, npdu *plumbing.NPDU) *ConfirmedEventNotification {
+	c := &ConfirmedEventNotification{
+		BVLC: bvlc,
+		NPDU: npdu,
+		APDU: plumbing.NewAPDU(plumbing.ConfirmedReq, ServiceConfirmedEventNotification, nil),
+	}
+	c.SetLength()
+
+	return c
+}
+
+func NewUnconfirmedEventNotification(bvlc *plumbing.BVLC, npdu *plumbing.NPDU) *UnconfirmedEventNotification {
+	u := &UnconfirmedEventNotification{
+		BVLC: bvlc,
+		NPDU: npdu,
+		APDU: plumbing.NewAPDU(plumbing.UnConfirmedReq, ServiceUnconfirmedEventNotification, nil),
+	}
+	u.SetLength()
+
+	return u
+}
+
+func (c *ConfirmedEventNotification) UnmarshalBinary(b []byte) error {
+	if l := len(b); l < c.MarshalLen() {
+		return common.ErrTooShortToParse
+	}
+
+	var offset int = 0
+	if err := c.BVLC.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+
+	return nil
+}
+
+func (c *ConfirmedEventNotification) MarshalBinary() ([]byte, error) {
+	b := make([]byte, c.MarshalLen())
+	if err := c.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (c *ConfirmedEventNotification) MarshalTo(b []byte) error {
+	if len(b) < c.MarshalLen() {
+		return common.ErrTooShortToMarshalBinary
+	}
+	var offset = 0
+	if err := c.BVLC.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *ConfirmedEventNotification) MarshalLen() int {
+	l := c.BVLC.MarshalLen()
+	l += c.NPDU.MarshalLen()
+	l += c.APDU.MarshalLen()
+
+	return l
+}
+
+func (c *ConfirmedEventNotification) SetLength() {
+	c.BVLC.Length = uint16(c.MarshalLen())
+}
+
+func (u *UnconfirmedEventNotification) UnmarshalBinary(b []byte) error {
+	if l := len(b); l < u.MarshalLen() {
+		return common.ErrTooShortToParse
+	}
+
+	var offset int = 0
+	if err := u.BVLC.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += u.BVLC.MarshalLen()
+
+	if err := u.NPDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += u.NPDU.MarshalLen()
+
+	if err := u.APDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+
+	return nil
+}
+
+func (u *UnconfirmedEventNotification) MarshalBinary() ([]byte, error) {
+	b := make([]byte, u.MarshalLen())
+	if err := u.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (u *UnconfirmedEventNotification) MarshalTo(b []byte) error {
+	if len(b) < u.MarshalLen() {
+		return common.ErrTooShortToMarshalBinary
+	}
+	var offset = 0
+	if err := u.BVLC.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += u.BVLC.MarshalLen()
+
+	if err := u.NPDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += u.NPDU.MarshalLen()
+
+	if err := u.APDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u *UnconfirmedEventNotification) MarshalLen() int {
+	l := u.BVLC.MarshalLen()
+	l += u.NPDU.MarshalLen()
+	l += u.APDU.MarshalLen()
+
+	return l
+}
+
+func (u *UnconfirmedEventNotification) SetLength() {
+	u.BVLC.Length = uint16(u.MarshalLen())
+}
+
+func (c *ConfirmedEventNotification) Decode() (EventNotificationDec, error) {
+	return decEventNotification(c.APDU.Objects)
+}
+
+func (u *UnconfirmedEventNotification) Decode() (EventNotificationDec, error) {
+	return decEventNotification(u.APDU.Objects)
+}
+
+func decEventNotification(rawPayloads []objects.APDUPayload) (EventNotificationDec, error) {
+	decEvent := EventNotificationDec{}
+
+	if len(rawPayloads) < 10 {
+		return decEvent, common.ErrWrongObjectCount
+	}
+	if !objects.IsContextTag(rawPayloads[0], 0) || !objects.IsContextTag(rawPayloads[1], 1) || !objects.IsContextTag(rawPayloads[2], 2) {
+		return decEvent, common.ErrWrongStructure
+	}
+
+	var err error
+	if decEvent.ProcessId, err = objects.DecUnisgnedInteger(rawPayloads[0]); err != nil {
+		return decEvent, err
+	}
+	device, err := objects.DecObjectIdentifier(rawPayloads[1])
+	if err != nil {
+		return decEvent, err
+	}
+	decEvent.DeviceId = device.InstanceNumber
+	if decEvent.Object, err = objects.DecObjectIdentifier(rawPayloads[2]); err != nil {
+		return decEvent, err
+	}
+
+	timestamp, i, err := objects.DecEnclosed(rawPayloads, 3, 3)
+	if err != nil {
+		return decEvent, err
+	}
+	if decEvent.TimeStamp, _, err = DecTimeStamp(timestamp, 0); err != nil {
+		return decEvent, err
+	}
+
+	if i+3 > len(rawPayloads) || !objects.IsContextTag(rawPayloads[i], 4) || !objects.IsContextTag(rawPayloads[i+1], 5) || !objects.IsContextTag(rawPayloads[i+2], 6) {
+		return decEvent, common.ErrWrongStructure
+	}
+	if decEvent.NotificationClass, err = objects.DecUnisgnedInteger(rawPayloads[i]); err != nil {
+		return decEvent, err
+	}
+	priority, err := objects.DecUnisgnedInteger(rawPayloads[i+1])
+	if err != nil {
+		return decEvent, err
+	}
+	decEvent.Priority = uint8(priority)
+	if decEvent.EventType, err = objects.DecEnumerated(rawPayloads[i+2]); err != nil {
+		return decEvent, err
+	}
+	i += 3
+
+	if i < len(rawPayloads) && objects.IsContextTag(rawPayloads[i], 7) {
+		if decEvent.MessageText, err = objects.DecCharacterString(rawPayloads[i]); err != nil {
+			return decEvent, err
+		}
+		i++
+	}
+
+	notifyType, i, err := decEventEnumerated(rawPayloads, i, 8)
+	if err != nil {
+		return decEvent, err
+	}
+	decEvent.NotifyType = uint8(notifyType)
+
+	if i < len(rawPayloads) && objects.IsContextTag(rawPayloads[i], 9) {
+		if decEvent.AckRequired, err = objects.DecBoolean(rawPayloads[i]); err != nil {
+			return decEvent, err
+		}
+		i++
+	}
+	if i < len(rawPayloads) && objects.IsContextTag(rawPayloads[i], 10) {
+		fromState, err := objects.DecEnumerated(rawPayloads[i])
+		if err != nil {
+			return decEvent, err
+		}
+		decEvent.FromState = uint8(fromState)
+		i++
+	}
+
+	toState, i, err := decEventEnumerated(rawPayloads, i, 11)
+	if err != nil {
+		return decEvent, err
+	}
+	decEvent.ToState = uint8(toState)
+
+	if i < len(rawPayloads) && objects.IsOpeningTag(rawPayloads[i], 12) {
+		parameters, next, err := objects.DecEnclosed(rawPayloads, i, 12)
+		if err != nil {
+			return decEvent, err
+		}
+		if decEvent.Parameters, err = decNotificationParameters(parameters); err != nil {
+			return decEvent, err
+		}
+		i = next
+	}
+
+	if i != len(rawPayloads) {
+		return decEvent, common.ErrWrongObjectCount
+	}
+
+	return decEvent, nil
+}
+
+// decEventEnumerated decodes the Enumerated context tagged with tagN at rawPayloads[i].
+func decEventEnumerated(rawPayloads []objects.APDUPayload, i int, tagN uint8) (uint32, int, error) {
+	if i >= len(rawPayloads) || !objects.IsContextTag(rawPayloads[i], tagN) {
+		return 0, i, common.ErrWrongStructure
+	}
+	value, err := objects.DecEnumerated(rawPayloads[i])
+	return value, i + 1, err
+}
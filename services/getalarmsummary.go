@@ -0,0 +1,140 @@
+package services
+
+import (
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+)
+
+// ConfirmedGetAlarmSummary is a BACnet message. It has no parameters.
+type ConfirmedGetAlarmSummary struct {
+	*plumbing.BVLC
+	*plumbing.NPDU
+	*plumbing.APDU
+}
+
+// AlarmSummary is the alarm state of an object whose Notify_Type is ALARM and which isn't
+// NORMAL.
+type AlarmSummary struct {
+	Object           objects.ObjectIdentifier
+	AlarmState       uint8
+	AckedTransitions objects.BitString
+}
+
+// GetAlarmSummaryACKObjects creates the objects of a ComplexACK answering a GetAlarmSummary
+// request.
+func GetAlarmSummaryACKObjects(summaries []AlarmSummary) []objects.APDUPayload {
+	objs := make([]objects.APDUPayload, 0, 3*len(summaries))
+
+	for _, s := range summaries {
+		objs = append(objs, objects.EncObjectIdentifier(false, objects.TagBACnetObjectIdentifier, s.Object.ObjectType, s.Object.InstanceNumber))
+		objs = append(objs, objects.EncEnumerated(uint32(s.AlarmState)))
+		objs = append(objs, objects.EncBitString(s.AckedTransitions))
+	}
+
+	return objs
+}
+
+func NewConfirmedGetAlarmSummary(bvlc *plumbing.BVLC, npdu *plumbing.NPDU) *ConfirmedGetAlarmSummary {
+	c := &ConfirmedGetAlarmSummary{
+		BVLC: bvlc,
+		NPDU: npdu,
+		APDU: plumbing.NewAPDU(plumbing.ConfirmedReq, ServiceConfirmedGetAlarmSummary, nil),
+	}
+	c.SetLength()
+
+	return c
+}
+
+func (c *ConfirmedGetAlarmSummary) UnmarshalBinary(b []byte) error {
+	if l := len(b); l < c.MarshalLen() {
+		return common.ErrTooShortToParse
+	}
+
+	var offset int = 0
+	if err := c.BVLC.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+
+	return nil
+}
+
+func (c *ConfirmedGetAlarmSummary) MarshalBinary() ([]byte, error) {
+	b := make([]byte, c.MarshalLen())
+	if err := c.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (c *ConfirmedGetAlarmSummary) MarshalTo(b []byte) error {
+	if len(b) < c.MarshalLen() {
+		return common.ErrTooShortToMarshalBinary
+	}
+	var offset = 0
+	if err := c.BVLC.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *ConfirmedGetAlarmSummary) MarshalLen() int {
+	l := c.BVLC.MarshalLen()
+	l += c.NPDU.MarshalLen()
+	l += c.APDU.MarshalLen()
+
+	return l
+}
+
+func (c *ConfirmedGetAlarmSummary) SetLength() {
+	c.BVLC.Length = uint16(c.MarshalLen())
+}
+
+// DecodeGetAlarmSummary decodes the objects of a ComplexACK answering a GetAlarmSummary
+// request.
+func (c *ComplexACK) DecodeGetAlarmSummary() ([]AlarmSummary, error) {
+	objs := c.APDU.Objects
+	if len(objs)%3 != 0 {
+		return nil, common.ErrWrongObjectCount
+	}
+
+	summaries := make([]AlarmSummary, 0, len(objs)/3)
+	for i := 0; i < len(objs); i += 3 {
+		values, err := objects.DecValues(objs[i : i+3])
+		if err != nil {
+			return nil, err
+		}
+
+		object, okObject := values[0].(objects.ObjectIdentifier)
+		state, okState := values[1].(objects.Enumerated)
+		acked, okAcked := values[2].(objects.BitString)
+		if !okObject || !okState || !okAcked {
+			return nil, common.ErrWrongStructure
+		}
+
+		summaries = append(summaries, AlarmSummary{Object: object, AlarmState: uint8(state), AckedTransitions: acked})
+	}
+
+	return summaries, nil
+}
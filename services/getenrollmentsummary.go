@@ -0,0 +1,273 @@
+package services
+
+import (
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+)
+
+// Acknowledgment filters of GetEnrollmentSummary requests.
+const (
+	EnrollmentAckAll uint8 = iota
+	EnrollmentAcked
+	EnrollmentNotAcked
+)
+
+// Event state filters of GetEnrollmentSummary requests.
+const (
+	EnrollmentStateOffnormal uint8 = iota
+	EnrollmentStateFault
+	EnrollmentStateNormal
+	EnrollmentStateAll
+	// EnrollmentStateActive matches every Event_State but NORMAL.
+	EnrollmentStateActive
+)
+
+// ConfirmedGetEnrollmentSummary is a BACnet message.
+type ConfirmedGetEnrollmentSummary struct {
+	*plumbing.BVLC
+	*plumbing.NPDU
+	*plumbing.APDU
+}
+
+// PriorityRange bounds the priorities of the transitions matched by a GetEnrollmentSummary
+// request.
+type PriorityRange struct {
+	Min uint8
+	Max uint8
+}
+
+// EnrollmentFilter tells the objects a GetEnrollmentSummary request asks about. Every
+// filter but Acknowledgment is left out when nil.
+type EnrollmentFilter struct {
+	// Acknowledgment is one of the EnrollmentAck constants.
+	Acknowledgment uint8
+	// Recipient restricts the objects to those notifying it along with ProcessId.
+	Recipient *Recipient
+	ProcessId uint32
+	// EventState is one of the EnrollmentState constants.
+	EventState        *uint8
+	EventType         *uint32
+	Priority          *PriorityRange
+	NotificationClass *uint32
+}
+
+type ConfirmedGetEnrollmentSummaryDec struct {
+	EnrollmentFilter
+}
+
+// EnrollmentSummary is an object matching the filters of a GetEnrollmentSummary request.
+type EnrollmentSummary struct {
+	Object     objects.ObjectIdentifier
+	EventType  uint32
+	EventState uint8
+	// Priority is the priority of transitions to the current Event_State.
+	Priority uint8
+	// NotificationClass is left out when nil.
+	NotificationClass *uint32
+}
+
+// ConfirmedGetEnrollmentSummaryObjects creates the GetEnrollmentSummary request objects.
+func ConfirmedGetEnrollmentSummaryObjects(filter EnrollmentFilter) []objects.APDUPayload {
+	objs := make([]objects.APDUPayload, 0, 12)
+
+	objs = append(objs, objects.EncContext(0, objects.EncEnumerated(uint32(filter.Acknowledgment))))
+	if filter.Recipient != nil {
+		process := objects.EncEnclosed(0, EncRecipient(*filter.Recipient)...)
+		process = append(process, objects.EncContext(1, objects.EncUnsignedInteger(filter.ProcessId)))
+		objs = append(objs, objects.EncEnclosed(1, process...)...)
+	}
+	if filter.EventState != nil {
+		objs = append(objs, objects.EncContext(2, objects.EncEnumerated(uint32(*filter.EventState))))
+	}
+	if filter.EventType != nil {
+		objs = append(objs, objects.EncContext(3, objects.EncEnumerated(*filter.EventType)))
+	}
+	if filter.Priority != nil {
+		objs = append(objs, objects.EncEnclosed(4,
+			objects.EncContext(0, objects.EncUnsignedInteger(uint32(filter.Priority.Min))),
+			objects.EncContext(1, objects.EncUnsignedInteger(uint32(filter.Priority.Max))),
+		)...)
+	}
+	if filter.NotificationClass != nil {
+		objs = append(objs, objects.EncContext(5, objects.EncUnsignedInteger(*filter.NotificationClass)))
+	}
+
+	return objs
+}
+
+// GetEnrollmentSummaryACKObjects creates the objects of a ComplexACK answering a
+// GetEnrollmentSummary request.
+func GetEnrollmentSummaryACKObjects(summaries []EnrollmentSummary) []objects.APDUPayload {
+	objs := make([]objects.APDUPayload, 0, 5*len(summaries))
+
+	for _, s := range summaries {
+		objs = append(objs, objects.EncObjectIdentifier(false, objects.TagBACnetObjectIdentifier, s.Object.ObjectType, s.Object.InstanceNumber))
+		objs = append(objs, objects.EncEnumerated(s.EventType))
+		objs = append(objs, objects.EncEnumerated(uint32(s.EventState)))
+		objs = append(objs, objects.EncUnsignedInteger(uint32(s.Priority)))
+		if s.NotificationClass != nil {
+			objs = append(objs, objects.EncUnsignedInteger(*s.NotificationClass))
+		}
+	}
+
+	return objs
+}
+
+func NewConfirmedGetEnrollmentSummary(bvlc *plumbing.BVLC, npdu *plumbing.NPDU) *ConfirmedGetEnrollmentSummary {
+	c := &ConfirmedGetEnrollmentSummary{
+		BVLC: bvlc,
+		NPDU: npdu,
+		APDU: plumbing.NewAPDU(plumbing.ConfirmedReq, ServiceConfirmedGetEnrollmentSummary, nil),
+	}
+	c.SetLength()
+
+	return c
+}
+
+func (c *ConfirmedGetEnrollmentSummary) UnmarshalBinary(b []byte) error {
+	if l := len(b); l < c.MarshalLen() {
+		return common.ErrTooShortToParse
+	}
+
+	var offset int = 0
+	if err := c.BVLC.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+
+	return nil
+}
+
+func (c *ConfirmedGetEnrollmentSummary) MarshalBinary() ([]byte, error) {
+	b := make([]byte, c.MarshalLen())
+	if err := c.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (c *ConfirmedGetEnrollmentSummary) MarshalTo(b []byte) error {
+	if len(b) < c.MarshalLen() {
+		return common.ErrTooShortToMarshalBinary
+	}
+	var offset = 0
+	if err := c.BVLC.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *ConfirmedGetEnrollmentSummary) MarshalLen() int {
+	l := c.BVLC.MarshalLen()
+	l += c.NPDU.MarshalLen()
+	l += c.APDU.MarshalLen()
+
+	return l
+}
+
+func (c *ConfirmedGetEnrollmentSummary) SetLength() {
+	c.BVLC.Length = uint16(c.MarshalLen())
+}
+
+func (c *ConfirmedGetEnrollmentSummary) Decode() (ConfirmedGetEnrollmentSummaryDec, error) {
+	decGES := ConfirmedGetEnrollmentSummaryDec{}
+
+	r := &contextReader{rawPayloads: c.APDU.Objects}
+	decGES.Acknowledgment = uint8(r.enumerated(0))
+	if r.hasEnclosed(1) {
+		process := &contextReader{rawPayloads: r.enclosed(1)}
+		if recipient := process.enclosed(0); process.err == nil {
+			rcpt, next, err := DecRecipient(recipient, 0)
+			if err == nil && next != len(recipient) {
+				err = common.ErrWrongStructure
+			}
+			process.fail(err)
+			decGES.Recipient = &rcpt
+		}
+		decGES.ProcessId = process.unsigned(1)
+		r.fail(process.done())
+	}
+	if r.has(2) {
+		state := uint8(r.enumerated(2))
+		decGES.EventState = &state
+	}
+	if r.has(3) {
+		eventType := r.enumerated(3)
+		decGES.EventType = &eventType
+	}
+	if r.hasEnclosed(4) {
+		priority := &contextReader{rawPayloads: r.enclosed(4)}
+		decGES.Priority = &PriorityRange{Min: uint8(priority.unsigned(0)), Max: uint8(priority.unsigned(1))}
+		r.fail(priority.done())
+	}
+	if r.has(5) {
+		class := r.unsigned(5)
+		decGES.NotificationClass = &class
+	}
+
+	if err := r.done(); err != nil {
+		return decGES, err
+	}
+	return decGES, nil
+}
+
+// DecodeGetEnrollmentSummary decodes the objects of a ComplexACK answering a
+// GetEnrollmentSummary request.
+func (c *ComplexACK) DecodeGetEnrollmentSummary() ([]EnrollmentSummary, error) {
+	objs := c.APDU.Objects
+
+	summaries := []EnrollmentSummary{}
+	for i := 0; i < len(objs); {
+		if i+4 > len(objs) {
+			return nil, common.ErrWrongObjectCount
+		}
+		values, err := objects.DecValues(objs[i : i+4])
+		if err != nil {
+			return nil, err
+		}
+		i += 4
+
+		object, okObject := values[0].(objects.ObjectIdentifier)
+		eventType, okType := values[1].(objects.Enumerated)
+		state, okState := values[2].(objects.Enumerated)
+		priority, okPriority := values[3].(uint32)
+		if !okObject || !okType || !okState || !okPriority {
+			return nil, common.ErrWrongStructure
+		}
+		s := EnrollmentSummary{Object: object, EventType: uint32(eventType), EventState: uint8(state), Priority: uint8(priority)}
+
+		if i < len(objs) {
+			if class, err := objects.DecValue(objs[i]); err == nil {
+				if class, ok := class.(uint32); ok {
+					s.NotificationClass = &class
+					i++
+				}
+			}
+		}
+
+		summaries = append(summaries, s)
+	}
+
+	return summaries, nil
+}
@@ -0,0 +1,229 @@
+package services
+
+import (
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+)
+
+// ConfirmedGetEventInformation is a BACnet message.
+type ConfirmedGetEventInformation struct {
+	*plumbing.BVLC
+	*plumbing.NPDU
+	*plumbing.APDU
+}
+
+type ConfirmedGetEventInformationDec struct {
+	// LastReceivedObject is the last object of the previous page of summaries, nil when
+	// asking for the first page.
+	LastReceivedObject *objects.ObjectIdentifier
+}
+
+// EventSummary is the event information of an object which isn't NORMAL or has transitions
+// yet to be acknowledged.
+type EventSummary struct {
+	Object           objects.ObjectIdentifier
+	EventState       uint8
+	AckedTransitions objects.BitString
+	// EventTimeStamps, EventEnable and EventPriorities hold an item per kind of transition.
+	EventTimeStamps [3]TimeStamp
+	NotifyType      uint8
+	EventEnable     objects.BitString
+	EventPriorities [3]uint8
+}
+
+type GetEventInformationACKDec struct {
+	Summaries []EventSummary
+	// MoreEvents tells whether the summaries didn't fit, and are to be asked for again from
+	// the object of the last one on.
+	MoreEvents bool
+}
+
+// ConfirmedGetEventInformationObjects creates the GetEventInformation request objects. Pass
+// nil as the lastReceived object to ask for the first page of summaries.
+func ConfirmedGetEventInformationObjects(lastReceived *objects.ObjectIdentifier) []objects.APDUPayload {
+	if lastReceived == nil {
+		return []objects.APDUPayload{}
+	}
+	return []objects.APDUPayload{objects.EncObjectIdentifier(true, 0, lastReceived.ObjectType, lastReceived.InstanceNumber)}
+}
+
+// EncEventSummary encodes an event summary of a GetEventInformation ACK.
+func EncEventSummary(s EventSummary) []objects.APDUPayload {
+	objs := make([]objects.APDUPayload, 0, 16)
+
+	objs = append(objs, objects.EncObjectIdentifier(true, 0, s.Object.ObjectType, s.Object.InstanceNumber))
+	objs = append(objs, objects.EncContext(1, objects.EncEnumerated(uint32(s.EventState))))
+	objs = append(objs, objects.EncContext(2, objects.EncBitString(s.AckedTransitions)))
+
+	stamps := []objects.APDUPayload{}
+	for _, ts := range s.EventTimeStamps {
+		stamps = append(stamps, EncTimeStamp(ts)...)
+	}
+	objs = append(objs, objects.EncEnclosed(3, stamps...)...)
+
+	objs = append(objs, objects.EncContext(4, objects.EncEnumerated(uint32(s.NotifyType))))
+	objs = append(objs, objects.EncContext(5, objects.EncBitString(s.EventEnable)))
+	objs = append(objs, objects.EncEnclosed(6,
+		objects.EncUnsignedInteger(uint32(s.EventPriorities[0])),
+		objects.EncUnsignedInteger(uint32(s.EventPriorities[1])),
+		objects.EncUnsignedInteger(uint32(s.EventPriorities[2])),
+	)...)
+
+	return objs
+}
+
+// GetEventInformationACKObjects creates the objects of a ComplexACK answering a
+// GetEventInformation request.
+func GetEventInformationACKObjects(summaries []EventSummary, moreEvents bool) []objects.APDUPayload {
+	list := []objects.APDUPayload{}
+	for _, s := range summaries {
+		list = append(list, EncEventSummary(s)...)
+	}
+
+	objs := objects.EncEnclosed(0, list...)
+	return append(objs, objects.EncContext(1, objects.EncBoolean(moreEvents)))
+}
+
+func NewConfirmedGetEventInformation(bvlc *plumbing.BVLC, npdu *plumbing.NPDU) *ConfirmedGetEventInformation {
+	c := &ConfirmedGetEventInformation{
+		BVLC: bvlc,
+		NPDU: npdu,
+		APDU: plumbing.NewAPDU(plumbing.ConfirmedReq, ServiceConfirmedGetEventInformation, nil),
+	}
+	c.SetLength()
+
+	return c
+}
+
+func (c *ConfirmedGetEventInformation) UnmarshalBinary(b []byte) error {
+	if l := len(b); l < c.MarshalLen() {
+		return common.ErrTooShortToParse
+	}
+
+	var offset int = 0
+	if err := c.BVLC.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+
+	return nil
+}
+
+func (c *ConfirmedGetEventInformation) MarshalBinary() ([]byte, error) {
+	b := make([]byte, c.MarshalLen())
+	if err := c.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (c *ConfirmedGetEventInformation) MarshalTo(b []byte) error {
+	if len(b) < c.MarshalLen() {
+		return common.ErrTooShortToMarshalBinary
+	}
+	var offset = 0
+	if err := c.BVLC.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *ConfirmedGetEventInformation) MarshalLen() int {
+	l := c.BVLC.MarshalLen()
+	l += c.NPDU.MarshalLen()
+	l += c.APDU.MarshalLen()
+
+	return l
+}
+
+func (c *ConfirmedGetEventInformation) SetLength() {
+	c.BVLC.Length = uint16(c.MarshalLen())
+}
+
+func (c *ConfirmedGetEventInformation) Decode() (ConfirmedGetEventInformationDec, error) {
+	decGEI := ConfirmedGetEventInformationDec{}
+
+	r := &contextReader{rawPayloads: c.APDU.Objects}
+	if r.has(0) {
+		last := r.objectIdentifier(0)
+		decGEI.LastReceivedObject = &last
+	}
+
+	if err := r.done(); err != nil {
+		return decGEI, err
+	}
+	return decGEI, nil
+}
+
+// DecodeGetEventInformation decodes the objects of a ComplexACK answering a
+// GetEventInformation request.
+func (c *ComplexACK) DecodeGetEventInformation() (GetEventInformationACKDec, error) {
+	decGEI := GetEventInformationACKDec{}
+
+	r := &contextReader{rawPayloads: c.APDU.Objects}
+	list := &contextReader{rawPayloads: r.enclosed(0)}
+	decGEI.MoreEvents = r.boolean(1)
+	if err := r.done(); err != nil {
+		return decGEI, err
+	}
+
+	decGEI.Summaries = []EventSummary{}
+	for list.i < len(list.rawPayloads) {
+		s := EventSummary{
+			Object:           list.objectIdentifier(0),
+			EventState:       uint8(list.enumerated(1)),
+			AckedTransitions: list.bitString(2),
+		}
+
+		stamps, next := list.enclosed(3), 0
+		for i := 0; i < len(s.EventTimeStamps) && list.err == nil; i++ {
+			var err error
+			s.EventTimeStamps[i], next, err = DecTimeStamp(stamps, next)
+			list.fail(err)
+		}
+		if list.err == nil && next != len(stamps) {
+			list.fail(common.ErrWrongStructure)
+		}
+
+		s.NotifyType = uint8(list.enumerated(4))
+		s.EventEnable = list.bitString(5)
+
+		priorities := list.enclosed(6)
+		if list.err == nil && len(priorities) != len(s.EventPriorities) {
+			list.fail(common.ErrWrongStructure)
+		}
+		for i := 0; i < len(s.EventPriorities) && list.err == nil; i++ {
+			priority, err := objects.DecUnisgnedInteger(priorities[i])
+			list.fail(err)
+			s.EventPriorities[i] = uint8(priority)
+		}
+
+		if list.err != nil {
+			return decGEI, list.err
+		}
+		decGEI.Summaries = append(decGEI.Summaries, s)
+	}
+
+	return decGEI, nil
+}
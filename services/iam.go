@@ -24,9 +24,9 @@ type UnconfirmedIAmDec struct {
 func IAmObjects(insNum uint32, acceptedSize uint16, supportedSeg uint8, vendorID uint16) []objects.APDUPayload {
 	objs := make([]objects.APDUPayload, 4)
 
-	objs[0] = objects.EncObjectIdentifier(false, objects.TagBACnetObjectIdentifier, objects.ObjectTypeDevice, 321)
+	objs[0] = objects.EncObjectIdentifier(false, objects.TagBACnetObjectIdentifier, objects.ObjectTypeDevice, insNum)
 	objs[1] = objects.EncUnsignedInteger16(acceptedSize)
-	objs[2] = objects.EncEnumerated(supportedSeg)
+	objs[2] = objects.EncEnumerated(uint32(supportedSeg))
 	objs[3] = objects.EncUnsignedInteger16(vendorID)
 
 	return objs
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+)
+
+// UnconfirmedIHave is a BACnet message.
+type UnconfirmedIHave struct {
+	*plumbing.BVLC
+	*plumbing.NPDU
+	*plumbing.APDU
+}
+
+type UnconfirmedIHaveDec struct {
+	DeviceId   uint32
+	ObjectType uint16
+	InstanceId uint32
+	ObjectName string
+}
+
+// IHaveObjects creates the UnconfirmedIHave objects announcing an object of a device.
+func IHaveObjects(deviceId uint32, objectType uint16, instN uint32, name string) []objects.APDUPayload {
+	objs := make([]objects.APDUPayload, 3)
+
+	objs[0] = objects.EncObjectIdentifier(false, objects.TagBACnetObjectIdentifier, objects.ObjectTypeDevice, deviceId)
+	objs[1] = objects.EncObjectIdentifier(false, objects.TagBACnetObjectIdentifier, objectType, instN)
+	objs[2] = objects.EncCharacterString(name)
+
+	return objs
+}
+
+func NewUnconfirmedIHave(bvlc *plumbing.BVLC, npdu *plumbing.NPDU) *UnconfirmedIHave {
+	u := &UnconfirmedIHave{
+		BVLC: bvlc,
+		NPDU: npdu,
+		APDU: plumbing.NewAPDU(plumbing.UnConfirmedReq, ServiceUnconfirmedIHave, nil),
+	}
+	u.SetLength()
+
+	return u
+}
+
+func (u *UnconfirmedIHave) UnmarshalBinary(b []byte) error {
+	if l := len(b); l < u.MarshalLen() {
+		return common.ErrTooShortToParse
+	}
+
+	var offset int = 0
+	if err := u.BVLC.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += u.BVLC.MarshalLen()
+
+	if err := u.NPDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += u.NPDU.MarshalLen()
+
+	if err := u.APDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+
+	return nil
+}
+
+func (u *UnconfirmedIHave) MarshalBinary() ([]byte, error) {
+	b := make([]byte, u.MarshalLen())
+	if err := u.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (u *UnconfirmedIHave) MarshalTo(b []byte) error {
+	if len(b) < u.MarshalLen() {
+		return common.ErrTooShortToMarshalBinary
+	}
+	var offset = 0
+	if err := u.BVLC.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += u.BVLC.MarshalLen()
+
+	if err := u.NPDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += u.NPDU.MarshalLen()
+
+	if err := u.APDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u *UnconfirmedIHave) MarshalLen() int {
+	l := u.BVLC.MarshalLen()
+	l += u.NPDU.MarshalLen()
+	l += u.APDU.MarshalLen()
+
+	return l
+}
+
+func (u *UnconfirmedIHave) SetLength() {
+	u.BVLC.Length = uint16(u.MarshalLen())
+}
+
+func (u *UnconfirmedIHave) Decode() (UnconfirmedIHaveDec, error) {
+	decIHave := UnconfirmedIHaveDec{}
+
+	if len(u.APDU.Objects) != 3 {
+		return decIHave, common.ErrWrongObjectCount
+	}
+
+	devId, err := objects.DecObjectIdentifier(u.APDU.Objects[0])
+	if err != nil {
+		return decIHave, err
+	}
+	if devId.ObjectType != objects.ObjectTypeDevice {
+		return decIHave, common.ErrWrongPayload
+	}
+	decIHave.DeviceId = devId.InstanceNumber
+
+	objId, err := objects.DecObjectIdentifier(u.APDU.Objects[1])
+	if err != nil {
+		return decIHave, err
+	}
+	decIHave.ObjectType = objId.ObjectType
+	decIHave.InstanceId = objId.InstanceNumber
+
+	if decIHave.ObjectName, err = objects.DecCharacterString(u.APDU.Objects[2]); err != nil {
+		return decIHave, err
+	}
+
+	return decIHave, nil
+}
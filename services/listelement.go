@@ -0,0 +1,268 @@
+package services
+
+import (
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+)
+
+// ConfirmedAddListElement is a BACnet message. It's acknowledged with a SimpleACK.
+type ConfirmedAddListElement struct {
+	*plumbing.BVLC
+	*plumbing.NPDU
+	*plumbing.APDU
+}
+
+// ConfirmedRemoveListElement is a BACnet message. It's acknowledged with a SimpleACK.
+type ConfirmedRemoveListElement struct {
+	*plumbing.BVLC
+	*plumbing.NPDU
+	*plumbing.APDU
+}
+
+// ConfirmedListElementDec is an AddListElement or RemoveListElement request.
+type ConfirmedListElementDec struct {
+	ObjectType uint16
+	InstanceId uint32
+	PropertyId uint32
+	// ArrayIndex is objects.ArrayAll unless the list is an element of an array.
+	ArrayIndex uint32
+	Elements   []objects.APDUPayload
+}
+
+// ChangeListErrorDec is a ChangeList-Error: the error an AddListElement or
+// RemoveListElement request ran into and the 1-based position of the element causing it, 0
+// if it wasn't caused by any.
+type ChangeListErrorDec struct {
+	ErrorClass         uint8
+	ErrorCode          uint8
+	FirstFailedElement uint32
+}
+
+// ConfirmedListElementObjects creates the objects of both AddListElement and
+// RemoveListElement requests. Pass objects.ArrayAll as the arrayIndex to leave it out.
+func ConfirmedListElementObjects(objectType uint16, instN uint32, propertyId uint32, arrayIndex uint32, elements []objects.APDUPayload) []objects.APDUPayload {
+	objs := make([]objects.APDUPayload, 0, 5+len(elements))
+
+	objs = append(objs, objects.EncObjectIdentifier(true, 0, objectType, instN))
+	objs = append(objs, objects.EncPropertyIdentifier(true, 1, propertyId))
+	if arrayIndex != objects.ArrayAll {
+		objs = append(objs, objects.EncArrayIndex(2, arrayIndex))
+	}
+	objs = append(objs, objects.EncEnclosed(3, elements...)...)
+
+	return objs
+}
+
+// ChangeListErrorObjects creates the ChangeList-Error objects.
+func ChangeListErrorObjects(errClass, errCode uint8, firstFailedElement uint32) []objects.APDUPayload {
+	return failedElementErrorObjects(errClass, errCode, firstFailedElement)
+}
+
+func NewConfirmedAddListElement(bvlc *plumbing.BVLC, npdu *plumbing.NPDU) *ConfirmedAddListElement {
+	c := &ConfirmedAddListElement{
+		BVLC: bvlc,
+		NPDU: npdu,
+		APDU: plumbing.NewAPDU(plumbing.ConfirmedReq, ServiceConfirmedAddListElement, nil),
+	}
+	c.SetLength()
+
+	return c
+}
+
+func NewConfirmedRemoveListElement(bvlc *plumbing.BVLC, npdu *plumbing.NPDU) *ConfirmedRemoveListElement {
+	c := &ConfirmedRemoveListElement{
+		BVLC: bvlc,
+		NPDU: npdu,
+		APDU: plumbing.NewAPDU(plumbing.ConfirmedReq, ServiceConfirmedRemoveListElement, nil),
+	}
+	c.SetLength()
+
+	return c
+}
+
+func (c *ConfirmedAddListElement) UnmarshalBinary(b []byte) error {
+	if l := len(b); l < c.MarshalLen() {
+		return common.ErrTooShortToParse
+	}
+
+	var offset int = 0
+	if err := c.BVLC.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+
+	return nil
+}
+
+func (c *ConfirmedAddListElement) MarshalBinary() ([]byte, error) {
+	b := make([]byte, c.MarshalLen())
+	if err := c.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (c *ConfirmedAddListElement) MarshalTo(b []byte) error {
+	if len(b) < c.MarshalLen() {
+		return common.ErrTooShortToMarshalBinary
+	}
+	var offset = 0
+	if err := c.BVLC.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *ConfirmedAddListElement) MarshalLen() int {
+	l := c.BVLC.MarshalLen()
+	l += c.NPDU.MarshalLen()
+	l += c.APDU.MarshalLen()
+
+	return l
+}
+
+func (c *ConfirmedAddListElement) SetLength() {
+	c.BVLC.Length = uint16(c.MarshalLen())
+}
+
+func (c *ConfirmedRemoveListElement) UnmarshalBinary(b []byte) error {
+	if l := len(b); l < c.MarshalLen() {
+		return common.ErrTooShortToParse
+	}
+
+	var offset int = 0
+	if err := c.BVLC.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+
+	return nil
+}
+
+func (c *ConfirmedRemoveListElement) MarshalBinary() ([]byte, error) {
+	b := make([]byte, c.MarshalLen())
+	if err := c.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (c *ConfirmedRemoveListElement) MarshalTo(b []byte) error {
+	if len(b) < c.MarshalLen() {
+		return common.ErrTooShortToMarshalBinary
+	}
+	var offset = 0
+	if err := c.BVLC.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *ConfirmedRemoveListElement) MarshalLen() int {
+	l := c.BVLC.MarshalLen()
+	l += c.NPDU.MarshalLen()
+	l += c.APDU.MarshalLen()
+
+	return l
+}
+
+func (c *ConfirmedRemoveListElement) SetLength() {
+	c.BVLC.Length = uint16(c.MarshalLen())
+}
+
+func (c *ConfirmedAddListElement) Decode() (ConfirmedListElementDec, error) {
+	return decListElement(c.APDU.Objects)
+}
+
+func (c *ConfirmedRemoveListElement) Decode() (ConfirmedListElementDec, error) {
+	return decListElement(c.APDU.Objects)
+}
+
+// DecodeChangeList decodes the objects of a ChangeList-Error.
+func (e *Error) DecodeChangeList() (ChangeListErrorDec, error) {
+	bErr, element, err := e.decFailedElement()
+	if err != nil {
+		return ChangeListErrorDec{}, err
+	}
+	return ChangeListErrorDec{ErrorClass: bErr.Class, ErrorCode: bErr.Code, FirstFailedElement: element}, nil
+}
+
+func decListElement(objs []objects.APDUPayload) (ConfirmedListElementDec, error) {
+	decLE := ConfirmedListElementDec{ArrayIndex: objects.ArrayAll}
+
+	if len(objs) < 4 {
+		return decLE, common.ErrWrongObjectCount
+	}
+	if !objects.IsContextTag(objs[0], 0) || !objects.IsContextTag(objs[1], 1) {
+		return decLE, common.ErrWrongStructure
+	}
+
+	objId, err := objects.DecObjectIdentifier(objs[0])
+	if err != nil {
+		return decLE, err
+	}
+	decLE.ObjectType = objId.ObjectType
+	decLE.InstanceId = objId.InstanceNumber
+
+	if decLE.PropertyId, err = objects.DecPropertyIdentifier(objs[1]); err != nil {
+		return decLE, err
+	}
+
+	offset := 2
+	if objects.IsContextTag(objs[offset], 2) {
+		if decLE.ArrayIndex, err = objects.DecArrayIndex(objs[offset]); err != nil {
+			return decLE, err
+		}
+		offset++
+	}
+
+	if decLE.Elements, offset, err = objects.DecEnclosed(objs, offset, 3); err != nil {
+		return decLE, err
+	}
+	if offset != len(objs) {
+		return decLE, common.ErrWrongObjectCount
+	}
+
+	return decLE, nil
+}
@@ -0,0 +1,216 @@
+package services
+
+import (
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+)
+
+// Bits of the log-status of log records.
+const (
+	LogStatusLogDisabled = iota
+	LogStatusBufferPurged
+	LogStatusLogInterrupted
+)
+
+// LogRecord is a BACnetLogRecord: an entry on the Log_Buffer of a Trend Log. It holds either
+// a change of the status of the log, a value logged or the error reading it failed with.
+type LogRecord struct {
+	Timestamp objects.DateTime
+	// LogStatus is only set on records telling the log was enabled, disabled or purged.
+	LogStatus objects.BitString
+	// Failure is only set when reading the value failed.
+	Failure *common.BACnetError
+	// Value is the value logged as mapped by objects.EncValue. Booleans, Reals, Enumerateds,
+	// Unsigneds, Signeds, Bit Strings and Nulls are logged as such. Other values, as well as
+	// []interface{} holding several of them, are logged as any-value.
+	Value interface{}
+	// StatusFlags are those of the object logged, if known.
+	StatusFlags objects.BitString
+}
+
+// EncLogRecord encodes a BACnetLogRecord.
+func EncLogRecord(r LogRecord) ([]objects.APDUPayload, error) {
+	var datum []objects.APDUPayload
+	switch {
+	case r.LogStatus != nil:
+		datum = []objects.APDUPayload{objects.EncContext(0, objects.EncBitString(r.LogStatus))}
+	case r.Failure != nil:
+		datum = objects.EncEnclosed(8,
+			objects.EncEnumerated(uint32(r.Failure.Class)), objects.EncEnumerated(uint32(r.Failure.Code)))
+	default:
+		var err error
+		if datum, err = encLogValue(r.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	objs := objects.EncEnclosed(0, objects.EncDateTime(r.Timestamp)...)
+	objs = append(objs, objects.EncEnclosed(1, datum...)...)
+	if r.StatusFlags != nil {
+		objs = append(objs, objects.EncContext(2, objects.EncBitString(r.StatusFlags)))
+	}
+
+	return objs, nil
+}
+
+func encLogValue(value interface{}) ([]objects.APDUPayload, error) {
+	switch v := value.(type) {
+	case bool:
+		return []objects.APDUPayload{objects.EncContext(1, objects.EncBoolean(v))}, nil
+	case float32:
+		return []objects.APDUPayload{objects.EncContext(2, objects.EncReal(v))}, nil
+	case objects.Enumerated:
+		return []objects.APDUPayload{objects.EncContext(3, objects.EncEnumerated(uint32(v)))}, nil
+	case uint32:
+		return []objects.APDUPayload{objects.EncContext(4, objects.EncUnsignedInteger(v))}, nil
+	case int32:
+		return []objects.APDUPayload{objects.EncContext(5, objects.EncSignedInteger(v))}, nil
+	case objects.BitString:
+		return []objects.APDUPayload{objects.EncContext(6, objects.EncBitString(v))}, nil
+	case nil:
+		return []objects.APDUPayload{objects.EncContext(7, objects.EncNull())}, nil
+	case []interface{}:
+		values, err := objects.EncValues(v...)
+		if err != nil {
+			return nil, err
+		}
+		return objects.EncEnclosed(10, values...), nil
+	}
+
+	obj, err := objects.EncValue(value)
+	if err != nil {
+		return nil, err
+	}
+	return objects.EncEnclosed(10, obj), nil
+}
+
+// DecLogRecord decodes the BACnetLogRecord starting at rawPayloads[i]. It returns the index
+// of the first payload past the record. Records of time changes aren't supported.
+func DecLogRecord(rawPayloads []objects.APDUPayload, i int) (LogRecord, int, error) {
+	r := LogRecord{}
+
+	timestamp, next, err := objects.DecEnclosed(rawPayloads, i, 0)
+	if err != nil {
+		return r, i, err
+	}
+	if r.Timestamp, err = objects.DecDateTime(timestamp); err != nil {
+		return r, i, err
+	}
+
+	datum, next, err := objects.DecEnclosed(rawPayloads, next, 1)
+	if err != nil {
+		return r, i, err
+	}
+	if err := decLogDatum(&r, datum); err != nil {
+		return r, i, err
+	}
+
+	if next < len(rawPayloads) && objects.IsContextTag(rawPayloads[next], 2) {
+		if r.StatusFlags, err = objects.DecBitString(rawPayloads[next]); err != nil {
+			return r, i, err
+		}
+		next++
+	}
+
+	return r, next, nil
+}
+
+// DecLogRecords decodes a sequence of BACnetLogRecords, such as the item data of a
+// ReadRange-ACK reading a Log_Buffer.
+func DecLogRecords(rawPayloads []objects.APDUPayload) ([]LogRecord, error) {
+	records := []LogRecord{}
+	for i := 0; i < len(rawPayloads); {
+		r, next, err := DecLogRecord(rawPayloads, i)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, r)
+		i = next
+	}
+	return records, nil
+}
+
+func decLogDatum(r *LogRecord, datum []objects.APDUPayload) error {
+	if len(datum) == 0 {
+		return common.ErrWrongStructure
+	}
+
+	if objects.IsOpeningTag(datum[0], 8) || objects.IsOpeningTag(datum[0], 10) {
+		failure := objects.IsOpeningTag(datum[0], 8)
+		tagN := uint8(10)
+		if failure {
+			tagN = 8
+		}
+		enclosed, next, err := objects.DecEnclosed(datum, 0, tagN)
+		if err != nil {
+			return err
+		}
+		if next != len(datum) {
+			return common.ErrWrongStructure
+		}
+
+		if failure {
+			return decLogFailure(r, enclosed)
+		}
+		values, err := objects.DecValues(enclosed)
+		if err != nil {
+			return err
+		}
+		r.Value = values
+		if len(values) == 1 {
+			r.Value = values[0]
+		}
+		return nil
+	}
+
+	if len(datum) != 1 {
+		return common.ErrWrongStructure
+	}
+	obj, ok := datum[0].(*objects.Object)
+	if !ok || !obj.TagClass {
+		return common.ErrWrongStructure
+	}
+
+	var err error
+	switch obj.TagNumber {
+	case 0:
+		r.LogStatus, err = objects.DecBitString(obj)
+	case 1:
+		r.Value, err = objects.DecBoolean(obj)
+	case 2:
+		r.Value, err = objects.DecReal(obj)
+	case 3:
+		var v uint32
+		v, err = objects.DecEnumerated(obj)
+		r.Value = objects.Enumerated(v)
+	case 4:
+		r.Value, err = objects.DecUnisgnedInteger(obj)
+	case 5:
+		r.Value, err = objects.DecSignedInteger(obj)
+	case 6:
+		r.Value, err = objects.DecBitString(obj)
+	case 7:
+		r.Value = nil
+	default:
+		return common.ErrNotImplemented
+	}
+	return err
+}
+
+func decLogFailure(r *LogRecord, enclosed []objects.APDUPayload) error {
+	if len(enclosed) != 2 {
+		return common.ErrWrongObjectCount
+	}
+
+	class, err := objects.DecEnumerated(enclosed[0])
+	if err != nil {
+		return err
+	}
+	code, err := objects.DecEnumerated(enclosed[1])
+	if err != nil {
+		return err
+	}
+
+	r.Failure = &common.BACnetError{Class: uint8(class), Code: uint8(code)}
+	return nil
+}
@@ -0,0 +1,480 @@
+package services
+
+import (
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+)
+
+// NotificationParameters are the event values of an event notification, whose kind depends
+// on the event type, such as OutOfRangeParameters or ChangeOfStateParameters. Proprietary
+// event types carry ComplexEventParameters.
+type NotificationParameters interface {
+	// encode encodes the event values enclosed in the tag of their choice.
+	encode() []objects.APDUPayload
+}
+
+// complexEventType is the choice of BACnetNotificationParameters of proprietary event types.
+const complexEventType uint8 = 6
+
+// ChangeOfBitstringParameters are the event values of CHANGE_OF_BITSTRING events.
+type ChangeOfBitstringParameters struct {
+	ReferencedBitstring objects.BitString
+	StatusFlags         objects.BitString
+}
+
+func (p ChangeOfBitstringParameters) encode() []objects.APDUPayload {
+	return objects.EncEnclosed(uint8(objects.EventTypeChangeOfBitstring),
+		objects.EncContext(0, objects.EncBitString(p.ReferencedBitstring)),
+		objects.EncContext(1, objects.EncBitString(p.StatusFlags)),
+	)
+}
+
+// ChangeOfStateParameters are the event values of CHANGE_OF_STATE events.
+type ChangeOfStateParameters struct {
+	NewState    PropertyState
+	StatusFlags objects.BitString
+}
+
+func (p ChangeOfStateParameters) encode() []objects.APDUPayload {
+	objs := objects.EncEnclosed(0, EncPropertyState(p.NewState))
+	objs = append(objs, objects.EncContext(1, objects.EncBitString(p.StatusFlags)))
+	return objects.EncEnclosed(uint8(objects.EventTypeChangeOfState), objs...)
+}
+
+// ChangeOfValueParameters are the event values of CHANGE_OF_VALUE events. The new value is
+// ChangedBits when it's not nil and ChangedValue otherwise.
+type ChangeOfValueParameters struct {
+	ChangedBits  objects.BitString
+	ChangedValue float32
+	StatusFlags  objects.BitString
+}
+
+func (p ChangeOfValueParameters) encode() []objects.APDUPayload {
+	newValue := objects.EncContext(1, objects.EncReal(p.ChangedValue))
+	if p.ChangedBits != nil {
+		newValue = objects.EncContext(0, objects.EncBitString(p.ChangedBits))
+	}
+
+	objs := objects.EncEnclosed(0, newValue)
+	objs = append(objs, objects.EncContext(1, objects.EncBitString(p.StatusFlags)))
+	return objects.EncEnclosed(uint8(objects.EventTypeChangeOfValue), objs...)
+}
+
+// CommandFailureParameters are the event values of COMMAND_FAILURE events. The values are
+// left encoded as their datatype depends on the commanded property.
+type CommandFailureParameters struct {
+	CommandValue  []objects.APDUPayload
+	StatusFlags   objects.BitString
+	FeedbackValue []objects.APDUPayload
+}
+
+func (p CommandFailureParameters) encode() []objects.APDUPayload {
+	objs := objects.EncEnclosed(0, p.CommandValue...)
+	objs = append(objs, objects.EncContext(1, objects.EncBitString(p.StatusFlags)))
+	objs = append(objs, objects.EncEnclosed(2, p.FeedbackValue...)...)
+	return objects.EncEnclosed(uint8(objects.EventTypeCommandFailure), objs...)
+}
+
+// FloatingLimitParameters are the event values of FLOATING_LIMIT events.
+type FloatingLimitParameters struct {
+	ReferenceValue float32
+	StatusFlags    objects.BitString
+	SetpointValue  float32
+	ErrorLimit     float32
+}
+
+func (p FloatingLimitParameters) encode() []objects.APDUPayload {
+	return objects.EncEnclosed(uint8(objects.EventTypeFloatingLimit),
+		objects.EncContext(0, objects.EncReal(p.ReferenceValue)),
+		objects.EncContext(1, objects.EncBitString(p.StatusFlags)),
+		objects.EncContext(2, objects.EncReal(p.SetpointValue)),
+		objects.EncContext(3, objects.EncReal(p.ErrorLimit)),
+	)
+}
+
+// OutOfRangeParameters are the event values of OUT_OF_RANGE events.
+type OutOfRangeParameters struct {
+	ExceedingValue float32
+	StatusFlags    objects.BitString
+	Deadband       float32
+	ExceededLimit  float32
+}
+
+func (p OutOfRangeParameters) encode() []objects.APDUPayload {
+	return objects.EncEnclosed(uint8(objects.EventTypeOutOfRange),
+		objects.EncContext(0, objects.EncReal(p.ExceedingValue)),
+		objects.EncContext(1, objects.EncBitString(p.StatusFlags)),
+		objects.EncContext(2, objects.EncReal(p.Deadband)),
+		objects.EncContext(3, objects.EncReal(p.ExceededLimit)),
+	)
+}
+
+// ComplexEventParameters are the event values of proprietary event types.
+type ComplexEventParameters struct {
+	Values []PropertyValue
+}
+
+func (p ComplexEventParameters) encode() []objects.APDUPayload {
+	return EncPropertyValues(complexEventType, p.Values)
+}
+
+// ChangeOfLifeSafetyParameters are the event values of CHANGE_OF_LIFE_SAFETY events.
+type ChangeOfLifeSafetyParameters struct {
+	NewState          uint32
+	NewMode           uint32
+	StatusFlags       objects.BitString
+	OperationExpected uint32
+}
+
+func (p ChangeOfLifeSafetyParameters) encode() []objects.APDUPayload {
+	return objects.EncEnclosed(uint8(objects.EventTypeChangeOfLifeSafety),
+		objects.EncContext(0, objects.EncEnumerated(p.NewState)),
+		objects.EncContext(1, objects.EncEnumerated(p.NewMode)),
+		objects.EncContext(2, objects.EncBitString(p.StatusFlags)),
+		objects.EncContext(3, objects.EncEnumerated(p.OperationExpected)),
+	)
+}
+
+// ExtendedParameters are the event values of EXTENDED events. The parameters are left encoded
+// as their meaning is up to the vendor.
+type ExtendedParameters struct {
+	VendorId          uint16
+	ExtendedEventType uint32
+	Parameters        []objects.APDUPayload
+}
+
+func (p ExtendedParameters) encode() []objects.APDUPayload {
+	objs := []objects.APDUPayload{
+		objects.EncContext(0, objects.EncUnsignedInteger16(p.VendorId)),
+		objects.EncContext(1, objects.EncUnsignedInteger(p.ExtendedEventType)),
+	}
+	objs = append(objs, objects.EncEnclosed(2, p.Parameters...)...)
+	return objects.EncEnclosed(uint8(objects.EventTypeExtended), objs...)
+}
+
+// BufferReadyParameters are the event values of BUFFER_READY events.
+type BufferReadyParameters struct {
+	BufferProperty       DeviceObjectPropertyReference
+	PreviousNotification uint32
+	CurrentNotification  uint32
+}
+
+func (p BufferReadyParameters) encode() []objects.APDUPayload {
+	objs := objects.EncEnclosed(0, EncDeviceObjectPropertyReference(p.BufferProperty)...)
+	objs = append(objs,
+		objects.EncContext(1, objects.EncUnsignedInteger(p.PreviousNotification)),
+		objects.EncContext(2, objects.EncUnsignedInteger(p.CurrentNotification)),
+	)
+	return objects.EncEnclosed(uint8(objects.EventTypeBufferReady), objs...)
+}
+
+// UnsignedRangeParameters are the event values of UNSIGNED_RANGE events.
+type UnsignedRangeParameters struct {
+	ExceedingValue uint32
+	StatusFlags    objects.BitString
+	ExceededLimit  uint32
+}
+
+func (p UnsignedRangeParameters) encode() []objects.APDUPayload {
+	return objects.EncEnclosed(uint8(objects.EventTypeUnsignedRange),
+		objects.EncContext(0, objects.EncUnsignedInteger(p.ExceedingValue)),
+		objects.EncContext(1, objects.EncBitString(p.StatusFlags)),
+		objects.EncContext(2, objects.EncUnsignedInteger(p.ExceededLimit)),
+	)
+}
+
+// AccessEventParameters are the event values of ACCESS_EVENT events.
+type AccessEventParameters struct {
+	AccessEvent      uint32
+	StatusFlags      objects.BitString
+	AccessEventTag   uint32
+	AccessEventTime  TimeStamp
+	AccessCredential DeviceObjectReference
+	// AuthenticationFactor is left encoded, and out when nil.
+	AuthenticationFactor []objects.APDUPayload
+}
+
+func (p AccessEventParameters) encode() []objects.APDUPayload {
+	objs := []objects.APDUPayload{
+		objects.EncContext(0, objects.EncEnumerated(p.AccessEvent)),
+		objects.EncContext(1, objects.EncBitString(p.StatusFlags)),
+		objects.EncContext(2, objects.EncUnsignedInteger(p.AccessEventTag)),
+	}
+	objs = append(objs, objects.EncEnclosed(3, EncTimeStamp(p.AccessEventTime)...)...)
+	objs = append(objs, objects.EncEnclosed(4, EncDeviceObjectReference(p.AccessCredential)...)...)
+	if p.AuthenticationFactor != nil {
+		objs = append(objs, objects.EncEnclosed(5, p.AuthenticationFactor...)...)
+	}
+	return objects.EncEnclosed(uint8(objects.EventTypeAccessEvent), objs...)
+}
+
+// DoubleOutOfRangeParameters are the event values of DOUBLE_OUT_OF_RANGE events.
+type DoubleOutOfRangeParameters struct {
+	ExceedingValue float64
+	StatusFlags    objects.BitString
+	Deadband       float64
+	ExceededLimit  float64
+}
+
+func (p DoubleOutOfRangeParameters) encode() []objects.APDUPayload {
+	return objects.EncEnclosed(uint8(objects.EventTypeDoubleOutOfRange),
+		objects.EncContext(0, objects.EncDouble(p.ExceedingValue)),
+		objects.EncContext(1, objects.EncBitString(p.StatusFlags)),
+		objects.EncContext(2, objects.EncDouble(p.Deadband)),
+		objects.EncContext(3, objects.EncDouble(p.ExceededLimit)),
+	)
+}
+
+// SignedOutOfRangeParameters are the event values of SIGNED_OUT_OF_RANGE events.
+type SignedOutOfRangeParameters struct {
+	ExceedingValue int32
+	StatusFlags    objects.BitString
+	Deadband       uint32
+	ExceededLimit  int32
+}
+
+func (p SignedOutOfRangeParameters) encode() []objects.APDUPayload {
+	return objects.EncEnclosed(uint8(objects.EventTypeSignedOutOfRange),
+		objects.EncContext(0, objects.EncSignedInteger(p.ExceedingValue)),
+		objects.EncContext(1, objects.EncBitString(p.StatusFlags)),
+		objects.EncContext(2, objects.EncUnsignedInteger(p.Deadband)),
+		objects.EncContext(3, objects.EncSignedInteger(p.ExceededLimit)),
+	)
+}
+
+// UnsignedOutOfRangeParameters are the event values of UNSIGNED_OUT_OF_RANGE events.
+type UnsignedOutOfRangeParameters struct {
+	ExceedingValue uint32
+	StatusFlags    objects.BitString
+	Deadband       uint32
+	ExceededLimit  uint32
+}
+
+func (p UnsignedOutOfRangeParameters) encode() []objects.APDUPayload {
+	return objects.EncEnclosed(uint8(objects.EventTypeUnsignedOutOfRange),
+		objects.EncContext(0, objects.EncUnsignedInteger(p.ExceedingValue)),
+		objects.EncContext(1, objects.EncBitString(p.StatusFlags)),
+		objects.EncContext(2, objects.EncUnsignedInteger(p.Deadband)),
+		objects.EncContext(3, objects.EncUnsignedInteger(p.ExceededLimit)),
+	)
+}
+
+// ChangeOfCharacterstringParameters are the event values of CHANGE_OF_CHARACTERSTRING events.
+type ChangeOfCharacterstringParameters struct {
+	ChangedValue string
+	StatusFlags  objects.BitString
+	AlarmValue   string
+}
+
+func (p ChangeOfCharacterstringParameters) encode() []objects.APDUPayload {
+	return objects.EncEnclosed(uint8(objects.EventTypeChangeOfCharacterstring),
+		objects.EncContext(0, objects.EncCharacterString(p.ChangedValue)),
+		objects.EncContext(1, objects.EncBitString(p.StatusFlags)),
+		objects.EncContext(2, objects.EncCharacterString(p.AlarmValue)),
+	)
+}
+
+// ChangeOfStatusFlagsParameters are the event values of CHANGE_OF_STATUS_FLAGS events.
+type ChangeOfStatusFlagsParameters struct {
+	// PresentValue is left encoded, and out when nil.
+	PresentValue    []objects.APDUPayload
+	ReferencedFlags objects.BitString
+}
+
+func (p ChangeOfStatusFlagsParameters) encode() []objects.APDUPayload {
+	objs := []objects.APDUPayload{}
+	if p.PresentValue != nil {
+		objs = append(objs, objects.EncEnclosed(0, p.PresentValue...)...)
+	}
+	objs = append(objs, objects.EncContext(1, objects.EncBitString(p.ReferencedFlags)))
+	return objects.EncEnclosed(uint8(objects.EventTypeChangeOfStatusFlags), objs...)
+}
+
+// ChangeOfReliabilityParameters are the event values of CHANGE_OF_RELIABILITY events.
+type ChangeOfReliabilityParameters struct {
+	Reliability    uint32
+	StatusFlags    objects.BitString
+	PropertyValues []PropertyValue
+}
+
+func (p ChangeOfReliabilityParameters) encode() []objects.APDUPayload {
+	objs := []objects.APDUPayload{
+		objects.EncContext(0, objects.EncEnumerated(p.Reliability)),
+		objects.EncContext(1, objects.EncBitString(p.StatusFlags)),
+	}
+	objs = append(objs, EncPropertyValues(2, p.PropertyValues)...)
+	return objects.EncEnclosed(uint8(objects.EventTypeChangeOfReliability), objs...)
+}
+
+// ChangeOfDiscreteValueParameters are the event values of CHANGE_OF_DISCRETE_VALUE events.
+type ChangeOfDiscreteValueParameters struct {
+	// NewValue holds either an application tagged value or a date and time enclosed in
+	// context tag 0.
+	NewValue    []objects.APDUPayload
+	StatusFlags objects.BitString
+}
+
+func (p ChangeOfDiscreteValueParameters) encode() []objects.APDUPayload {
+	objs := objects.EncEnclosed(0, p.NewValue...)
+	objs = append(objs, objects.EncContext(1, objects.EncBitString(p.StatusFlags)))
+	return objects.EncEnclosed(uint8(objects.EventTypeChangeOfDiscreteValue), objs...)
+}
+
+// ChangeOfTimerParameters are the event values of CHANGE_OF_TIMER events.
+type ChangeOfTimerParameters struct {
+	NewState    uint32
+	StatusFlags objects.BitString
+	UpdateTime  objects.DateTime
+	// LastStateChange, InitialTimeout and ExpirationTime are left out when nil.
+	LastStateChange *uint32
+	InitialTimeout  *uint32
+	ExpirationTime  *objects.DateTime
+}
+
+func (p ChangeOfTimerParameters) encode() []objects.APDUPayload {
+	objs := []objects.APDUPayload{
+		objects.EncContext(0, objects.EncEnumerated(p.NewState)),
+		objects.EncContext(1, objects.EncBitString(p.StatusFlags)),
+	}
+	objs = append(objs, objects.EncEnclosed(2, objects.EncDateTime(p.UpdateTime)...)...)
+	if p.LastStateChange != nil {
+		objs = append(objs, objects.EncContext(3, objects.EncEnumerated(*p.LastStateChange)))
+	}
+	if p.InitialTimeout != nil {
+		objs = append(objs, objects.EncContext(4, objects.EncUnsignedInteger(*p.InitialTimeout)))
+	}
+	if p.ExpirationTime != nil {
+		objs = append(objs, objects.EncEnclosed(5, objects.EncDateTime(*p.ExpirationTime)...)...)
+	}
+	return objects.EncEnclosed(uint8(objects.EventTypeChangeOfTimer), objs...)
+}
+
+// decNotificationParameters decodes the event values enclosed in the tag of their choice.
+func decNotificationParameters(rawPayloads []objects.APDUPayload) (NotificationParameters, error) {
+	if len(rawPayloads) == 0 {
+		return nil, common.ErrWrongObjectCount
+	}
+	tag, ok := rawPayloads[0].(*objects.NamedTag)
+	if !ok {
+		return nil, common.ErrWrongStructure
+	}
+
+	if tag.TagNumber == complexEventType {
+		values, next, err := DecPropertyValues(rawPayloads, 0, complexEventType)
+		if err != nil {
+			return nil, err
+		}
+		if next != len(rawPayloads) {
+			return nil, common.ErrWrongObjectCount
+		}
+		return ComplexEventParameters{Values: values}, nil
+	}
+
+	values, next, err := objects.DecEnclosed(rawPayloads, 0, tag.TagNumber)
+	if err != nil {
+		return nil, err
+	}
+	if next != len(rawPayloads) {
+		return nil, common.ErrWrongObjectCount
+	}
+
+	r := &contextReader{rawPayloads: values}
+	var p NotificationParameters
+	switch uint32(tag.TagNumber) {
+	case objects.EventTypeChangeOfBitstring:
+		p = ChangeOfBitstringParameters{ReferencedBitstring: r.bitString(0), StatusFlags: r.bitString(1)}
+	case objects.EventTypeChangeOfState:
+		p = ChangeOfStateParameters{NewState: r.propertyState(0), StatusFlags: r.bitString(1)}
+	case objects.EventTypeChangeOfValue:
+		cov := ChangeOfValueParameters{}
+		newValue := &contextReader{rawPayloads: r.enclosed(0)}
+		if newValue.has(0) {
+			cov.ChangedBits = newValue.bitString(0)
+		} else {
+			cov.ChangedValue = newValue.real(1)
+		}
+		r.fail(newValue.done())
+		cov.StatusFlags = r.bitString(1)
+		p = cov
+	case objects.EventTypeCommandFailure:
+		p = CommandFailureParameters{CommandValue: r.enclosed(0), StatusFlags: r.bitString(1), FeedbackValue: r.enclosed(2)}
+	case objects.EventTypeFloatingLimit:
+		p = FloatingLimitParameters{ReferenceValue: r.real(0), StatusFlags: r.bitString(1), SetpointValue: r.real(2), ErrorLimit: r.real(3)}
+	case objects.EventTypeOutOfRange:
+		p = OutOfRangeParameters{ExceedingValue: r.real(0), StatusFlags: r.bitString(1), Deadband: r.real(2), ExceededLimit: r.real(3)}
+	case objects.EventTypeChangeOfLifeSafety:
+		p = ChangeOfLifeSafetyParameters{NewState: r.enumerated(0), NewMode: r.enumerated(1), StatusFlags: r.bitString(2), OperationExpected: r.enumerated(3)}
+	case objects.EventTypeExtended:
+		p = ExtendedParameters{VendorId: uint16(r.unsigned(0)), ExtendedEventType: r.unsigned(1), Parameters: r.enclosed(2)}
+	case objects.EventTypeBufferReady:
+		br := BufferReadyParameters{}
+		ref := r.enclosed(0)
+		if r.err == nil {
+			var next int
+			if br.BufferProperty, next, r.err = DecDeviceObjectPropertyReference(ref, 0); r.err == nil && next != len(ref) {
+				r.fail(common.ErrWrongStructure)
+			}
+		}
+		br.PreviousNotification, br.CurrentNotification = r.unsigned(1), r.unsigned(2)
+		p = br
+	case objects.EventTypeUnsignedRange:
+		p = UnsignedRangeParameters{ExceedingValue: r.unsigned(0), StatusFlags: r.bitString(1), ExceededLimit: r.unsigned(2)}
+	case objects.EventTypeAccessEvent:
+		ae := AccessEventParameters{AccessEvent: r.enumerated(0), StatusFlags: r.bitString(1), AccessEventTag: r.unsigned(2)}
+		ae.AccessEventTime = r.timeStamp(3)
+		ref := r.enclosed(4)
+		if r.err == nil {
+			var next int
+			if ae.AccessCredential, next, r.err = DecDeviceObjectReference(ref, 0); r.err == nil && next != len(ref) {
+				r.fail(common.ErrWrongStructure)
+			}
+		}
+		if r.hasEnclosed(5) {
+			ae.AuthenticationFactor = r.enclosed(5)
+		}
+		p = ae
+	case objects.EventTypeDoubleOutOfRange:
+		p = DoubleOutOfRangeParameters{ExceedingValue: r.double(0), StatusFlags: r.bitString(1), Deadband: r.double(2), ExceededLimit: r.double(3)}
+	case objects.EventTypeSignedOutOfRange:
+		p = SignedOutOfRangeParameters{ExceedingValue: r.signed(0), StatusFlags: r.bitString(1), Deadband: r.unsigned(2), ExceededLimit: r.signed(3)}
+	case objects.EventTypeUnsignedOutOfRange:
+		p = UnsignedOutOfRangeParameters{ExceedingValue: r.unsigned(0), StatusFlags: r.bitString(1), Deadband: r.unsigned(2), ExceededLimit: r.unsigned(3)}
+	case objects.EventTypeChangeOfCharacterstring:
+		p = ChangeOfCharacterstringParameters{ChangedValue: r.characterString(0), StatusFlags: r.bitString(1), AlarmValue: r.characterString(2)}
+	case objects.EventTypeChangeOfStatusFlags:
+		csf := ChangeOfStatusFlagsParameters{}
+		if r.hasEnclosed(0) {
+			csf.PresentValue = r.enclosed(0)
+		}
+		csf.ReferencedFlags = r.bitString(1)
+		p = csf
+	case objects.EventTypeChangeOfReliability:
+		cor := ChangeOfReliabilityParameters{Reliability: r.enumerated(0), StatusFlags: r.bitString(1)}
+		cor.PropertyValues = r.propertyValues(2)
+		p = cor
+	case objects.EventTypeChangeOfDiscreteValue:
+		p = ChangeOfDiscreteValueParameters{NewValue: r.enclosed(0), StatusFlags: r.bitString(1)}
+	case objects.EventTypeChangeOfTimer:
+		cot := ChangeOfTimerParameters{NewState: r.enumerated(0), StatusFlags: r.bitString(1), UpdateTime: r.dateTime(2)}
+		if r.has(3) {
+			v := r.enumerated(3)
+			cot.LastStateChange = &v
+		}
+		if r.has(4) {
+			v := r.unsigned(4)
+			cot.InitialTimeout = &v
+		}
+		if r.hasEnclosed(5) {
+			v := r.dateTime(5)
+			cot.ExpirationTime = &v
+		}
+		p = cot
+	default:
+		return nil, common.ErrNotImplemented
+	}
+
+	if err := r.done(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
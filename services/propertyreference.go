@@ -0,0 +1,164 @@
+package services
+
+import (
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+)
+
+// PropertyReference is a BACnetPropertyReference.
+type PropertyReference struct {
+	PropertyId uint32
+	// ArrayIndex is objects.ArrayAll when the whole property is meant.
+	ArrayIndex uint32
+}
+
+// EncPropertyReference encodes a BACnetPropertyReference without enclosing it.
+func EncPropertyReference(ref PropertyReference) []objects.APDUPayload {
+	objs := []objects.APDUPayload{objects.EncPropertyIdentifier(true, 0, ref.PropertyId)}
+	if ref.ArrayIndex != objects.ArrayAll {
+		objs = append(objs, objects.EncArrayIndex(1, ref.ArrayIndex))
+	}
+
+	return objs
+}
+
+// DecPropertyReference decodes the BACnetPropertyReference starting at rawPayloads[i]. It
+// returns the index of the first payload past the reference.
+func DecPropertyReference(rawPayloads []objects.APDUPayload, i int) (PropertyReference, int, error) {
+	ref := PropertyReference{ArrayIndex: objects.ArrayAll}
+
+	if i >= len(rawPayloads) || !objects.IsContextTag(rawPayloads[i], 0) {
+		return ref, i, common.ErrWrongStructure
+	}
+
+	var err error
+	if ref.PropertyId, err = objects.DecPropertyIdentifier(rawPayloads[i]); err != nil {
+		return ref, i, err
+	}
+	next := i + 1
+
+	if next < len(rawPayloads) && objects.IsContextTag(rawPayloads[next], 1) {
+		if ref.ArrayIndex, err = objects.DecArrayIndex(rawPayloads[next]); err != nil {
+			return ref, i, err
+		}
+		next++
+	}
+
+	return ref, next, nil
+}
+
+// DeviceObjectPropertyReference is a BACnetDeviceObjectPropertyReference: a property of an
+// object which may live on another device.
+type DeviceObjectPropertyReference struct {
+	Object     objects.ObjectIdentifier
+	PropertyId uint32
+	// ArrayIndex is objects.ArrayAll when the whole property is meant.
+	ArrayIndex uint32
+	// DeviceId is objects.MaxInstance when the object lives on the device holding the
+	// reference.
+	DeviceId uint32
+}
+
+// EncDeviceObjectPropertyReference encodes a BACnetDeviceObjectPropertyReference without
+// enclosing it.
+func EncDeviceObjectPropertyReference(ref DeviceObjectPropertyReference) []objects.APDUPayload {
+	objs := []objects.APDUPayload{
+		objects.EncObjectIdentifier(true, 0, ref.Object.ObjectType, ref.Object.InstanceNumber),
+		objects.EncPropertyIdentifier(true, 1, ref.PropertyId),
+	}
+	if ref.ArrayIndex != objects.ArrayAll {
+		objs = append(objs, objects.EncArrayIndex(2, ref.ArrayIndex))
+	}
+	if ref.DeviceId != objects.MaxInstance {
+		objs = append(objs, objects.EncObjectIdentifier(true, 3, objects.ObjectTypeDevice, ref.DeviceId))
+	}
+
+	return objs
+}
+
+// DecDeviceObjectPropertyReference decodes the BACnetDeviceObjectPropertyReference starting at
+// rawPayloads[i]. It returns the index of the first payload past the reference.
+func DecDeviceObjectPropertyReference(rawPayloads []objects.APDUPayload, i int) (DeviceObjectPropertyReference, int, error) {
+	ref := DeviceObjectPropertyReference{ArrayIndex: objects.ArrayAll, DeviceId: objects.MaxInstance}
+
+	if i+1 >= len(rawPayloads) || !objects.IsContextTag(rawPayloads[i], 0) || !objects.IsContextTag(rawPayloads[i+1], 1) {
+		return ref, i, common.ErrWrongStructure
+	}
+
+	var err error
+	if ref.Object, err = objects.DecObjectIdentifier(rawPayloads[i]); err != nil {
+		return ref, i, err
+	}
+	if ref.PropertyId, err = objects.DecPropertyIdentifier(rawPayloads[i+1]); err != nil {
+		return ref, i, err
+	}
+	next := i + 2
+
+	if next < len(rawPayloads) && objects.IsContextTag(rawPayloads[next], 2) {
+		if ref.ArrayIndex, err = objects.DecArrayIndex(rawPayloads[next]); err != nil {
+			return ref, i, err
+		}
+		next++
+	}
+	if next < len(rawPayloads) && objects.IsContextTag(rawPayloads[next], 3) {
+		device, err := objects.DecObjectIdentifier(rawPayloads[next])
+		if err != nil {
+			return ref, i, err
+		}
+		if device.ObjectType != objects.ObjectTypeDevice {
+			return ref, i, common.ErrWrongStructure
+		}
+		ref.DeviceId = device.InstanceNumber
+		next++
+	}
+
+	return ref, next, nil
+}
+
+// DeviceObjectReference is a BACnetDeviceObjectReference: an object which may live on another
+// device.
+type DeviceObjectReference struct {
+	// DeviceId is objects.MaxInstance when the object lives on the device holding the
+	// reference.
+	DeviceId uint32
+	Object   objects.ObjectIdentifier
+}
+
+// EncDeviceObjectReference encodes a BACnetDeviceObjectReference without enclosing it.
+func EncDeviceObjectReference(ref DeviceObjectReference) []objects.APDUPayload {
+	objs := []objects.APDUPayload{}
+	if ref.DeviceId != objects.MaxInstance {
+		objs = append(objs, objects.EncObjectIdentifier(true, 0, objects.ObjectTypeDevice, ref.DeviceId))
+	}
+
+	return append(objs, objects.EncObjectIdentifier(true, 1, ref.Object.ObjectType, ref.Object.InstanceNumber))
+}
+
+// DecDeviceObjectReference decodes the BACnetDeviceObjectReference starting at rawPayloads[i].
+// It returns the index of the first payload past the reference.
+func DecDeviceObjectReference(rawPayloads []objects.APDUPayload, i int) (DeviceObjectReference, int, error) {
+	ref := DeviceObjectReference{DeviceId: objects.MaxInstance}
+	next := i
+
+	if next < len(rawPayloads) && objects.IsContextTag(rawPayloads[next], 0) {
+		device, err := objects.DecObjectIdentifier(rawPayloads[next])
+		if err != nil {
+			return ref, i, err
+		}
+		if device.ObjectType != objects.ObjectTypeDevice {
+			return ref, i, common.ErrWrongStructure
+		}
+		ref.DeviceId = device.InstanceNumber
+		next++
+	}
+
+	if next >= len(rawPayloads) || !objects.IsContextTag(rawPayloads[next], 1) {
+		return ref, i, common.ErrWrongStructure
+	}
+	var err error
+	if ref.Object, err = objects.DecObjectIdentifier(rawPayloads[next]); err != nil {
+		return ref, i, err
+	}
+
+	return ref, next + 1, nil
+}
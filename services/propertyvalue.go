@@ -0,0 +1,79 @@
+package services
+
+import (
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+)
+
+// PropertyValue is a BACnetPropertyValue as carried by COV notifications and the
+// services creating and writing several properties at once.
+type PropertyValue struct {
+	PropertyId uint32
+	// ArrayIndex is objects.ArrayAll when the whole property is meant.
+	ArrayIndex uint32
+	Values     []objects.APDUPayload
+	// Priority is 0 when not present.
+	Priority uint8
+}
+
+// EncPropertyValues encodes a list of BACnetPropertyValues enclosed in the given context tag.
+func EncPropertyValues(tagN uint8, values []PropertyValue) []objects.APDUPayload {
+	objs := []objects.APDUPayload{}
+
+	for _, v := range values {
+		objs = append(objs, objects.EncPropertyIdentifier(true, 0, v.PropertyId))
+		if v.ArrayIndex != objects.ArrayAll {
+			objs = append(objs, objects.EncArrayIndex(1, v.ArrayIndex))
+		}
+		objs = append(objs, objects.EncEnclosed(2, v.Values...)...)
+		if v.Priority != 0 {
+			objs = append(objs, objects.EncPriority(true, 3, v.Priority))
+		}
+	}
+
+	return objects.EncEnclosed(tagN, objs...)
+}
+
+// DecPropertyValues decodes a list of BACnetPropertyValues enclosed in the given context tag
+// starting at rawPayloads[i]. It returns the index of the first payload past the list.
+func DecPropertyValues(rawPayloads []objects.APDUPayload, i int, tagN uint8) ([]PropertyValue, int, error) {
+	enclosed, next, err := objects.DecEnclosed(rawPayloads, i, tagN)
+	if err != nil {
+		return nil, i, err
+	}
+
+	values := []PropertyValue{}
+	for offset := 0; offset < len(enclosed); {
+		v := PropertyValue{ArrayIndex: objects.ArrayAll}
+
+		if !objects.IsContextTag(enclosed[offset], 0) {
+			return nil, i, common.ErrWrongStructure
+		}
+		if v.PropertyId, err = objects.DecPropertyIdentifier(enclosed[offset]); err != nil {
+			return nil, i, err
+		}
+		offset++
+
+		if offset < len(enclosed) && objects.IsContextTag(enclosed[offset], 1) {
+			if v.ArrayIndex, err = objects.DecArrayIndex(enclosed[offset]); err != nil {
+				return nil, i, err
+			}
+			offset++
+		}
+
+		if v.Values, offset, err = objects.DecEnclosed(enclosed, offset, 2); err != nil {
+			return nil, i, err
+		}
+
+		if offset < len(enclosed) && objects.IsContextTag(enclosed[offset], 3) {
+			if v.Priority, err = objects.DecPriority(enclosed[offset]); err != nil {
+				return nil, i, err
+			}
+			offset++
+		}
+
+		values = append(values, v)
+	}
+
+	return values, next, nil
+}
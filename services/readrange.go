@@ -0,0 +1,336 @@
+package services
+
+import (
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+)
+
+// Forms of the range of a ReadRange request. They match the context tags the range is
+// enclosed in on the wire.
+const (
+	// RangeAll leaves the range out, reading every item.
+	RangeAll              uint8 = 0
+	RangeByPosition       uint8 = 3
+	RangeBySequenceNumber uint8 = 6
+	RangeByTime           uint8 = 7
+)
+
+// Bits of the BACnetResultFlags of ReadRange ACKs.
+const (
+	ResultFlagFirstItem = iota
+	ResultFlagLastItem
+	ResultFlagMoreItems
+)
+
+// ConfirmedReadRange is a BACnet message.
+type ConfirmedReadRange struct {
+	*plumbing.BVLC
+	*plumbing.NPDU
+	*plumbing.APDU
+}
+
+// Range is the range of items a ReadRange request asks for.
+type Range struct {
+	// Type is one of the Range constants.
+	Type uint8
+	// Reference is the 1-based index of the reference item when reading by position and its
+	// sequence number when reading by sequence number.
+	Reference uint32
+	// Time is the reference time when reading by time.
+	Time objects.DateTime
+	// Count is the number of items to read from the reference on, the reference included
+	// unless reading by time. Negative counts read the items preceding it.
+	Count int32
+}
+
+// RangeFromFirst is the range by time of the first count records of a log: those following
+// 1900-01-01 00:00:00.00, the earliest time there is, on any weekday.
+func RangeFromFirst(count int32) Range {
+	return Range{
+		Type:  RangeByTime,
+		Time:  objects.DateTime{Date: objects.Date{Year: 0, Month: 1, Day: 1, Weekday: objects.Unspecified}},
+		Count: count,
+	}
+}
+
+type ConfirmedReadRangeDec struct {
+	ObjectType uint16
+	InstanceId uint32
+	PropertyId uint32
+	// ArrayIndex is objects.ArrayAll when not present.
+	ArrayIndex uint32
+	Range      Range
+}
+
+// RangeResult is the part of a ReadRange ACK describing the items read.
+type RangeResult struct {
+	FirstItem bool
+	LastItem  bool
+	MoreItems bool
+	ItemCount uint32
+	ItemData  []objects.APDUPayload
+	// FirstSequenceNumber is the sequence number of the first item read by sequence number
+	// or by time, 0 when not present.
+	FirstSequenceNumber uint32
+}
+
+type ReadRangeACKDec struct {
+	ObjectType uint16
+	InstanceId uint32
+	PropertyId uint32
+	ArrayIndex uint32
+	RangeResult
+}
+
+// ConfirmedReadRangeObjects creates the ReadRange request objects. Pass objects.ArrayAll as
+// the arrayIndex to leave it out.
+func ConfirmedReadRangeObjects(objectType uint16, instN uint32, propertyId uint32, arrayIndex uint32, rng Range) []objects.APDUPayload {
+	objs := make([]objects.APDUPayload, 0, 8)
+
+	objs = append(objs, objects.EncObjectIdentifier(true, 0, objectType, instN))
+	objs = append(objs, objects.EncPropertyIdentifier(true, 1, propertyId))
+	if arrayIndex != objects.ArrayAll {
+		objs = append(objs, objects.EncArrayIndex(2, arrayIndex))
+	}
+
+	switch rng.Type {
+	case RangeByPosition, RangeBySequenceNumber:
+		objs = append(objs, objects.EncEnclosed(rng.Type,
+			objects.EncUnsignedInteger(rng.Reference), objects.EncSignedInteger(rng.Count))...)
+	case RangeByTime:
+		ref := append(objects.EncDateTime(rng.Time), objects.EncSignedInteger(rng.Count))
+		objs = append(objs, objects.EncEnclosed(rng.Type, ref...)...)
+	}
+
+	return objs
+}
+
+// ReadRangeACKObjects creates the objects of a ComplexACK answering a ReadRange request.
+func ReadRangeACKObjects(objectType uint16, instN uint32, propertyId uint32, arrayIndex uint32, result RangeResult) []objects.APDUPayload {
+	objs := make([]objects.APDUPayload, 0, 8+len(result.ItemData))
+
+	objs = append(objs, objects.EncObjectIdentifier(true, 0, objectType, instN))
+	objs = append(objs, objects.EncPropertyIdentifier(true, 1, propertyId))
+	if arrayIndex != objects.ArrayAll {
+		objs = append(objs, objects.EncArrayIndex(2, arrayIndex))
+	}
+
+	flags := make(objects.BitString, 3)
+	flags[ResultFlagFirstItem] = result.FirstItem
+	flags[ResultFlagLastItem] = result.LastItem
+	flags[ResultFlagMoreItems] = result.MoreItems
+	objs = append(objs, objects.EncContext(3, objects.EncBitString(flags)))
+	objs = append(objs, objects.EncContext(4, objects.EncUnsignedInteger(result.ItemCount)))
+	objs = append(objs, objects.EncEnclosed(5, result.ItemData...)...)
+	if result.FirstSequenceNumber != 0 {
+		objs = append(objs, objects.EncContext(6, objects.EncUnsignedInteger(result.FirstSequenceNumber)))
+	}
+
+	return objs
+}
+
+func NewConfirmedReadRange(bvlc *plumbing.BVLC, npdu *plumbing.NPDU) *ConfirmedReadRange {
+	c := &ConfirmedReadRange{
+		BVLC: bvlc,
+		NPDU: npdu,
+		APDU: plumbing.NewAPDU(plumbing.ConfirmedReq, ServiceConfirmedReadRange, nil),
+	}
+	c.SetLength()
+
+	return c
+}
+
+func (c *ConfirmedReadRange) UnmarshalBinary(b []byte) error {
+	if l := len(b); l < c.MarshalLen() {
+		return common.ErrTooShortToParse
+	}
+
+	var offset int = 0
+	if err := c.BVLC.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+
+	return nil
+}
+
+func (c *ConfirmedReadRange) MarshalBinary() ([]byte, error) {
+	b := make([]byte, c.MarshalLen())
+	if err := c.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (c *ConfirmedReadRange) MarshalTo(b []byte) error {
+	if len(b) < c.MarshalLen() {
+		return common.ErrTooShortToMarshalBinary
+	}
+	var offset = 0
+	if err := c.BVLC.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *ConfirmedReadRange) MarshalLen() int {
+	l := c.BVLC.MarshalLen()
+	l += c.NPDU.MarshalLen()
+	l += c.APDU.MarshalLen()
+
+	return l
+}
+
+func (c *ConfirmedReadRange) SetLength() {
+	c.BVLC.Length = uint16(c.MarshalLen())
+}
+
+func (c *ConfirmedReadRange) Decode() (ConfirmedReadRangeDec, error) {
+	decRR := ConfirmedReadRangeDec{ArrayIndex: objects.ArrayAll}
+
+	objs := c.APDU.Objects
+	offset, err := decPropertyTarget(objs, &decRR.ObjectType, &decRR.InstanceId, &decRR.PropertyId, &decRR.ArrayIndex)
+	if err != nil {
+		return decRR, err
+	}
+	if offset == len(objs) {
+		return decRR, nil
+	}
+
+	for _, t := range []uint8{RangeByPosition, RangeBySequenceNumber, RangeByTime} {
+		if objects.IsOpeningTag(objs[offset], t) {
+			decRR.Range.Type = t
+		}
+	}
+	if decRR.Range.Type == RangeAll {
+		return decRR, common.ErrWrongStructure
+	}
+
+	ref, offset, err := objects.DecEnclosed(objs, offset, decRR.Range.Type)
+	if err != nil {
+		return decRR, err
+	}
+	if offset != len(objs) {
+		return decRR, common.ErrWrongObjectCount
+	}
+
+	if decRR.Range.Type == RangeByTime {
+		if len(ref) != 3 {
+			return decRR, common.ErrWrongObjectCount
+		}
+		if decRR.Range.Time, err = objects.DecDateTime(ref[:2]); err != nil {
+			return decRR, err
+		}
+	} else {
+		if len(ref) != 2 {
+			return decRR, common.ErrWrongObjectCount
+		}
+		if decRR.Range.Reference, err = objects.DecUnisgnedInteger(ref[0]); err != nil {
+			return decRR, err
+		}
+	}
+	decRR.Range.Count, err = objects.DecSignedInteger(ref[len(ref)-1])
+
+	return decRR, err
+}
+
+// DecodeReadRange decodes the objects of a ComplexACK answering a ReadRange request.
+func (c *ComplexACK) DecodeReadRange() (ReadRangeACKDec, error) {
+	decRR := ReadRangeACKDec{ArrayIndex: objects.ArrayAll}
+
+	objs := c.APDU.Objects
+	offset, err := decPropertyTarget(objs, &decRR.ObjectType, &decRR.InstanceId, &decRR.PropertyId, &decRR.ArrayIndex)
+	if err != nil {
+		return decRR, err
+	}
+
+	if offset+2 > len(objs) || !objects.IsContextTag(objs[offset], 3) || !objects.IsContextTag(objs[offset+1], 4) {
+		return decRR, common.ErrWrongStructure
+	}
+	flags, err := objects.DecBitString(objs[offset])
+	if err != nil {
+		return decRR, err
+	}
+	if len(flags) < 3 {
+		return decRR, common.ErrWrongStructure
+	}
+	decRR.FirstItem = flags[ResultFlagFirstItem]
+	decRR.LastItem = flags[ResultFlagLastItem]
+	decRR.MoreItems = flags[ResultFlagMoreItems]
+
+	if decRR.ItemCount, err = objects.DecUnisgnedInteger(objs[offset+1]); err != nil {
+		return decRR, err
+	}
+
+	if decRR.ItemData, offset, err = objects.DecEnclosed(objs, offset+2, 5); err != nil {
+		return decRR, err
+	}
+
+	if offset < len(objs) {
+		if !objects.IsContextTag(objs[offset], 6) {
+			return decRR, common.ErrWrongStructure
+		}
+		if decRR.FirstSequenceNumber, err = objects.DecUnisgnedInteger(objs[offset]); err != nil {
+			return decRR, err
+		}
+		offset++
+	}
+	if offset != len(objs) {
+		return decRR, common.ErrWrongObjectCount
+	}
+
+	return decRR, nil
+}
+
+// decPropertyTarget decodes the object identifier, property identifier and optional array
+// index heading ReadRange requests and ACKs. It returns the index of the next payload.
+func decPropertyTarget(objs []objects.APDUPayload, objectType *uint16, instance *uint32, propertyId *uint32, arrayIndex *uint32) (int, error) {
+	if len(objs) < 2 {
+		return 0, common.ErrWrongObjectCount
+	}
+	if !objects.IsContextTag(objs[0], 0) || !objects.IsContextTag(objs[1], 1) {
+		return 0, common.ErrWrongStructure
+	}
+
+	objId, err := objects.DecObjectIdentifier(objs[0])
+	if err != nil {
+		return 0, err
+	}
+	*objectType = objId.ObjectType
+	*instance = objId.InstanceNumber
+
+	if *propertyId, err = objects.DecPropertyIdentifier(objs[1]); err != nil {
+		return 0, err
+	}
+
+	offset := 2
+	if offset < len(objs) && objects.IsContextTag(objs[offset], 2) {
+		if *arrayIndex, err = objects.DecArrayIndex(objs[offset]); err != nil {
+			return 0, err
+		}
+		offset++
+	}
+
+	return offset, nil
+}
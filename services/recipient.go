@@ -0,0 +1,73 @@
+package services
+
+import (
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+)
+
+// Address is a BACnetAddress: a MAC address on a network, network 0 being the local one. An
+// empty MAC address stands for every device on the network.
+type Address struct {
+	Net uint16
+	MAC []byte
+}
+
+// Recipient is a BACnetRecipient: a device, when Address is nil, or an address.
+type Recipient struct {
+	DeviceId uint32
+	Address  *Address
+}
+
+// EncRecipient encodes a BACnetRecipient without enclosing it.
+func EncRecipient(r Recipient) []objects.APDUPayload {
+	if r.Address == nil {
+		return []objects.APDUPayload{objects.EncObjectIdentifier(true, 0, objects.ObjectTypeDevice, r.DeviceId)}
+	}
+	return objects.EncEnclosed(1,
+		objects.EncUnsignedInteger(uint32(r.Address.Net)), objects.EncOctetString(r.Address.MAC))
+}
+
+// DecRecipient decodes the BACnetRecipient starting at rawPayloads[i]. It returns the index of
+// the first payload past the recipient.
+func DecRecipient(rawPayloads []objects.APDUPayload, i int) (Recipient, int, error) {
+	r := Recipient{}
+
+	if i >= len(rawPayloads) {
+		return r, i, common.ErrWrongStructure
+	}
+
+	if objects.IsContextTag(rawPayloads[i], 0) {
+		device, err := objects.DecObjectIdentifier(rawPayloads[i])
+		if err != nil {
+			return r, i, err
+		}
+		if device.ObjectType != objects.ObjectTypeDevice {
+			return r, i, common.ErrWrongStructure
+		}
+		r.DeviceId = device.InstanceNumber
+		return r, i + 1, nil
+	}
+
+	enclosed, next, err := objects.DecEnclosed(rawPayloads, i, 1)
+	if err != nil {
+		return r, i, err
+	}
+	if len(enclosed) != 2 {
+		return r, i, common.ErrWrongObjectCount
+	}
+
+	net, err := objects.DecUnisgnedInteger(enclosed[0])
+	if err != nil {
+		return r, i, err
+	}
+	if net > 0xFFFF {
+		return r, i, common.ErrWrongStructure
+	}
+	mac, err := objects.DecOctetString(enclosed[1])
+	if err != nil {
+		return r, i, err
+	}
+
+	r.Address = &Address{Net: uint16(net), MAC: mac}
+	return r, next, nil
+}
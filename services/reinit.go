@@ -0,0 +1,141 @@
+package services
+
+import (
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+)
+
+// ConfirmedReinitializeDevice is a BACnet message. It's acknowledged with a SimpleACK.
+type ConfirmedReinitializeDevice struct {
+	*plumbing.BVLC
+	*plumbing.NPDU
+	*plumbing.APDU
+}
+
+type ConfirmedReinitializeDeviceDec struct {
+	// State is one of the objects.ReinitializedState constants.
+	State    uint8
+	Password string
+}
+
+// ConfirmedReinitializeDeviceObjects creates the ReinitializeDevice request objects. An empty
+// password is left out.
+func ConfirmedReinitializeDeviceObjects(state uint8, password string) []objects.APDUPayload {
+	objs := make([]objects.APDUPayload, 0, 2)
+
+	objs = append(objs, objects.EncContext(0, objects.EncEnumerated(uint32(state))))
+	if password != "" {
+		objs = append(objs, objects.EncContext(1, objects.EncCharacterString(password)))
+	}
+
+	return objs
+}
+
+func NewConfirmedReinitializeDevice(bvlc *plumbing.BVLC, npdu *plumbing.NPDU) *ConfirmedReinitializeDevice {
+	c := &ConfirmedReinitializeDevice{
+		BVLC: bvlc,
+		NPDU: npdu,
+		APDU: plumbing.NewAPDU(plumbing.ConfirmedReq, ServiceConfirmedReinitializeDevice, nil),
+	}
+	c.SetLength()
+
+	return c
+}
+
+func (c *ConfirmedReinitializeDevice) UnmarshalBinary(b []byte) error {
+	if l := len(b); l < c.MarshalLen() {
+		return common.ErrTooShortToParse
+	}
+
+	var offset int = 0
+	if err := c.BVLC.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+
+	return nil
+}
+
+func (c *ConfirmedReinitializeDevice) MarshalBinary() ([]byte, error) {
+	b := make([]byte, c.MarshalLen())
+	if err := c.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (c *ConfirmedReinitializeDevice) MarshalTo(b []byte) error {
+	if len(b) < c.MarshalLen() {
+		return common.ErrTooShortToMarshalBinary
+	}
+	var offset = 0
+	if err := c.BVLC.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *ConfirmedReinitializeDevice) MarshalLen() int {
+	l := c.BVLC.MarshalLen()
+	l += c.NPDU.MarshalLen()
+	l += c.APDU.MarshalLen()
+
+	return l
+}
+
+func (c *ConfirmedReinitializeDevice) SetLength() {
+	c.BVLC.Length = uint16(c.MarshalLen())
+}
+
+func (c *ConfirmedReinitializeDevice) Decode() (ConfirmedReinitializeDeviceDec, error) {
+	decRD := ConfirmedReinitializeDeviceDec{}
+
+	objs := c.APDU.Objects
+	if len(objs) < 1 || len(objs) > 2 {
+		return decRD, common.ErrWrongObjectCount
+	}
+
+	if !objects.IsContextTag(objs[0], 0) {
+		return decRD, common.ErrWrongStructure
+	}
+	state, err := objects.DecEnumerated(objs[0])
+	if err != nil {
+		return decRD, err
+	}
+	if state > uint32(objects.ReinitializedStateActivateChanges) {
+		return decRD, common.ErrUndefinedEnumeration
+	}
+	decRD.State = uint8(state)
+
+	if len(objs) == 2 {
+		if !objects.IsContextTag(objs[1], 1) {
+			return decRD, common.ErrWrongStructure
+		}
+		if decRD.Password, err = objects.DecCharacterString(objs[1]); err != nil {
+			return decRD, err
+		}
+	}
+
+	return decRD, nil
+}
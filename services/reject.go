@@ -0,0 +1,98 @@
+package services
+
+import (
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/plumbing"
+)
+
+// Reject is a BACnet message.
+type Reject struct {
+	*plumbing.BVLC
+	*plumbing.NPDU
+	*plumbing.APDU
+}
+
+type RejectDec struct {
+	Reason uint8
+}
+
+func NewReject(bvlc *plumbing.BVLC, npdu *plumbing.NPDU) *Reject {
+	r := &Reject{
+		BVLC: bvlc,
+		NPDU: npdu,
+		APDU: plumbing.NewAPDU(plumbing.Reject, plumbing.RejectReasonOther, nil),
+	}
+	r.SetLength()
+
+	return r
+}
+
+func (r *Reject) UnmarshalBinary(b []byte) error {
+	if l := len(b); l < r.MarshalLen() {
+		return common.ErrTooShortToParse
+	}
+
+	var offset int = 0
+	if err := r.BVLC.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += r.BVLC.MarshalLen()
+
+	if err := r.NPDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += r.NPDU.MarshalLen()
+
+	if err := r.APDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+
+	return nil
+}
+
+func (r *Reject) MarshalBinary() ([]byte, error) {
+	b := make([]byte, r.MarshalLen())
+	if err := r.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (r *Reject) MarshalTo(b []byte) error {
+	if len(b) < r.MarshalLen() {
+		return common.ErrTooShortToMarshalBinary
+	}
+	var offset = 0
+	if err := r.BVLC.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += r.BVLC.MarshalLen()
+
+	if err := r.NPDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += r.NPDU.MarshalLen()
+
+	if err := r.APDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *Reject) MarshalLen() int {
+	l := r.BVLC.MarshalLen()
+	l += r.NPDU.MarshalLen()
+	l += r.APDU.MarshalLen()
+
+	return l
+}
+
+func (r *Reject) SetLength() {
+	r.BVLC.Length = uint16(r.MarshalLen())
+}
+
+// Decode returns the reject reason, which travels where the service choice goes.
+func (r *Reject) Decode() (RejectDec, error) {
+	return RejectDec{Reason: r.APDU.Service}, nil
+}
@@ -6,7 +6,7 @@ import (
 	"github.com/ulbios/bacnet/plumbing"
 )
 
-// UnconfirmedIAm is a BACnet message.
+// ConfirmedReadProperty is a BACnet message.
 type ConfirmedReadProperty struct {
 	*plumbing.BVLC
 	*plumbing.NPDU
@@ -16,15 +16,22 @@ type ConfirmedReadProperty struct {
 type ConfirmedReadPropertyDec struct {
 	ObjectType uint16
 	InstanceId uint32
-	PropertyId uint8
+	PropertyId uint32
+	ArrayIndex uint32
 }
 
-func ConfirmedReadPropertyObjects(objectType uint16, instN uint32, propertyId uint8) []objects.APDUPayload {
-	objs := make([]objects.APDUPayload, 2)
+// ConfirmedReadPropertyObjects creates the ReadProperty request objects. Pass objects.ArrayAll
+// as the arrayIndex to leave it out.
+func ConfirmedReadPropertyObjects(objectType uint16, instN uint32, propertyId uint32, arrayIndex uint32) []objects.APDUPayload {
+	objs := make([]objects.APDUPayload, 2, 3)
 
 	objs[0] = objects.EncObjectIdentifier(true, 0, objectType, instN)
 	objs[1] = objects.EncPropertyIdentifier(true, 1, propertyId)
 
+	if arrayIndex != objects.ArrayAll {
+		objs = append(objs, objects.EncArrayIndex(2, arrayIndex))
+	}
+
 	return objs
 }
 
@@ -34,7 +41,7 @@ func NewConfirmedReadProperty(bvlc *plumbing.BVLC, npdu *plumbing.NPDU) *Confirm
 		NPDU: npdu,
 		// TODO: Consider to implement parameter struct to an argment of New functions.
 		APDU: plumbing.NewAPDU(plumbing.ConfirmedReq, ServiceConfirmedReadProperty, ConfirmedReadPropertyObjects(
-			objects.ObjectTypeAnalogOutput, 1, objects.PropertyIdPresentValue)),
+			objects.ObjectTypeAnalogOutput, 1, objects.PropertyIdPresentValue, objects.ArrayAll)),
 	}
 	c.SetLength()
 
@@ -107,9 +114,9 @@ func (c *ConfirmedReadProperty) SetLength() {
 }
 
 func (c *ConfirmedReadProperty) Decode() (ConfirmedReadPropertyDec, error) {
-	decCRP := ConfirmedReadPropertyDec{}
+	decCRP := ConfirmedReadPropertyDec{ArrayIndex: objects.ArrayAll}
 
-	if len(c.APDU.Objects) != 2 {
+	if len(c.APDU.Objects) != 2 && len(c.APDU.Objects) != 3 {
 		return decCRP, common.ErrWrongObjectCount
 	}
 
@@ -128,6 +135,12 @@ func (c *ConfirmedReadProperty) Decode() (ConfirmedReadPropertyDec, error) {
 				return decCRP, err
 			}
 			decCRP.PropertyId = propId
+		case 2:
+			arrayIndex, err := objects.DecArrayIndex(obj)
+			if err != nil {
+				return decCRP, err
+			}
+			decCRP.ArrayIndex = arrayIndex
 		}
 	}
 
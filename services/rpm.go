@@ -0,0 +1,301 @@
+package services
+
+import (
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+)
+
+// ConfirmedReadPropertyMultiple is a BACnet message. It's answered with a ComplexACK whose
+// objects are decoded by ComplexACK.DecodeReadPropertyMultiple.
+type ConfirmedReadPropertyMultiple struct {
+	*plumbing.BVLC
+	*plumbing.NPDU
+	*plumbing.APDU
+}
+
+// ReadAccessSpecification lists the properties of an object to be read. Besides actual
+// properties, objects.PropertyIdAll, objects.PropertyIdRequired and objects.PropertyIdOptional
+// can be asked for.
+type ReadAccessSpecification struct {
+	ObjectType uint16
+	InstanceId uint32
+	Properties []PropertyReference
+}
+
+type ConfirmedReadPropertyMultipleDec struct {
+	Specifications []ReadAccessSpecification
+}
+
+// ReadResult is the outcome of reading a property: either its value or the error preventing it.
+type ReadResult struct {
+	PropertyId uint32
+	// ArrayIndex is objects.ArrayAll when the whole property was read.
+	ArrayIndex uint32
+	Values     []objects.APDUPayload
+	// Error is nil for successful reads.
+	Error *common.BACnetError
+}
+
+// ReadAccessResult holds the outcome of a ReadAccessSpecification.
+type ReadAccessResult struct {
+	ObjectType uint16
+	InstanceId uint32
+	Results    []ReadResult
+}
+
+type ReadPropertyMultipleACKDec struct {
+	Results []ReadAccessResult
+}
+
+// ConfirmedReadPropertyMultipleObjects creates the ReadPropertyMultiple request objects.
+func ConfirmedReadPropertyMultipleObjects(specs []ReadAccessSpecification) []objects.APDUPayload {
+	objs := []objects.APDUPayload{}
+
+	for _, spec := range specs {
+		objs = append(objs, objects.EncObjectIdentifier(true, 0, spec.ObjectType, spec.InstanceId))
+
+		refs := []objects.APDUPayload{}
+		for _, ref := range spec.Properties {
+			refs = append(refs, EncPropertyReference(ref)...)
+		}
+		objs = append(objs, objects.EncEnclosed(1, refs...)...)
+	}
+
+	return objs
+}
+
+// ReadPropertyMultipleACKObjects creates the ReadPropertyMultiple-ACK objects.
+func ReadPropertyMultipleACKObjects(results []ReadAccessResult) []objects.APDUPayload {
+	objs := []objects.APDUPayload{}
+
+	for _, result := range results {
+		objs = append(objs, objects.EncObjectIdentifier(true, 0, result.ObjectType, result.InstanceId))
+
+		list := []objects.APDUPayload{}
+		for _, r := range result.Results {
+			list = append(list, objects.EncPropertyIdentifier(true, 2, r.PropertyId))
+			if r.ArrayIndex != objects.ArrayAll {
+				list = append(list, objects.EncArrayIndex(3, r.ArrayIndex))
+			}
+			if r.Error != nil {
+				list = append(list, objects.EncEnclosed(5, ErrorObjects(r.Error.Class, r.Error.Code)...)...)
+			} else {
+				list = append(list, objects.EncEnclosed(4, r.Values...)...)
+			}
+		}
+		objs = append(objs, objects.EncEnclosed(1, list...)...)
+	}
+
+	return objs
+}
+
+func NewConfirmedReadPropertyMultiple(bvlc *plumbing.BVLC, npdu *plumbing.NPDU) *ConfirmedReadPropertyMultiple {
+	c := &ConfirmedReadPropertyMultiple{
+		BVLC: bvlc,
+		NPDU: npdu,
+		APDU: plumbing.NewAPDU(plumbing.ConfirmedReq, ServiceConfirmedReadPropMultiple, nil),
+	}
+	c.SetLength()
+
+	return c
+}
+
+func (c *ConfirmedReadPropertyMultiple) UnmarshalBinary(b []byte) error {
+	if l := len(b); l < c.MarshalLen() {
+		return common.ErrTooShortToParse
+	}
+
+	var offset int = 0
+	if err := c.BVLC.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+
+	return nil
+}
+
+func (c *ConfirmedReadPropertyMultiple) MarshalBinary() ([]byte, error) {
+	b := make([]byte, c.MarshalLen())
+	if err := c.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (c *ConfirmedReadPropertyMultiple) MarshalTo(b []byte) error {
+	if len(b) < c.MarshalLen() {
+		return common.ErrTooShortToMarshalBinary
+	}
+	var offset = 0
+	if err := c.BVLC.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *ConfirmedReadPropertyMultiple) MarshalLen() int {
+	l := c.BVLC.MarshalLen()
+	l += c.NPDU.MarshalLen()
+	l += c.APDU.MarshalLen()
+
+	return l
+}
+
+func (c *ConfirmedReadPropertyMultiple) SetLength() {
+	c.BVLC.Length = uint16(c.MarshalLen())
+}
+
+func (c *ConfirmedReadPropertyMultiple) Decode() (ConfirmedReadPropertyMultipleDec, error) {
+	decRPM := ConfirmedReadPropertyMultipleDec{}
+
+	if len(c.APDU.Objects) < 3 {
+		return decRPM, common.ErrWrongObjectCount
+	}
+
+	for offset := 0; offset < len(c.APDU.Objects); {
+		if !objects.IsContextTag(c.APDU.Objects[offset], 0) {
+			return decRPM, common.ErrWrongStructure
+		}
+		objId, err := objects.DecObjectIdentifier(c.APDU.Objects[offset])
+		if err != nil {
+			return decRPM, err
+		}
+		spec := ReadAccessSpecification{ObjectType: objId.ObjectType, InstanceId: objId.InstanceNumber}
+
+		var refs []objects.APDUPayload
+		if refs, offset, err = objects.DecEnclosed(c.APDU.Objects, offset+1, 1); err != nil {
+			return decRPM, err
+		}
+		if len(refs) == 0 {
+			return decRPM, common.ErrWrongObjectCount
+		}
+
+		for i := 0; i < len(refs); {
+			var ref PropertyReference
+			if ref, i, err = DecPropertyReference(refs, i); err != nil {
+				return decRPM, err
+			}
+			spec.Properties = append(spec.Properties, ref)
+		}
+
+		decRPM.Specifications = append(decRPM.Specifications, spec)
+	}
+
+	return decRPM, nil
+}
+
+// DecodeReadPropertyMultiple decodes the objects of a ReadPropertyMultiple-ACK.
+func (c *ComplexACK) DecodeReadPropertyMultiple() (ReadPropertyMultipleACKDec, error) {
+	decRPM := ReadPropertyMultipleACKDec{}
+
+	for offset := 0; offset < len(c.APDU.Objects); {
+		if !objects.IsContextTag(c.APDU.Objects[offset], 0) {
+			return decRPM, common.ErrWrongStructure
+		}
+		objId, err := objects.DecObjectIdentifier(c.APDU.Objects[offset])
+		if err != nil {
+			return decRPM, err
+		}
+		result := ReadAccessResult{ObjectType: objId.ObjectType, InstanceId: objId.InstanceNumber}
+		offset++
+
+		// The list of results is optional.
+		if offset < len(c.APDU.Objects) && objects.IsOpeningTag(c.APDU.Objects[offset], 1) {
+			var list []objects.APDUPayload
+			if list, offset, err = objects.DecEnclosed(c.APDU.Objects, offset, 1); err != nil {
+				return decRPM, err
+			}
+			if result.Results, err = decReadResults(list); err != nil {
+				return decRPM, err
+			}
+		}
+
+		decRPM.Results = append(decRPM.Results, result)
+	}
+
+	return decRPM, nil
+}
+
+func decReadResults(rawPayloads []objects.APDUPayload) ([]ReadResult, error) {
+	results := []ReadResult{}
+
+	for i := 0; i < len(rawPayloads); {
+		r := ReadResult{ArrayIndex: objects.ArrayAll}
+
+		if !objects.IsContextTag(rawPayloads[i], 2) {
+			return nil, common.ErrWrongStructure
+		}
+		var err error
+		if r.PropertyId, err = objects.DecPropertyIdentifier(rawPayloads[i]); err != nil {
+			return nil, err
+		}
+		i++
+
+		if i < len(rawPayloads) && objects.IsContextTag(rawPayloads[i], 3) {
+			if r.ArrayIndex, err = objects.DecArrayIndex(rawPayloads[i]); err != nil {
+				return nil, err
+			}
+			i++
+		}
+
+		switch {
+		case i < len(rawPayloads) && objects.IsOpeningTag(rawPayloads[i], 4):
+			if r.Values, i, err = objects.DecEnclosed(rawPayloads, i, 4); err != nil {
+				return nil, err
+			}
+		case i < len(rawPayloads) && objects.IsOpeningTag(rawPayloads[i], 5):
+			var rawErr []objects.APDUPayload
+			if rawErr, i, err = objects.DecEnclosed(rawPayloads, i, 5); err != nil {
+				return nil, err
+			}
+			if r.Error, err = decBACnetError(rawErr); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, common.ErrWrongStructure
+		}
+
+		results = append(results, r)
+	}
+
+	return results, nil
+}
+
+// decBACnetError decodes the error class and code pair of an Error.
+func decBACnetError(rawPayloads []objects.APDUPayload) (*common.BACnetError, error) {
+	if len(rawPayloads) != 2 {
+		return nil, common.ErrWrongObjectCount
+	}
+
+	errClass, err := objects.DecEnumerated(rawPayloads[0])
+	if err != nil {
+		return nil, err
+	}
+	errCode, err := objects.DecEnumerated(rawPayloads[1])
+	if err != nil {
+		return nil, err
+	}
+
+	return &common.BACnetError{Class: uint8(errClass), Code: uint8(errCode)}, nil
+}
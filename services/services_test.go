@@ -5,11 +5,13 @@
 package services_test
 
 import (
+	"math"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/ulbios/bacnet"
 	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
 	"github.com/ulbios/bacnet/plumbing"
 	"github.com/ulbios/bacnet/services"
 )
@@ -40,6 +42,25 @@ func TestUnconfirmedWhoIs(t *testing.T) {
 				0x10, 0x08, // APDU
 			},
 		},
+		{
+			description: "Unconfirmed request WhoIs frame with range limits",
+			structured: func() serializeable {
+				u := services.NewUnconfirmedWhoIs(
+					plumbing.NewBVLC(plumbing.BVLCFuncBroadcast),
+					plumbing.NewNPDU(false, false, false, false),
+				)
+				u.APDU.Objects = services.WhoIsObjects(100, 200)
+				u.SetLength()
+				return u
+			}(),
+			serialized: []byte{
+				0x81, 0x0b, 0x00, 0x0c, // BVLC
+				0x01, 0x00, // NPDU
+				0x10, 0x08, // APDU
+				0x09, 0x64, // Low limit
+				0x19, 0xc8, // High limit
+			},
+		},
 	}
 
 	for _, c := range testcases {
@@ -67,6 +88,25 @@ func TestUnconfirmedWhoIs(t *testing.T) {
 			})
 		})
 	}
+
+	t.Run("Decode range limits", func(t *testing.T) {
+		for i, want := range []services.UnconfirmedWhoIsDec{
+			{LowLimit: 0, HighLimit: objects.MaxInstance},
+			{LowLimit: 100, HighLimit: 200},
+		} {
+			msg, err := bacnet.Parse(testcases[i].serialized)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dec, err := msg.(*services.UnconfirmedWhoIs).Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(want, dec); diff != "" {
+				t.Errorf("differs: (-want +got)\n%s", diff)
+			}
+		}
+	})
 }
 
 func TestUnconfirmedIAm(t *testing.T) {
@@ -117,58 +157,2312 @@ func TestUnconfirmedIAm(t *testing.T) {
 		})
 	}
 }
-func TestBoolToInt(t *testing.T) {
-	cases := []struct {
-		description string
-		b           bool
-		i           int
-	}{
+
+func TestUnconfirmedWhoHas(t *testing.T) {
+	t.Helper()
+	var testcases = []testCase{
 		{
-			"case of true",
-			true,
-			1,
+			description: "Unconfirmed request WhoHas frame by name with range limits",
+			structured: func() serializeable {
+				u := services.NewUnconfirmedWhoHas(
+					plumbing.NewBVLC(plumbing.BVLCFuncBroadcast),
+					plumbing.NewNPDU(false, false, false, false),
+				)
+				u.APDU.Objects = services.WhoHasNameObjects(100, 200, "AV1")
+				u.SetLength()
+				return u
+			}(),
+			serialized: []byte{
+				0x81, 0x0b, 0x00, 0x11, // BVLC
+				0x01, 0x00, // NPDU
+				0x10, 0x07, // APDU
+				0x09, 0x64, // Low limit
+				0x19, 0xc8, // High limit
+				0x3c, 0x00, 0x41, 0x56, 0x31, // Object name
+			},
 		},
 		{
-			"case of false",
-			false,
-			0,
+			description: "Unconfirmed request WhoHas frame by identifier",
+			structured: func() serializeable {
+				u := services.NewUnconfirmedWhoHas(
+					plumbing.NewBVLC(plumbing.BVLCFuncBroadcast),
+					plumbing.NewNPDU(false, false, false, false),
+				)
+				u.APDU.Objects = services.WhoHasObjects(0, objects.MaxInstance, objects.ObjectTypeAnalogValue, 1)
+				u.SetLength()
+				return u
+			}(),
+			serialized: []byte{
+				0x81, 0x0b, 0x00, 0x0d, // BVLC
+				0x01, 0x00, // NPDU
+				0x10, 0x07, // APDU
+				0x2c, 0x00, 0x80, 0x00, 0x01, // Object identifier
+			},
 		},
 	}
 
-	for _, c := range cases {
-		t.Run(c.description, func(t *testing.T) {
-			if got, want := common.BoolToInt(c.b), c.i; got != want {
-				t.Fail()
+	testSerialization(t, testcases)
+
+	t.Run("Decode", func(t *testing.T) {
+		for i, want := range []services.UnconfirmedWhoHasDec{
+			{LowLimit: 100, HighLimit: 200, ObjectName: "AV1"},
+			{LowLimit: 0, HighLimit: objects.MaxInstance, ObjectType: objects.ObjectTypeAnalogValue, InstanceId: 1},
+		} {
+			msg, err := bacnet.Parse(testcases[i].serialized)
+			if err != nil {
+				t.Fatal(err)
 			}
+			dec, err := msg.(*services.UnconfirmedWhoHas).Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(want, dec); diff != "" {
+				t.Errorf("differs: (-want +got)\n%s", diff)
+			}
+		}
+	})
+}
 
-		})
+func TestUnconfirmedIHave(t *testing.T) {
+	t.Helper()
+	var testcases = []testCase{
+		{
+			description: "Unconfirmed request IHave frame",
+			structured: func() serializeable {
+				u := services.NewUnconfirmedIHave(
+					plumbing.NewBVLC(plumbing.BVLCFuncBroadcast),
+					plumbing.NewNPDU(false, false, false, false),
+				)
+				u.APDU.Objects = services.IHaveObjects(321, objects.ObjectTypeAnalogValue, 1, "AV1")
+				u.SetLength()
+				return u
+			}(),
+			serialized: []byte{
+				0x81, 0x0b, 0x00, 0x17, // BVLC
+				0x01, 0x00, // NPDU
+				0x10, 0x01, // APDU
+				0xc4, 0x02, 0x00, 0x01, 0x41, // Device identifier
+				0xc4, 0x00, 0x80, 0x00, 0x01, // Object identifier
+				0x74, 0x00, 0x41, 0x56, 0x31, // Object name
+			},
+		},
 	}
+
+	testSerialization(t, testcases)
+
+	t.Run("Decode", func(t *testing.T) {
+		msg, err := bacnet.Parse(testcases[0].serialized)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dec, err := msg.(*services.UnconfirmedIHave).Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := services.UnconfirmedIHaveDec{DeviceId: 321, ObjectType: objects.ObjectTypeAnalogValue, InstanceId: 1, ObjectName: "AV1"}
+		if diff := cmp.Diff(want, dec); diff != "" {
+			t.Errorf("differs: (-want +got)\n%s", diff)
+		}
+	})
 }
 
-func TestIntToBool(t *testing.T) {
-	cases := []struct {
-		description string
-		b           bool
-		i           int
-	}{
+func TestTimeSynchronization(t *testing.T) {
+	t.Helper()
+	dateTime := objects.DateTime{
+		Date: objects.Date{Year: 126, Month: 10, Day: 19, Weekday: 1},
+		Time: objects.Time{Hour: 13, Minute: 45, Second: 30, Hundredths: 50},
+	}
+
+	var testcases = []testCase{
 		{
-			"case of true",
-			true,
-			1,
+			description: "Unconfirmed request TimeSynchronization frame",
+			structured: func() serializeable {
+				u := services.NewUnconfirmedTimeSynchronization(
+					plumbing.NewBVLC(plumbing.BVLCFuncBroadcast),
+					plumbing.NewNPDU(false, false, false, false),
+				)
+				u.APDU.Objects = services.TimeSynchronizationObjects(dateTime)
+				u.SetLength()
+				return u
+			}(),
+			serialized: []byte{
+				0x81, 0x0b, 0x00, 0x12, // BVLC
+				0x01, 0x00, // NPDU
+				0x10, 0x06, // APDU
+				0xa4, 0x7e, 0x0a, 0x13, 0x01, // Date
+				0xb4, 0x0d, 0x2d, 0x1e, 0x32, // Time
+			},
 		},
 		{
-			"case of false",
-			false,
-			0,
+			description: "Unconfirmed request UTCTimeSynchronization frame",
+			structured: func() serializeable {
+				u := services.NewUnconfirmedUTCTimeSynchronization(
+					plumbing.NewBVLC(plumbing.BVLCFuncBroadcast),
+					plumbing.NewNPDU(false, false, false, false),
+				)
+				u.APDU.Objects = services.TimeSynchronizationObjects(dateTime)
+				u.SetLength()
+				return u
+			}(),
+			serialized: []byte{
+				0x81, 0x0b, 0x00, 0x12, // BVLC
+				0x01, 0x00, // NPDU
+				0x10, 0x09, // APDU
+				0xa4, 0x7e, 0x0a, 0x13, 0x01, // Date
+				0xb4, 0x0d, 0x2d, 0x1e, 0x32, // Time
+			},
 		},
 	}
 
-	for _, c := range cases {
-		t.Run(c.description, func(t *testing.T) {
-			if got, want := common.IntToBool(c.i), c.b; got != want {
-				t.Fail()
+	testSerialization(t, testcases)
+
+	t.Run("Decode", func(t *testing.T) {
+		for _, c := range testcases {
+			msg, err := bacnet.Parse(c.serialized)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var dec services.TimeSynchronizationDec
+			switch m := msg.(type) {
+			case *services.UnconfirmedTimeSynchronization:
+				dec, err = m.Decode()
+			case *services.UnconfirmedUTCTimeSynchronization:
+				dec, err = m.Decode()
+			default:
+				t.Fatalf("parsed as %T", msg)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(services.TimeSynchronizationDec{DateTime: dateTime}, dec); diff != "" {
+				t.Errorf("differs: (-want +got)\n%s", diff)
+			}
+		}
+	})
+}
+
+func TestConfirmedSubscribeCOV(t *testing.T) {
+	t.Helper()
+	var testcases = []testCase{
+		{
+			description: "Confirmed request SubscribeCOV frame",
+			structured: func() serializeable {
+				c := services.NewConfirmedSubscribeCOV(
+					plumbing.NewBVLC(plumbing.BVLCFuncUnicast),
+					plumbing.NewNPDU(false, false, false, true),
+				)
+				c.APDU.MaxSize = 5
+				c.APDU.InvokeID = 1
+				c.APDU.Objects = services.ConfirmedSubscribeCOVObjects(18, objects.ObjectTypeAnalogInput, 10, true, 120)
+				c.SetLength()
+				return c
+			}(),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x15, // BVLC
+				0x01, 0x04, // NPDU
+				0x00, 0x05, 0x01, 0x05, // APDU
+				0x09, 0x12, // Subscriber process ID
+				0x1c, 0x00, 0x00, 0x00, 0x0a, // Monitored object
+				0x29, 0x01, // Issue confirmed notifications
+				0x39, 0x78, // Lifetime
+			},
+		},
+		{
+			description: "Confirmed request SubscribeCOV cancellation frame",
+			structured: func() serializeable {
+				c := services.NewConfirmedSubscribeCOV(
+					plumbing.NewBVLC(plumbing.BVLCFuncUnicast),
+					plumbing.NewNPDU(false, false, false, true),
+				)
+				c.APDU.MaxSize = 5
+				c.APDU.InvokeID = 2
+				c.APDU.Objects = services.ConfirmedSubscribeCOVCancelObjects(18, objects.ObjectTypeAnalogInput, 10)
+				c.SetLength()
+				return c
+			}(),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x11, // BVLC
+				0x01, 0x04, // NPDU
+				0x00, 0x05, 0x02, 0x05, // APDU
+				0x09, 0x12, // Subscriber process ID
+				0x1c, 0x00, 0x00, 0x00, 0x0a, // Monitored object
+			},
+		},
+	}
+
+	testSerialization(t, testcases)
+}
+
+func TestCOVNotification(t *testing.T) {
+	t.Helper()
+	values := []services.PropertyValue{
+		{
+			PropertyId: objects.PropertyIdPresentValue,
+			ArrayIndex: objects.ArrayAll,
+			Values:     []objects.APDUPayload{objects.EncReal(65)},
+		},
+		{
+			PropertyId: objects.PropertyIdStatusFlags,
+			ArrayIndex: objects.ArrayAll,
+			Values:     []objects.APDUPayload{objects.EncBitString(make(objects.BitString, 4))},
+		},
+	}
+	notification := []byte{
+		0x09, 0x12, // Subscriber process ID
+		0x1c, 0x02, 0x00, 0x01, 0x41, // Initiating device
+		0x2c, 0x00, 0x00, 0x00, 0x0a, // Monitored object
+		0x39, 0x3c, // Time remaining
+		0x4e,       // List of values
+		0x09, 0x55, // Present_Value
+		0x2e, 0x44, 0x42, 0x82, 0x00, 0x00, 0x2f,
+		0x09, 0x6f, // Status_Flags
+		0x2e, 0x82, 0x04, 0x00, 0x2f,
+		0x4f,
+	}
+
+	var testcases = []testCase{
+		{
+			description: "Confirmed request COVNotification frame",
+			structured: func() serializeable {
+				c := services.NewConfirmedCOVNotification(
+					plumbing.NewBVLC(plumbing.BVLCFuncUnicast),
+					plumbing.NewNPDU(false, false, false, true),
+				)
+				c.APDU.MaxSize = 5
+				c.APDU.InvokeID = 3
+				c.APDU.Objects = services.COVNotificationObjects(18, 321, objects.ObjectTypeAnalogInput, 10, 60, values)
+				c.SetLength()
+				return c
+			}(),
+			serialized: append([]byte{
+				0x81, 0x0a, 0x00, 0x2a, // BVLC
+				0x01, 0x04, // NPDU
+				0x00, 0x05, 0x03, 0x01, // APDU
+			}, notification...),
+		},
+		{
+			description: "Unconfirmed request COVNotification frame",
+			structured: func() serializeable {
+				u := services.NewUnconfirmedCOVNotification(
+					plumbing.NewBVLC(plumbing.BVLCFuncUnicast),
+					plumbing.NewNPDU(false, false, false, false),
+				)
+				u.APDU.Objects = services.COVNotificationObjects(18, 321, objects.ObjectTypeAnalogInput, 10, 60, values)
+				u.SetLength()
+				return u
+			}(),
+			serialized: append([]byte{
+				0x81, 0x0a, 0x00, 0x28, // BVLC
+				0x01, 0x00, // NPDU
+				0x10, 0x02, // APDU
+			}, notification...),
+		},
+	}
+
+	testSerialization(t, testcases)
+
+	t.Run("Decode values", func(t *testing.T) {
+		msg, err := bacnet.Parse(testcases[1].serialized)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dec, err := msg.(*services.UnconfirmedCOVNotification).Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := services.COVNotificationDec{
+			ProcessId:     18,
+			DeviceId:      321,
+			ObjectType:    objects.ObjectTypeAnalogInput,
+			InstanceId:    10,
+			TimeRemaining: 60,
+			Values:        values,
+		}
+		if diff := cmp.Diff(want, dec); diff != "" {
+			t.Errorf("differs: (-want +got)\n%s", diff)
+		}
+	})
+}
+
+func TestConfirmedSubscribeCOVProperty(t *testing.T) {
+	t.Helper()
+	increment := float32(0.5)
+
+	var testcases = []testCase{
+		{
+			description: "Confirmed request SubscribeCOVProperty frame",
+			structured: func() serializeable {
+				c := services.NewConfirmedSubscribeCOVProperty(
+					plumbing.NewBVLC(plumbing.BVLCFuncUnicast),
+					plumbing.NewNPDU(false, false, false, true),
+				)
+				c.APDU.MaxSize = 5
+				c.APDU.InvokeID = 4
+				c.APDU.Objects = services.ConfirmedSubscribeCOVPropertyObjects(18, objects.ObjectTypeAnalogInput, 10, true, 120,
+					services.PropertyReference{PropertyId: objects.PropertyIdPresentValue, ArrayIndex: objects.ArrayAll}, &increment)
+				c.SetLength()
+				return c
+			}(),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x1e, // BVLC
+				0x01, 0x04, // NPDU
+				0x00, 0x05, 0x04, 0x1c, // APDU
+				0x09, 0x12, // Subscriber process ID
+				0x1c, 0x00, 0x00, 0x00, 0x0a, // Monitored object
+				0x29, 0x01, // Issue confirmed notifications
+				0x39, 0x78, // Lifetime
+				0x4e, 0x09, 0x55, 0x4f, // Monitored property
+				0x5c, 0x3f, 0x00, 0x00, 0x00, // COV increment
+			},
+		},
+		{
+			description: "Confirmed request SubscribeCOVProperty cancellation frame",
+			structured: func() serializeable {
+				c := services.NewConfirmedSubscribeCOVProperty(
+					plumbing.NewBVLC(plumbing.BVLCFuncUnicast),
+					plumbing.NewNPDU(false, false, false, true),
+				)
+				c.APDU.MaxSize = 5
+				c.APDU.InvokeID = 5
+				c.APDU.Objects = services.ConfirmedSubscribeCOVPropertyCancelObjects(18, objects.ObjectTypeAnalogInput, 10,
+					services.PropertyReference{PropertyId: objects.PropertyIdPresentValue, ArrayIndex: 2})
+				c.SetLength()
+				return c
+			}(),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x17, // BVLC
+				0x01, 0x04, // NPDU
+				0x00, 0x05, 0x05, 0x1c, // APDU
+				0x09, 0x12, // Subscriber process ID
+				0x1c, 0x00, 0x00, 0x00, 0x0a, // Monitored object
+				0x4e, 0x09, 0x55, 0x19, 0x02, 0x4f, // Monitored property
+			},
+		},
+	}
+
+	testSerialization(t, testcases)
+
+	t.Run("Decode request", func(t *testing.T) {
+		cases := []struct {
+			serialized []byte
+			want       services.ConfirmedSubscribeCOVPropertyDec
+		}{
+			{
+				testcases[0].serialized,
+				services.ConfirmedSubscribeCOVPropertyDec{
+					ProcessId:  18,
+					ObjectType: objects.ObjectTypeAnalogInput,
+					InstanceId: 10,
+					Confirmed:  true,
+					Lifetime:   120,
+					Property:   services.PropertyReference{PropertyId: objects.PropertyIdPresentValue, ArrayIndex: objects.ArrayAll},
+					Increment:  &increment,
+				},
+			},
+			{
+				testcases[1].serialized,
+				services.ConfirmedSubscribeCOVPropertyDec{
+					ProcessId:  18,
+					ObjectType: objects.ObjectTypeAnalogInput,
+					InstanceId: 10,
+					Cancel:     true,
+					Property:   services.PropertyReference{PropertyId: objects.PropertyIdPresentValue, ArrayIndex: 2},
+				},
+			},
+		}
+
+		for _, c := range cases {
+			msg, err := bacnet.Parse(c.serialized)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dec, err := msg.(*services.ConfirmedSubscribeCOVProperty).Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(c.want, dec); diff != "" {
+				t.Errorf("differs: (-want +got)\n%s", diff)
+			}
+		}
+	})
+}
+
+func TestConfirmedSubscribeCOVPropertyMultiple(t *testing.T) {
+	t.Helper()
+	increment := float32(0.5)
+	specs := []services.COVSubscriptionSpecification{
+		{
+			ObjectType: objects.ObjectTypeAnalogInput,
+			InstanceId: 10,
+			References: []services.COVReference{
+				{
+					Property:  services.PropertyReference{PropertyId: objects.PropertyIdPresentValue, ArrayIndex: objects.ArrayAll},
+					Increment: &increment,
+				},
+				{
+					Property:    services.PropertyReference{PropertyId: objects.PropertyIdStatusFlags, ArrayIndex: objects.ArrayAll},
+					Timestamped: true,
+				},
+			},
+		},
+	}
+
+	var testcases = []testCase{
+		{
+			description: "Confirmed request SubscribeCOVPropertyMultiple frame",
+			structured: func() serializeable {
+				c := services.NewConfirmedSubscribeCOVPropertyMultiple(
+					plumbing.NewBVLC(plumbing.BVLCFuncUnicast),
+					plumbing.NewNPDU(false, false, false, true),
+				)
+				c.APDU.MaxSize = 5
+				c.APDU.InvokeID = 6
+				c.APDU.Objects = services.ConfirmedSubscribeCOVPropertyMultipleObjects(18, true, 120, 5, specs)
+				c.SetLength()
+				return c
+			}(),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x2c, // BVLC
+				0x01, 0x04, // NPDU
+				0x00, 0x05, 0x06, 0x1e, // APDU
+				0x09, 0x12, // Subscriber process ID
+				0x19, 0x01, // Issue confirmed notifications
+				0x29, 0x78, // Lifetime
+				0x39, 0x05, // Max notification delay
+				0x4e,                         // List of COV subscription specifications
+				0x0c, 0x00, 0x00, 0x00, 0x0a, // Monitored object
+				0x1e,                   // List of COV references
+				0x0e, 0x09, 0x55, 0x0f, // Present_Value
+				0x1c, 0x3f, 0x00, 0x00, 0x00, // COV increment
+				0x29, 0x00, // Timestamped
+				0x0e, 0x09, 0x6f, 0x0f, // Status_Flags
+				0x29, 0x01, // Timestamped
+				0x1f,
+				0x4f,
+			},
+		},
+		{
+			description: "Confirmed request SubscribeCOVPropertyMultiple cancellation frame",
+			structured: func() serializeable {
+				c := services.NewConfirmedSubscribeCOVPropertyMultiple(
+					plumbing.NewBVLC(plumbing.BVLCFuncUnicast),
+					plumbing.NewNPDU(false, false, false, true),
+				)
+				c.APDU.MaxSize = 5
+				c.APDU.InvokeID = 7
+				c.APDU.Objects = services.ConfirmedSubscribeCOVPropertyMultipleCancelObjects(18, specs)
+				c.SetLength()
+				return c
+			}(),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x26, // BVLC
+				0x01, 0x04, // NPDU
+				0x00, 0x05, 0x07, 0x1e, // APDU
+				0x09, 0x12, // Subscriber process ID
+				0x4e,                         // List of COV subscription specifications
+				0x0c, 0x00, 0x00, 0x00, 0x0a, // Monitored object
+				0x1e,                   // List of COV references
+				0x0e, 0x09, 0x55, 0x0f, // Present_Value
+				0x1c, 0x3f, 0x00, 0x00, 0x00, // COV increment
+				0x29, 0x00, // Timestamped
+				0x0e, 0x09, 0x6f, 0x0f, // Status_Flags
+				0x29, 0x01, // Timestamped
+				0x1f,
+				0x4f,
+			},
+		},
+	}
+
+	testSerialization(t, testcases)
+
+	t.Run("Decode request", func(t *testing.T) {
+		cases := []struct {
+			serialized []byte
+			want       services.ConfirmedSubscribeCOVPropertyMultipleDec
+		}{
+			{
+				testcases[0].serialized,
+				services.ConfirmedSubscribeCOVPropertyMultipleDec{
+					ProcessId:            18,
+					Confirmed:            true,
+					Lifetime:             120,
+					MaxNotificationDelay: 5,
+					Specifications:       specs,
+				},
+			},
+			{
+				testcases[1].serialized,
+				services.ConfirmedSubscribeCOVPropertyMultipleDec{
+					ProcessId:      18,
+					Cancel:         true,
+					Specifications: specs,
+				},
+			},
+		}
+
+		for _, c := range cases {
+			msg, err := bacnet.Parse(c.serialized)
+			if err != nil {
+				t.Fatal(err)
 			}
+			dec, err := msg.(*services.ConfirmedSubscribeCOVPropertyMultiple).Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(c.want, dec); diff != "" {
+				t.Errorf("differs: (-want +got)\n%s", diff)
+			}
+		}
+	})
+}
+
+func TestCOVNotificationMultiple(t *testing.T) {
+	t.Helper()
+	timestamp := objects.DateTime{
+		Date: objects.Date{Year: 126, Month: 10, Day: 19, Weekday: 1},
+		Time: objects.Time{Hour: 12, Minute: 30},
+	}
+	timeOfChange := objects.Time{Hour: 12, Minute: 29, Second: 59, Hundredths: 50}
+	notifications := []services.COVObjectNotification{
+		{
+			ObjectType: objects.ObjectTypeAnalogInput,
+			InstanceId: 10,
+			Values: []services.COVPropertyValue{
+				{
+					PropertyId:   objects.PropertyIdPresentValue,
+					ArrayIndex:   objects.ArrayAll,
+					Values:       []objects.APDUPayload{objects.EncReal(65)},
+					TimeOfChange: &timeOfChange,
+				},
+				{
+					PropertyId: objects.PropertyIdStatusFlags,
+					ArrayIndex: objects.ArrayAll,
+					Values:     []objects.APDUPayload{objects.EncBitString(make(objects.BitString, 4))},
+				},
+			},
+		},
+	}
+	notification := []byte{
+		0x09, 0x12, // Subscriber process ID
+		0x1c, 0x02, 0x00, 0x01, 0x41, // Initiating device
+		0x29, 0x3c, // Time remaining
+		0x3e, 0xa4, 0x7e, 0x0a, 0x13, 0x01, 0xb4, 0x0c, 0x1e, 0x00, 0x00, 0x3f, // Timestamp
+		0x4e,                         // List of COV notifications
+		0x0c, 0x00, 0x00, 0x00, 0x0a, // Monitored object
+		0x1e,       // List of values
+		0x09, 0x55, // Present_Value
+		0x2e, 0x44, 0x42, 0x82, 0x00, 0x00, 0x2f,
+		0x3c, 0x0c, 0x1d, 0x3b, 0x32, // Time of change
+		0x09, 0x6f, // Status_Flags
+		0x2e, 0x82, 0x04, 0x00, 0x2f,
+		0x1f,
+		0x4f,
+	}
+
+	var testcases = []testCase{
+		{
+			description: "Confirmed request COVNotificationMultiple frame",
+			structured: func() serializeable {
+				c := services.NewConfirmedCOVNotificationMultiple(
+					plumbing.NewBVLC(plumbing.BVLCFuncUnicast),
+					plumbing.NewNPDU(false, false, false, true),
+				)
+				c.APDU.MaxSize = 5
+				c.APDU.InvokeID = 8
+				c.APDU.Objects = services.COVNotificationMultipleObjects(18, 321, 60, &timestamp, notifications)
+				c.SetLength()
+				return c
+			}(),
+			serialized: append([]byte{
+				0x81, 0x0a, 0x00, 0x3d, // BVLC
+				0x01, 0x04, // NPDU
+				0x00, 0x05, 0x08, 0x1f, // APDU
+			}, notification...),
+		},
+		{
+			description: "Unconfirmed request COVNotificationMultiple frame",
+			structured: func() serializeable {
+				u := services.NewUnconfirmedCOVNotificationMultiple(
+					plumbing.NewBVLC(plumbing.BVLCFuncUnicast),
+					plumbing.NewNPDU(false, false, false, false),
+				)
+				u.APDU.Objects = services.COVNotificationMultipleObjects(18, 321, 60, &timestamp, notifications)
+				u.SetLength()
+				return u
+			}(),
+			serialized: append([]byte{
+				0x81, 0x0a, 0x00, 0x3b, // BVLC
+				0x01, 0x00, // NPDU
+				0x10, 0x0b, // APDU
+			}, notification...),
+		},
+	}
+
+	testSerialization(t, testcases)
+
+	t.Run("Decode values", func(t *testing.T) {
+		msg, err := bacnet.Parse(testcases[0].serialized)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dec, err := msg.(*services.ConfirmedCOVNotificationMultiple).Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := services.COVNotificationMultipleDec{
+			ProcessId:     18,
+			DeviceId:      321,
+			TimeRemaining: 60,
+			Timestamp:     &timestamp,
+			Notifications: notifications,
+		}
+		if diff := cmp.Diff(want, dec); diff != "" {
+			t.Errorf("differs: (-want +got)\n%s", diff)
+		}
+	})
+}
+
+func TestReadPropertyMultiple(t *testing.T) {
+	t.Helper()
+	specs := []services.ReadAccessSpecification{
+		{
+			ObjectType: objects.ObjectTypeAnalogInput,
+			InstanceId: 10,
+			Properties: []services.PropertyReference{
+				{PropertyId: objects.PropertyIdPresentValue, ArrayIndex: objects.ArrayAll},
+				{PropertyId: objects.PropertyIdObjectName, ArrayIndex: objects.ArrayAll},
+			},
+		},
+		{
+			ObjectType: objects.ObjectTypeDevice,
+			InstanceId: 321,
+			Properties: []services.PropertyReference{
+				{PropertyId: objects.PropertyIdAll, ArrayIndex: objects.ArrayAll},
+			},
+		},
+	}
+	results := []services.ReadAccessResult{
+		{
+			ObjectType: objects.ObjectTypeAnalogInput,
+			InstanceId: 10,
+			Results: []services.ReadResult{
+				{
+					PropertyId: objects.PropertyIdPresentValue,
+					ArrayIndex: objects.ArrayAll,
+					Values:     []objects.APDUPayload{objects.EncReal(1.5)},
+				},
+				{
+					PropertyId: objects.PropertyIdDescription,
+					ArrayIndex: objects.ArrayAll,
+					Error:      &common.BACnetError{Class: objects.ErrorClassProperty, Code: objects.ErrorCodeUnknownProperty},
+				},
+			},
+		},
+		{
+			ObjectType: objects.ObjectTypeMultiStateValue,
+			InstanceId: 1,
+			Results: []services.ReadResult{
+				{
+					PropertyId: objects.PropertyIdStateText,
+					ArrayIndex: 1,
+					Values:     []objects.APDUPayload{objects.EncCharacterString("On")},
+				},
+			},
+		},
+	}
+
+	var testcases = []testCase{
+		{
+			description: "Confirmed request ReadPropertyMultiple frame",
+			structured: func() serializeable {
+				c := services.NewConfirmedReadPropertyMultiple(
+					plumbing.NewBVLC(plumbing.BVLCFuncUnicast),
+					plumbing.NewNPDU(false, false, false, true),
+				)
+				c.APDU.MaxSize = 5
+				c.APDU.InvokeID = 9
+				c.APDU.Objects = services.ConfirmedReadPropertyMultipleObjects(specs)
+				c.SetLength()
+				return c
+			}(),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x1e, // BVLC
+				0x01, 0x04, // NPDU
+				0x00, 0x05, 0x09, 0x0e, // APDU
+				0x0c, 0x00, 0x00, 0x00, 0x0a, // Object identifier
+				0x1e, 0x09, 0x55, 0x09, 0x4d, 0x1f, // Present_Value and Object_Name
+				0x0c, 0x02, 0x00, 0x01, 0x41, // Object identifier
+				0x1e, 0x09, 0x08, 0x1f, // ALL
+			},
+		},
+		{
+			description: "ReadPropertyMultiple-ACK frame",
+			structured: func() serializeable {
+				c := services.NewComplexACK(
+					plumbing.NewBVLC(plumbing.BVLCFuncUnicast),
+					plumbing.NewNPDU(false, false, false, false),
+				)
+				c.APDU.Service = services.ServiceConfirmedReadPropMultiple
+				c.APDU.InvokeID = 9
+				c.APDU.Objects = services.ReadPropertyMultipleACKObjects(results)
+				c.SetLength()
+				return c
+			}(),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x32, // BVLC
+				0x01, 0x00, // NPDU
+				0x30, 0x09, 0x0e, // APDU
+				0x0c, 0x00, 0x00, 0x00, 0x0a, // Object identifier
+				0x1e,
+				0x29, 0x55, 0x4e, 0x44, 0x3f, 0xc0, 0x00, 0x00, 0x4f, // Present_Value
+				0x29, 0x1c, 0x5e, 0x91, 0x02, 0x91, 0x20, 0x5f, // Description access error
+				0x1f,
+				0x0c, 0x04, 0xc0, 0x00, 0x01, // Object identifier
+				0x1e,
+				0x29, 0x6e, 0x39, 0x01, 0x4e, 0x73, 0x00, 0x4f, 0x6e, 0x4f, // State_Text[1]
+				0x1f,
+			},
+		},
+	}
+
+	testSerialization(t, testcases)
+
+	t.Run("Decode request", func(t *testing.T) {
+		msg, err := bacnet.Parse(testcases[0].serialized)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dec, err := msg.(*services.ConfirmedReadPropertyMultiple).Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := services.ConfirmedReadPropertyMultipleDec{Specifications: specs}
+		if diff := cmp.Diff(want, dec); diff != "" {
+			t.Errorf("differs: (-want +got)\n%s", diff)
+		}
+	})
+
+	t.Run("Decode ACK", func(t *testing.T) {
+		msg, err := bacnet.Parse(testcases[1].serialized)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dec, err := msg.(*services.ComplexACK).DecodeReadPropertyMultiple()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := services.ReadPropertyMultipleACKDec{Results: results}
+		if diff := cmp.Diff(want, dec); diff != "" {
+			t.Errorf("differs: (-want +got)\n%s", diff)
+		}
+	})
+}
+
+func TestWritePropertyMultiple(t *testing.T) {
+	t.Helper()
+	specs := []services.WriteAccessSpecification{
+		{
+			ObjectType: objects.ObjectTypeAnalogOutput,
+			InstanceId: 1,
+			Values: []services.PropertyValue{{
+				PropertyId: objects.PropertyIdPresentValue,
+				ArrayIndex: objects.ArrayAll,
+				Values:     []objects.APDUPayload{objects.EncReal(21.5)},
+				Priority:   8,
+			}},
+		},
+		{
+			ObjectType: objects.ObjectTypeMultiStateValue,
+			InstanceId: 0,
+			Values: []services.PropertyValue{{
+				PropertyId: objects.PropertyIdStateText,
+				ArrayIndex: 2,
+				Values:     []objects.APDUPayload{objects.EncCharacterString("mid")},
+			}},
+		},
+	}
 
+	var testcases = []testCase{
+		{
+			description: "Confirmed request WritePropertyMultiple frame",
+			structured: func() serializeable {
+				c := services.NewConfirmedWritePropertyMultiple(
+					plumbing.NewBVLC(plumbing.BVLCFuncUnicast),
+					plumbing.NewNPDU(false, false, false, true),
+				)
+				c.APDU.MaxSize = 5
+				c.APDU.InvokeID = 10
+				c.APDU.Objects = services.ConfirmedWritePropertyMultipleObjects(specs)
+				c.SetLength()
+				return c
+			}(),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x2e, // BVLC
+				0x01, 0x04, // NPDU
+				0x00, 0x05, 0x0a, 0x10, // APDU
+				0x0c, 0x00, 0x40, 0x00, 0x01, // Object identifier
+				0x1e, 0x09, 0x55, 0x2e, 0x44, 0x41, 0xac, 0x00, 0x00, 0x2f, 0x39, 0x08, 0x1f, // Present_Value at priority 8
+				0x0c, 0x04, 0xc0, 0x00, 0x00, // Object identifier
+				0x1e, 0x09, 0x6e, 0x19, 0x02, 0x2e, 0x74, 0x00, 0x6d, 0x69, 0x64, 0x2f, 0x1f, // State_Text[2]
+			},
+		},
+		{
+			description: "WritePropertyMultiple-Error frame",
+			structured: func() serializeable {
+				e := services.NewError(
+					plumbing.NewBVLC(plumbing.BVLCFuncUnicast),
+					plumbing.NewNPDU(false, false, false, false),
+				)
+				e.APDU.Service = services.ServiceConfirmedWritePropMultiple
+				e.APDU.InvokeID = 10
+				e.APDU.Objects = services.WritePropertyMultipleErrorObjects(objects.ErrorClassProperty, objects.ErrorCodeWriteAccessDenied,
+					objects.ObjectTypeMultiStateValue, 0, objects.PropertyIdStateText, 2)
+				e.SetLength()
+				return e
+			}(),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x1a, // BVLC
+				0x01, 0x00, // NPDU
+				0x50, 0x0a, 0x10, // APDU
+				0x0e, 0x91, 0x02, 0x91, 0x28, 0x0f, // Error
+				0x1e, 0x0c, 0x04, 0xc0, 0x00, 0x00, 0x19, 0x6e, 0x29, 0x02, 0x1f, // First failed write attempt
+			},
+		},
+	}
+
+	testSerialization(t, testcases)
+
+	t.Run("Decode request", func(t *testing.T) {
+		msg, err := bacnet.Parse(testcases[0].serialized)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dec, err := msg.(*services.ConfirmedWritePropertyMultiple).Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := services.ConfirmedWritePropertyMultipleDec{Specifications: specs}
+		if diff := cmp.Diff(want, dec); diff != "" {
+			t.Errorf("differs: (-want +got)\n%s", diff)
+		}
+	})
+
+	t.Run("Decode error", func(t *testing.T) {
+		msg, err := bacnet.Parse(testcases[1].serialized)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dec, err := msg.(*services.Error).DecodeWritePropertyMultiple()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := services.WritePropertyMultipleErrorDec{
+			ErrorClass: objects.ErrorClassProperty,
+			ErrorCode:  objects.ErrorCodeWriteAccessDenied,
+			ObjectType: objects.ObjectTypeMultiStateValue,
+			InstanceId: 0,
+			PropertyId: objects.PropertyIdStateText,
+			ArrayIndex: 2,
+		}
+		if diff := cmp.Diff(want, dec); diff != "" {
+			t.Errorf("differs: (-want +got)\n%s", diff)
+		}
+	})
+}
+
+// testSerialization checks every test case both parses into and serializes from its structure.
+func testSerialization(t *testing.T, testcases []testCase) {
+	t.Helper()
+	for _, c := range testcases {
+		t.Run(c.description, func(t *testing.T) {
+			t.Run("Decode", func(t *testing.T) {
+				msg, err := bacnet.Parse(c.serialized)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				want, got := c.structured, msg
+				if diff := cmp.Diff(want, got); diff != "" {
+					t.Errorf("differs: (-want +got)\n%s", diff)
+				}
+			})
+			t.Run("Serialize", func(t *testing.T) {
+				b, err := c.structured.MarshalBinary()
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				want, got := c.serialized, b
+				if diff := cmp.Diff(want, got); diff != "" {
+					t.Errorf("differs: (-want +got)\n%s", diff)
+				}
+			})
+		})
+	}
+}
+
+func TestBoolToInt(t *testing.T) {
+	cases := []struct {
+		description string
+		b           bool
+		i           int
+	}{
+		{
+			"case of true",
+			true,
+			1,
+		},
+		{
+			"case of false",
+			false,
+			0,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			if got, want := common.BoolToInt(c.b), c.i; got != want {
+				t.Fail()
+			}
+
+		})
+	}
+}
+
+func TestIntToBool(t *testing.T) {
+	cases := []struct {
+		description string
+		b           bool
+		i           int
+	}{
+		{
+			"case of true",
+			true,
+			1,
+		},
+		{
+			"case of false",
+			false,
+			0,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.description, func(t *testing.T) {
+			if got, want := common.IntToBool(c.i), c.b; got != want {
+				t.Fail()
+			}
+
+		})
+	}
+}
+
+func TestDeviceCommunicationControl(t *testing.T) {
+	var testcases = []testCase{
+		{
+			description: "Confirmed request DeviceCommunicationControl frame",
+			structured: func() serializeable {
+				c := services.NewConfirmedDeviceCommunicationControl(
+					plumbing.NewBVLC(plumbing.BVLCFuncUnicast),
+					plumbing.NewNPDU(false, false, false, true),
+				)
+				c.APDU.MaxSize = 5
+				c.APDU.InvokeID = 1
+				c.APDU.Objects = services.ConfirmedDeviceCommunicationControlObjects(5, objects.CommunicationDisable, "secret")
+				c.SetLength()
+				return c
+			}(),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x17, // BVLC
+				0x01, 0x04, // NPDU
+				0x00, 0x05, 0x01, 0x11, // APDU
+				0x09, 0x05, // Duration
+				0x19, 0x01, // State
+				0x2d, 0x07, 0x00, 0x73, 0x65, 0x63, 0x72, 0x65, 0x74, // Password
+			},
+		},
+		{
+			description: "Confirmed request DeviceCommunicationControl frame without duration nor password",
+			structured: func() serializeable {
+				c := services.NewConfirmedDeviceCommunicationControl(
+					plumbing.NewBVLC(plumbing.BVLCFuncUnicast),
+					plumbing.NewNPDU(false, false, false, true),
+				)
+				c.APDU.MaxSize = 5
+				c.APDU.InvokeID = 2
+				c.APDU.Objects = services.ConfirmedDeviceCommunicationControlObjects(0, objects.CommunicationEnable, "")
+				c.SetLength()
+				return c
+			}(),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x0c, // BVLC
+				0x01, 0x04, // NPDU
+				0x00, 0x05, 0x02, 0x11, // APDU
+				0x19, 0x00, // State
+			},
+		},
+	}
+
+	testSerialization(t, testcases)
+
+	t.Run("Decode", func(t *testing.T) {
+		want := []services.ConfirmedDeviceCommunicationControlDec{
+			{Duration: 5, State: objects.CommunicationDisable, Password: "secret"},
+			{State: objects.CommunicationEnable},
+		}
+		for i, c := range testcases {
+			msg, err := bacnet.Parse(c.serialized)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dec, err := msg.(*services.ConfirmedDeviceCommunicationControl).Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(want[i], dec); diff != "" {
+				t.Errorf("%s: mismatch (-want +got):\n%s", c.description, diff)
+			}
+		}
+	})
+}
+
+func TestReinitializeDevice(t *testing.T) {
+	var testcases = []testCase{
+		{
+			description: "Confirmed request ReinitializeDevice frame",
+			structured: func() serializeable {
+				c := services.NewConfirmedReinitializeDevice(
+					plumbing.NewBVLC(plumbing.BVLCFuncUnicast),
+					plumbing.NewNPDU(false, false, false, true),
+				)
+				c.APDU.MaxSize = 5
+				c.APDU.InvokeID = 1
+				c.APDU.Objects = services.ConfirmedReinitializeDeviceObjects(objects.ReinitializedStateWarmstart, "pw")
+				c.SetLength()
+				return c
+			}(),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x10, // BVLC
+				0x01, 0x04, // NPDU
+				0x00, 0x05, 0x01, 0x14, // APDU
+				0x09, 0x01, // State
+				0x1b, 0x00, 0x70, 0x77, // Password
+			},
+		},
+		{
+			description: "Confirmed request ReinitializeDevice frame without password",
+			structured: func() serializeable {
+				c := services.NewConfirmedReinitializeDevice(
+					plumbing.NewBVLC(plumbing.BVLCFuncUnicast),
+					plumbing.NewNPDU(false, false, false, true),
+				)
+				c.APDU.MaxSize = 5
+				c.APDU.InvokeID = 2
+				c.APDU.Objects = services.ConfirmedReinitializeDeviceObjects(objects.ReinitializedStateStartBackup, "")
+				c.SetLength()
+				return c
+			}(),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x0c, // BVLC
+				0x01, 0x04, // NPDU
+				0x00, 0x05, 0x02, 0x14, // APDU
+				0x09, 0x02, // State
+			},
+		},
+	}
+
+	testSerialization(t, testcases)
+
+	t.Run("Decode", func(t *testing.T) {
+		want := []services.ConfirmedReinitializeDeviceDec{
+			{State: objects.ReinitializedStateWarmstart, Password: "pw"},
+			{State: objects.ReinitializedStateStartBackup},
+		}
+		for i, c := range testcases {
+			msg, err := bacnet.Parse(c.serialized)
+			if err != nil {
+				t.Fatal(err)
+			}
+			dec, err := msg.(*services.ConfirmedReinitializeDevice).Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(want[i], dec); diff != "" {
+				t.Errorf("%s: mismatch (-want +got):\n%s", c.description, diff)
+			}
+		}
+	})
+}
+
+func TestAtomicFile(t *testing.T) {
+	request := func(service uint8, invokeID uint8, objs []objects.APDUPayload) serializeable {
+		var c interface {
+			serializeable
+			GetAPDU() *plumbing.APDU
+			SetLength()
+		}
+		if service == services.ServiceConfirmedAtomicReadFile {
+			c = services.NewConfirmedAtomicReadFile(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+		} else {
+			c = services.NewConfirmedAtomicWriteFile(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+		}
+		c.GetAPDU().MaxSize = 5
+		c.GetAPDU().InvokeID = invokeID
+		c.GetAPDU().Objects = objs
+		c.SetLength()
+		return c
+	}
+	ack := func(service uint8, objs []objects.APDUPayload) serializeable {
+		c := services.NewComplexACK(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, false))
+		c.APDU.Service = service
+		c.APDU.InvokeID = 9
+		c.APDU.Objects = objs
+		c.SetLength()
+		return c
+	}
+
+	var testcases = []testCase{
+		{
+			description: "Confirmed request AtomicReadFile stream access frame",
+			structured:  request(services.ServiceConfirmedAtomicReadFile, 1, services.ConfirmedAtomicReadFileObjects(1, true, 0, 100)),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x15, // BVLC
+				0x01, 0x04, // NPDU
+				0x00, 0x05, 0x01, 0x06, // APDU
+				0xc4, 0x02, 0x80, 0x00, 0x01, // File identifier
+				0x0e,
+				0x31, 0x00, // File start position
+				0x21, 0x64, // Requested octet count
+				0x0f,
+			},
+		},
+		{
+			description: "Confirmed request AtomicReadFile record access frame",
+			structured:  request(services.ServiceConfirmedAtomicReadFile, 2, services.ConfirmedAtomicReadFileObjects(1, false, 2, 3)),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x15, // BVLC
+				0x01, 0x04, // NPDU
+				0x00, 0x05, 0x02, 0x06, // APDU
+				0xc4, 0x02, 0x80, 0x00, 0x01, // File identifier
+				0x1e,
+				0x31, 0x02, // File start record
+				0x21, 0x03, // Requested record count
+				0x1f,
+			},
+		},
+		{
+			description: "Confirmed request AtomicWriteFile stream access frame",
+			structured:  request(services.ServiceConfirmedAtomicWriteFile, 3, services.ConfirmedAtomicWriteFileStreamObjects(1, -1, []byte("abc"))),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x17, // BVLC
+				0x01, 0x04, // NPDU
+				0x00, 0x05, 0x03, 0x07, // APDU
+				0xc4, 0x02, 0x80, 0x00, 0x01, // File identifier
+				0x0e,
+				0x31, 0xff, // File start position
+				0x63, 0x61, 0x62, 0x63, // File data
+				0x0f,
+			},
+		},
+		{
+			description: "Confirmed request AtomicWriteFile record access frame",
+			structured:  request(services.ServiceConfirmedAtomicWriteFile, 4, services.ConfirmedAtomicWriteFileRecordObjects(1, 0, [][]byte{[]byte("a"), []byte("bc")})),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x1a, // BVLC
+				0x01, 0x04, // NPDU
+				0x00, 0x05, 0x04, 0x07, // APDU
+				0xc4, 0x02, 0x80, 0x00, 0x01, // File identifier
+				0x1e,
+				0x31, 0x00, // File start record
+				0x21, 0x02, // Record count
+				0x61, 0x61, // Record
+				0x62, 0x62, 0x63, // Record
+				0x1f,
+			},
+		},
+		{
+			description: "Complex ACK AtomicReadFile stream access frame",
+			structured:  ack(services.ServiceConfirmedAtomicReadFile, services.AtomicReadFileACKStreamObjects(true, 0, []byte("abc"))),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x12, // BVLC
+				0x01, 0x00, // NPDU
+				0x30, 0x09, 0x06, // APDU
+				0x11, // End of file
+				0x0e,
+				0x31, 0x00, // File start position
+				0x63, 0x61, 0x62, 0x63, // File data
+				0x0f,
+			},
+		},
+		{
+			description: "Complex ACK AtomicReadFile record access frame",
+			structured:  ack(services.ServiceConfirmedAtomicReadFile, services.AtomicReadFileACKRecordObjects(false, 2, [][]byte{[]byte("a")})),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x12, // BVLC
+				0x01, 0x00, // NPDU
+				0x30, 0x09, 0x06, // APDU
+				0x10, // End of file
+				0x1e,
+				0x31, 0x02, // File start record
+				0x21, 0x01, // Returned record count
+				0x61, 0x61, // Record
+				0x1f,
+			},
+		},
+		{
+			description: "Complex ACK AtomicWriteFile frame",
+			structured:  ack(services.ServiceConfirmedAtomicWriteFile, services.AtomicWriteFileACKObjects(true, 5)),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x0b, // BVLC
+				0x01, 0x00, // NPDU
+				0x30, 0x09, 0x07, // APDU
+				0x09, 0x05, // File start position
+			},
+		},
+	}
+
+	testSerialization(t, testcases)
+
+	t.Run("Decode", func(t *testing.T) {
+		want := []interface{}{
+			services.ConfirmedAtomicReadFileDec{InstanceId: 1, Stream: true, Count: 100},
+			services.ConfirmedAtomicReadFileDec{InstanceId: 1, Start: 2, Count: 3},
+			services.ConfirmedAtomicWriteFileDec{InstanceId: 1, Stream: true, Start: -1, Data: []byte("abc")},
+			services.ConfirmedAtomicWriteFileDec{InstanceId: 1, Records: [][]byte{[]byte("a"), []byte("bc")}},
+			services.AtomicReadFileACKDec{EndOfFile: true, Stream: true, Data: []byte("abc")},
+			services.AtomicReadFileACKDec{Start: 2, Records: [][]byte{[]byte("a")}},
+			services.AtomicWriteFileACKDec{Stream: true, Start: 5},
+		}
+		for i, c := range testcases {
+			msg, err := bacnet.Parse(c.serialized)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got interface{}
+			switch m := msg.(type) {
+			case *services.ConfirmedAtomicReadFile:
+				got, err = m.Decode()
+			case *services.ConfirmedAtomicWriteFile:
+				got, err = m.Decode()
+			case *services.ComplexACK:
+				if m.APDU.Service == services.ServiceConfirmedAtomicReadFile {
+					got, err = m.DecodeAtomicReadFile()
+				} else {
+					got, err = m.DecodeAtomicWriteFile()
+				}
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(want[i], got); diff != "" {
+				t.Errorf("%s: mismatch (-want +got):\n%s", c.description, diff)
+			}
+		}
+	})
+}
+
+func TestCreateDeleteObject(t *testing.T) {
+	request := func(service uint8, invokeID uint8, objs []objects.APDUPayload) serializeable {
+		var c interface {
+			serializeable
+			GetAPDU() *plumbing.APDU
+			SetLength()
+		}
+		if service == services.ServiceConfirmedCreateObject {
+			c = services.NewConfirmedCreateObject(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+		} else {
+			c = services.NewConfirmedDeleteObject(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+		}
+		c.GetAPDU().MaxSize = 5
+		c.GetAPDU().InvokeID = invokeID
+		c.GetAPDU().Objects = objs
+		c.SetLength()
+		return c
+	}
+	values := []services.PropertyValue{
+		{PropertyId: objects.PropertyIdObjectName, ArrayIndex: objects.ArrayAll, Values: []objects.APDUPayload{objects.EncCharacterString("x")}},
+		{PropertyId: objects.PropertyIdPresentValue, ArrayIndex: objects.ArrayAll, Values: []objects.APDUPayload{objects.EncReal(1.5)}},
+	}
+
+	var testcases = []testCase{
+		{
+			description: "Confirmed request CreateObject by type frame",
+			structured:  request(services.ServiceConfirmedCreateObject, 1, services.ConfirmedCreateObjectObjects(objects.ObjectTypeAnalogValue, objects.MaxInstance, nil)),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x0e, // BVLC
+				0x01, 0x04, // NPDU
+				0x00, 0x05, 0x01, 0x0a, // APDU
+				0x0e, 0x09, 0x02, 0x0f, // Object type
+			},
+		},
+		{
+			description: "Confirmed request CreateObject by identifier with initial values frame",
+			structured:  request(services.ServiceConfirmedCreateObject, 2, services.ConfirmedCreateObjectObjects(objects.ObjectTypeAnalogValue, 3, values)),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x23, // BVLC
+				0x01, 0x04, // NPDU
+				0x00, 0x05, 0x02, 0x0a, // APDU
+				0x0e, 0x1c, 0x00, 0x80, 0x00, 0x03, 0x0f, // Object identifier
+				0x1e,
+				0x09, 0x4d, 0x2e, 0x72, 0x00, 0x78, 0x2f, // Object_Name
+				0x09, 0x55, 0x2e, 0x44, 0x3f, 0xc0, 0x00, 0x00, 0x2f, // Present_Value
+				0x1f,
+			},
+		},
+		{
+			description: "Complex ACK CreateObject frame",
+			structured: func() serializeable {
+				c := services.NewComplexACK(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, false))
+				c.APDU.Service = services.ServiceConfirmedCreateObject
+				c.APDU.InvokeID = 9
+				c.APDU.Objects = services.CreateObjectACKObjects(objects.ObjectTypeAnalogValue, 3)
+				c.SetLength()
+				return c
+			}(),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x0e, // BVLC
+				0x01, 0x00, // NPDU
+				0x30, 0x09, 0x0a, // APDU
+				0xc4, 0x00, 0x80, 0x00, 0x03, // Object identifier
+			},
+		},
+		{
+			description: "CreateObject-Error frame",
+			structured: func() serializeable {
+				e := services.NewError(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, false))
+				e.APDU.Service = services.ServiceConfirmedCreateObject
+				e.APDU.InvokeID = 10
+				e.APDU.Objects = services.CreateObjectErrorObjects(objects.ErrorClassProperty, objects.ErrorCodeValueOutOfRange, 2)
+				e.SetLength()
+				return e
+			}(),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x11, // BVLC
+				0x01, 0x00, // NPDU
+				0x50, 0x0a, 0x0a, // APDU
+				0x0e, 0x91, 0x02, 0x91, 0x25, 0x0f, // Error
+				0x19, 0x02, // First failed element number
+			},
+		},
+		{
+			description: "Confirmed request DeleteObject frame",
+			structured:  request(services.ServiceConfirmedDeleteObject, 3, services.ConfirmedDeleteObjectObjects(objects.ObjectTypeAnalogValue, 3)),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x0f, // BVLC
+				0x01, 0x04, // NPDU
+				0x00, 0x05, 0x03, 0x0b, // APDU
+				0xc4, 0x00, 0x80, 0x00, 0x03, // Object identifier
+			},
+		},
+	}
+
+	testSerialization(t, testcases)
+
+	t.Run("Decode", func(t *testing.T) {
+		want := []interface{}{
+			services.ConfirmedCreateObjectDec{ObjectType: objects.ObjectTypeAnalogValue, InstanceId: objects.MaxInstance},
+			services.ConfirmedCreateObjectDec{ObjectType: objects.ObjectTypeAnalogValue, InstanceId: 3, InitialValues: values},
+			objects.ObjectIdentifier{ObjectType: objects.ObjectTypeAnalogValue, InstanceNumber: 3},
+			services.CreateObjectErrorDec{ErrorClass: objects.ErrorClassProperty, ErrorCode: objects.ErrorCodeValueOutOfRange, FirstFailedElement: 2},
+			services.ConfirmedDeleteObjectDec{ObjectType: objects.ObjectTypeAnalogValue, InstanceId: 3},
+		}
+		for i, c := range testcases {
+			msg, err := bacnet.Parse(c.serialized)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got interface{}
+			switch m := msg.(type) {
+			case *services.ConfirmedCreateObject:
+				got, err = m.Decode()
+			case *services.ComplexACK:
+				got, err = m.DecodeCreateObject()
+			case *services.Error:
+				got, err = m.DecodeCreateObject()
+			case *services.ConfirmedDeleteObject:
+				got, err = m.Decode()
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(want[i], got); diff != "" {
+				t.Errorf("%s: mismatch (-want +got):\n%s", c.description, diff)
+			}
+		}
+	})
+}
+
+func TestListElement(t *testing.T) {
+	request := func(service uint8, invokeID uint8, objs []objects.APDUPayload) serializeable {
+		var c interface {
+			serializeable
+			GetAPDU() *plumbing.APDU
+			SetLength()
+		}
+		if service == services.ServiceConfirmedAddListElement {
+			c = services.NewConfirmedAddListElement(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+		} else {
+			c = services.NewConfirmedRemoveListElement(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+		}
+		c.GetAPDU().MaxSize = 5
+		c.GetAPDU().InvokeID = invokeID
+		c.GetAPDU().Objects = objs
+		c.SetLength()
+		return c
+	}
+	date := []objects.APDUPayload{objects.EncDate(objects.Date{Year: 120, Month: 12, Day: 25, Weekday: 5})}
+	unsigned := []objects.APDUPayload{objects.EncUnsignedInteger(1), objects.EncUnsignedInteger(2)}
+
+	var testcases = []testCase{
+		{
+			description: "Confirmed request AddListElement frame",
+			structured: request(services.ServiceConfirmedAddListElement, 1, services.ConfirmedListElementObjects(
+				objects.ObjectTypeCalendar, 1, objects.PropertyIdDateList, objects.ArrayAll, date)),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x18, // BVLC
+				0x01, 0x04, // NPDU
+				0x00, 0x05, 0x01, 0x08, // APDU
+				0x0c, 0x01, 0x80, 0x00, 0x01, // Object identifier
+				0x19, 0x17, // Property identifier
+				0x3e, 0xa4, 0x78, 0x0c, 0x19, 0x05, 0x3f, // List of elements
+			},
+		},
+		{
+			description: "Confirmed request RemoveListElement with array index frame",
+			structured: request(services.ServiceConfirmedRemoveListElement, 2, services.ConfirmedListElementObjects(
+				objects.ObjectTypeCalendar, 1, objects.PropertyIdDateList, 2, unsigned)),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x19, // BVLC
+				0x01, 0x04, // NPDU
+				0x00, 0x05, 0x02, 0x09, // APDU
+				0x0c, 0x01, 0x80, 0x00, 0x01, // Object identifier
+				0x19, 0x17, // Property identifier
+				0x29, 0x02, // Property array index
+				0x3e, 0x21, 0x01, 0x21, 0x02, 0x3f, // List of elements
+			},
+		},
+		{
+			description: "ChangeList-Error frame",
+			structured: func() serializeable {
+				e := services.NewError(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, false))
+				e.APDU.Service = services.ServiceConfirmedRemoveListElement
+				e.APDU.InvokeID = 10
+				e.APDU.Objects = services.ChangeListErrorObjects(objects.ErrorClassService, objects.ErrorCodeListElementNotFound, 1)
+				e.SetLength()
+				return e
+			}(),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x11, // BVLC
+				0x01, 0x00, // NPDU
+				0x50, 0x0a, 0x09, // APDU
+				0x0e, 0x91, 0x05, 0x91, 0x51, 0x0f, // Error
+				0x19, 0x01, // First failed element number
+			},
+		},
+	}
+
+	testSerialization(t, testcases)
+
+	t.Run("Decode", func(t *testing.T) {
+		want := []interface{}{
+			services.ConfirmedListElementDec{ObjectType: objects.ObjectTypeCalendar, InstanceId: 1,
+				PropertyId: objects.PropertyIdDateList, ArrayIndex: objects.ArrayAll, Elements: date},
+			services.ConfirmedListElementDec{ObjectType: objects.ObjectTypeCalendar, InstanceId: 1,
+				PropertyId: objects.PropertyIdDateList, ArrayIndex: 2, Elements: unsigned},
+			services.ChangeListErrorDec{ErrorClass: objects.ErrorClassService, ErrorCode: objects.ErrorCodeListElementNotFound, FirstFailedElement: 1},
+		}
+		for i, c := range testcases {
+			msg, err := bacnet.Parse(c.serialized)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got interface{}
+			switch m := msg.(type) {
+			case *services.ConfirmedAddListElement:
+				got, err = m.Decode()
+			case *services.ConfirmedRemoveListElement:
+				got, err = m.Decode()
+			case *services.Error:
+				got, err = m.DecodeChangeList()
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(want[i], got); diff != "" {
+				t.Errorf("%s: mismatch (-want +got):\n%s", c.description, diff)
+			}
+		}
+	})
+}
+
+func TestReadRange(t *testing.T) {
+	request := func(invokeID uint8, objs []objects.APDUPayload) serializeable {
+		c := services.NewConfirmedReadRange(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+		c.APDU.MaxSize = 5
+		c.APDU.InvokeID = invokeID
+		c.APDU.Objects = objs
+		c.SetLength()
+		return c
+	}
+	ack := func(objs []objects.APDUPayload) serializeable {
+		c := services.NewComplexACK(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, false))
+		c.APDU.Service = services.ServiceConfirmedReadRange
+		c.APDU.InvokeID = 9
+		c.APDU.Objects = objs
+		c.SetLength()
+		return c
+	}
+	byPosition := services.Range{Type: services.RangeByPosition, Reference: 1, Count: 10}
+	bySequence := services.Range{Type: services.RangeBySequenceNumber, Reference: 100, Count: -5}
+	byTime := services.Range{Type: services.RangeByTime, Count: 2, Time: objects.DateTime{
+		Date: objects.Date{Year: 120, Month: 1, Day: 2, Weekday: 4},
+		Time: objects.Time{Hour: 3, Minute: 4, Second: 5, Hundredths: 6},
+	}}
+	items := services.RangeResult{
+		LastItem:            true,
+		ItemCount:           2,
+		ItemData:            []objects.APDUPayload{objects.EncUnsignedInteger(1), objects.EncUnsignedInteger(2)},
+		FirstSequenceNumber: 100,
+	}
+
+	var testcases = []testCase{
+		{
+			description: "Confirmed request ReadRange by position frame",
+			structured:  request(1, services.ConfirmedReadRangeObjects(objects.ObjectTypeTrendLog, 1, objects.PropertyIdLogBuffer, objects.ArrayAll, byPosition)),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x17, // BVLC
+				0x01, 0x04, // NPDU
+				0x00, 0x05, 0x01, 0x1a, // APDU
+				0x0c, 0x05, 0x00, 0x00, 0x01, // Object identifier
+				0x19, 0x83, // Property identifier
+				0x3e, 0x21, 0x01, 0x31, 0x0a, 0x3f, // By position
+			},
+		},
+		{
+			description: "Confirmed request ReadRange by sequence number frame",
+			structured:  request(2, services.ConfirmedReadRangeObjects(objects.ObjectTypeTrendLog, 1, objects.PropertyIdLogBuffer, objects.ArrayAll, bySequence)),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x17, // BVLC
+				0x01, 0x04, // NPDU
+				0x00, 0x05, 0x02, 0x1a, // APDU
+				0x0c, 0x05, 0x00, 0x00, 0x01, // Object identifier
+				0x19, 0x83, // Property identifier
+				0x6e, 0x21, 0x64, 0x31, 0xfb, 0x6f, // By sequence number
+			},
+		},
+		{
+			description: "Confirmed request ReadRange by time frame",
+			structured:  request(3, services.ConfirmedReadRangeObjects(objects.ObjectTypeTrendLog, 1, objects.PropertyIdLogBuffer, objects.ArrayAll, byTime)),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x1f, // BVLC
+				0x01, 0x04, // NPDU
+				0x00, 0x05, 0x03, 0x1a, // APDU
+				0x0c, 0x05, 0x00, 0x00, 0x01, // Object identifier
+				0x19, 0x83, // Property identifier
+				0x7e, 0xa4, 0x78, 0x01, 0x02, 0x04, 0xb4, 0x03, 0x04, 0x05, 0x06, 0x31, 0x02, 0x7f, // By time
+			},
+		},
+		{
+			description: "Confirmed request ReadRange from the first record frame",
+			structured:  request(5, services.ConfirmedReadRangeObjects(objects.ObjectTypeTrendLog, 1, objects.PropertyIdLogBuffer, objects.ArrayAll, services.RangeFromFirst(math.MaxInt16))),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x20, // BVLC
+				0x01, 0x04, // NPDU
+				0x00, 0x05, 0x05, 0x1a, // APDU
+				0x0c, 0x05, 0x00, 0x00, 0x01, // Object identifier
+				0x19, 0x83, // Property identifier
+				0x7e, 0xa4, 0x00, 0x01, 0x01, 0xff, 0xb4, 0x00, 0x00, 0x00, 0x00, 0x32, 0x7f, 0xff, 0x7f, // By time
+			},
+		},
+		{
+			description: "Confirmed request ReadRange of every item frame",
+			structured:  request(4, services.ConfirmedReadRangeObjects(objects.ObjectTypeCalendar, 1, objects.PropertyIdDateList, objects.ArrayAll, services.Range{})),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x11, // BVLC
+				0x01, 0x04, // NPDU
+				0x00, 0x05, 0x04, 0x1a, // APDU
+				0x0c, 0x01, 0x80, 0x00, 0x01, // Object identifier
+				0x19, 0x17, // Property identifier
+			},
+		},
+		{
+			description: "Complex ACK ReadRange frame",
+			structured:  ack(services.ReadRangeACKObjects(objects.ObjectTypeTrendLog, 1, objects.PropertyIdLogBuffer, objects.ArrayAll, items)),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x1d, // BVLC
+				0x01, 0x00, // NPDU
+				0x30, 0x09, 0x1a, // APDU
+				0x0c, 0x05, 0x00, 0x00, 0x01, // Object identifier
+				0x19, 0x83, // Property identifier
+				0x3a, 0x05, 0x40, // Result flags
+				0x49, 0x02, // Item count
+				0x5e, 0x21, 0x01, 0x21, 0x02, 0x5f, // Item data
+				0x69, 0x64, // First sequence number
+			},
+		},
+		{
+			description: "Complex ACK ReadRange without items frame",
+			structured: ack(services.ReadRangeACKObjects(objects.ObjectTypeTrendLog, 1, objects.PropertyIdLogBuffer, objects.ArrayAll,
+				services.RangeResult{ItemData: []objects.APDUPayload{}})),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x17, // BVLC
+				0x01, 0x00, // NPDU
+				0x30, 0x09, 0x1a, // APDU
+				0x0c, 0x05, 0x00, 0x00, 0x01, // Object identifier
+				0x19, 0x83, // Property identifier
+				0x3a, 0x05, 0x00, // Result flags
+				0x49, 0x00, // Item count
+				0x5e, 0x5f, // Item data
+			},
+		},
+	}
+
+	testSerialization(t, testcases)
+
+	t.Run("Decode", func(t *testing.T) {
+		request := func(objectType uint16, propertyId uint32, rng services.Range) services.ConfirmedReadRangeDec {
+			return services.ConfirmedReadRangeDec{ObjectType: objectType, InstanceId: 1, PropertyId: propertyId, ArrayIndex: objects.ArrayAll, Range: rng}
+		}
+		want := []interface{}{
+			request(objects.ObjectTypeTrendLog, objects.PropertyIdLogBuffer, byPosition),
+			request(objects.ObjectTypeTrendLog, objects.PropertyIdLogBuffer, bySequence),
+			request(objects.ObjectTypeTrendLog, objects.PropertyIdLogBuffer, byTime),
+			request(objects.ObjectTypeTrendLog, objects.PropertyIdLogBuffer, services.RangeFromFirst(math.MaxInt16)),
+			request(objects.ObjectTypeCalendar, objects.PropertyIdDateList, services.Range{}),
+			services.ReadRangeACKDec{ObjectType: objects.ObjectTypeTrendLog, InstanceId: 1, PropertyId: objects.PropertyIdLogBuffer,
+				ArrayIndex: objects.ArrayAll, RangeResult: items},
+			services.ReadRangeACKDec{ObjectType: objects.ObjectTypeTrendLog, InstanceId: 1, PropertyId: objects.PropertyIdLogBuffer,
+				ArrayIndex: objects.ArrayAll, RangeResult: services.RangeResult{ItemData: []objects.APDUPayload{}}},
+		}
+		for i, c := range testcases {
+			msg, err := bacnet.Parse(c.serialized)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got interface{}
+			switch m := msg.(type) {
+			case *services.ConfirmedReadRange:
+				got, err = m.Decode()
+			case *services.ComplexACK:
+				got, err = m.DecodeReadRange()
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(want[i], got); diff != "" {
+				t.Errorf("%s: mismatch (-want +got):\n%s", c.description, diff)
+			}
+		}
+	})
+}
+
+func TestLogRecord(t *testing.T) {
+	timestamp := objects.DateTime{
+		Date: objects.Date{Year: 120, Month: 1, Day: 2, Weekday: 4},
+		Time: objects.Time{Hour: 3, Minute: 4, Second: 5, Hundredths: 6},
+	}
+	records := []services.LogRecord{
+		{Timestamp: timestamp, LogStatus: objects.BitString{false, true, false}},
+		{Timestamp: timestamp, Value: float32(21.5), StatusFlags: objects.BitString{false, false, false, false}},
+		{Timestamp: timestamp, Failure: &common.BACnetError{Class: objects.ErrorClassProperty, Code: objects.ErrorCodeUnknownProperty}},
+	}
+
+	result := services.RangeResult{FirstItem: true, LastItem: true, ItemCount: 3, ItemData: []objects.APDUPayload{}, FirstSequenceNumber: 7}
+	for _, r := range records {
+		objs, err := services.EncLogRecord(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result.ItemData = append(result.ItemData, objs...)
+	}
+
+	c := services.NewComplexACK(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, false))
+	c.APDU.Service = services.ServiceConfirmedReadRange
+	c.APDU.InvokeID = 9
+	c.APDU.Objects = services.ReadRangeACKObjects(objects.ObjectTypeTrendLog, 1, objects.PropertyIdLogBuffer, objects.ArrayAll, result)
+	c.SetLength()
+
+	var testcases = []testCase{
+		{
+			description: "Complex ACK ReadRange of log records frame",
+			structured:  c,
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x54, // BVLC
+				0x01, 0x00, // NPDU
+				0x30, 0x09, 0x1a, // APDU
+				0x0c, 0x05, 0x00, 0x00, 0x01, // Object identifier
+				0x19, 0x83, // Property identifier
+				0x3a, 0x05, 0xc0, // Result flags
+				0x49, 0x03, // Item count
+				// Item data
+				0x5e,
+				0x0e, 0xa4, 0x78, 0x01, 0x02, 0x04, 0xb4, 0x03, 0x04, 0x05, 0x06, 0x0f, // Timestamp
+				0x1e, 0x0a, 0x05, 0x40, 0x1f, // Log status
+				0x0e, 0xa4, 0x78, 0x01, 0x02, 0x04, 0xb4, 0x03, 0x04, 0x05, 0x06, 0x0f, // Timestamp
+				0x1e, 0x2c, 0x41, 0xac, 0x00, 0x00, 0x1f, // Real
+				0x2a, 0x04, 0x00, // Status flags
+				0x0e, 0xa4, 0x78, 0x01, 0x02, 0x04, 0xb4, 0x03, 0x04, 0x05, 0x06, 0x0f, // Timestamp
+				0x1e, 0x8e, 0x91, 0x02, 0x91, 0x20, 0x8f, 0x1f, // Failure
+				0x5f,
+				0x69, 0x07, // First sequence number
+			},
+		},
+	}
+
+	testSerialization(t, testcases)
+
+	t.Run("Decode", func(t *testing.T) {
+		msg, err := bacnet.Parse(testcases[0].serialized)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dec, err := msg.(*services.ComplexACK).DecodeReadRange()
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := services.DecLogRecords(dec.ItemData)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(records, got); diff != "" {
+			t.Errorf("mismatch (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestEventNotification(t *testing.T) {
+	dec := services.EventNotificationDec{
+		ProcessId: 1,
+		DeviceId:  321,
+		Object:    objects.ObjectIdentifier{ObjectType: objects.ObjectTypeAnalogInput, InstanceNumber: 10},
+		TimeStamp: services.TimeStamp{
+			Choice: services.TimeStampDateTime,
+			DateTime: objects.DateTime{
+				Date: objects.Date{Year: 120, Month: 1, Day: 2, Weekday: 4},
+				Time: objects.Time{Hour: 3, Minute: 4, Second: 5, Hundredths: 6},
+			},
+		},
+		NotificationClass: 5,
+		Priority:          100,
+		EventType:         objects.EventTypeOutOfRange,
+		NotifyType:        objects.NotifyTypeAlarm,
+		AckRequired:       true,
+		FromState:         objects.EventStateNormal,
+		ToState:           objects.EventStateHighLimit,
+		Parameters: services.OutOfRangeParameters{
+			ExceedingValue: 85,
+			StatusFlags:    objects.BitString{true, false, false, false},
+			Deadband:       1,
+			ExceededLimit:  80,
+		},
+	}
+	notification := []byte{
+		0x09, 0x01, // Process ID
+		0x1c, 0x02, 0x00, 0x01, 0x41, // Initiating device
+		0x2c, 0x00, 0x00, 0x00, 0x0a, // Event object
+		0x3e, 0x2e, 0xa4, 0x78, 0x01, 0x02, 0x04, 0xb4, 0x03, 0x04, 0x05, 0x06, 0x2f, 0x3f, // Time stamp
+		0x49, 0x05, // Notification class
+		0x59, 0x64, // Priority
+		0x69, 0x05, // Event type
+		0x89, 0x00, // Notify type
+		0x99, 0x01, // Ack required
+		0xa9, 0x00, // From state
+		0xb9, 0x03, // To state
+		0xce, 0x5e, // Event values
+		0x0c, 0x42, 0xaa, 0x00, 0x00, // Exceeding value
+		0x1a, 0x04, 0x80, // Status flags
+		0x2c, 0x3f, 0x80, 0x00, 0x00, // Deadband
+		0x3c, 0x42, 0xa0, 0x00, 0x00, // Exceeded limit
+		0x5f, 0xcf,
+	}
+
+	var testcases = []testCase{
+		{
+			description: "Confirmed request EventNotification frame",
+			structured: func() serializeable {
+				c := services.NewConfirmedEventNotification(
+					plumbing.NewBVLC(plumbing.BVLCFuncUnicast),
+					plumbing.NewNPDU(false, false, false, true),
+				)
+				c.APDU.MaxSize = 5
+				c.APDU.InvokeID = 3
+				c.APDU.Objects = services.EventNotificationObjects(dec)
+				c.SetLength()
+				return c
+			}(),
+			serialized: append([]byte{
+				0x81, 0x0a, 0x00, 0x48, // BVLC
+				0x01, 0x04, // NPDU
+				0x00, 0x05, 0x03, 0x02, // APDU
+			}, notification...),
+		},
+		{
+			description: "Unconfirmed request EventNotification frame",
+			structured: func() serializeable {
+				u := services.NewUnconfirmedEventNotification(
+					plumbing.NewBVLC(plumbing.BVLCFuncUnicast),
+					plumbing.NewNPDU(false, false, false, false),
+				)
+				u.APDU.Objects = services.EventNotificationObjects(dec)
+				u.SetLength()
+				return u
+			}(),
+			serialized: append([]byte{
+				0x81, 0x0a, 0x00, 0x46, // BVLC
+				0x01, 0x00, // NPDU
+				0x10, 0x03, // APDU
+			}, notification...),
+		},
+	}
+
+	testSerialization(t, testcases)
+
+	t.Run("Decode values", func(t *testing.T) {
+		msg, err := bacnet.Parse(testcases[1].serialized)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := msg.(*services.UnconfirmedEventNotification).Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(dec, got); diff != "" {
+			t.Errorf("differs: (-want +got)\n%s", diff)
+		}
+	})
+
+	t.Run("Round trip", func(t *testing.T) {
+		for _, want := range []services.EventNotificationDec{
+			{
+				DeviceId:    321,
+				Object:      objects.ObjectIdentifier{ObjectType: objects.ObjectTypeBinaryInput, InstanceNumber: 2},
+				TimeStamp:   services.TimeStamp{Choice: services.TimeStampSequence, Sequence: 42},
+				EventType:   objects.EventTypeChangeOfState,
+				MessageText: "Door open",
+				NotifyType:  objects.NotifyTypeEvent,
+				ToState:     objects.EventStateOffnormal,
+				Parameters: services.ChangeOfStateParameters{
+					NewState:    services.PropertyState{Choice: services.PropertyStateBinaryValue, Value: uint32(objects.BinaryActive)},
+					StatusFlags: objects.BitString{true, false, false, false},
+				},
+			},
+			{
+				DeviceId:   321,
+				Object:     objects.ObjectIdentifier{ObjectType: objects.ObjectTypeMultiStateInput, InstanceNumber: 3},
+				TimeStamp:  services.TimeStamp{Choice: services.TimeStampTime, Time: objects.Time{Hour: 12}},
+				EventType:  objects.EventTypeChangeOfState,
+				NotifyType: objects.NotifyTypeAckNotification,
+				ToState:    objects.EventStateOffnormal,
+			},
+		} {
+			u := services.NewUnconfirmedEventNotification(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, false))
+			u.APDU.Objects = services.EventNotificationObjects(want)
+			u.SetLength()
+			b, err := u.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			msg, err := bacnet.Parse(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := msg.(*services.UnconfirmedEventNotification).Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("differs: (-want +got)\n%s", diff)
+			}
+		}
+	})
+
+	t.Run("Notification parameters", func(t *testing.T) {
+		flags := objects.BitString{false, true, false, false}
+		dateTime := objects.DateTime{
+			Date: objects.Date{Year: 120, Month: 1, Day: 2, Weekday: 4},
+			Time: objects.Time{Hour: 3, Minute: 4, Second: 5, Hundredths: 6},
+		}
+		lastStateChange, initialTimeout := uint32(1), uint32(60)
+		values := []services.PropertyValue{
+			{PropertyId: objects.PropertyIdPresentValue, ArrayIndex: objects.ArrayAll, Values: []objects.APDUPayload{objects.EncReal(1)}},
+		}
+
+		for eventType, parameters := range map[uint32]services.NotificationParameters{
+			objects.EventTypeChangeOfBitstring: services.ChangeOfBitstringParameters{ReferencedBitstring: objects.BitString{true, false}, StatusFlags: flags},
+			objects.EventTypeChangeOfValue:     services.ChangeOfValueParameters{ChangedValue: 2.5, StatusFlags: flags},
+			objects.EventTypeCommandFailure: services.CommandFailureParameters{
+				CommandValue:  []objects.APDUPayload{objects.EncEnumerated(1)},
+				StatusFlags:   flags,
+				FeedbackValue: []objects.APDUPayload{objects.EncEnumerated(0)},
+			},
+			objects.EventTypeFloatingLimit:      services.FloatingLimitParameters{ReferenceValue: 30, StatusFlags: flags, SetpointValue: 20, ErrorLimit: 5},
+			128:                                 services.ComplexEventParameters{Values: values},
+			objects.EventTypeChangeOfLifeSafety: services.ChangeOfLifeSafetyParameters{NewState: 2, NewMode: 1, StatusFlags: flags, OperationExpected: 3},
+			objects.EventTypeExtended: services.ExtendedParameters{
+				VendorId:          260,
+				ExtendedEventType: 7,
+				Parameters:        []objects.APDUPayload{objects.EncUnsignedInteger(1), objects.EncCharacterString("x")},
+			},
+			objects.EventTypeBufferReady: services.BufferReadyParameters{
+				BufferProperty: services.DeviceObjectPropertyReference{
+					Object:     objects.ObjectIdentifier{ObjectType: objects.ObjectTypeTrendLog, InstanceNumber: 1},
+					PropertyId: objects.PropertyIdLogBuffer,
+					ArrayIndex: objects.ArrayAll,
+					DeviceId:   objects.MaxInstance,
+				},
+				PreviousNotification: 10,
+				CurrentNotification:  20,
+			},
+			objects.EventTypeUnsignedRange: services.UnsignedRangeParameters{ExceedingValue: 11, StatusFlags: flags, ExceededLimit: 10},
+			objects.EventTypeAccessEvent: services.AccessEventParameters{
+				AccessEvent:      1,
+				StatusFlags:      flags,
+				AccessEventTag:   4,
+				AccessEventTime:  services.TimeStamp{Choice: services.TimeStampSequence, Sequence: 9},
+				AccessCredential: services.DeviceObjectReference{DeviceId: 7, Object: objects.ObjectIdentifier{ObjectType: 32, InstanceNumber: 1}},
+			},
+			objects.EventTypeDoubleOutOfRange:        services.DoubleOutOfRangeParameters{ExceedingValue: 101, StatusFlags: flags, Deadband: 0.5, ExceededLimit: 100},
+			objects.EventTypeSignedOutOfRange:        services.SignedOutOfRangeParameters{ExceedingValue: -11, StatusFlags: flags, Deadband: 1, ExceededLimit: -10},
+			objects.EventTypeUnsignedOutOfRange:      services.UnsignedOutOfRangeParameters{ExceedingValue: 11, StatusFlags: flags, Deadband: 1, ExceededLimit: 10},
+			objects.EventTypeChangeOfCharacterstring: services.ChangeOfCharacterstringParameters{ChangedValue: "FAIL", StatusFlags: flags, AlarmValue: "FAIL"},
+			objects.EventTypeChangeOfStatusFlags: services.ChangeOfStatusFlagsParameters{
+				PresentValue:    []objects.APDUPayload{objects.EncReal(3)},
+				ReferencedFlags: flags,
+			},
+			objects.EventTypeChangeOfReliability: services.ChangeOfReliabilityParameters{Reliability: 2, StatusFlags: flags, PropertyValues: values},
+			objects.EventTypeChangeOfDiscreteValue: services.ChangeOfDiscreteValueParameters{
+				NewValue:    objects.EncEnclosed(0, objects.EncDateTime(dateTime)...),
+				StatusFlags: flags,
+			},
+			objects.EventTypeChangeOfTimer: services.ChangeOfTimerParameters{
+				NewState:        1,
+				StatusFlags:     flags,
+				UpdateTime:      dateTime,
+				LastStateChange: &lastStateChange,
+				InitialTimeout:  &initialTimeout,
+				ExpirationTime:  &dateTime,
+			},
+		} {
+			want := services.EventNotificationDec{
+				DeviceId:   321,
+				Object:     objects.ObjectIdentifier{ObjectType: objects.ObjectTypeAnalogValue, InstanceNumber: 1},
+				TimeStamp:  services.TimeStamp{Choice: services.TimeStampSequence, Sequence: 1},
+				EventType:  eventType,
+				NotifyType: objects.NotifyTypeEvent,
+				ToState:    objects.EventStateOffnormal,
+				Parameters: parameters,
+			}
+
+			c := services.NewConfirmedEventNotification(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+			c.APDU.Objects = services.EventNotificationObjects(want)
+			c.SetLength()
+			b, err := c.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			msg, err := bacnet.Parse(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := msg.(*services.ConfirmedEventNotification).Decode()
+			if err != nil {
+				t.Fatalf("event type %d: %v", eventType, err)
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("event type %d differs: (-want +got)\n%s", eventType, diff)
+			}
+		}
+	})
+	t.Run("Extended tag numbers", func(t *testing.T) {
+		u := services.NewUnconfirmedEventNotification(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, false))
+		u.APDU.Objects = services.EventNotificationObjects(services.EventNotificationDec{
+			EventType:  objects.EventTypeChangeOfStatusFlags,
+			NotifyType: objects.NotifyTypeEvent,
+			Parameters: services.ChangeOfStatusFlagsParameters{ReferencedFlags: objects.BitString{false, true, false, false}},
+		})
+		u.SetLength()
+		b, err := u.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want := []byte{
+			0xce, 0xfe, 0x12, // Event values
+			0x1a, 0x04, 0x40, // Referenced flags
+			0xff, 0x12, 0xcf,
+		}
+		if diff := cmp.Diff(want, b[len(b)-len(want):]); diff != "" {
+			t.Errorf("differs: (-want +got)\n%s", diff)
+		}
+	})
+}
+
+func TestAlarmServices(t *testing.T) {
+	ack := services.ConfirmedAcknowledgeAlarmDec{
+		ProcessId:         1,
+		Object:            objects.ObjectIdentifier{ObjectType: objects.ObjectTypeAnalogInput, InstanceNumber: 10},
+		EventState:        objects.EventStateHighLimit,
+		TimeStamp:         services.TimeStamp{Choice: services.TimeStampSequence, Sequence: 5},
+		Source:            "op",
+		TimeOfAcknowledge: services.TimeStamp{Choice: services.TimeStampTime, Time: objects.Time{Hour: 12, Minute: 30}},
+	}
+
+	var testcases = []testCase{
+		{
+			description: "Confirmed request AcknowledgeAlarm frame",
+			structured: func() serializeable {
+				c := services.NewConfirmedAcknowledgeAlarm(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+				c.APDU.MaxSize = 5
+				c.APDU.InvokeID = 1
+				c.APDU.Objects = services.ConfirmedAcknowledgeAlarmObjects(ack.ProcessId, ack.Object.ObjectType, ack.Object.InstanceNumber,
+					ack.EventState, ack.TimeStamp, ack.Source, ack.TimeOfAcknowledge)
+				c.SetLength()
+				return c
+			}(),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x22, // BVLC
+				0x01, 0x04, // NPDU
+				0x00, 0x05, 0x01, 0x00, // APDU
+				0x09, 0x01, // Process ID
+				0x1c, 0x00, 0x00, 0x00, 0x0a, // Event object
+				0x29, 0x03, // Event state acknowledged
+				0x3e, 0x19, 0x05, 0x3f, // Time stamp
+				0x4b, 0x00, 0x6f, 0x70, // Acknowledgment source
+				0x5e, 0x0c, 0x0c, 0x1e, 0x00, 0x00, 0x5f, // Time of acknowledgment
+			},
+		},
+		{
+			description: "Confirmed request GetAlarmSummary frame",
+			structured: func() serializeable {
+				c := services.NewConfirmedGetAlarmSummary(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+				c.APDU.MaxSize = 5
+				c.APDU.InvokeID = 2
+				c.SetLength()
+				return c
+			}(),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x0a, // BVLC
+				0x01, 0x04, // NPDU
+				0x00, 0x05, 0x02, 0x03, // APDU
+			},
+		},
+		{
+			description: "Complex ACK GetAlarmSummary frame",
+			structured: func() serializeable {
+				c := services.NewComplexACK(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, false))
+				c.APDU.Service = services.ServiceConfirmedGetAlarmSummary
+				c.APDU.InvokeID = 2
+				c.APDU.Objects = services.GetAlarmSummaryACKObjects([]services.AlarmSummary{{
+					Object:           objects.ObjectIdentifier{ObjectType: objects.ObjectTypeAnalogInput, InstanceNumber: 10},
+					AlarmState:       objects.EventStateHighLimit,
+					AckedTransitions: objects.BitString{false, true, true},
+				}})
+				c.SetLength()
+				return c
+			}(),
+			serialized: []byte{
+				0x81, 0x0a, 0x00, 0x13, // BVLC
+				0x01, 0x00, // NPDU
+				0x30, 0x02, 0x03, // APDU
+				0xc4, 0x00, 0x00, 0x00, 0x0a, // Object identifier
+				0x91, 0x03, // Alarm state
+				0x82, 0x05, 0x60, // Acknowledged transitions
+			},
+		},
+	}
+
+	testSerialization(t, testcases)
+
+	t.Run("Decode AcknowledgeAlarm", func(t *testing.T) {
+		msg, err := bacnet.Parse(testcases[0].serialized)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := msg.(*services.ConfirmedAcknowledgeAlarm).Decode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(ack, got); diff != "" {
+			t.Errorf("differs: (-want +got)\n%s", diff)
+		}
+	})
+
+	complexACK := func(t *testing.T, service uint8, objs []objects.APDUPayload) *services.ComplexACK {
+		t.Helper()
+		c := services.NewComplexACK(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, false))
+		c.APDU.Service = service
+		c.APDU.Objects = objs
+		c.SetLength()
+		b, err := c.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg, err := bacnet.Parse(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return msg.(*services.ComplexACK)
+	}
+	request := func(t *testing.T, c interface {
+		serializeable
+		SetLength()
+	}) plumbing.BACnet {
+		t.Helper()
+		c.SetLength()
+		b, err := c.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		msg, err := bacnet.Parse(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return msg
+	}
+
+	t.Run("GetAlarmSummary round trip", func(t *testing.T) {
+		got, err := complexACK(t, services.ServiceConfirmedGetAlarmSummary, services.GetAlarmSummaryACKObjects(nil)).DecodeGetAlarmSummary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 0 {
+			t.Errorf("got %v, want no summaries", got)
+		}
+	})
+
+	t.Run("GetEventInformation round trip", func(t *testing.T) {
+		last := objects.ObjectIdentifier{ObjectType: objects.ObjectTypeBinaryValue, InstanceNumber: 4}
+		for _, want := range []services.ConfirmedGetEventInformationDec{{}, {LastReceivedObject: &last}} {
+			c := services.NewConfirmedGetEventInformation(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+			c.APDU.Objects = services.ConfirmedGetEventInformationObjects(want.LastReceivedObject)
+			got, err := request(t, c).(*services.ConfirmedGetEventInformation).Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("differs: (-want +got)\n%s", diff)
+			}
+		}
+
+		stamp := services.TimeStamp{Choice: services.TimeStampDateTime, DateTime: objects.DateTime{
+			Date: objects.Date{Year: 120, Month: 1, Day: 2, Weekday: 4},
+			Time: objects.Time{Hour: 3, Minute: 4, Second: 5, Hundredths: 6},
+		}}
+		want := services.GetEventInformationACKDec{
+			Summaries: []services.EventSummary{
+				{
+					Object:           objects.ObjectIdentifier{ObjectType: objects.ObjectTypeAnalogInput, InstanceNumber: 10},
+					EventState:       objects.EventStateHighLimit,
+					AckedTransitions: objects.BitString{false, true, true},
+					EventTimeStamps:  [3]services.TimeStamp{stamp, {Choice: services.TimeStampSequence}, {Choice: services.TimeStampTime}},
+					NotifyType:       objects.NotifyTypeAlarm,
+					EventEnable:      objects.BitString{true, true, true},
+					EventPriorities:  [3]uint8{10, 20, 200},
+				},
+				{
+					Object:           objects.ObjectIdentifier{ObjectType: objects.ObjectTypeBinaryValue, InstanceNumber: 4},
+					EventState:       objects.EventStateNormal,
+					AckedTransitions: objects.BitString{true, true, false},
+					EventTimeStamps:  [3]services.TimeStamp{stamp, stamp, stamp},
+					NotifyType:       objects.NotifyTypeEvent,
+					EventEnable:      objects.BitString{true, false, true},
+					EventPriorities:  [3]uint8{255, 255, 255},
+				},
+			},
+			MoreEvents: true,
+		}
+		got, err := complexACK(t, services.ServiceConfirmedGetEventInformation,
+			services.GetEventInformationACKObjects(want.Summaries, want.MoreEvents)).DecodeGetEventInformation()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("differs: (-want +got)\n%s", diff)
+		}
+	})
+
+	t.Run("GetEnrollmentSummary round trip", func(t *testing.T) {
+		state, eventType, class := services.EnrollmentStateActive, objects.EventTypeOutOfRange, uint32(5)
+		for _, want := range []services.ConfirmedGetEnrollmentSummaryDec{
+			{EnrollmentFilter: services.EnrollmentFilter{Acknowledgment: services.EnrollmentNotAcked}},
+			{EnrollmentFilter: services.EnrollmentFilter{
+				Acknowledgment:    services.EnrollmentAckAll,
+				Recipient:         &services.Recipient{Address: &services.Address{Net: 5, MAC: []byte{0x0a}}},
+				ProcessId:         7,
+				EventState:        &state,
+				EventType:         &eventType,
+				Priority:          &services.PriorityRange{Min: 10, Max: 100},
+				NotificationClass: &class,
+			}},
+		} {
+			c := services.NewConfirmedGetEnrollmentSummary(plumbing.NewBVLC(plumbing.BVLCFuncUnicast), plumbing.NewNPDU(false, false, false, true))
+			c.APDU.Objects = services.ConfirmedGetEnrollmentSummaryObjects(want.EnrollmentFilter)
+			got, err := request(t, c).(*services.ConfirmedGetEnrollmentSummary).Decode()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Errorf("differs: (-want +got)\n%s", diff)
+			}
+		}
+
+		want := []services.EnrollmentSummary{
+			{
+				Object:            objects.ObjectIdentifier{ObjectType: objects.ObjectTypeAnalogInput, InstanceNumber: 10},
+				EventType:         objects.EventTypeOutOfRange,
+				EventState:        objects.EventStateHighLimit,
+				Priority:          10,
+				NotificationClass: &class,
+			},
+			{
+				Object:     objects.ObjectIdentifier{ObjectType: objects.ObjectTypeBinaryValue, InstanceNumber: 4},
+				EventType:  objects.EventTypeChangeOfState,
+				EventState: objects.EventStateNormal,
+				Priority:   200,
+			},
+		}
+		got, err := complexACK(t, services.ServiceConfirmedGetEnrollmentSummary,
+			services.GetEnrollmentSummaryACKObjects(want)).DecodeGetEnrollmentSummary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Errorf("differs: (-want +got)\n%s", diff)
+		}
+	})
+}
+
+func TestParseTruncated(t *testing.T) {
+	for _, tc := range []struct {
+		description string
+		serialized  []byte
+	}{
+		{"NPDU ending the frame", []byte{0x81, 0x0a, 0x00, 0x0a, 0x01, 0x20, 0x00, 0x01, 0x00, 0xff}},
+		{"routed NPDU from a source without APDU", []byte{
+			0x81, 0x0a, 0x00, 0x11, 0x01, 0x09, 0x04, 0x00, 0x05, 0x6b, 0x0c, 0x0c, 0x00, 0x00}},
+		{"routed NPDU to a destination without APDU", []byte{
+			0x81, 0x0b, 0xbd, 0x11, 0x01, 0x20, 0xff, 0xff, 0x00, 0xff}},
+		{"truncated NPDU", []byte{0x81, 0x0b, 0x00, 0x08, 0x01, 0x20, 0xff, 0xff}},
+		{"truncated confirmed request header", []byte{0x81, 0x0a, 0x00, 0x09, 0x01, 0x04, 0x00, 0x05, 0x01}},
+		{"truncated unconfirmed request header", []byte{0x81, 0x0b, 0x00, 0x08, 0x01, 0x00, 0x10, 0x00}[:7]},
+	} {
+		t.Run(tc.description, func(t *testing.T) {
+			if _, err := bacnet.Parse(tc.serialized); err == nil {
+				t.Error("truncated frame parsed")
+			}
 		})
 	}
 }
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+)
+
+// ConfirmedSubscribeCOV is a BACnet message. It's acknowledged with a SimpleACK.
+type ConfirmedSubscribeCOV struct {
+	*plumbing.BVLC
+	*plumbing.NPDU
+	*plumbing.APDU
+}
+
+type ConfirmedSubscribeCOVDec struct {
+	ProcessId  uint32
+	ObjectType uint16
+	InstanceId uint32
+	// Cancel is set when both the confirmed flag and the lifetime were left out.
+	Cancel    bool
+	Confirmed bool
+	// Lifetime is in seconds, with 0 meaning an indefinite subscription.
+	Lifetime uint32
+}
+
+// ConfirmedSubscribeCOVObjects creates the SubscribeCOV request objects subscribing to the
+// given object for lifetime seconds.
+func ConfirmedSubscribeCOVObjects(processId uint32, objectType uint16, instN uint32, confirmed bool, lifetime uint32) []objects.APDUPayload {
+	objs := make([]objects.APDUPayload, 4)
+
+	objs[0] = objects.EncContext(0, objects.EncUnsignedInteger(processId))
+	objs[1] = objects.EncObjectIdentifier(true, 1, objectType, instN)
+	objs[2] = objects.EncContext(2, objects.EncBoolean(confirmed))
+	objs[3] = objects.EncContext(3, objects.EncUnsignedInteger(lifetime))
+
+	return objs
+}
+
+// ConfirmedSubscribeCOVCancelObjects creates the SubscribeCOV request objects cancelling a
+// subscription to the given object.
+func ConfirmedSubscribeCOVCancelObjects(processId uint32, objectType uint16, instN uint32) []objects.APDUPayload {
+	objs := make([]objects.APDUPayload, 2)
+
+	objs[0] = objects.EncContext(0, objects.EncUnsignedInteger(processId))
+	objs[1] = objects.EncObjectIdentifier(true, 1, objectType, instN)
+
+	return objs
+}
+
+func NewConfirmedSubscribeCOV(bvlc *plumbing.BVLC, npdu *plumbing.NPDU) *ConfirmedSubscribeCOV {
+	c := &ConfirmedSubscribeCOV{
+		BVLC: bvlc,
+		NPDU: npdu,
+		APDU: plumbing.NewAPDU(plumbing.ConfirmedReq, ServiceConfirmedSubscribeCOV, nil),
+	}
+	c.SetLength()
+
+	return c
+}
+
+func (c *ConfirmedSubscribeCOV) UnmarshalBinary(b []byte) error {
+	if l := len(b); l < c.MarshalLen() {
+		return common.ErrTooShortToParse
+	}
+
+	var offset int = 0
+	if err := c.BVLC.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+
+	return nil
+}
+
+func (c *ConfirmedSubscribeCOV) MarshalBinary() ([]byte, error) {
+	b := make([]byte, c.MarshalLen())
+	if err := c.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (c *ConfirmedSubscribeCOV) MarshalTo(b []byte) error {
+	if len(b) < c.MarshalLen() {
+		return common.ErrTooShortToMarshalBinary
+	}
+	var offset = 0
+	if err := c.BVLC.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *ConfirmedSubscribeCOV) MarshalLen() int {
+	l := c.BVLC.MarshalLen()
+	l += c.NPDU.MarshalLen()
+	l += c.APDU.MarshalLen()
+
+	return l
+}
+
+func (c *ConfirmedSubscribeCOV) SetLength() {
+	c.BVLC.Length = uint16(c.MarshalLen())
+}
+
+func (c *ConfirmedSubscribeCOV) Decode() (ConfirmedSubscribeCOVDec, error) {
+	decSCOV := ConfirmedSubscribeCOVDec{}
+
+	if len(c.APDU.Objects) < 2 || len(c.APDU.Objects) > 4 {
+		return decSCOV, common.ErrWrongObjectCount
+	}
+
+	if !objects.IsContextTag(c.APDU.Objects[0], 0) || !objects.IsContextTag(c.APDU.Objects[1], 1) {
+		return decSCOV, common.ErrWrongStructure
+	}
+
+	processId, err := objects.DecUnisgnedInteger(c.APDU.Objects[0])
+	if err != nil {
+		return decSCOV, err
+	}
+	decSCOV.ProcessId = processId
+
+	objId, err := objects.DecObjectIdentifier(c.APDU.Objects[1])
+	if err != nil {
+		return decSCOV, err
+	}
+	decSCOV.ObjectType = objId.ObjectType
+	decSCOV.InstanceId = objId.InstanceNumber
+
+	decSCOV.Cancel = len(c.APDU.Objects) == 2
+
+	offset := 2
+	if offset < len(c.APDU.Objects) && objects.IsContextTag(c.APDU.Objects[offset], 2) {
+		if decSCOV.Confirmed, err = objects.DecBoolean(c.APDU.Objects[offset]); err != nil {
+			return decSCOV, err
+		}
+		offset++
+	}
+	if offset < len(c.APDU.Objects) && objects.IsContextTag(c.APDU.Objects[offset], 3) {
+		if decSCOV.Lifetime, err = objects.DecUnisgnedInteger(c.APDU.Objects[offset]); err != nil {
+			return decSCOV, err
+		}
+		offset++
+	}
+
+	if offset != len(c.APDU.Objects) {
+		return decSCOV, common.ErrWrongStructure
+	}
+
+	return decSCOV, nil
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+)
+
+// ConfirmedSubscribeCOVProperty is a BACnet message. It's acknowledged with a SimpleACK.
+type ConfirmedSubscribeCOVProperty struct {
+	*plumbing.BVLC
+	*plumbing.NPDU
+	*plumbing.APDU
+}
+
+type ConfirmedSubscribeCOVPropertyDec struct {
+	ProcessId  uint32
+	ObjectType uint16
+	InstanceId uint32
+	// Cancel is set when both the confirmed flag and the lifetime were left out.
+	Cancel    bool
+	Confirmed bool
+	// Lifetime is in seconds, with 0 meaning an indefinite subscription.
+	Lifetime uint32
+	Property PropertyReference
+	// Increment is nil when the subscriber relies on the COV_Increment of the object.
+	Increment *float32
+}
+
+// ConfirmedSubscribeCOVPropertyObjects creates the SubscribeCOVProperty request objects
+// subscribing to the given property for lifetime seconds. A nil increment leaves the
+// COV_Increment of the object in charge.
+func ConfirmedSubscribeCOVPropertyObjects(processId uint32, objectType uint16, instN uint32, confirmed bool, lifetime uint32, property PropertyReference, increment *float32) []objects.APDUPayload {
+	objs := make([]objects.APDUPayload, 0, 9)
+
+	objs = append(objs, objects.EncContext(0, objects.EncUnsignedInteger(processId)))
+	objs = append(objs, objects.EncObjectIdentifier(true, 1, objectType, instN))
+	objs = append(objs, objects.EncContext(2, objects.EncBoolean(confirmed)))
+	objs = append(objs, objects.EncContext(3, objects.EncUnsignedInteger(lifetime)))
+	objs = append(objs, objects.EncEnclosed(4, EncPropertyReference(property)...)...)
+	if increment != nil {
+		objs = append(objs, objects.EncContext(5, objects.EncReal(*increment)))
+	}
+
+	return objs
+}
+
+// ConfirmedSubscribeCOVPropertyCancelObjects creates the SubscribeCOVProperty request objects
+// cancelling a subscription.
+func ConfirmedSubscribeCOVPropertyCancelObjects(processId uint32, objectType uint16, instN uint32, property PropertyReference) []objects.APDUPayload {
+	objs := make([]objects.APDUPayload, 0, 5)
+
+	objs = append(objs, objects.EncContext(0, objects.EncUnsignedInteger(processId)))
+	objs = append(objs, objects.EncObjectIdentifier(true, 1, objectType, instN))
+	objs = append(objs, objects.EncEnclosed(4, EncPropertyReference(property)...)...)
+
+	return objs
+}
+
+func NewConfirmedSubscribeCOVProperty(bvlc *plumbing.BVLC, npdu *plumbing.NPDU) *ConfirmedSubscribeCOVProperty {
+	c := &ConfirmedSubscribeCOVProperty{
+		BVLC: bvlc,
+		NPDU: npdu,
+		APDU: plumbing.NewAPDU(plumbing.ConfirmedReq, ServiceConfirmedSubscribeCOVProperty, nil),
+	}
+	c.SetLength()
+
+	return c
+}
+
+func (c *ConfirmedSubscribeCOVProperty) UnmarshalBinary(b []byte) error {
+	if l := len(b); l < c.MarshalLen() {
+		return common.ErrTooShortToParse
+	}
+
+	var offset int = 0
+	if err := c.BVLC.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+
+	return nil
+}
+
+func (c *ConfirmedSubscribeCOVProperty) MarshalBinary() ([]byte, error) {
+	b := make([]byte, c.MarshalLen())
+	if err := c.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (c *ConfirmedSubscribeCOVProperty) MarshalTo(b []byte) error {
+	if len(b) < c.MarshalLen() {
+		return common.ErrTooShortToMarshalBinary
+	}
+	var offset = 0
+	if err := c.BVLC.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *ConfirmedSubscribeCOVProperty) MarshalLen() int {
+	l := c.BVLC.MarshalLen()
+	l += c.NPDU.MarshalLen()
+	l += c.APDU.MarshalLen()
+
+	return l
+}
+
+func (c *ConfirmedSubscribeCOVProperty) SetLength() {
+	c.BVLC.Length = uint16(c.MarshalLen())
+}
+
+func (c *ConfirmedSubscribeCOVProperty) Decode() (ConfirmedSubscribeCOVPropertyDec, error) {
+	decSCOV := ConfirmedSubscribeCOVPropertyDec{}
+
+	if len(c.APDU.Objects) < 5 {
+		return decSCOV, common.ErrWrongObjectCount
+	}
+
+	if !objects.IsContextTag(c.APDU.Objects[0], 0) || !objects.IsContextTag(c.APDU.Objects[1], 1) {
+		return decSCOV, common.ErrWrongStructure
+	}
+
+	processId, err := objects.DecUnisgnedInteger(c.APDU.Objects[0])
+	if err != nil {
+		return decSCOV, err
+	}
+	decSCOV.ProcessId = processId
+
+	objId, err := objects.DecObjectIdentifier(c.APDU.Objects[1])
+	if err != nil {
+		return decSCOV, err
+	}
+	decSCOV.ObjectType = objId.ObjectType
+	decSCOV.InstanceId = objId.InstanceNumber
+
+	decSCOV.Cancel = true
+
+	offset := 2
+	if objects.IsContextTag(c.APDU.Objects[offset], 2) {
+		if decSCOV.Confirmed, err = objects.DecBoolean(c.APDU.Objects[offset]); err != nil {
+			return decSCOV, err
+		}
+		decSCOV.Cancel = false
+		offset++
+	}
+	if offset < len(c.APDU.Objects) && objects.IsContextTag(c.APDU.Objects[offset], 3) {
+		if decSCOV.Lifetime, err = objects.DecUnisgnedInteger(c.APDU.Objects[offset]); err != nil {
+			return decSCOV, err
+		}
+		decSCOV.Cancel = false
+		offset++
+	}
+
+	enclosed, offset, err := objects.DecEnclosed(c.APDU.Objects, offset, 4)
+	if err != nil {
+		return decSCOV, err
+	}
+	property, next, err := DecPropertyReference(enclosed, 0)
+	if err != nil {
+		return decSCOV, err
+	}
+	if next != len(enclosed) {
+		return decSCOV, common.ErrWrongStructure
+	}
+	decSCOV.Property = property
+
+	if offset < len(c.APDU.Objects) && objects.IsContextTag(c.APDU.Objects[offset], 5) {
+		increment, err := objects.DecReal(c.APDU.Objects[offset])
+		if err != nil {
+			return decSCOV, err
+		}
+		decSCOV.Increment = &increment
+		offset++
+	}
+
+	if offset != len(c.APDU.Objects) {
+		return decSCOV, common.ErrWrongStructure
+	}
+
+	return decSCOV, nil
+}
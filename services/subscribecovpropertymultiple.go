@@ -0,0 +1,281 @@
+package services
+
+import (
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+)
+
+// ConfirmedSubscribeCOVPropertyMultiple is a BACnet message. It's acknowledged with a SimpleACK.
+type ConfirmedSubscribeCOVPropertyMultiple struct {
+	*plumbing.BVLC
+	*plumbing.NPDU
+	*plumbing.APDU
+}
+
+// COVReference is a property monitored by a SubscribeCOVPropertyMultiple subscription.
+type COVReference struct {
+	Property PropertyReference
+	// Increment is nil when the subscriber relies on the COV_Increment of the object.
+	Increment *float32
+	// Timestamped asks for the time of change to be notified along with the value.
+	Timestamped bool
+}
+
+// COVSubscriptionSpecification lists the properties of an object a
+// SubscribeCOVPropertyMultiple subscription monitors.
+type COVSubscriptionSpecification struct {
+	ObjectType uint16
+	InstanceId uint32
+	References []COVReference
+}
+
+type ConfirmedSubscribeCOVPropertyMultipleDec struct {
+	ProcessId uint32
+	// Cancel is set when both the confirmed flag and the lifetime were left out.
+	Cancel    bool
+	Confirmed bool
+	// Lifetime is in seconds, with 0 meaning an indefinite subscription.
+	Lifetime uint32
+	// MaxNotificationDelay is the number of seconds changes may be held back so as to be
+	// notified together.
+	MaxNotificationDelay uint32
+	Specifications       []COVSubscriptionSpecification
+}
+
+// ConfirmedSubscribeCOVPropertyMultipleObjects creates the SubscribeCOVPropertyMultiple
+// request objects subscribing to the given properties for lifetime seconds.
+func ConfirmedSubscribeCOVPropertyMultipleObjects(processId uint32, confirmed bool, lifetime uint32, maxNotificationDelay uint32, specs []COVSubscriptionSpecification) []objects.APDUPayload {
+	objs := make([]objects.APDUPayload, 0, 6)
+
+	objs = append(objs, objects.EncContext(0, objects.EncUnsignedInteger(processId)))
+	objs = append(objs, objects.EncContext(1, objects.EncBoolean(confirmed)))
+	objs = append(objs, objects.EncContext(2, objects.EncUnsignedInteger(lifetime)))
+	objs = append(objs, objects.EncContext(3, objects.EncUnsignedInteger(maxNotificationDelay)))
+	objs = append(objs, encCOVSubscriptionSpecifications(specs)...)
+
+	return objs
+}
+
+// ConfirmedSubscribeCOVPropertyMultipleCancelObjects creates the SubscribeCOVPropertyMultiple
+// request objects cancelling the subscriptions to the given properties.
+func ConfirmedSubscribeCOVPropertyMultipleCancelObjects(processId uint32, specs []COVSubscriptionSpecification) []objects.APDUPayload {
+	objs := make([]objects.APDUPayload, 0, 3)
+
+	objs = append(objs, objects.EncContext(0, objects.EncUnsignedInteger(processId)))
+	objs = append(objs, encCOVSubscriptionSpecifications(specs)...)
+
+	return objs
+}
+
+func encCOVSubscriptionSpecifications(specs []COVSubscriptionSpecification) []objects.APDUPayload {
+	objs := []objects.APDUPayload{}
+
+	for _, spec := range specs {
+		objs = append(objs, objects.EncObjectIdentifier(true, 0, spec.ObjectType, spec.InstanceId))
+
+		refs := []objects.APDUPayload{}
+		for _, ref := range spec.References {
+			refs = append(refs, objects.EncEnclosed(0, EncPropertyReference(ref.Property)...)...)
+			if ref.Increment != nil {
+				refs = append(refs, objects.EncContext(1, objects.EncReal(*ref.Increment)))
+			}
+			refs = append(refs, objects.EncContext(2, objects.EncBoolean(ref.Timestamped)))
+		}
+		objs = append(objs, objects.EncEnclosed(1, refs...)...)
+	}
+
+	return objects.EncEnclosed(4, objs...)
+}
+
+func NewConfirmedSubscribeCOVPropertyMultiple(bvlc *plumbing.BVLC, npdu *plumbing.NPDU) *ConfirmedSubscribeCOVPropertyMultiple {
+	c := &ConfirmedSubscribeCOVPropertyMultiple{
+		BVLC: bvlc,
+		NPDU: npdu,
+		APDU: plumbing.NewAPDU(plumbing.ConfirmedReq, ServiceConfirmedSubscribeCOVPropertyMultiple, nil),
+	}
+	c.SetLength()
+
+	return c
+}
+
+func (c *ConfirmedSubscribeCOVPropertyMultiple) UnmarshalBinary(b []byte) error {
+	if l := len(b); l < c.MarshalLen() {
+		return common.ErrTooShortToParse
+	}
+
+	var offset int = 0
+	if err := c.BVLC.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+
+	return nil
+}
+
+func (c *ConfirmedSubscribeCOVPropertyMultiple) MarshalBinary() ([]byte, error) {
+	b := make([]byte, c.MarshalLen())
+	if err := c.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (c *ConfirmedSubscribeCOVPropertyMultiple) MarshalTo(b []byte) error {
+	if len(b) < c.MarshalLen() {
+		return common.ErrTooShortToMarshalBinary
+	}
+	var offset = 0
+	if err := c.BVLC.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *ConfirmedSubscribeCOVPropertyMultiple) MarshalLen() int {
+	l := c.BVLC.MarshalLen()
+	l += c.NPDU.MarshalLen()
+	l += c.APDU.MarshalLen()
+
+	return l
+}
+
+func (c *ConfirmedSubscribeCOVPropertyMultiple) SetLength() {
+	c.BVLC.Length = uint16(c.MarshalLen())
+}
+
+func (c *ConfirmedSubscribeCOVPropertyMultiple) Decode() (ConfirmedSubscribeCOVPropertyMultipleDec, error) {
+	decSCOV := ConfirmedSubscribeCOVPropertyMultipleDec{}
+
+	if len(c.APDU.Objects) < 3 {
+		return decSCOV, common.ErrWrongObjectCount
+	}
+
+	if !objects.IsContextTag(c.APDU.Objects[0], 0) {
+		return decSCOV, common.ErrWrongStructure
+	}
+
+	processId, err := objects.DecUnisgnedInteger(c.APDU.Objects[0])
+	if err != nil {
+		return decSCOV, err
+	}
+	decSCOV.ProcessId = processId
+
+	decSCOV.Cancel = true
+
+	offset := 1
+	if objects.IsContextTag(c.APDU.Objects[offset], 1) {
+		if decSCOV.Confirmed, err = objects.DecBoolean(c.APDU.Objects[offset]); err != nil {
+			return decSCOV, err
+		}
+		decSCOV.Cancel = false
+		offset++
+	}
+	if offset < len(c.APDU.Objects) && objects.IsContextTag(c.APDU.Objects[offset], 2) {
+		if decSCOV.Lifetime, err = objects.DecUnisgnedInteger(c.APDU.Objects[offset]); err != nil {
+			return decSCOV, err
+		}
+		decSCOV.Cancel = false
+		offset++
+	}
+	if offset < len(c.APDU.Objects) && objects.IsContextTag(c.APDU.Objects[offset], 3) {
+		if decSCOV.MaxNotificationDelay, err = objects.DecUnisgnedInteger(c.APDU.Objects[offset]); err != nil {
+			return decSCOV, err
+		}
+		offset++
+	}
+
+	specs, offset, err := decCOVSubscriptionSpecifications(c.APDU.Objects, offset)
+	if err != nil {
+		return decSCOV, err
+	}
+	decSCOV.Specifications = specs
+
+	if offset != len(c.APDU.Objects) {
+		return decSCOV, common.ErrWrongStructure
+	}
+
+	return decSCOV, nil
+}
+
+func decCOVSubscriptionSpecifications(rawPayloads []objects.APDUPayload, i int) ([]COVSubscriptionSpecification, int, error) {
+	enclosed, next, err := objects.DecEnclosed(rawPayloads, i, 4)
+	if err != nil {
+		return nil, i, err
+	}
+
+	specs := []COVSubscriptionSpecification{}
+	for offset := 0; offset < len(enclosed); {
+		if !objects.IsContextTag(enclosed[offset], 0) {
+			return nil, i, common.ErrWrongStructure
+		}
+		objId, err := objects.DecObjectIdentifier(enclosed[offset])
+		if err != nil {
+			return nil, i, err
+		}
+		spec := COVSubscriptionSpecification{ObjectType: objId.ObjectType, InstanceId: objId.InstanceNumber}
+
+		var rawRefs []objects.APDUPayload
+		if rawRefs, offset, err = objects.DecEnclosed(enclosed, offset+1, 1); err != nil {
+			return nil, i, err
+		}
+
+		for j := 0; j < len(rawRefs); {
+			var rawProperty []objects.APDUPayload
+			if rawProperty, j, err = objects.DecEnclosed(rawRefs, j, 0); err != nil {
+				return nil, i, err
+			}
+			property, end, err := DecPropertyReference(rawProperty, 0)
+			if err != nil {
+				return nil, i, err
+			}
+			if end != len(rawProperty) {
+				return nil, i, common.ErrWrongStructure
+			}
+			ref := COVReference{Property: property}
+
+			if j < len(rawRefs) && objects.IsContextTag(rawRefs[j], 1) {
+				increment, err := objects.DecReal(rawRefs[j])
+				if err != nil {
+					return nil, i, err
+				}
+				ref.Increment = &increment
+				j++
+			}
+
+			if j >= len(rawRefs) || !objects.IsContextTag(rawRefs[j], 2) {
+				return nil, i, common.ErrWrongStructure
+			}
+			if ref.Timestamped, err = objects.DecBoolean(rawRefs[j]); err != nil {
+				return nil, i, err
+			}
+			j++
+
+			spec.References = append(spec.References, ref)
+		}
+
+		specs = append(specs, spec)
+	}
+
+	return specs, next, nil
+}
@@ -0,0 +1,70 @@
+package services
+
+import (
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+)
+
+// Choices of BACnetTimeStamps.
+const (
+	TimeStampTime uint8 = iota
+	TimeStampSequence
+	TimeStampDateTime
+)
+
+// TimeStamp is a BACnetTimeStamp: a time, a sequence number or a date and time, as told by
+// Choice.
+type TimeStamp struct {
+	Choice   uint8
+	Time     objects.Time
+	Sequence uint32
+	DateTime objects.DateTime
+}
+
+// EncTimeStamp encodes a BACnetTimeStamp without enclosing it.
+func EncTimeStamp(ts TimeStamp) []objects.APDUPayload {
+	switch ts.Choice {
+	case TimeStampTime:
+		return []objects.APDUPayload{objects.EncContext(0, objects.EncTime(ts.Time))}
+	case TimeStampSequence:
+		return []objects.APDUPayload{objects.EncContext(1, objects.EncUnsignedInteger(ts.Sequence))}
+	}
+	return objects.EncEnclosed(2, objects.EncDateTime(ts.DateTime)...)
+}
+
+// DecTimeStamp decodes the BACnetTimeStamp starting at rawPayloads[i]. It returns the index
+// of the first payload past the time stamp.
+func DecTimeStamp(rawPayloads []objects.APDUPayload, i int) (TimeStamp, int, error) {
+	ts := TimeStamp{}
+
+	if i >= len(rawPayloads) {
+		return ts, i, common.ErrWrongStructure
+	}
+
+	var err error
+	switch {
+	case objects.IsContextTag(rawPayloads[i], 0):
+		ts.Choice = TimeStampTime
+		ts.Time, err = objects.DecTime(rawPayloads[i])
+	case objects.IsContextTag(rawPayloads[i], 1):
+		ts.Choice = TimeStampSequence
+		ts.Sequence, err = objects.DecUnisgnedInteger(rawPayloads[i])
+	case objects.IsOpeningTag(rawPayloads[i], 2):
+		enclosed, next, err := objects.DecEnclosed(rawPayloads, i, 2)
+		if err != nil {
+			return ts, i, err
+		}
+		ts.Choice = TimeStampDateTime
+		if ts.DateTime, err = objects.DecDateTime(enclosed); err != nil {
+			return ts, i, err
+		}
+		return ts, next, nil
+	default:
+		return ts, i, common.ErrWrongStructure
+	}
+	if err != nil {
+		return ts, i, err
+	}
+
+	return ts, i + 1, nil
+}
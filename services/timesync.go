@@ -0,0 +1,199 @@
+package services
+
+import (
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+)
+
+// UnconfirmedTimeSynchronization is a BACnet message carrying the local date and time.
+type UnconfirmedTimeSynchronization struct {
+	*plumbing.BVLC
+	*plumbing.NPDU
+	*plumbing.APDU
+}
+
+// UnconfirmedUTCTimeSynchronization is a BACnet message carrying the UTC date and time.
+type UnconfirmedUTCTimeSynchronization struct {
+	*plumbing.BVLC
+	*plumbing.NPDU
+	*plumbing.APDU
+}
+
+type TimeSynchronizationDec struct {
+	DateTime objects.DateTime
+}
+
+// TimeSynchronizationObjects creates the objects of both UnconfirmedTimeSynchronization and
+// UnconfirmedUTCTimeSynchronization.
+func TimeSynchronizationObjects(dateTime objects.DateTime) []objects.APDUPayload {
+	return objects.EncDateTime(dateTime)
+}
+
+func NewUnconfirmedTimeSynchronization(bvlc *plumbing.BVLC, npdu *plumbing.NPDU) *UnconfirmedTimeSynchronization {
+	u := &UnconfirmedTimeSynchronization{
+		BVLC: bvlc,
+		NPDU: npdu,
+		APDU: plumbing.NewAPDU(plumbing.UnConfirmedReq, ServiceUnconfirmedTimeSync, nil),
+	}
+	u.SetLength()
+
+	return u
+}
+
+func NewUnconfirmedUTCTimeSynchronization(bvlc *plumbing.BVLC, npdu *plumbing.NPDU) *UnconfirmedUTCTimeSynchronization {
+	u := &UnconfirmedUTCTimeSynchronization{
+		BVLC: bvlc,
+		NPDU: npdu,
+		APDU: plumbing.NewAPDU(plumbing.UnConfirmedReq, ServiceUnconfirmedUTCTimeSync, nil),
+	}
+	u.SetLength()
+
+	return u
+}
+
+func (u *UnconfirmedTimeSynchronization) UnmarshalBinary(b []byte) error {
+	if l := len(b); l < u.MarshalLen() {
+		return common.ErrTooShortToParse
+	}
+
+	var offset int = 0
+	if err := u.BVLC.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += u.BVLC.MarshalLen()
+
+	if err := u.NPDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += u.NPDU.MarshalLen()
+
+	if err := u.APDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+
+	return nil
+}
+
+func (u *UnconfirmedTimeSynchronization) MarshalBinary() ([]byte, error) {
+	b := make([]byte, u.MarshalLen())
+	if err := u.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (u *UnconfirmedTimeSynchronization) MarshalTo(b []byte) error {
+	if len(b) < u.MarshalLen() {
+		return common.ErrTooShortToMarshalBinary
+	}
+	var offset = 0
+	if err := u.BVLC.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += u.BVLC.MarshalLen()
+
+	if err := u.NPDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += u.NPDU.MarshalLen()
+
+	if err := u.APDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u *UnconfirmedTimeSynchronization) MarshalLen() int {
+	l := u.BVLC.MarshalLen()
+	l += u.NPDU.MarshalLen()
+	l += u.APDU.MarshalLen()
+
+	return l
+}
+
+func (u *UnconfirmedTimeSynchronization) SetLength() {
+	u.BVLC.Length = uint16(u.MarshalLen())
+}
+
+func (u *UnconfirmedTimeSynchronization) Decode() (TimeSynchronizationDec, error) {
+	return decTimeSynchronization(u.APDU.Objects)
+}
+
+func (u *UnconfirmedUTCTimeSynchronization) UnmarshalBinary(b []byte) error {
+	if l := len(b); l < u.MarshalLen() {
+		return common.ErrTooShortToParse
+	}
+
+	var offset int = 0
+	if err := u.BVLC.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += u.BVLC.MarshalLen()
+
+	if err := u.NPDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += u.NPDU.MarshalLen()
+
+	if err := u.APDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+
+	return nil
+}
+
+func (u *UnconfirmedUTCTimeSynchronization) MarshalBinary() ([]byte, error) {
+	b := make([]byte, u.MarshalLen())
+	if err := u.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (u *UnconfirmedUTCTimeSynchronization) MarshalTo(b []byte) error {
+	if len(b) < u.MarshalLen() {
+		return common.ErrTooShortToMarshalBinary
+	}
+	var offset = 0
+	if err := u.BVLC.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += u.BVLC.MarshalLen()
+
+	if err := u.NPDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += u.NPDU.MarshalLen()
+
+	if err := u.APDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u *UnconfirmedUTCTimeSynchronization) MarshalLen() int {
+	l := u.BVLC.MarshalLen()
+	l += u.NPDU.MarshalLen()
+	l += u.APDU.MarshalLen()
+
+	return l
+}
+
+func (u *UnconfirmedUTCTimeSynchronization) SetLength() {
+	u.BVLC.Length = uint16(u.MarshalLen())
+}
+
+func (u *UnconfirmedUTCTimeSynchronization) Decode() (TimeSynchronizationDec, error) {
+	return decTimeSynchronization(u.APDU.Objects)
+}
+
+func decTimeSynchronization(rawPayloads []objects.APDUPayload) (TimeSynchronizationDec, error) {
+	dateTime, err := objects.DecDateTime(rawPayloads)
+	if err != nil {
+		return TimeSynchronizationDec{}, err
+	}
+	return TimeSynchronizationDec{DateTime: dateTime}, nil
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+)
+
+// UnconfirmedWhoHas is a BACnet message.
+type UnconfirmedWhoHas struct {
+	*plumbing.BVLC
+	*plumbing.NPDU
+	*plumbing.APDU
+}
+
+// UnconfirmedWhoHasDec holds the object a Who-Has looks for and the device instance range
+// it's meant for. Who-Has without range limits are decoded as ranging from 0 to
+// objects.MaxInstance.
+type UnconfirmedWhoHasDec struct {
+	LowLimit  uint32
+	HighLimit uint32
+	// ObjectName is empty when the object is looked for by its identifier.
+	ObjectName string
+	ObjectType uint16
+	InstanceId uint32
+}
+
+// Includes tells whether the device with the given instance falls within the range.
+func (d UnconfirmedWhoHasDec) Includes(instance uint32) bool {
+	return d.LowLimit <= instance && instance <= d.HighLimit
+}
+
+// WhoHasObjects creates the UnconfirmedWhoHas objects looking for an object by its
+// identifier. Range limits are left out when they span every instance.
+func WhoHasObjects(low, high uint32, objectType uint16, instN uint32) []objects.APDUPayload {
+	objs := encWhoHasLimits(low, high)
+	return append(objs, objects.EncObjectIdentifier(true, 2, objectType, instN))
+}
+
+// WhoHasNameObjects creates the UnconfirmedWhoHas objects looking for an object by its
+// name. Range limits are left out when they span every instance.
+func WhoHasNameObjects(low, high uint32, name string) []objects.APDUPayload {
+	objs := encWhoHasLimits(low, high)
+	return append(objs, objects.EncContext(3, objects.EncCharacterString(name)))
+}
+
+func encWhoHasLimits(low, high uint32) []objects.APDUPayload {
+	if low == 0 && high >= objects.MaxInstance {
+		return make([]objects.APDUPayload, 0, 1)
+	}
+
+	objs := make([]objects.APDUPayload, 2, 3)
+
+	objs[0] = objects.EncContext(0, objects.EncUnsignedInteger(low))
+	objs[1] = objects.EncContext(1, objects.EncUnsignedInteger(high))
+
+	return objs
+}
+
+func NewUnconfirmedWhoHas(bvlc *plumbing.BVLC, npdu *plumbing.NPDU) *UnconfirmedWhoHas {
+	u := &UnconfirmedWhoHas{
+		BVLC: bvlc,
+		NPDU: npdu,
+		APDU: plumbing.NewAPDU(plumbing.UnConfirmedReq, ServiceUnconfirmedWhoHas, nil),
+	}
+	u.SetLength()
+
+	return u
+}
+
+func (u *UnconfirmedWhoHas) UnmarshalBinary(b []byte) error {
+	if l := len(b); l < u.MarshalLen() {
+		return common.ErrTooShortToParse
+	}
+
+	var offset int = 0
+	if err := u.BVLC.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += u.BVLC.MarshalLen()
+
+	if err := u.NPDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += u.NPDU.MarshalLen()
+
+	if err := u.APDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+
+	return nil
+}
+
+func (u *UnconfirmedWhoHas) MarshalBinary() ([]byte, error) {
+	b := make([]byte, u.MarshalLen())
+	if err := u.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (u *UnconfirmedWhoHas) MarshalTo(b []byte) error {
+	if len(b) < u.MarshalLen() {
+		return common.ErrTooShortToMarshalBinary
+	}
+	var offset = 0
+	if err := u.BVLC.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += u.BVLC.MarshalLen()
+
+	if err := u.NPDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += u.NPDU.MarshalLen()
+
+	if err := u.APDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u *UnconfirmedWhoHas) MarshalLen() int {
+	l := u.BVLC.MarshalLen()
+	l += u.NPDU.MarshalLen()
+	l += u.APDU.MarshalLen()
+
+	return l
+}
+
+func (u *UnconfirmedWhoHas) SetLength() {
+	u.BVLC.Length = uint16(u.MarshalLen())
+}
+
+func (u *UnconfirmedWhoHas) Decode() (UnconfirmedWhoHasDec, error) {
+	decWhoHas := UnconfirmedWhoHasDec{LowLimit: 0, HighLimit: objects.MaxInstance}
+
+	if len(u.APDU.Objects) != 1 && len(u.APDU.Objects) != 3 {
+		return decWhoHas, common.ErrWrongObjectCount
+	}
+
+	if len(u.APDU.Objects) == 3 {
+		if !objects.IsContextTag(u.APDU.Objects[0], 0) || !objects.IsContextTag(u.APDU.Objects[1], 1) {
+			return decWhoHas, common.ErrWrongStructure
+		}
+
+		low, err := objects.DecUnisgnedInteger(u.APDU.Objects[0])
+		if err != nil {
+			return decWhoHas, err
+		}
+		high, err := objects.DecUnisgnedInteger(u.APDU.Objects[1])
+		if err != nil {
+			return decWhoHas, err
+		}
+		if low > objects.MaxInstance || high > objects.MaxInstance {
+			return decWhoHas, common.ErrTooBigValue
+		}
+		decWhoHas.LowLimit = low
+		decWhoHas.HighLimit = high
+	}
+
+	obj := u.APDU.Objects[len(u.APDU.Objects)-1]
+	switch {
+	case objects.IsContextTag(obj, 2):
+		objId, err := objects.DecObjectIdentifier(obj)
+		if err != nil {
+			return decWhoHas, err
+		}
+		decWhoHas.ObjectType = objId.ObjectType
+		decWhoHas.InstanceId = objId.InstanceNumber
+	case objects.IsContextTag(obj, 3):
+		name, err := objects.DecCharacterString(obj)
+		if err != nil {
+			return decWhoHas, err
+		}
+		if name == "" {
+			return decWhoHas, common.ErrWrongPayload
+		}
+		decWhoHas.ObjectName = name
+	default:
+		return decWhoHas, common.ErrWrongStructure
+	}
+
+	return decWhoHas, nil
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
 	"github.com/ulbios/bacnet/plumbing"
 )
 
@@ -12,6 +13,29 @@ type UnconfirmedWhoIs struct {
 	*plumbing.APDU
 }
 
+// UnconfirmedWhoIsDec holds the device instance range a Who-Is is meant for. Who-Is
+// without range limits are decoded as ranging from 0 to objects.MaxInstance.
+type UnconfirmedWhoIsDec struct {
+	LowLimit  uint32
+	HighLimit uint32
+}
+
+// Includes tells whether the device with the given instance falls within the range.
+func (d UnconfirmedWhoIsDec) Includes(instance uint32) bool {
+	return d.LowLimit <= instance && instance <= d.HighLimit
+}
+
+// WhoIsObjects creates the UnconfirmedWhoIs objects limiting the request to the devices
+// whose instance falls between low and high, both included.
+func WhoIsObjects(low, high uint32) []objects.APDUPayload {
+	objs := make([]objects.APDUPayload, 2)
+
+	objs[0] = objects.EncContext(0, objects.EncUnsignedInteger(low))
+	objs[1] = objects.EncContext(1, objects.EncUnsignedInteger(high))
+
+	return objs
+}
+
 // NewUnconfirmedWhoIs creates a UnconfirmedWhoIs.
 func NewUnconfirmedWhoIs(bvlc *plumbing.BVLC, npdu *plumbing.NPDU) *UnconfirmedWhoIs {
 	u := &UnconfirmedWhoIs{
@@ -92,3 +116,34 @@ func (u *UnconfirmedWhoIs) MarshalLen() int {
 func (u *UnconfirmedWhoIs) SetLength() {
 	u.BVLC.Length = uint16(u.MarshalLen())
 }
+
+func (u *UnconfirmedWhoIs) Decode() (UnconfirmedWhoIsDec, error) {
+	decWhoIs := UnconfirmedWhoIsDec{LowLimit: 0, HighLimit: objects.MaxInstance}
+
+	if len(u.APDU.Objects) == 0 {
+		return decWhoIs, nil
+	}
+	if len(u.APDU.Objects) != 2 {
+		return decWhoIs, common.ErrWrongObjectCount
+	}
+
+	if !objects.IsContextTag(u.APDU.Objects[0], 0) || !objects.IsContextTag(u.APDU.Objects[1], 1) {
+		return decWhoIs, common.ErrWrongStructure
+	}
+
+	low, err := objects.DecUnisgnedInteger(u.APDU.Objects[0])
+	if err != nil {
+		return decWhoIs, err
+	}
+	high, err := objects.DecUnisgnedInteger(u.APDU.Objects[1])
+	if err != nil {
+		return decWhoIs, err
+	}
+	if low > objects.MaxInstance || high > objects.MaxInstance {
+		return decWhoIs, common.ErrTooBigValue
+	}
+	decWhoIs.LowLimit = low
+	decWhoIs.HighLimit = high
+
+	return decWhoIs, nil
+}
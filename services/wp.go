@@ -6,7 +6,7 @@ import (
 	"github.com/ulbios/bacnet/plumbing"
 )
 
-// UnconfirmedIAm is a BACnet message.
+// ConfirmedWriteProperty is a BACnet message.
 type ConfirmedWriteProperty struct {
 	*plumbing.BVLC
 	*plumbing.NPDU
@@ -16,21 +16,27 @@ type ConfirmedWriteProperty struct {
 type ConfirmedWritePropertyDec struct {
 	ObjectType uint16
 	InstanceId uint32
-	PropertyId uint8
+	PropertyId uint32
+	ArrayIndex uint32
 	Value      float32
+	Values     []objects.APDUPayload
 	Priority   uint8
 }
 
-func ConfirmedWritePropertyObjects(objectType uint16, instN uint32, propertyId uint8, value float32) []objects.APDUPayload {
-	objs := make([]objects.APDUPayload, 7)
+// ConfirmedWritePropertyObjects creates the WriteProperty request objects. Pass objects.ArrayAll
+// as the arrayIndex and 0 as the priority to leave them out.
+func ConfirmedWritePropertyObjects(objectType uint16, instN uint32, propertyId uint32, arrayIndex uint32, values []objects.APDUPayload, priority uint8) []objects.APDUPayload {
+	objs := make([]objects.APDUPayload, 0, 6+len(values))
 
-	objs[0] = objects.EncObjectIdentifier(true, 0, objectType, instN)
-	objs[1] = objects.EncPropertyIdentifier(true, 1, propertyId)
-	objs[2] = objects.EncOpeningTag(3)
-	objs[3] = objects.EncReal(value)
-	objs[4] = objects.EncNull()
-	objs[5] = objects.EncClosingTag(3)
-	objs[6] = objects.EncPriority(true, 4, 16)
+	objs = append(objs, objects.EncObjectIdentifier(true, 0, objectType, instN))
+	objs = append(objs, objects.EncPropertyIdentifier(true, 1, propertyId))
+	if arrayIndex != objects.ArrayAll {
+		objs = append(objs, objects.EncArrayIndex(2, arrayIndex))
+	}
+	objs = append(objs, objects.EncEnclosed(3, values...)...)
+	if priority != 0 {
+		objs = append(objs, objects.EncPriority(true, 4, priority))
+	}
 
 	return objs
 }
@@ -112,41 +118,62 @@ func (c *ConfirmedWriteProperty) SetLength() {
 }
 
 func (c *ConfirmedWriteProperty) Decode() (ConfirmedWritePropertyDec, error) {
-	decCWP := ConfirmedWritePropertyDec{}
+	decCWP := ConfirmedWritePropertyDec{ArrayIndex: objects.ArrayAll}
 
-	if len(c.APDU.Objects) != 5 {
+	if len(c.APDU.Objects) < 4 {
 		return decCWP, common.ErrWrongObjectCount
 	}
 
-	for i, obj := range c.APDU.Objects {
-		switch i {
-		case 0:
-			objId, err := objects.DecObjectIdentifier(obj)
-			if err != nil {
-				return decCWP, err
-			}
-			decCWP.ObjectType = objId.ObjectType
-			decCWP.InstanceId = objId.InstanceNumber
-		case 1:
-			propId, err := objects.DecPropertyIdentifier(obj)
-			if err != nil {
-				return decCWP, err
-			}
-			decCWP.PropertyId = propId
-		case 2:
-			value, err := objects.DecReal(obj)
-			if err != nil {
-				return decCWP, err
-			}
+	objId, err := objects.DecObjectIdentifier(c.APDU.Objects[0])
+	if err != nil {
+		return decCWP, err
+	}
+	decCWP.ObjectType = objId.ObjectType
+	decCWP.InstanceId = objId.InstanceNumber
+
+	propId, err := objects.DecPropertyIdentifier(c.APDU.Objects[1])
+	if err != nil {
+		return decCWP, err
+	}
+	decCWP.PropertyId = propId
+
+	offset := 2
+	if objects.IsContextTag(c.APDU.Objects[offset], 2) {
+		arrayIndex, err := objects.DecArrayIndex(c.APDU.Objects[offset])
+		if err != nil {
+			return decCWP, err
+		}
+		decCWP.ArrayIndex = arrayIndex
+		offset++
+	}
+
+	values, offset, err := objects.DecEnclosed(c.APDU.Objects, offset, 3)
+	if err != nil {
+		return decCWP, err
+	}
+	decCWP.Values = values
+
+	if len(values) > 0 {
+		if value, err := objects.DecReal(values[0]); err == nil {
 			decCWP.Value = value
-		case 4:
-			priority, err := objects.DecPriority(obj)
-			if err != nil {
-				return decCWP, err
-			}
-			decCWP.Priority = priority
 		}
 	}
 
+	if offset < len(c.APDU.Objects) {
+		if !objects.IsContextTag(c.APDU.Objects[offset], 4) {
+			return decCWP, common.ErrWrongStructure
+		}
+		priority, err := objects.DecPriority(c.APDU.Objects[offset])
+		if err != nil {
+			return decCWP, err
+		}
+		decCWP.Priority = priority
+		offset++
+	}
+
+	if offset != len(c.APDU.Objects) {
+		return decCWP, common.ErrWrongObjectCount
+	}
+
 	return decCWP, nil
 }
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+)
+
+// ConfirmedWritePropertyMultiple is a BACnet message. It's acknowledged with a SimpleACK or
+// answered with an Error decoded by Error.DecodeWritePropertyMultiple.
+type ConfirmedWritePropertyMultiple struct {
+	*plumbing.BVLC
+	*plumbing.NPDU
+	*plumbing.APDU
+}
+
+// WriteAccessSpecification lists the properties of an object to be written.
+type WriteAccessSpecification struct {
+	ObjectType uint16
+	InstanceId uint32
+	Values     []PropertyValue
+}
+
+type ConfirmedWritePropertyMultipleDec struct {
+	Specifications []WriteAccessSpecification
+}
+
+// WritePropertyMultipleErrorDec is a WritePropertyMultiple-Error: the error the first failed
+// write ran into and the property it targeted.
+type WritePropertyMultipleErrorDec struct {
+	ErrorClass uint8
+	ErrorCode  uint8
+	ObjectType uint16
+	InstanceId uint32
+	PropertyId uint32
+	ArrayIndex uint32
+}
+
+// ConfirmedWritePropertyMultipleObjects creates the WritePropertyMultiple request objects.
+func ConfirmedWritePropertyMultipleObjects(specs []WriteAccessSpecification) []objects.APDUPayload {
+	objs := []objects.APDUPayload{}
+
+	for _, spec := range specs {
+		objs = append(objs, objects.EncObjectIdentifier(true, 0, spec.ObjectType, spec.InstanceId))
+		objs = append(objs, EncPropertyValues(1, spec.Values)...)
+	}
+
+	return objs
+}
+
+// WritePropertyMultipleErrorObjects creates the WritePropertyMultiple-Error objects. Pass
+// objects.ArrayAll as the arrayIndex to leave it out.
+func WritePropertyMultipleErrorObjects(errClass, errCode uint8, objectType uint16, instN uint32, propertyId uint32, arrayIndex uint32) []objects.APDUPayload {
+	objs := objects.EncEnclosed(0, ErrorObjects(errClass, errCode)...)
+
+	ref := []objects.APDUPayload{
+		objects.EncObjectIdentifier(true, 0, objectType, instN),
+		objects.EncPropertyIdentifier(true, 1, propertyId),
+	}
+	if arrayIndex != objects.ArrayAll {
+		ref = append(ref, objects.EncArrayIndex(2, arrayIndex))
+	}
+
+	return append(objs, objects.EncEnclosed(1, ref...)...)
+}
+
+func NewConfirmedWritePropertyMultiple(bvlc *plumbing.BVLC, npdu *plumbing.NPDU) *ConfirmedWritePropertyMultiple {
+	c := &ConfirmedWritePropertyMultiple{
+		BVLC: bvlc,
+		NPDU: npdu,
+		APDU: plumbing.NewAPDU(plumbing.ConfirmedReq, ServiceConfirmedWritePropMultiple, nil),
+	}
+	c.SetLength()
+
+	return c
+}
+
+func (c *ConfirmedWritePropertyMultiple) UnmarshalBinary(b []byte) error {
+	if l := len(b); l < c.MarshalLen() {
+		return common.ErrTooShortToParse
+	}
+
+	var offset int = 0
+	if err := c.BVLC.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.UnmarshalBinary(b[offset:]); err != nil {
+		return common.ErrTooShortToParse
+	}
+
+	return nil
+}
+
+func (c *ConfirmedWritePropertyMultiple) MarshalBinary() ([]byte, error) {
+	b := make([]byte, c.MarshalLen())
+	if err := c.MarshalTo(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (c *ConfirmedWritePropertyMultiple) MarshalTo(b []byte) error {
+	if len(b) < c.MarshalLen() {
+		return common.ErrTooShortToMarshalBinary
+	}
+	var offset = 0
+	if err := c.BVLC.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.BVLC.MarshalLen()
+
+	if err := c.NPDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+	offset += c.NPDU.MarshalLen()
+
+	if err := c.APDU.MarshalTo(b[offset:]); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c *ConfirmedWritePropertyMultiple) MarshalLen() int {
+	l := c.BVLC.MarshalLen()
+	l += c.NPDU.MarshalLen()
+	l += c.APDU.MarshalLen()
+
+	return l
+}
+
+func (c *ConfirmedWritePropertyMultiple) SetLength() {
+	c.BVLC.Length = uint16(c.MarshalLen())
+}
+
+func (c *ConfirmedWritePropertyMultiple) Decode() (ConfirmedWritePropertyMultipleDec, error) {
+	decWPM := ConfirmedWritePropertyMultipleDec{}
+
+	if len(c.APDU.Objects) < 3 {
+		return decWPM, common.ErrWrongObjectCount
+	}
+
+	for offset := 0; offset < len(c.APDU.Objects); {
+		if !objects.IsContextTag(c.APDU.Objects[offset], 0) {
+			return decWPM, common.ErrWrongStructure
+		}
+		objId, err := objects.DecObjectIdentifier(c.APDU.Objects[offset])
+		if err != nil {
+			return decWPM, err
+		}
+		spec := WriteAccessSpecification{ObjectType: objId.ObjectType, InstanceId: objId.InstanceNumber}
+
+		if spec.Values, offset, err = DecPropertyValues(c.APDU.Objects, offset+1, 1); err != nil {
+			return decWPM, err
+		}
+		if len(spec.Values) == 0 {
+			return decWPM, common.ErrWrongObjectCount
+		}
+
+		decWPM.Specifications = append(decWPM.Specifications, spec)
+	}
+
+	return decWPM, nil
+}
+
+// DecodeWritePropertyMultiple decodes the objects of a WritePropertyMultiple-Error.
+func (e *Error) DecodeWritePropertyMultiple() (WritePropertyMultipleErrorDec, error) {
+	decErr := WritePropertyMultipleErrorDec{ArrayIndex: objects.ArrayAll}
+
+	rawErr, offset, err := objects.DecEnclosed(e.APDU.Objects, 0, 0)
+	if err != nil {
+		return decErr, err
+	}
+	bErr, err := decBACnetError(rawErr)
+	if err != nil {
+		return decErr, err
+	}
+	decErr.ErrorClass = bErr.Class
+	decErr.ErrorCode = bErr.Code
+
+	ref, offset, err := objects.DecEnclosed(e.APDU.Objects, offset, 1)
+	if err != nil {
+		return decErr, err
+	}
+	if offset != len(e.APDU.Objects) {
+		return decErr, common.ErrWrongObjectCount
+	}
+
+	if len(ref) < 2 || !objects.IsContextTag(ref[0], 0) || !objects.IsContextTag(ref[1], 1) {
+		return decErr, common.ErrWrongStructure
+	}
+	objId, err := objects.DecObjectIdentifier(ref[0])
+	if err != nil {
+		return decErr, err
+	}
+	decErr.ObjectType = objId.ObjectType
+	decErr.InstanceId = objId.InstanceNumber
+
+	if decErr.PropertyId, err = objects.DecPropertyIdentifier(ref[1]); err != nil {
+		return decErr, err
+	}
+
+	switch {
+	case len(ref) == 3 && objects.IsContextTag(ref[2], 2):
+		if decErr.ArrayIndex, err = objects.DecArrayIndex(ref[2]); err != nil {
+			return decErr, err
+		}
+	case len(ref) != 2:
+		return decErr, common.ErrWrongStructure
+	}
+
+	return decErr, nil
+}
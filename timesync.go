@@ -0,0 +1,101 @@
+package bacnet
+
+import (
+	"context"
+	"net"
+	"time"
+
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+	"github.com/ulbios/bacnet/services"
+)
+
+// TimeMaster distributes the time to a list of recipients, either on demand or periodically.
+// Configure it before calling Run.
+type TimeMaster struct {
+	// Broadcast synchronizes every device on the local network at once.
+	Broadcast bool
+	// Recipients are the addresses synchronizations are sent to.
+	Recipients []net.Addr
+	// Devices are the instances of devices bound in the Devices of the Client
+	// synchronizations are sent to as well.
+	Devices []uint32
+	// UTC sends UTCTimeSynchronization rather than TimeSynchronization.
+	UTC bool
+	// Interval between the synchronizations sent by Run.
+	Interval time.Duration
+	// Clock tells the time to distribute, being time.Now if nil.
+	Clock func() time.Time
+	// Location is the time zone of the local time sent by TimeSynchronization, being
+	// time.Local if nil.
+	Location *time.Location
+
+	client *Client
+}
+
+// NewTimeMaster creates a TimeMaster sending synchronizations through c every hour.
+func NewTimeMaster(c *Client) *TimeMaster {
+	return &TimeMaster{Interval: time.Hour, client: c}
+}
+
+// Sync sends a synchronization to every recipient right away. It carries on when sending to
+// one of them fails, returning the first error.
+func (m *TimeMaster) Sync() error {
+	var err error
+	if m.Broadcast {
+		err = m.client.Broadcast(m.message())
+	}
+	for _, addr := range m.Recipients {
+		if sErr := m.client.Send(addr, m.message()); sErr != nil && err == nil {
+			err = sErr
+		}
+	}
+	for _, deviceId := range m.Devices {
+		if sErr := m.client.SendDevice(deviceId, m.message()); sErr != nil && err == nil {
+			err = sErr
+		}
+	}
+	return err
+}
+
+// Run sends a synchronization every Interval, starting right away, until ctx is done.
+func (m *TimeMaster) Run(ctx context.Context) error {
+	ticker := time.NewTicker(m.Interval)
+	defer ticker.Stop()
+
+	for {
+		m.Sync()
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// message creates the synchronization carrying the current time.
+func (m *TimeMaster) message() plumbing.BACnet {
+	now := time.Now()
+	if m.Clock != nil {
+		now = m.Clock()
+	}
+
+	bvlc := plumbing.NewBVLC(plumbing.BVLCFuncUnicast)
+	npdu := plumbing.NewNPDU(false, false, false, false)
+
+	if m.UTC {
+		now = now.UTC()
+		u := services.NewUnconfirmedUTCTimeSynchronization(bvlc, npdu)
+		u.APDU.Objects = services.TimeSynchronizationObjects(objects.DateTimeOf(now))
+		return u
+	}
+
+	loc := m.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	u := services.NewUnconfirmedTimeSynchronization(bvlc, npdu)
+	u.APDU.Objects = services.TimeSynchronizationObjects(objects.DateTimeOf(now.In(loc)))
+	return u
+}
@@ -0,0 +1,130 @@
+package bacnet
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ulbios/bacnet/common"
+	"github.com/ulbios/bacnet/device"
+	"github.com/ulbios/bacnet/objects"
+	"github.com/ulbios/bacnet/plumbing"
+	"github.com/ulbios/bacnet/services"
+)
+
+// IHave is an I-Have received from a device.
+type IHave struct {
+	Source net.Addr
+	services.UnconfirmedIHaveDec
+}
+
+// WhoHas broadcasts a Who-Has made of the objects created by services.WhoHasObjects or
+// services.WhoHasNameObjects and collects the I-Have answering it until wait elapses. A
+// single reply per device is kept. If ctx is done first, the replies collected so far are
+// returned along with its error.
+func (c *Client) WhoHas(ctx context.Context, objs []objects.APDUPayload, wait time.Duration) ([]IHave, error) {
+	req := services.NewUnconfirmedWhoHas(plumbing.NewBVLC(plumbing.BVLCFuncBroadcast), newGlobalBroadcastNPDU())
+	req.APDU.Objects = objs
+	req.SetLength()
+
+	query, err := req.Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	var mu sync.Mutex
+	replies := []IHave{}
+	seen := map[uint32]bool{}
+
+	stop := c.watch(services.ServiceUnconfirmedIHave, func(msg plumbing.BACnet, src net.Addr) {
+		dec, err := msg.(*services.UnconfirmedIHave).Decode()
+		if err != nil || !query.Includes(dec.DeviceId) {
+			return
+		}
+		if query.ObjectName != "" && dec.ObjectName != query.ObjectName {
+			return
+		}
+		if query.ObjectName == "" && (dec.ObjectType != query.ObjectType || dec.InstanceId != query.InstanceId) {
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		if !seen[dec.DeviceId] {
+			seen[dec.DeviceId] = true
+			replies = append(replies, IHave{Source: src, UnconfirmedIHaveDec: dec})
+		}
+	})
+	defer stop()
+
+	if err := c.Broadcast(req); err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-c.closed:
+		err = common.ErrClosed
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	return append([]IHave{}, replies...), err
+}
+
+// IHaveResponder answers the Who-Has requests looking for objects of a device with an I-Have.
+type IHaveResponder struct {
+	// MaxDelay bounds the random delay replies are held back for. Replies are sent right
+	// away when zero.
+	MaxDelay time.Duration
+
+	client *Client
+	device *device.Device
+}
+
+// NewIHaveResponder creates an IHaveResponder answering for dev through c. Register its
+// ServeWhoHas method as the Who-Has handler of c to put it to work.
+func NewIHaveResponder(c *Client, dev *device.Device) *IHaveResponder {
+	return &IHaveResponder{client: c, device: dev}
+}
+
+// Lookup returns the object a Who-Has looks for, or nil if the device doesn't fall within
+// its range or has no such object.
+func (r *IHaveResponder) Lookup(req services.UnconfirmedWhoHasDec) *device.Object {
+	if !req.Includes(r.device.Instance()) {
+		return nil
+	}
+
+	if req.ObjectName != "" {
+		return r.device.LookupName(req.ObjectName)
+	}
+	return r.device.Lookup(req.ObjectType, req.InstanceId)
+}
+
+// ServeWhoHas answers a Who-Has if the device has the object it looks for. I-Have are
+// globally broadcast as the standard requires.
+func (r *IHaveResponder) ServeWhoHas(msg plumbing.BACnet, src net.Addr) {
+	req, err := msg.(*services.UnconfirmedWhoHas).Decode()
+	if err != nil {
+		return
+	}
+
+	o := r.Lookup(req)
+	if o == nil {
+		return
+	}
+
+	u := services.NewUnconfirmedIHave(plumbing.NewBVLC(plumbing.BVLCFuncBroadcast), newGlobalBroadcastNPDU())
+	u.APDU.Objects = services.IHaveObjects(r.device.Instance(), o.Identifier.ObjectType, o.Identifier.InstanceNumber, o.Name())
+	u.SetLength()
+
+	after(r.MaxDelay, func() { r.client.Broadcast(u) })
+}